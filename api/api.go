@@ -57,6 +57,8 @@ type Api struct {
 
 	authSecret        string
 	authAnonymousRole rbac.RoleName
+	oidc              oidcProviderCache
+	apiKeyUsage       *db.ApiKeyUsageTracker
 
 	deploymentUuid string
 	instanceUuid   string
@@ -82,6 +84,7 @@ func NewApi(cfg *config.Config, cache *cache.Cache, db *db.DB, collector *collec
 		deploymentUuid:   deploymentUuid,
 		instanceUuid:     instanceUuid,
 		loadWorld:        loadWorld,
+		apiKeyUsage:      newApiKeyUsageTracker(db),
 	}
 }
 
@@ -110,6 +113,7 @@ func (api *Api) User(w http.ResponseWriter, r *http.Request, u *db.User) {
 			}
 			return
 		}
+		api.audit(u, "", db.AuditObjectUser, u.Email, db.AuditActionUpdate, nil, map[string]bool{"password_changed": true})
 		return
 	}
 
@@ -161,22 +165,31 @@ func (api *Api) Users(w http.ResponseWriter, r *http.Request, u *db.User) {
 				http.Error(w, "", http.StatusInternalServerError)
 				return
 			}
+			api.audit(u, "", db.AuditObjectUser, form.Email, db.AuditActionCreate, nil, newAuditUser(form.Email, form.Name, []rbac.RoleName{form.Role}))
 		case forms.UserActionUpdate:
 			if !form.Role.Valid(roles) {
 				http.Error(w, fmt.Sprintf("Unknown role: %s", form.Name), http.StatusBadRequest)
 				return
 			}
+			before := api.getAuditUser(form.Id)
 			if err := api.db.UpdateUser(form.Id, form.Email, form.Password, form.Name, form.Role); err != nil {
 				klog.Errorln(err)
 				http.Error(w, "", http.StatusInternalServerError)
 				return
 			}
+			after := newAuditUser(form.Email, form.Name, []rbac.RoleName{form.Role})
+			after.PasswordChanged = form.Password != ""
+			api.audit(u, "", db.AuditObjectUser, form.Email, db.AuditActionUpdate, before, after)
 		case forms.UserActionDelete:
+			before := api.getAuditUser(form.Id)
 			if err := api.db.DeleteUser(form.Id); err != nil {
 				klog.Errorln(err)
 				http.Error(w, "", http.StatusInternalServerError)
 				return
 			}
+			if before != nil {
+				api.audit(u, "", db.AuditObjectUser, before.Email, db.AuditActionDelete, before, nil)
+			}
 		}
 		return
 	}
@@ -192,44 +205,81 @@ func (api *Api) Users(w http.ResponseWriter, r *http.Request, u *db.User) {
 
 func (api *Api) Roles(w http.ResponseWriter, r *http.Request, u *db.User) {
 	if r.Method == http.MethodPost {
-		http.Error(w, "", http.StatusMethodNotAllowed)
-		return
-	}
-	qaSample := rbac.NewRole("QA",
-		rbac.NewPermission(rbac.ScopeProjectAll, rbac.ActionAll, rbac.Object{"project_id": "staging"}),
-	)
-	dbaSample := rbac.NewRole("DBA",
-		rbac.NewPermission(rbac.ScopeProjectInstrumentations, rbac.ActionEdit, nil),
-		rbac.NewPermission(rbac.ScopeApplication, rbac.ActionView, rbac.Object{"application_category": "databases"}),
-		rbac.NewPermission(rbac.ScopeNode, rbac.ActionView, rbac.Object{"node_name": "db*"}),
-		rbac.NewPermission(rbac.ScopeDashboard, rbac.ActionView, rbac.Object{"dashboard_name": "db*"}),
-	)
-	roles, err := api.roles.GetRoles()
-	if err != nil {
-		klog.Errorln(err)
-		http.Error(w, "", http.StatusInternalServerError)
+		if !api.IsAllowed(u, rbac.Actions.Roles().Edit()) {
+			http.Error(w, "You are not allowed to edit roles.", http.StatusForbidden)
+			return
+		}
+		mgr, ok := api.roles.(rbac.EditableRoleManager)
+		if !ok {
+			http.Error(w, "", http.StatusMethodNotAllowed)
+			return
+		}
+		var form forms.RoleForm
+		if err := forms.ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid data.", http.StatusBadRequest)
+			return
+		}
+		if form.Action != forms.RoleActionDelete {
+			if err := form.Role.Validate(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		roles, err := api.roles.GetRoles()
+		if err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		var before *rbac.Role
+		for i := range roles {
+			if roles[i].Name == form.Id {
+				before = &roles[i]
+			}
+		}
+		switch form.Action {
+		case forms.RoleActionAdd:
+			err = mgr.AddRole(form.Role)
+		case forms.RoleActionEdit:
+			err = mgr.UpdateRole(form.Id, form.Role)
+		case forms.RoleActionDelete:
+			err = mgr.DeleteRole(form.Id)
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, db.ErrNotFound):
+				http.Error(w, "Role not found.", http.StatusNotFound)
+			case errors.Is(err, db.ErrConflict):
+				switch {
+				case form.Action == forms.RoleActionDelete:
+					http.Error(w, "The role is assigned to users or is built-in.", http.StatusConflict)
+				default:
+					http.Error(w, "A role with this name already exists.", http.StatusConflict)
+				}
+			default:
+				klog.Errorln(err)
+				http.Error(w, "", http.StatusInternalServerError)
+			}
+			return
+		}
+		switch form.Action {
+		case forms.RoleActionAdd:
+			api.audit(u, "", db.AuditObjectRole, string(form.Role.Name), db.AuditActionCreate, nil, form.Role)
+		case forms.RoleActionEdit:
+			api.audit(u, "", db.AuditObjectRole, string(form.Role.Name), db.AuditActionUpdate, before, form.Role)
+		case forms.RoleActionDelete:
+			api.audit(u, "", db.AuditObjectRole, string(form.Id), db.AuditActionDelete, before, nil)
+		}
 		return
 	}
-	utils.WriteJson(w, views.Roles(append(roles, qaSample, dbaSample)))
-}
-
-func (api *Api) SSO(w http.ResponseWriter, r *http.Request, u *db.User) {
 	roles, err := api.roles.GetRoles()
 	if err != nil {
 		klog.Errorln(err)
 		http.Error(w, "", http.StatusInternalServerError)
 		return
 	}
-	res := struct {
-		Roles       []rbac.RoleName `json:"roles"`
-		DefaultRole rbac.RoleName   `json:"default_role"`
-	}{
-		DefaultRole: rbac.RoleViewer,
-	}
-	for _, role := range roles {
-		res.Roles = append(res.Roles, role.Name)
-	}
-	utils.WriteJson(w, res)
+	utils.WriteJson(w, views.Roles(roles))
 }
 
 func (api *Api) AI(w http.ResponseWriter, r *http.Request, u *db.User) {
@@ -305,6 +355,7 @@ func (api *Api) Project(w http.ResponseWriter, r *http.Request, u *db.User) {
 		isNew := projectId == ""
 
 		var project *db.Project
+		var before *auditProject
 		var err error
 		if isNew {
 			project = &db.Project{
@@ -327,6 +378,7 @@ func (api *Api) Project(w http.ResponseWriter, r *http.Request, u *db.User) {
 				http.Error(w, "This project is defined through the config and cannot be modified via the UI.", http.StatusForbidden)
 				return
 			}
+			before = newAuditProject(project)
 			project.Name = form.Name
 			project.Settings.MemberProjects = form.MemberProjects
 		}
@@ -350,6 +402,11 @@ func (api *Api) Project(w http.ResponseWriter, r *http.Request, u *db.User) {
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		if isNew {
+			api.audit(u, project.Id, db.AuditObjectProject, string(project.Id), db.AuditActionCreate, nil, newAuditProject(project))
+		} else {
+			api.audit(u, project.Id, db.AuditObjectProject, string(project.Id), db.AuditActionUpdate, before, newAuditProject(project))
+		}
 		if isNew && !project.Multicluster() && api.globalClickHouse != nil {
 			err = api.collector.MigrateClickhouseDatabase(r.Context(), project)
 			if err != nil {
@@ -365,11 +422,18 @@ func (api *Api) Project(w http.ResponseWriter, r *http.Request, u *db.User) {
 			http.Error(w, "You are not allowed to delete the project.", http.StatusForbidden)
 			return
 		}
-		if err := api.db.DeleteProject(db.ProjectId(projectId)); err != nil {
+		project, err := api.db.GetProject(db.ProjectId(projectId))
+		if err != nil {
+			klog.Errorln("failed to get project:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		if err = api.db.DeleteProject(project.Id); err != nil {
 			klog.Errorln("failed to delete project:", err)
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		api.audit(u, project.Id, db.AuditObjectProject, string(project.Id), db.AuditActionDelete, newAuditProject(project), nil)
 		http.Error(w, "", http.StatusOK)
 
 	default:
@@ -397,7 +461,31 @@ func (api *Api) Status(w http.ResponseWriter, r *http.Request, u *db.User) {
 		http.Error(w, "", http.StatusInternalServerError)
 		return
 	}
-	utils.WriteJson(w, api.WithContext(project, cacheStatus, world, renderStatus(project, cacheStatus, world, api.globalPrometheus)))
+	status := renderStatus(project, cacheStatus, world, api.globalPrometheus)
+	status.Ingestion = api.collector.GetIngestionUsage(project.Id)
+	utils.WriteJson(w, api.WithContext(project, cacheStatus, world, status))
+}
+
+// CollectorSpool reports the telemetry waiting to be written to ClickHouse and the telemetry that has been lost.
+func (api *Api) CollectorSpool(w http.ResponseWriter, r *http.Request, u *db.User) {
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	if _, err := api.db.GetProject(projectId); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			http.Error(w, "Project not found", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	res := struct {
+		Enabled bool                  `json:"enabled"`
+		Stats   *collector.SpoolStats `json:"stats,omitempty"`
+	}{}
+	if res.Stats = api.collector.GetSpoolStats(projectId); res.Stats != nil {
+		res.Enabled = true
+	}
+	utils.WriteJson(w, res)
 }
 
 func (api *Api) Overview(w http.ResponseWriter, r *http.Request, u *db.User) {
@@ -407,12 +495,12 @@ func (api *Api) Overview(w http.ResponseWriter, r *http.Request, u *db.User) {
 
 	switch view {
 	case "traces":
-		if !api.IsAllowed(u, rbac.Actions.Project(projectId).Traces().View()) {
+		if !api.IsAllowedUnrestricted(u, rbac.Actions.Project(projectId).Application("*", "*", "*", "*").Traces().View()) {
 			http.Error(w, "You are not allowed to view traces.", http.StatusForbidden)
 			return
 		}
 	case "logs":
-		if !api.IsAllowed(u, rbac.Actions.Project(projectId).Logs().View()) {
+		if !api.IsAllowedUnrestricted(u, rbac.Actions.Project(projectId).Application("*", "*", "*", "*").Logs().View()) {
 			http.Error(w, "You are not allowed to view logs.", http.StatusForbidden)
 			return
 		}
@@ -475,10 +563,19 @@ func (api *Api) Dashboards(w http.ResponseWriter, r *http.Request, u *db.User) {
 			http.Error(w, "", http.StatusBadRequest)
 			return
 		}
+		var before *db.Dashboard
+		if form.Action != "create" {
+			if before, err = api.db.GetDashboard(project.Id, id); err != nil && !errors.Is(err, db.ErrNotFound) {
+				klog.Errorln(err)
+				http.Error(w, "", http.StatusInternalServerError)
+				return
+			}
+		}
 		switch form.Action {
 		case "create":
 			id, err = api.db.CreateDashboard(project.Id, form.Name, form.Description)
 			if err == nil {
+				api.audit(u, project.Id, db.AuditObjectDashboard, id, db.AuditActionCreate, nil, &db.Dashboard{Id: id, Name: form.Name, Description: form.Description})
 				http.Error(w, id, http.StatusCreated)
 				return
 			}
@@ -494,6 +591,13 @@ func (api *Api) Dashboards(w http.ResponseWriter, r *http.Request, u *db.User) {
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		if form.Action == "delete" {
+			api.audit(u, project.Id, db.AuditObjectDashboard, id, db.AuditActionDelete, before, nil)
+		} else if after, err := api.db.GetDashboard(project.Id, id); err != nil {
+			klog.Errorln(err)
+		} else {
+			api.audit(u, project.Id, db.AuditObjectDashboard, id, db.AuditActionUpdate, before, after)
+		}
 		return
 	}
 
@@ -617,17 +721,32 @@ func (api *Api) ApiKeys(w http.ResponseWriter, r *http.Request, u *db.User) {
 	isAllowed := api.IsAllowed(u, rbac.Actions.Project(projectId).Settings().Edit())
 
 	if r.Method == http.MethodGet {
+		lastUsed, err := api.db.GetApiKeysLastUsed(project.Id)
+		if err != nil {
+			klog.Errorln("failed to get api keys usage:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		type apiKey struct {
+			db.ApiKey
+			LastUsedAt timeseries.Time `json:"last_used_at,omitempty"`
+			Expired    bool            `json:"expired,omitempty"`
+		}
 		res := struct {
-			Editable bool        `json:"editable"`
-			Keys     []db.ApiKey `json:"keys"`
+			Editable bool             `json:"editable"`
+			Keys     []apiKey         `json:"keys"`
+			Scopes   []db.ApiKeyScope `json:"scopes"`
 		}{
 			Editable: isAllowed && !project.Settings.Readonly && !project.Multicluster(),
-			Keys:     project.Settings.ApiKeys,
+			Scopes:   db.ApiKeyScopes,
 		}
-		if !isAllowed {
-			for i := range res.Keys {
-				res.Keys[i].Key = ""
+		now := timeseries.Now()
+		for _, k := range project.Settings.ApiKeys {
+			key := apiKey{ApiKey: k, LastUsedAt: lastUsed[k.Key], Expired: k.IsExpired(now)}
+			if !isAllowed {
+				key.Key = ""
 			}
+			res.Keys = append(res.Keys, key)
 		}
 		utils.WriteJson(w, res)
 		return
@@ -643,10 +762,17 @@ func (api *Api) ApiKeys(w http.ResponseWriter, r *http.Request, u *db.User) {
 		http.Error(w, "", http.StatusBadRequest)
 		return
 	}
+	var before, after *auditApiKey
+	for _, k := range project.Settings.ApiKeys {
+		if k.Key == form.Key {
+			before = newAuditApiKey(k)
+		}
+	}
 	switch form.Action {
 	case "generate":
 		form.Key = utils.RandomString(32)
 		project.Settings.ApiKeys = append(project.Settings.ApiKeys, form.ApiKey)
+		before, after = nil, newAuditApiKey(form.ApiKey)
 	case "delete":
 		project.Settings.ApiKeys = slices.DeleteFunc(project.Settings.ApiKeys, func(k db.ApiKey) bool {
 			return k.Key == form.Key
@@ -655,6 +781,9 @@ func (api *Api) ApiKeys(w http.ResponseWriter, r *http.Request, u *db.User) {
 		for i, k := range project.Settings.ApiKeys {
 			if k.Key == form.Key {
 				project.Settings.ApiKeys[i].Description = form.Description
+				project.Settings.ApiKeys[i].Scopes = form.Scopes
+				project.Settings.ApiKeys[i].ExpiresAt = form.ExpiresAt
+				after = newAuditApiKey(project.Settings.ApiKeys[i])
 			}
 		}
 	default:
@@ -665,6 +794,14 @@ func (api *Api) ApiKeys(w http.ResponseWriter, r *http.Request, u *db.User) {
 		http.Error(w, "", http.StatusInternalServerError)
 		return
 	}
+	switch {
+	case before == nil && after != nil:
+		api.audit(u, project.Id, db.AuditObjectApiKey, after.Key, db.AuditActionCreate, nil, after)
+	case before != nil && after == nil:
+		api.audit(u, project.Id, db.AuditObjectApiKey, before.Key, db.AuditActionDelete, before, nil)
+	case before != nil:
+		api.audit(u, project.Id, db.AuditObjectApiKey, before.Key, db.AuditActionUpdate, before, after)
+	}
 }
 
 func (api *Api) Inspections(w http.ResponseWriter, r *http.Request, u *db.User) {
@@ -728,6 +865,10 @@ func (api *Api) ApplicationCategories(w http.ResponseWriter, r *http.Request, u
 		default:
 			category = &form.ApplicationCategory
 		}
+		var before *db.ApplicationCategory
+		if form.Id != "" {
+			before = project.GetApplicationCategories()[form.Id]
+		}
 		if err = api.db.SaveApplicationCategory(project, form.Id, category); err != nil {
 			if errors.Is(err, db.ErrConflict) {
 				http.Error(w, "Application category already exists.", http.StatusConflict)
@@ -737,6 +878,14 @@ func (api *Api) ApplicationCategories(w http.ResponseWriter, r *http.Request, u
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		switch {
+		case category == nil:
+			api.audit(u, project.Id, db.AuditObjectApplicationCategory, string(form.Id), db.AuditActionDelete, before, nil)
+		case before == nil:
+			api.audit(u, project.Id, db.AuditObjectApplicationCategory, string(category.Name), db.AuditActionCreate, nil, category)
+		default:
+			api.audit(u, project.Id, db.AuditObjectApplicationCategory, string(category.Name), db.AuditActionUpdate, before, category)
+		}
 		return
 	}
 
@@ -793,16 +942,222 @@ func (api *Api) CustomApplications(w http.ResponseWriter, r *http.Request, u *db
 			http.Error(w, "Invalid name or patterns", http.StatusBadRequest)
 			return
 		}
+		before, existed := project.Settings.CustomApplications[form.Name]
 		if err = api.db.SaveCustomApplication(project.Id, form.Name, form.NewName, form.InstancePatterns); err != nil {
 			klog.Errorln("failed to save:", err)
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		if updated, err := api.db.GetProject(project.Id); err != nil {
+			klog.Errorln(err)
+		} else if after, exists := updated.Settings.CustomApplications[form.NewName]; !exists {
+			api.audit(u, project.Id, db.AuditObjectCustomApplication, form.Name, db.AuditActionDelete, before, nil)
+		} else if !existed {
+			api.audit(u, project.Id, db.AuditObjectCustomApplication, form.NewName, db.AuditActionCreate, nil, after)
+		} else {
+			api.audit(u, project.Id, db.AuditObjectCustomApplication, form.NewName, db.AuditActionUpdate,
+				map[string]any{"name": form.Name, "instance_patterns": before.InstancePatterns},
+				map[string]any{"name": form.NewName, "instance_patterns": after.InstancePatterns})
+		}
 		return
 	}
 	utils.WriteJson(w, views.CustomApplications(project))
 }
 
+func (api *Api) RedactionRules(w http.ResponseWriter, r *http.Request, u *db.User) {
+	vars := mux.Vars(r)
+	projectId := vars["project"]
+
+	project, err := api.db.GetProject(db.ProjectId(projectId))
+	if err != nil {
+		klog.Errorln("failed to get project:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	isAllowed := api.IsAllowed(u, rbac.Actions.Project(projectId).Settings().Edit())
+
+	if r.Method == http.MethodGet {
+		res := struct {
+			Editable bool               `json:"editable"`
+			Rules    []db.RedactionRule `json:"rules"`
+		}{
+			Editable: isAllowed && !project.Settings.Readonly && !project.Multicluster(),
+			Rules:    project.Settings.RedactionRules,
+		}
+		utils.WriteJson(w, res)
+		return
+	}
+
+	if !isAllowed {
+		http.Error(w, "You are not allowed to configure redaction rules.", http.StatusForbidden)
+		return
+	}
+	var form forms.RedactionRulesForm
+	if err = utils.ReadJson(r, &form); err != nil {
+		klog.Warningln("bad request:", err)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+	if err = db.ValidateRedactionRules(form.Rules); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	before := project.Settings.RedactionRules
+	project.Settings.RedactionRules = form.Rules
+	if err = api.db.SaveProjectSettings(project); err != nil {
+		klog.Errorln("failed to save redaction rules:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	api.audit(u, project.Id, db.AuditObjectRedactionRules, "", db.AuditActionUpdate, before, project.Settings.RedactionRules)
+}
+
+func (api *Api) TraceSampling(w http.ResponseWriter, r *http.Request, u *db.User) {
+	vars := mux.Vars(r)
+	projectId := vars["project"]
+
+	project, err := api.db.GetProject(db.ProjectId(projectId))
+	if err != nil {
+		klog.Errorln("failed to get project:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	isAllowed := api.IsAllowed(u, rbac.Actions.Project(projectId).Settings().Edit())
+
+	if r.Method == http.MethodGet {
+		res := struct {
+			Editable bool              `json:"editable"`
+			Sampling *db.TraceSampling `json:"sampling"`
+		}{
+			Editable: isAllowed && !project.Settings.Readonly && !project.Multicluster(),
+			Sampling: project.Settings.TraceSampling,
+		}
+		utils.WriteJson(w, res)
+		return
+	}
+
+	if !isAllowed {
+		http.Error(w, "You are not allowed to configure trace sampling.", http.StatusForbidden)
+		return
+	}
+	var form forms.TraceSamplingForm
+	if err = utils.ReadJson(r, &form); err != nil {
+		klog.Warningln("bad request:", err)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+	if err = form.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	before := project.Settings.TraceSampling
+	project.Settings.TraceSampling = &form.TraceSampling
+	if err = api.db.SaveProjectSettings(project); err != nil {
+		klog.Errorln("failed to save trace sampling settings:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	api.audit(u, project.Id, db.AuditObjectTraceSampling, "", db.AuditActionUpdate, before, project.Settings.TraceSampling)
+}
+
+// IngestionLimits are configured by admins, since they protect the storage shared by all projects.
+func (api *Api) IngestionLimits(w http.ResponseWriter, r *http.Request, u *db.User) {
+	vars := mux.Vars(r)
+	projectId := vars["project"]
+
+	project, err := api.db.GetProject(db.ProjectId(projectId))
+	if err != nil {
+		klog.Errorln("failed to get project:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	isAllowed := api.IsAllowed(u, rbac.Actions.Settings().Edit())
+
+	if r.Method == http.MethodGet {
+		res := struct {
+			Editable bool                      `json:"editable"`
+			Limits   *db.IngestionLimits       `json:"limits"`
+			Usage    *collector.IngestionUsage `json:"usage"`
+		}{
+			Editable: isAllowed && !project.Multicluster(),
+			Limits:   project.Settings.IngestionLimits,
+			Usage:    api.collector.GetIngestionUsage(project.Id),
+		}
+		utils.WriteJson(w, res)
+		return
+	}
+
+	if !isAllowed {
+		http.Error(w, "You are not allowed to configure ingestion limits.", http.StatusForbidden)
+		return
+	}
+	var form forms.IngestionLimitsForm
+	if err = utils.ReadJson(r, &form); err != nil {
+		klog.Warningln("bad request:", err)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+	if err = form.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	before := project.Settings.IngestionLimits
+	project.Settings.IngestionLimits = nil
+	if !form.IsEmpty() {
+		project.Settings.IngestionLimits = &form.IngestionLimits
+	}
+	if err = api.db.SaveProjectSettings(project); err != nil {
+		klog.Errorln("failed to save ingestion limits:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	api.audit(u, project.Id, db.AuditObjectIngestionLimits, "", db.AuditActionUpdate, before, project.Settings.IngestionLimits)
+}
+
+// RedactionDryRun shows which rules would fire on a sample OTLP/JSON payload without storing anything.
+// If no rules are provided in the form, the project's rules are used.
+func (api *Api) RedactionDryRun(w http.ResponseWriter, r *http.Request, u *db.User) {
+	vars := mux.Vars(r)
+	projectId := vars["project"]
+
+	project, err := api.db.GetProject(db.ProjectId(projectId))
+	if err != nil {
+		klog.Errorln("failed to get project:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if !api.IsAllowed(u, rbac.Actions.Project(projectId).Settings().Edit()) {
+		http.Error(w, "You are not allowed to configure redaction rules.", http.StatusForbidden)
+		return
+	}
+	var form forms.RedactionDryRunForm
+	if err = utils.ReadJson(r, &form); err != nil {
+		klog.Warningln("bad request:", err)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+	rules := form.Rules
+	if rules == nil {
+		rules = project.Settings.RedactionRules
+	}
+	report, redacted, err := collector.RedactionDryRun(rules, form.Signal, []byte(form.Payload))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res := struct {
+		*collector.RedactionReport
+		Payload string `json:"payload"`
+	}{
+		RedactionReport: report,
+		Payload:         string(redacted),
+	}
+	utils.WriteJson(w, res)
+}
+
 func (api *Api) CustomCloudPricing(w http.ResponseWriter, r *http.Request, u *db.User) {
 	vars := mux.Vars(r)
 	projectId := vars["project"]
@@ -817,6 +1172,7 @@ func (api *Api) CustomCloudPricing(w http.ResponseWriter, r *http.Request, u *db
 		utils.WriteJson(w, p.Settings.CustomCloudPricing)
 		return
 	}
+	before := p.Settings.CustomCloudPricing
 	if !api.IsAllowed(u, rbac.Actions.Project(projectId).CustomCloudPricing().Edit()) {
 		http.Error(w, "You are not allowed to configure custom cloud pricing.", http.StatusForbidden)
 		return
@@ -841,6 +1197,11 @@ func (api *Api) CustomCloudPricing(w http.ResponseWriter, r *http.Request, u *db
 		http.Error(w, "", http.StatusInternalServerError)
 		return
 	}
+	action := db.AuditActionUpdate
+	if p.Settings.CustomCloudPricing == nil {
+		action = db.AuditActionDelete
+	}
+	api.audit(u, p.Id, db.AuditObjectCustomCloudPricing, "", action, before, p.Settings.CustomCloudPricing)
 }
 
 func (api *Api) Integrations(w http.ResponseWriter, r *http.Request, u *db.User) {
@@ -858,11 +1219,17 @@ func (api *Api) Integrations(w http.ResponseWriter, r *http.Request, u *db.User)
 			http.Error(w, "Invalid base url", http.StatusBadRequest)
 			return
 		}
+		var before string
+		if project, err := api.db.GetProject(db.ProjectId(projectId)); err == nil {
+			before = project.Settings.Integrations.BaseUrl
+		}
 		if err := api.db.SaveIntegrationsBaseUrl(db.ProjectId(projectId), form.BaseUrl); err != nil {
 			klog.Errorln("failed to save:", err)
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		api.audit(u, db.ProjectId(projectId), db.AuditObjectIntegration, "base_url", db.AuditActionUpdate,
+			map[string]string{"base_url": before}, map[string]string{"base_url": form.BaseUrl})
 		return
 	}
 
@@ -955,6 +1322,8 @@ func (api *Api) Integration(w http.ResponseWriter, r *http.Request, u *db.User)
 		}
 	}
 
+	before := api.getAuditIntegration(project.Id, t)
+
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 	switch r.Method {
@@ -984,6 +1353,11 @@ func (api *Api) Integration(w http.ResponseWriter, r *http.Request, u *db.User)
 		http.Error(w, "", http.StatusInternalServerError)
 		return
 	}
+	if r.Method == http.MethodDelete {
+		api.audit(u, project.Id, db.AuditObjectIntegration, string(t), db.AuditActionDelete, before, nil)
+	} else {
+		api.audit(u, project.Id, db.AuditObjectIntegration, string(t), db.AuditActionUpdate, before, api.getAuditIntegration(project.Id, t))
+	}
 	if api.globalClickHouse == nil {
 		err = api.collector.UpdateClickhouseClient(r.Context(), project)
 		if err != nil {
@@ -1251,6 +1625,13 @@ func (api *Api) Alerts(w http.ResponseWriter, r *http.Request, u *db.User) {
 		return
 	}
 
+	silences, err := api.db.GetActiveSilences(projectId, timeseries.Now())
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
 	world, project, cacheStatus, err := api.LoadWorldByRequest(r)
 	if err != nil {
 		klog.Errorln(err)
@@ -1261,7 +1642,7 @@ func (api *Api) Alerts(w http.ResponseWriter, r *http.Request, u *db.User) {
 		utils.WriteJson(w, api.WithContext(project, cacheStatus, world, nil))
 		return
 	}
-	utils.WriteJson(w, api.WithContext(project, cacheStatus, world, views.Alerts(world, result, rules, notifications)))
+	utils.WriteJson(w, api.WithContext(project, cacheStatus, world, views.Alerts(world, result, rules, notifications, silences)))
 }
 
 func (api *Api) Alert(w http.ResponseWriter, r *http.Request, u *db.User) {
@@ -1299,6 +1680,13 @@ func (api *Api) Alert(w http.ResponseWriter, r *http.Request, u *db.User) {
 		return
 	}
 
+	silences, err := api.db.GetActiveSilences(projectId, timeseries.Now())
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
 	world, project, cacheStatus, err := api.LoadWorldByRequest(r)
 	if err != nil {
 		klog.Errorln(err)
@@ -1320,7 +1708,7 @@ func (api *Api) Alert(w http.ResponseWriter, r *http.Request, u *db.User) {
 	}
 	chs := api.GetClickhouseClients(project)
 	defer chs.Close()
-	utils.WriteJson(w, api.WithContext(project, cacheStatus, world, views.Alert(world, a, app, rules, notifications[alertId], chs)))
+	utils.WriteJson(w, api.WithContext(project, cacheStatus, world, views.Alert(world, a, app, rules, notifications[alertId], silences, chs)))
 }
 
 func (api *Api) ResolveAlerts(w http.ResponseWriter, r *http.Request, u *db.User) {
@@ -1356,6 +1744,7 @@ func (api *Api) ResolveAlerts(w http.ResponseWriter, r *http.Request, u *db.User
 		http.Error(w, "", http.StatusInternalServerError)
 		return
 	}
+	api.auditAlerts(u, project.Id, req.Ids, db.AuditActionResolve, map[string]string{"resolved_by": resolvedBy})
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -1433,6 +1822,7 @@ func (api *Api) SuppressAlerts(w http.ResponseWriter, r *http.Request, u *db.Use
 		http.Error(w, "", http.StatusInternalServerError)
 		return
 	}
+	api.auditAlerts(u, db.ProjectId(projectId), req.Ids, db.AuditActionSuppress, map[string]string{"suppressed_by": suppressedBy})
 
 	if len(alertsToNotify) > 0 {
 		project, err := api.db.GetProject(db.ProjectId(projectId))
@@ -1457,6 +1847,42 @@ func (api *Api) SuppressAlerts(w http.ResponseWriter, r *http.Request, u *db.Use
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (api *Api) AcknowledgeAlerts(w http.ResponseWriter, r *http.Request, u *db.User) {
+	vars := mux.Vars(r)
+	projectId := vars["project"]
+
+	if !api.IsAllowed(u, rbac.Actions.Project(projectId).Alerts().Edit()) {
+		http.Error(w, "", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		Ids []string `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Ids) == 0 {
+		http.Error(w, "no alert ids provided", http.StatusBadRequest)
+		return
+	}
+
+	acknowledgedBy := u.Name
+	if acknowledgedBy == "" {
+		acknowledgedBy = u.Email
+	}
+
+	if err := api.db.AcknowledgeAlerts(db.ProjectId(projectId), req.Ids, acknowledgedBy); err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	api.auditAlerts(u, db.ProjectId(projectId), req.Ids, db.AuditActionAcknowledge, map[string]string{"acknowledged_by": acknowledgedBy})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (api *Api) ReopenAlerts(w http.ResponseWriter, r *http.Request, u *db.User) {
 	vars := mux.Vars(r)
 	projectId := vars["project"]
@@ -1484,6 +1910,7 @@ func (api *Api) ReopenAlerts(w http.ResponseWriter, r *http.Request, u *db.User)
 		http.Error(w, "", http.StatusInternalServerError)
 		return
 	}
+	api.auditAlerts(u, db.ProjectId(projectId), req.Ids, db.AuditActionReopen, nil)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -1554,6 +1981,7 @@ func (api *Api) AlertingRules(w http.ResponseWriter, r *http.Request, u *db.User
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		api.audit(u, db.ProjectId(projectId), db.AuditObjectAlertingRule, string(rule.Id), db.AuditActionCreate, nil, rule)
 		utils.WriteJson(w, rule)
 	}
 }
@@ -1612,6 +2040,7 @@ func (api *Api) AlertingRule(w http.ResponseWriter, r *http.Request, u *db.User)
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		api.audit(u, db.ProjectId(projectId), db.AuditObjectAlertingRule, string(ruleId), db.AuditActionUpdate, existing, rule)
 		utils.WriteJson(w, rule)
 
 	case http.MethodDelete:
@@ -1647,6 +2076,7 @@ func (api *Api) AlertingRule(w http.ResponseWriter, r *http.Request, u *db.User)
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		api.audit(u, db.ProjectId(projectId), db.AuditObjectAlertingRule, string(ruleId), db.AuditActionDelete, rule, nil)
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
@@ -1710,6 +2140,124 @@ func (api *Api) AlertingRulesExport(w http.ResponseWriter, r *http.Request, u *d
 	utils.WriteJson(w, map[string]string{"yaml": string(out)})
 }
 
+func (api *Api) Silences(w http.ResponseWriter, r *http.Request, u *db.User) {
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+
+	switch r.Method {
+	case http.MethodGet:
+		if !api.IsAllowed(u, rbac.Actions.Project(string(projectId)).Alerts().View()) {
+			http.Error(w, "", http.StatusForbidden)
+			return
+		}
+		silences, err := api.db.GetSilences(projectId)
+		if err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		if silences == nil {
+			silences = []*model.Silence{}
+		}
+		utils.WriteJson(w, silences)
+
+	case http.MethodPost:
+		if !api.IsAllowed(u, rbac.Actions.Project(string(projectId)).Alerts().Edit()) {
+			http.Error(w, "", http.StatusForbidden)
+			return
+		}
+		var form forms.SilenceForm
+		if err := forms.ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid silence: a time window and at least one matcher are required.", http.StatusBadRequest)
+			return
+		}
+		createdBy := u.Name
+		if createdBy == "" {
+			createdBy = u.Email
+		}
+		silence := &model.Silence{
+			StartsAt:  form.StartsAt,
+			EndsAt:    form.EndsAt,
+			CreatedBy: createdBy,
+			Comment:   form.Comment,
+			Matchers:  form.Matchers,
+		}
+		if err := api.db.CreateSilence(projectId, silence); err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		api.audit(u, projectId, db.AuditObjectSilence, silence.Id, db.AuditActionCreate, nil, silence)
+		utils.WriteJson(w, silence)
+	}
+}
+
+func (api *Api) Silence(w http.ResponseWriter, r *http.Request, u *db.User) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	silenceId := vars["silence"]
+
+	action := rbac.Actions.Project(string(projectId)).Alerts().Edit()
+	if r.Method == http.MethodGet {
+		action = rbac.Actions.Project(string(projectId)).Alerts().View()
+	}
+	if !api.IsAllowed(u, action) {
+		http.Error(w, "", http.StatusForbidden)
+		return
+	}
+
+	silence, err := api.db.GetSilence(projectId, silenceId)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			http.Error(w, "Silence not found", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		utils.WriteJson(w, silence)
+
+	case http.MethodPut:
+		var form forms.SilenceForm
+		if err := forms.ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid silence: a time window and at least one matcher are required.", http.StatusBadRequest)
+			return
+		}
+		before := *silence
+		silence.StartsAt = form.StartsAt
+		silence.EndsAt = form.EndsAt
+		silence.Comment = form.Comment
+		silence.Matchers = form.Matchers
+		if err := api.db.UpdateSilence(projectId, silence); err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		api.audit(u, projectId, db.AuditObjectSilence, silenceId, db.AuditActionUpdate, before, silence)
+		utils.WriteJson(w, silence)
+
+	case http.MethodDelete:
+		// a silence that hasn't ended yet is expired to keep it in the history
+		if now := timeseries.Now(); silence.EndsAt.After(now) {
+			err = api.db.ExpireSilence(projectId, silenceId, now)
+		} else {
+			err = api.db.DeleteSilence(projectId, silenceId)
+		}
+		if err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		api.audit(u, projectId, db.AuditObjectSilence, silenceId, db.AuditActionDelete, silence, nil)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
 func (api *Api) appConfigProjectId(project *db.Project, appId model.ApplicationId) (db.ProjectId, bool) {
 	if !project.Multicluster() || appId.ClusterId == "" {
 		return project.Id, true
@@ -1807,6 +2355,29 @@ func (api *Api) Inspection(w http.ResponseWriter, r *http.Request, u *db.User) {
 					if webhook := notificationSettings.Webhook; webhook != nil && webhook.Enabled {
 						res.Integrations = append(res.Integrations, Integration{Name: "Webhook"})
 					}
+					if email := notificationSettings.Email; email != nil && email.Enabled {
+						details := "default recipients"
+						if len(email.To) > 0 {
+							details = fmt.Sprintf("to: %s", strings.Join(email.To, ", "))
+						}
+						res.Integrations = append(res.Integrations, Integration{Name: "Email", Details: details})
+					}
+					if telegram := notificationSettings.Telegram; telegram != nil && telegram.Enabled {
+						res.Integrations = append(res.Integrations, Integration{Name: "Telegram", Details: fmt.Sprintf("chat: %s", telegram.ChatId)})
+					}
+					if discord := notificationSettings.Discord; discord != nil && discord.Enabled {
+						res.Integrations = append(res.Integrations, Integration{Name: "Discord"})
+					}
+					if googleChat := notificationSettings.GoogleChat; googleChat != nil && googleChat.Enabled {
+						res.Integrations = append(res.Integrations, Integration{Name: "Google Chat"})
+					}
+					if mattermost := notificationSettings.Mattermost; mattermost != nil && mattermost.Enabled {
+						details := "default channel"
+						if mattermost.Channel != "" {
+							details = fmt.Sprintf("channel: %s", mattermost.Channel)
+						}
+						res.Integrations = append(res.Integrations, Integration{Name: "Mattermost", Details: details})
+					}
 				}
 			}
 		}
@@ -1836,6 +2407,7 @@ func (api *Api) Inspection(w http.ResponseWriter, r *http.Request, u *db.User) {
 			http.Error(w, "You are not allowed to configure inspections.", http.StatusForbidden)
 			return
 		}
+		before := api.getAuditCheckConfigs(configProjectId, checkId)
 		switch checkId {
 		case model.Checks.SLOAvailability.Id:
 			var form forms.CheckConfigSLOAvailabilityForm
@@ -1894,8 +2466,8 @@ func (api *Api) Inspection(w http.ResponseWriter, r *http.Request, u *db.User) {
 					return
 				}
 			}
-			return
 		}
+		api.audit(u, configProjectId, db.AuditObjectInspection, string(checkId), db.AuditActionUpdate, before, api.getAuditCheckConfigs(configProjectId, checkId))
 	}
 }
 
@@ -1944,11 +2516,13 @@ func (api *Api) Instrumentation(w http.ResponseWriter, r *http.Request, u *db.Us
 			http.Error(w, "invalid data", http.StatusBadRequest)
 			return
 		}
+		before := api.getAuditApplicationSettings(configProjectId, appId)
 		if err = api.db.SaveApplicationSetting(configProjectId, appId, &form.ApplicationInstrumentation); err != nil {
 			klog.Errorln(err)
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		api.audit(u, configProjectId, db.AuditObjectApplicationSettings, appId.String(), db.AuditActionUpdate, before, api.getAuditApplicationSettings(configProjectId, appId))
 		return
 	}
 
@@ -2010,11 +2584,13 @@ func (api *Api) Profiling(w http.ResponseWriter, r *http.Request, u *db.User) {
 			http.Error(w, "invalid data", http.StatusBadRequest)
 			return
 		}
+		before := api.getAuditApplicationSettings(configProjectId, appId)
 		if err := api.db.SaveApplicationSetting(configProjectId, appId, &form.ApplicationSettingsProfiling); err != nil {
 			klog.Errorln(err)
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		api.audit(u, configProjectId, db.AuditObjectApplicationSettings, appId.String(), db.AuditActionUpdate, before, api.getAuditApplicationSettings(configProjectId, appId))
 		return
 	}
 
@@ -2081,11 +2657,13 @@ func (api *Api) Tracing(w http.ResponseWriter, r *http.Request, u *db.User) {
 			http.Error(w, "invalid data", http.StatusBadRequest)
 			return
 		}
+		before := api.getAuditApplicationSettings(configProjectId, appId)
 		if err := api.db.SaveApplicationSetting(configProjectId, appId, &form.ApplicationSettingsTracing); err != nil {
 			klog.Errorln(err)
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		api.audit(u, configProjectId, db.AuditObjectApplicationSettings, appId.String(), db.AuditActionUpdate, before, api.getAuditApplicationSettings(configProjectId, appId))
 		return
 	}
 
@@ -2105,6 +2683,10 @@ func (api *Api) Tracing(w http.ResponseWriter, r *http.Request, u *db.User) {
 		http.Error(w, "Application not found", http.StatusNotFound)
 		return
 	}
+	if !api.IsAllowed(u, rbac.Actions.Project(projectId).Application(app.Category, app.Id.Namespace, app.Id.Kind, app.Id.Name).Traces().View()) {
+		http.Error(w, "You are not allowed to view traces of this application.", http.StatusForbidden)
+		return
+	}
 	q := r.URL.Query()
 	var ch *clickhouse.Client
 	if ch, err = api.GetClickhouseClient(project, app.Id.ClusterId); err != nil {
@@ -2152,11 +2734,13 @@ func (api *Api) Logs(w http.ResponseWriter, r *http.Request, u *db.User) {
 			http.Error(w, "invalid data", http.StatusBadRequest)
 			return
 		}
+		before := api.getAuditApplicationSettings(configProjectId, appId)
 		if err := api.db.SaveApplicationSetting(configProjectId, appId, &form.ApplicationSettingsLogs); err != nil {
 			klog.Errorln(err)
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		api.audit(u, configProjectId, db.AuditObjectApplicationSettings, appId.String(), db.AuditActionUpdate, before, api.getAuditApplicationSettings(configProjectId, appId))
 		return
 	}
 
@@ -2176,6 +2760,10 @@ func (api *Api) Logs(w http.ResponseWriter, r *http.Request, u *db.User) {
 		http.Error(w, "Application not found", http.StatusNotFound)
 		return
 	}
+	if !api.IsAllowed(u, rbac.Actions.Project(projectId).Application(app.Category, app.Id.Namespace, app.Id.Kind, app.Id.Name).Logs().View()) {
+		http.Error(w, "You are not allowed to view logs of this application.", http.StatusForbidden)
+		return
+	}
 	ch, chErr := api.GetClickhouseClient(project, app.Id.ClusterId)
 	if chErr != nil {
 		klog.Warningln(chErr)
@@ -2263,11 +2851,13 @@ func (api *Api) Risks(w http.ResponseWriter, r *http.Request, u *db.User) {
 			http.Error(w, "Application not found", http.StatusNotFound)
 			return
 		}
+		before := api.getAuditApplicationSettings(configProjectId, appId)
 		if err = api.db.SaveApplicationSetting(configProjectId, appId, overrides); err != nil {
 			klog.Errorln(err)
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		api.audit(u, configProjectId, db.AuditObjectApplicationSettings, appId.String(), db.AuditActionUpdate, before, api.getAuditApplicationSettings(configProjectId, appId))
 		return
 	}
 }
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/coroot/coroot/api/forms"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/rbac"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"k8s.io/klog"
+)
+
+func (api *Api) Audit(w http.ResponseWriter, r *http.Request, u *db.User) {
+	if !api.IsAllowed(u, rbac.Actions.Audit().View()) {
+		http.Error(w, "You are not allowed to view the audit log.", http.StatusForbidden)
+		return
+	}
+	q := r.URL.Query()
+	query := db.AuditQuery{
+		ProjectId:  db.ProjectId(q.Get("project")),
+		Actor:      q.Get("actor"),
+		ObjectType: db.AuditObjectType(q.Get("object_type")),
+		ObjectId:   q.Get("object_id"),
+		Action:     db.AuditAction(q.Get("action")),
+		From:       utils.ParseTime(timeseries.Now(), q.Get("from"), 0),
+		To:         utils.ParseTime(timeseries.Now(), q.Get("to"), 0),
+		Limit:      100,
+	}
+	if l := q.Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 1000 {
+			query.Limit = parsed
+		}
+	}
+	if o := q.Get("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			query.Offset = parsed
+		}
+	}
+	res, err := api.db.QueryAuditLog(query)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJson(w, res)
+}
+
+// audit records a change made by the user. Failures are logged and don't affect the request,
+// since the change itself has already been applied.
+func (api *Api) audit(u *db.User, projectId db.ProjectId, objectType db.AuditObjectType, objectId string, action db.AuditAction, before, after any) {
+	changes, err := db.AuditDiff(before, after)
+	if err != nil {
+		klog.Errorln("failed to calculate audit diff:", err)
+	}
+	if action == db.AuditActionUpdate && err == nil && len(changes) == 0 {
+		return
+	}
+	e := &db.AuditEntry{
+		Actor:      auditActor(u),
+		ProjectId:  projectId,
+		ObjectType: objectType,
+		ObjectId:   objectId,
+		Action:     action,
+		Changes:    changes,
+	}
+	if err = api.db.AddAuditEntry(e); err != nil {
+		klog.Errorln("failed to add audit log entry:", err)
+	}
+}
+
+func auditActor(u *db.User) string {
+	switch {
+	case u == nil:
+		return "system"
+	case u.Email != "":
+		return u.Email
+	case u.Name != "":
+		return u.Name
+	case u.Anonymous:
+		return "anonymous"
+	}
+	return "unknown"
+}
+
+// auditUser is the representation of a user in the audit log, without the password.
+type auditUser struct {
+	Email           string          `json:"email"`
+	Name            string          `json:"name"`
+	Roles           []rbac.RoleName `json:"roles"`
+	PasswordChanged bool            `json:"password_changed,omitempty"`
+}
+
+func newAuditUser(email, name string, roles []rbac.RoleName) *auditUser {
+	return &auditUser{Email: email, Name: name, Roles: roles}
+}
+
+func (api *Api) getAuditUser(id int) *auditUser {
+	user, err := api.db.GetUser(id)
+	if err != nil {
+		klog.Errorln(err)
+		return nil
+	}
+	return newAuditUser(user.Email, user.Name, user.Roles)
+}
+
+type auditProject struct {
+	Name           string   `json:"name"`
+	MemberProjects []string `json:"member_projects,omitempty"`
+}
+
+func newAuditProject(p *db.Project) *auditProject {
+	return &auditProject{Name: p.Name, MemberProjects: p.Settings.MemberProjects}
+}
+
+// auditApiKey is the representation of an API key in the audit log. The key itself is masked.
+type auditApiKey struct {
+	Key         string           `json:"key"`
+	Description string           `json:"description"`
+	Scopes      []db.ApiKeyScope `json:"scopes,omitempty"`
+	ExpiresAt   timeseries.Time  `json:"expires_at,omitempty"`
+}
+
+func newAuditApiKey(k db.ApiKey) *auditApiKey {
+	key := k.Key
+	if len(key) > 4 {
+		key = "..." + key[len(key)-4:]
+	}
+	return &auditApiKey{Key: key, Description: k.Description, Scopes: k.Scopes, ExpiresAt: k.ExpiresAt}
+}
+
+// getAuditIntegration returns the masked integration form, so that secrets don't end up in the audit log.
+// The project is loaded separately since masking modifies the configuration the form refers to.
+func (api *Api) getAuditIntegration(projectId db.ProjectId, t db.IntegrationType) forms.IntegrationForm {
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		return nil
+	}
+	form := forms.NewIntegrationForm(t, api.globalClickHouse, api.globalPrometheus)
+	if form == nil {
+		return nil
+	}
+	form.Get(project, true)
+	return form
+}
+
+func (api *Api) auditAlerts(u *db.User, projectId db.ProjectId, ids []string, action db.AuditAction, details any) {
+	for _, id := range ids {
+		api.audit(u, projectId, db.AuditObjectAlert, id, action, nil, details)
+	}
+}
+
+// getAuditCheckConfigs returns the configs of the check for all the applications of the project.
+// The project-level config is reported as "default".
+func (api *Api) getAuditCheckConfigs(projectId db.ProjectId, checkId model.CheckId) map[string]json.RawMessage {
+	checkConfigs, err := api.db.GetCheckConfigs(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		return nil
+	}
+	res := map[string]json.RawMessage{}
+	for appId, configs := range checkConfigs {
+		cfg, ok := configs[checkId]
+		if !ok {
+			continue
+		}
+		if appId.IsZero() {
+			res["default"] = cfg
+		} else {
+			res[appId.String()] = cfg
+		}
+	}
+	return res
+}
+
+// getAuditApplicationSettings returns the settings of the application with the instrumentation passwords masked.
+func (api *Api) getAuditApplicationSettings(projectId db.ProjectId, appId model.ApplicationId) *model.ApplicationSettings {
+	settings, err := api.db.GetApplicationSettings(projectId, appId)
+	if err != nil {
+		klog.Errorln(err)
+		return nil
+	}
+	if settings == nil || len(settings.Instrumentation) == 0 {
+		return settings
+	}
+	res := *settings
+	res.Instrumentation = make(map[model.ApplicationType]*model.ApplicationInstrumentation, len(settings.Instrumentation))
+	for t, i := range settings.Instrumentation {
+		if i != nil && i.Credentials.Password != "" {
+			masked := *i
+			masked.Credentials.Password = "<hidden>"
+			i = &masked
+		}
+		res.Instrumentation[t] = i
+	}
+	return &res
+}
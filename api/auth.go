@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -15,7 +16,11 @@ import (
 	"github.com/coroot/coroot/collector"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/rbac"
+	"github.com/coroot/coroot/timeseries"
 	"github.com/coroot/coroot/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+	"golang.org/x/exp/maps"
 	"k8s.io/klog"
 )
 
@@ -93,25 +98,39 @@ func (api *Api) Auth(h func(http.ResponseWriter, *http.Request, *db.User)) http.
 	}
 }
 
-func (api *Api) getProjectByApiKey(apiKey string) (*db.Project, error) {
+// getProjectByApiKey returns the project the API key belongs to, checking that the key hasn't expired and allows the scope.
+func (api *Api) getProjectByApiKey(apiKey string, scope db.ApiKeyScope) (*db.Project, *db.ApiKey, error) {
 	projects, err := api.db.GetProjects()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	for _, p := range projects {
-		if p.Multicluster() {
-			continue
-		}
-		for _, key := range p.Settings.ApiKeys {
-			if !key.IsEmpty() && key.Key == apiKey {
-				return p, nil
-			}
-		}
+	p, k := db.FindApiKey(slices.DeleteFunc(maps.Values(projects), func(p *db.Project) bool { return p.Multicluster() }), apiKey)
+	if k == nil || k.IsEmpty() {
+		return nil, nil, nil
+	}
+	now := timeseries.Now()
+	if err = k.Check(scope, now); err != nil {
+		return nil, nil, err
+	}
+	api.apiKeyUsage.Touch(p.Id, k.Key, now)
+	return p, k, nil
+}
+
+func newApiKeyUsageTracker(database *db.DB) *db.ApiKeyUsageTracker {
+	return db.NewApiKeyUsageTracker(database)
+}
+
+func apiKeyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, db.ErrApiKeyExpired):
+		return http.StatusUnauthorized
+	case errors.Is(err, db.ErrApiKeyForbidden):
+		return http.StatusForbidden
 	}
-	return nil, nil
+	return http.StatusInternalServerError
 }
 
-func (api *Api) ApiKeyAuth(h func(http.ResponseWriter, *http.Request, *db.Project)) http.HandlerFunc {
+func (api *Api) ApiKeyAuth(scope db.ApiKeyScope, h func(http.ResponseWriter, *http.Request, *db.Project)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		apiKey := r.Header.Get(collector.ApiKeyHeader)
 		if apiKey == "" {
@@ -119,10 +138,10 @@ func (api *Api) ApiKeyAuth(h func(http.ResponseWriter, *http.Request, *db.Projec
 			http.Error(w, "no api key", http.StatusBadRequest)
 			return
 		}
-		project, err := api.getProjectByApiKey(apiKey)
+		project, _, err := api.getProjectByApiKey(apiKey, scope)
 		if err != nil {
-			klog.Errorln(err)
-			http.Error(w, "", http.StatusUnauthorized)
+			klog.Warningln(err)
+			http.Error(w, err.Error(), apiKeyErrorStatus(err))
 			return
 		}
 		if project == nil {
@@ -135,6 +154,40 @@ func (api *Api) ApiKeyAuth(h func(http.ResponseWriter, *http.Request, *db.Projec
 	}
 }
 
+// AuthOrApiKey authenticates the request either with the user session or, if the X-API-Key header is set,
+// with a key of the project from the URL that allows the scope. In the latter case, the handler is called
+// with a pseudo-user whose permissions are limited to the scope.
+func (api *Api) AuthOrApiKey(scope db.ApiKeyScope, h func(http.ResponseWriter, *http.Request, *db.User)) http.HandlerFunc {
+	auth := api.Auth(h)
+	return func(w http.ResponseWriter, r *http.Request) {
+		apiKey := r.Header.Get(collector.ApiKeyHeader)
+		if apiKey == "" {
+			auth(w, r)
+			return
+		}
+		project, key, err := api.getProjectByApiKey(apiKey, scope)
+		if err != nil {
+			klog.Warningln(err)
+			http.Error(w, err.Error(), apiKeyErrorStatus(err))
+			return
+		}
+		if project == nil || string(project.Id) != mux.Vars(r)["project"] {
+			http.Error(w, "", http.StatusUnauthorized)
+			return
+		}
+		h(w, r, db.ApiKeyUser(key, apiKeyPermissions(project.Id, scope)))
+	}
+}
+
+func apiKeyPermissions(projectId db.ProjectId, scope db.ApiKeyScope) rbac.PermissionSet {
+	object := rbac.Object{"project_id": string(projectId)}
+	switch scope {
+	case db.ApiKeyScopeAlertingRules:
+		return rbac.PermissionSet{rbac.NewPermission(rbac.ScopeProjectAlertingRules, rbac.ActionAll, object)}
+	}
+	return rbac.PermissionSet{}
+}
+
 func (api *Api) Login(w http.ResponseWriter, r *http.Request) {
 	var form forms.LoginForm
 	if err := forms.ReadAndValidate(r, &form); err != nil {
@@ -170,8 +223,19 @@ func (api *Api) Login(w http.ResponseWriter, r *http.Request) {
 			}
 			return
 		}
+		api.audit(admin, "", db.AuditObjectUser, admin.Email, db.AuditActionUpdate, nil, map[string]bool{"password_changed": true})
 		userId = admin.Id
 	default:
+		sso, err := api.db.GetSSOSettings()
+		if err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		if sso.Enabled && sso.ForceSSO && form.Email != db.AdminUserLogin { // the built-in admin can still log in in case SSO breaks
+			http.Error(w, "Password login is disabled. Please use SSO to sign in.", http.StatusForbidden)
+			return
+		}
 		id, err := api.db.AuthUser(form.Email, form.Password)
 		if err != nil {
 			klog.Errorln(err)
@@ -218,6 +282,36 @@ func (api *Api) SetSessionCookie(w http.ResponseWriter, userId int, ttl time.Dur
 	return nil
 }
 
+func (api *Api) tokenSecret() ([]byte, error) {
+	var secret string
+	if err := api.db.GetSetting(AuthSecretSettingName, &secret); err != nil {
+		return nil, fmt.Errorf("failed to get auth secret: %w", err)
+	}
+	return []byte(secret), nil
+}
+
+// signToken issues a JWT signed with the auth secret, e.g., an MCP OAuth token or the SSO state.
+func (api *Api) signToken(claims jwt.Claims) (string, error) {
+	secret, err := api.tokenSecret()
+	if err != nil {
+		return "", err
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+}
+
+// verifyToken checks the signature of a token issued by signToken and that it was issued for the given audience.
+func (api *Api) verifyToken(audience string, token string, out jwt.Claims) error {
+	secret, err := api.tokenSecret()
+	if err != nil {
+		return err
+	}
+	_, err = jwt.ParseWithClaims(token, out, func(*jwt.Token) (any, error) { return secret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(audience),
+	)
+	return err
+}
+
 func (api *Api) GetUser(r *http.Request) *db.User {
 	if api.authAnonymousRole != "" {
 		return db.AnonymousUser(api.authAnonymousRole)
@@ -259,6 +353,14 @@ func (api *Api) GetUser(r *http.Request) *db.User {
 }
 
 func (api *Api) IsAllowed(u *db.User, actions ...rbac.Action) bool {
+	if u.Permissions != nil {
+		for _, action := range actions {
+			if u.Permissions.Allows(action) {
+				return true
+			}
+		}
+		return false
+	}
 	roles, err := api.roles.GetRoles()
 	if err != nil {
 		klog.Errorln(err)
@@ -279,3 +381,25 @@ func (api *Api) IsAllowed(u *db.User, actions ...rbac.Action) bool {
 	}
 	return false
 }
+
+// IsAllowedUnrestricted reports whether the user is allowed to perform the action on any object, i.e.,
+// the permission is not limited by object selectors to, for example, certain applications.
+func (api *Api) IsAllowedUnrestricted(u *db.User, action rbac.Action) bool {
+	if u.Permissions != nil {
+		return u.Permissions.AllowsUnrestricted(action)
+	}
+	roles, err := api.roles.GetRoles()
+	if err != nil {
+		klog.Errorln(err)
+		return false
+	}
+
+	for _, rn := range u.Roles {
+		for _, r := range roles {
+			if r.Name == rn && r.Permissions.AllowsUnrestricted(action) {
+				return true
+			}
+		}
+	}
+	return false
+}
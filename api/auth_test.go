@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginForceSSO(t *testing.T) {
+	database, err := db.NewSqlite(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, database.Migrate())
+	require.NoError(t, database.CreateAdminIfNotExists("admin-password"))
+	require.NoError(t, database.AddUser("alice@example.com", "alice-password", "Alice", rbac.RoleViewer))
+	require.NoError(t, database.SaveSSOSettings(db.SSOSettings{Enabled: true, ForceSSO: true}))
+	api := &Api{db: database, authSecret: "secret"}
+
+	login := func(email, password string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"email": "`+email+`", "password": "`+password+`"}`))
+		w := httptest.NewRecorder()
+		api.Login(w, r)
+		return w
+	}
+
+	w := login("alice@example.com", "alice-password")
+	assert.Equal(t, http.StatusForbidden, w.Code, "password login is disabled")
+
+	w = login(db.AdminUserLogin, "admin-password")
+	assert.Equal(t, http.StatusOK, w.Code, "the built-in admin can still log in with a password")
+	assert.NotEmpty(t, w.Result().Cookies())
+
+	w = login(db.AdminUserLogin, "wrong")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -39,9 +39,9 @@ func (api *Api) ClickhouseConnect(w http.ResponseWriter, r *http.Request) {
 		klog.Warningln("no api key")
 		return
 	}
-	project, err := api.getProjectByApiKey(apiKey)
+	project, _, err := api.getProjectByApiKey(apiKey, db.ApiKeyScopeAll)
 	if err != nil {
-		_ = binary.Write(clientConn, binary.LittleEndian, uint32(http.StatusInternalServerError))
+		_ = binary.Write(clientConn, binary.LittleEndian, uint32(apiKeyErrorStatus(err)))
 		klog.Errorln(err)
 		return
 	}
@@ -50,6 +50,7 @@ func (api *Api) Cloud(w http.ResponseWriter, r *http.Request, u *db.User) {
 			http.Error(w, "", http.StatusBadRequest)
 			return
 		}
+		before := settings
 		settings.ApiKey = form.ApiKey
 		settings.RCA.DisableIncidentsAutoInvestigation = !form.IncidentsAutoInvestigation
 		if err = cloudAPI.SaveSettings(settings); err != nil {
@@ -57,6 +58,7 @@ func (api *Api) Cloud(w http.ResponseWriter, r *http.Request, u *db.User) {
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		api.audit(u, "", db.AuditObjectCloud, "", db.AuditActionUpdate, auditCloudSettings(before), auditCloudSettings(settings))
 		return
 	}
 
@@ -77,3 +79,11 @@ func (api *Api) Cloud(w http.ResponseWriter, r *http.Request, u *db.User) {
 	res.Form.IncidentsAutoInvestigation = !settings.RCA.DisableIncidentsAutoInvestigation
 	utils.WriteJson(w, res)
 }
+
+// auditCloudSettings returns the settings with the API key masked.
+func auditCloudSettings(s cloud.Settings) cloud.Settings {
+	if len(s.ApiKey) > 4 {
+		s.ApiKey = "..." + s.ApiKey[len(s.ApiKey)-4:]
+	}
+	return s
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/coroot/coroot/api/views/overview"
 	"github.com/coroot/coroot/cache"
+	"github.com/coroot/coroot/collector"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/utils"
@@ -37,6 +38,8 @@ type Status struct {
 	Prometheus       Prometheus        `json:"prometheus"`
 	NodeAgent        NodeAgent         `json:"node_agent"`
 	KubeStateMetrics *KubeStateMetrics `json:"kube_state_metrics"`
+
+	Ingestion *collector.IngestionUsage `json:"ingestion,omitempty"`
 }
 
 type Prometheus struct {
@@ -1,6 +1,7 @@
 package forms
 
 import (
+	"encoding/json"
 	"strings"
 
 	"github.com/coroot/coroot/rbac"
@@ -50,3 +51,95 @@ func (f *UserForm) Valid() bool {
 	f.Name = strings.TrimSpace(f.Name)
 	return f.Email != "" && f.Name != ""
 }
+
+type SSOAction string
+
+const (
+	SSOActionSave    SSOAction = "save"
+	SSOActionDisable SSOAction = "disable"
+	SSOActionUpload  SSOAction = "upload"
+)
+
+type SSOForm struct {
+	Action      SSOAction     `json:"action"`
+	Provider    string        `json:"provider"`
+	DefaultRole rbac.RoleName `json:"default_role"`
+	ForceSSO    bool          `json:"force_sso"`
+	OIDC        *OIDCForm     `json:"oidc"`
+}
+
+type OIDCForm struct {
+	IssuerURL    string                   `json:"issuer_url"`
+	ClientId     string                   `json:"client_id"`
+	ClientSecret string                   `json:"client_secret"`
+	RolesClaim   string                   `json:"roles_claim"`
+	RoleMapping  map[string]rbac.RoleName `json:"role_mapping"`
+}
+
+func (f *SSOForm) Valid() bool {
+	switch f.Action {
+	case SSOActionDisable, SSOActionUpload:
+		return true
+	case SSOActionSave:
+		if f.OIDC == nil {
+			return f.Provider != "oidc"
+		}
+		f.OIDC.IssuerURL = strings.TrimSpace(f.OIDC.IssuerURL)
+		f.OIDC.ClientId = strings.TrimSpace(f.OIDC.ClientId)
+		f.OIDC.RolesClaim = strings.TrimSpace(f.OIDC.RolesClaim)
+		return f.OIDC.IssuerURL != "" && f.OIDC.ClientId != ""
+	}
+	return false
+}
+
+type RoleAction string
+
+const (
+	RoleActionAdd    RoleAction = "add"
+	RoleActionEdit   RoleAction = "edit"
+	RoleActionDelete RoleAction = "delete"
+)
+
+type RoleForm struct {
+	Action      RoleAction           `json:"action"`
+	Id          rbac.RoleName        `json:"id"`
+	Name        rbac.RoleName        `json:"name"`
+	Permissions []RolePermissionForm `json:"permissions"`
+
+	Role rbac.Role `json:"-"`
+}
+
+type RolePermissionForm struct {
+	Scope  rbac.Scope `json:"scope"`
+	Action rbac.Verb  `json:"action"`
+	// Object is either a JSON object or a string containing one (as entered in the UI); an empty string or "*" means any object.
+	Object json.RawMessage `json:"object"`
+}
+
+func (f *RoleForm) Valid() bool {
+	if f.Action == RoleActionDelete {
+		return f.Id != ""
+	}
+	if f.Action == RoleActionEdit && f.Id == "" {
+		return false
+	}
+	f.Role = rbac.Role{Name: rbac.RoleName(strings.TrimSpace(string(f.Name)))}
+	for _, p := range f.Permissions {
+		permission := rbac.NewPermission(p.Scope, p.Action, nil)
+		var s string
+		if err := json.Unmarshal(p.Object, &s); err == nil {
+			s = strings.TrimSpace(s)
+			if s != "" && s != "*" {
+				if err = json.Unmarshal([]byte(s), &permission.Object); err != nil {
+					return false
+				}
+			}
+		} else if len(p.Object) > 0 && string(p.Object) != "null" {
+			if err = json.Unmarshal(p.Object, &permission.Object); err != nil {
+				return false
+			}
+		}
+		f.Role.Permissions = append(f.Role.Permissions, permission)
+	}
+	return true
+}
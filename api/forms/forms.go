@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/coroot/coroot/clickhouse"
@@ -70,6 +71,14 @@ type ApiKeyForm struct {
 }
 
 func (f *ApiKeyForm) Valid() bool {
+	for _, scope := range f.Scopes {
+		if !slices.Contains(db.ApiKeyScopes, scope) {
+			return false
+		}
+	}
+	if f.Action == "generate" && !f.ExpiresAt.IsZero() && !f.ExpiresAt.After(timeseries.Now()) {
+		return false
+	}
 	return true
 }
 
@@ -82,6 +91,37 @@ func (f *DashboardForm) Valid() bool {
 	return f.Name != ""
 }
 
+type SilenceForm struct {
+	StartsAt timeseries.Time       `json:"starts_at"`
+	EndsAt   timeseries.Time       `json:"ends_at"`
+	Comment  string                `json:"comment"`
+	Matchers model.SilenceMatchers `json:"matchers"`
+}
+
+func (f *SilenceForm) Valid() bool {
+	if f.StartsAt.IsZero() {
+		f.StartsAt = timeseries.Now()
+	}
+	if !f.EndsAt.After(f.StartsAt) {
+		return false
+	}
+	if f.Matchers.IsEmpty() {
+		return false
+	}
+	if !utils.GlobValidate(f.Matchers.ApplicationIdPatterns) {
+		return false
+	}
+	for i := range f.Matchers.Labels {
+		l := &f.Matchers.Labels[i]
+		l.Name = strings.TrimSpace(l.Name)
+		if l.Name == "" || !utils.GlobValidate([]string{l.Value}) {
+			return false
+		}
+	}
+	f.Comment = strings.TrimSpace(f.Comment)
+	return true
+}
+
 type CheckConfigForm struct {
 	Configs []*model.CheckConfigSimple `json:"configs"`
 }
@@ -177,6 +217,27 @@ func (f *ApplicationCategoryForm) Valid() bool {
 			return false
 		}
 	}
+	for _, w := range f.MaintenanceWindows {
+		if w.Validate() != nil {
+			return false
+		}
+	}
+	ns := f.NotificationSettings
+	for _, d := range []db.ApplicationCategoryNotificationDestinations{
+		ns.Incidents.ApplicationCategoryNotificationDestinations,
+		ns.Deployments.ApplicationCategoryNotificationDestinations,
+		ns.Alerts.ApplicationCategoryNotificationDestinations,
+	} {
+		if d.Validate() != nil {
+			return false
+		}
+	}
+	if g := f.NotificationSettings.Alerts.Grouping; g != nil && g.Validate() != nil {
+		return false
+	}
+	if e := f.NotificationSettings.Alerts.Escalation; e != nil && e.Validate() != nil {
+		return false
+	}
 	return true
 }
 
@@ -203,6 +264,21 @@ func (f *ApplicationCategoryForm) SendTestNotification(ctx context.Context, proj
 		if webhook := f.Test.Incident.Webhook; webhook != nil && integrations.Webhook != nil {
 			client = notifications.NewWebhook(integrations.Webhook)
 		}
+		if email := f.Test.Incident.Email; email != nil && integrations.Email != nil {
+			client = notifications.NewEmail(integrations.Email, email.To)
+		}
+		if telegram := f.Test.Incident.Telegram; telegram != nil && integrations.Telegram != nil {
+			client = notifications.NewTelegram(integrations.Telegram.BotToken, cmp.Or(telegram.ChatId, integrations.Telegram.DefaultChatId))
+		}
+		if discord := f.Test.Incident.Discord; discord != nil && integrations.Discord != nil {
+			client = notifications.NewDiscord(integrations.Discord.WebhookUrl)
+		}
+		if googleChat := f.Test.Incident.GoogleChat; googleChat != nil && integrations.GoogleChat != nil {
+			client = notifications.NewGoogleChat(integrations.GoogleChat.WebhookUrl)
+		}
+		if mattermost := f.Test.Incident.Mattermost; mattermost != nil && integrations.Mattermost != nil {
+			client = notifications.NewMattermost(integrations.Mattermost.WebhookUrl, cmp.Or(mattermost.Channel, integrations.Mattermost.DefaultChannel))
+		}
 		if client != nil {
 			return client.SendIncident(ctx, integrations.BaseUrl, testIncidentNotification(project))
 		}
@@ -216,6 +292,21 @@ func (f *ApplicationCategoryForm) SendTestNotification(ctx context.Context, proj
 		if webhook := f.Test.Deployment.Webhook; webhook != nil && integrations.Webhook != nil {
 			client = notifications.NewWebhook(integrations.Webhook)
 		}
+		if email := f.Test.Deployment.Email; email != nil && integrations.Email != nil {
+			client = notifications.NewEmail(integrations.Email, email.To)
+		}
+		if telegram := f.Test.Deployment.Telegram; telegram != nil && integrations.Telegram != nil {
+			client = notifications.NewTelegram(integrations.Telegram.BotToken, cmp.Or(telegram.ChatId, integrations.Telegram.DefaultChatId))
+		}
+		if discord := f.Test.Deployment.Discord; discord != nil && integrations.Discord != nil {
+			client = notifications.NewDiscord(integrations.Discord.WebhookUrl)
+		}
+		if googleChat := f.Test.Deployment.GoogleChat; googleChat != nil && integrations.GoogleChat != nil {
+			client = notifications.NewGoogleChat(integrations.GoogleChat.WebhookUrl)
+		}
+		if mattermost := f.Test.Deployment.Mattermost; mattermost != nil && integrations.Mattermost != nil {
+			client = notifications.NewMattermost(integrations.Mattermost.WebhookUrl, cmp.Or(mattermost.Channel, integrations.Mattermost.DefaultChannel))
+		}
 		if client != nil {
 			return client.SendDeployment(ctx, project, testDeploymentNotification(project))
 		}
@@ -253,6 +344,24 @@ func (f *CustomCloudPricingForm) Valid() bool {
 	return true
 }
 
+type RedactionRulesForm struct {
+	Rules []db.RedactionRule `json:"rules"`
+}
+
+type RedactionDryRunForm struct {
+	Signal  string             `json:"signal"`
+	Payload string             `json:"payload"`
+	Rules   []db.RedactionRule `json:"rules"`
+}
+
+type TraceSamplingForm struct {
+	db.TraceSampling
+}
+
+type IngestionLimitsForm struct {
+	db.IngestionLimits
+}
+
 type ApplicationInstrumentationForm struct {
 	model.ApplicationInstrumentation
 }
@@ -332,6 +441,16 @@ func NewIntegrationForm(t db.IntegrationType, globalClickHouse *db.IntegrationCl
 		return &IntegrationFormOpsgenie{}
 	case db.IntegrationTypeWebhook:
 		return &IntegrationFormWebhook{}
+	case db.IntegrationTypeEmail:
+		return &IntegrationFormEmail{}
+	case db.IntegrationTypeTelegram:
+		return &IntegrationFormTelegram{}
+	case db.IntegrationTypeDiscord:
+		return &IntegrationFormDiscord{}
+	case db.IntegrationTypeGoogleChat:
+		return &IntegrationFormGoogleChat{}
+	case db.IntegrationTypeMattermost:
+		return &IntegrationFormMattermost{}
 	}
 	return nil
 }
@@ -372,10 +491,11 @@ func (f *IntegrationFormPrometheus) Get(project *db.Project, masked bool) {
 	}
 	if masked {
 		f.Url = "http://<hidden>"
+		// the config is shared with the project, so the pointer and the slice must not be modified in place
 		if f.BasicAuth != nil {
-			f.BasicAuth.User = "<hidden>"
-			f.BasicAuth.Password = "<hidden>"
+			f.BasicAuth = &utils.BasicAuth{User: "<hidden>", Password: "<hidden>"}
 		}
+		f.CustomHeaders = slices.Clone(f.CustomHeaders)
 		for i := range f.CustomHeaders {
 			f.CustomHeaders[i].Value = "<hidden>"
 		}
@@ -722,6 +842,201 @@ func (f *IntegrationFormWebhook) Test(ctx context.Context, project *db.Project)
 	return nil
 }
 
+type IntegrationFormEmail struct {
+	db.IntegrationEmail
+}
+
+func (f *IntegrationFormEmail) Valid() bool {
+	if f.TLS == "" {
+		f.TLS = db.EmailTLSStartTLS
+	}
+	if err := f.Validate(); err != nil {
+		return false
+	}
+	return true
+}
+
+func (f *IntegrationFormEmail) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Email
+	if cfg == nil {
+		f.Port = 587
+		f.TLS = db.EmailTLSStartTLS
+		f.Incidents = true
+		f.Deployments = true
+		f.Alerts = ptrBool(true)
+		return
+	}
+	f.IntegrationEmail = *cfg
+	if masked && f.Password != "" {
+		f.Password = "<hidden>"
+	}
+}
+
+func (f *IntegrationFormEmail) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationEmail
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.Email = cfg
+	return nil
+}
+
+func (f *IntegrationFormEmail) Test(ctx context.Context, project *db.Project) error {
+	return notifications.NewEmail(&f.IntegrationEmail, nil).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testIncidentNotification(project))
+}
+
+type IntegrationFormTelegram struct {
+	db.IntegrationTelegram
+}
+
+func (f *IntegrationFormTelegram) Valid() bool {
+	if err := f.Validate(); err != nil {
+		return false
+	}
+	return true
+}
+
+func (f *IntegrationFormTelegram) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Telegram
+	if cfg == nil {
+		f.Incidents = true
+		f.Deployments = true
+		f.Alerts = ptrBool(true)
+		return
+	}
+	f.IntegrationTelegram = *cfg
+	if masked {
+		f.BotToken = "<hidden>"
+	}
+}
+
+func (f *IntegrationFormTelegram) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationTelegram
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.Telegram = cfg
+	return nil
+}
+
+func (f *IntegrationFormTelegram) Test(ctx context.Context, project *db.Project) error {
+	return notifications.NewTelegram(f.BotToken, f.DefaultChatId).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testIncidentNotification(project))
+}
+
+type IntegrationFormDiscord struct {
+	db.IntegrationDiscord
+}
+
+func (f *IntegrationFormDiscord) Valid() bool {
+	if err := f.Validate(); err != nil {
+		return false
+	}
+	return true
+}
+
+func (f *IntegrationFormDiscord) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Discord
+	if cfg == nil {
+		f.Incidents = true
+		f.Deployments = true
+		f.Alerts = ptrBool(true)
+		return
+	}
+	f.IntegrationDiscord = *cfg
+	if masked {
+		f.WebhookUrl = "<hidden>"
+	}
+}
+
+func (f *IntegrationFormDiscord) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationDiscord
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.Discord = cfg
+	return nil
+}
+
+func (f *IntegrationFormDiscord) Test(ctx context.Context, project *db.Project) error {
+	return notifications.NewDiscord(f.WebhookUrl).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testIncidentNotification(project))
+}
+
+type IntegrationFormGoogleChat struct {
+	db.IntegrationGoogleChat
+}
+
+func (f *IntegrationFormGoogleChat) Valid() bool {
+	if err := f.Validate(); err != nil {
+		return false
+	}
+	return true
+}
+
+func (f *IntegrationFormGoogleChat) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.GoogleChat
+	if cfg == nil {
+		f.Incidents = true
+		f.Deployments = true
+		f.Alerts = ptrBool(true)
+		return
+	}
+	f.IntegrationGoogleChat = *cfg
+	if masked {
+		f.WebhookUrl = "<hidden>"
+	}
+}
+
+func (f *IntegrationFormGoogleChat) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationGoogleChat
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.GoogleChat = cfg
+	return nil
+}
+
+func (f *IntegrationFormGoogleChat) Test(ctx context.Context, project *db.Project) error {
+	return notifications.NewGoogleChat(f.WebhookUrl).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testIncidentNotification(project))
+}
+
+type IntegrationFormMattermost struct {
+	db.IntegrationMattermost
+}
+
+func (f *IntegrationFormMattermost) Valid() bool {
+	if err := f.Validate(); err != nil {
+		return false
+	}
+	return true
+}
+
+func (f *IntegrationFormMattermost) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Mattermost
+	if cfg == nil {
+		f.Incidents = true
+		f.Deployments = true
+		f.Alerts = ptrBool(true)
+		return
+	}
+	f.IntegrationMattermost = *cfg
+	if masked {
+		f.WebhookUrl = "<hidden>"
+	}
+}
+
+func (f *IntegrationFormMattermost) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationMattermost
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.Mattermost = cfg
+	return nil
+}
+
+func (f *IntegrationFormMattermost) Test(ctx context.Context, project *db.Project) error {
+	return notifications.NewMattermost(f.WebhookUrl, f.DefaultChannel).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testIncidentNotification(project))
+}
+
 func testIncidentNotification(project *db.Project) *db.IncidentNotification {
 	return &db.IncidentNotification{
 		ProjectId:     project.Id,
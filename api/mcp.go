@@ -175,7 +175,7 @@ func (h *MCPHandler) registerTools() {
 	)
 	h.AddTool(
 		mcp.NewTool("list_alerts",
-			mcp.WithDescription("List alerts for the selected project. Use state to choose: 'firing' (active triage, default), 'resolved' (most-recent resolved history), or 'any' (mixed, sorted by opened time)."),
+			mcp.WithDescription("List alerts for the selected project. Use state to choose: 'firing' (active triage, default), 'resolved' (most-recent resolved history), or 'any' (mixed, sorted by opened time). Firing alerts with acknowledged_at set are already being handled by someone (acknowledged_by)."),
 			mcp.WithString("state", mcp.Description("'firing' | 'resolved' | 'any'. Default: 'firing'.")),
 			mcp.WithString("app_id", mcp.Description("Filter to one application (id from list_applications).")),
 			mcp.WithNumber("limit", mcp.Description("Max alerts to return. Default: 100, max: 1000.")),
@@ -1054,6 +1054,7 @@ func (h *MCPHandler) toolResolveAlerts(ctx context.Context, req mcp.CallToolRequ
 		klog.Errorln("mcp: resolve_alerts:", err)
 		return mcp.NewToolResultError("failed to resolve alerts"), nil
 	}
+	h.Api.auditAlerts(user, project.Id, ids, db.AuditActionResolve, map[string]string{"resolved_by": resolvedBy})
 	return MCPJSON(map[string]any{"resolved": len(ids), "notified": notified})
 }
 
@@ -1293,7 +1294,8 @@ func (h *MCPHandler) runTracesQuery(ctx context.Context, req mcp.CallToolRequest
 	if errResult != nil {
 		return nil, errResult
 	}
-	if !h.Api.IsAllowed(user, rbac.Actions.Project(string(project.Id)).Traces().View()) {
+	tracesAction := rbac.Actions.Project(string(project.Id)).Application("*", "*", "*", "*").Traces().View()
+	if !h.Api.IsAllowed(user, tracesAction) {
 		return nil, mcp.NewToolResultError("forbidden: no permission to view traces in this project")
 	}
 	from, to, _, _ := h.Api.getTimeContext(project.Id, req.GetString("from", ""), req.GetString("to", ""), "", "")
@@ -1302,6 +1304,15 @@ func (h *MCPHandler) runTracesQuery(ctx context.Context, req mcp.CallToolRequest
 		klog.Errorln("mcp: traces:", err)
 		return nil, mcp.NewToolResultError("failed to load world")
 	}
+	if !h.Api.IsAllowedUnrestricted(user, tracesAction) {
+		service := req.GetString("service", "")
+		if service == "" || q.TraceId != "" {
+			return nil, mcp.NewToolResultError("forbidden: traces can only be viewed for specific applications; pass the 'service' of one of them")
+		}
+		if !h.tracesServiceAllowed(user, project, world, service) {
+			return nil, mcp.NewToolResultError("forbidden: no permission to view traces of this service")
+		}
+	}
 	chs := h.Api.GetClickhouseClients(project)
 	defer chs.Close()
 	if s := req.GetString("service", ""); s != "" {
@@ -1318,6 +1329,25 @@ func (h *MCPHandler) runTracesQuery(ctx context.Context, req mcp.CallToolRequest
 	return res, nil
 }
 
+// tracesServiceAllowed reports whether the OpenTelemetry service belongs to an application whose traces the user can view.
+func (h *MCPHandler) tracesServiceAllowed(user *db.User, project *db.Project, world *model.World, service string) bool {
+	for _, app := range world.Applications {
+		var linked string
+		if app.Settings != nil && app.Settings.Tracing != nil {
+			linked = app.Settings.Tracing.Service
+		} else {
+			linked = model.GuessService([]string{service}, world, app)
+		}
+		if linked != service {
+			continue
+		}
+		if h.Api.IsAllowed(user, rbac.Actions.Project(string(project.Id)).Application(app.Category, app.Id.Namespace, app.Id.Kind, app.Id.Name).Traces().View()) {
+			return true
+		}
+	}
+	return false
+}
+
 func (h *MCPHandler) toolTracesSummary(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	res, errResult := h.runTracesQuery(ctx, req, overview.Query{})
 	if errResult != nil {
@@ -1454,7 +1484,8 @@ func (h *MCPHandler) toolQueryLogs(ctx context.Context, req mcp.CallToolRequest)
 	if errResult != nil {
 		return errResult, nil
 	}
-	if !h.Api.IsAllowed(user, rbac.Actions.Project(string(project.Id)).Logs().View()) {
+	logsAction := rbac.Actions.Project(string(project.Id)).Application("*", "*", "*", "*").Logs().View()
+	if !h.Api.IsAllowed(user, logsAction) {
 		return mcp.NewToolResultError("forbidden: no permission to view logs in this project"), nil
 	}
 
@@ -1483,6 +1514,12 @@ func (h *MCPHandler) toolQueryLogs(ctx context.Context, req mcp.CallToolRequest)
 	if errResult != nil {
 		return errResult, nil
 	}
+	if app == nil && !h.Api.IsAllowedUnrestricted(user, logsAction) {
+		return mcp.NewToolResultError("forbidden: logs can only be viewed for specific applications; pass the 'app_id' of one of them"), nil
+	}
+	if app != nil && !h.Api.IsAllowed(user, rbac.Actions.Project(string(project.Id)).Application(app.Category, app.Id.Namespace, app.Id.Kind, app.Id.Name).Logs().View()) {
+		return mcp.NewToolResultError("forbidden: no permission to view logs of this application"), nil
+	}
 	clusterId := ""
 	if app != nil {
 		clusterId = app.Id.ClusterId
@@ -71,36 +71,8 @@ type mcpTokenClaims struct {
 	jwt.RegisteredClaims
 }
 
-func (api *Api) mcpAuthSecret() ([]byte, error) {
-	var secret string
-	if err := api.db.GetSetting(AuthSecretSettingName, &secret); err != nil {
-		return nil, fmt.Errorf("failed to get auth secret: %w", err)
-	}
-	return []byte(secret), nil
-}
-
-func (api *Api) mcpSign(claims jwt.Claims) (string, error) {
-	secret, err := api.mcpAuthSecret()
-	if err != nil {
-		return "", err
-	}
-	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
-}
-
-func (api *Api) mcpVerify(audience string, token string, out jwt.Claims) error {
-	secret, err := api.mcpAuthSecret()
-	if err != nil {
-		return err
-	}
-	_, err = jwt.ParseWithClaims(token, out, func(*jwt.Token) (any, error) { return secret, nil },
-		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
-		jwt.WithAudience(audience),
-	)
-	return err
-}
-
 func (api *Api) mcpIssueToken(audience string, userId int, clientId string, ttl time.Duration) (string, error) {
-	return api.mcpSign(mcpTokenClaims{
+	return api.signToken(mcpTokenClaims{
 		ClientId: clientId,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   strconv.Itoa(userId),
@@ -117,7 +89,7 @@ func (api *Api) MCPUserFromBearer(r *http.Request) *db.User {
 	}
 	token := strings.TrimPrefix(auth, mcpBearerPrefix)
 	var claims mcpTokenClaims
-	if err := api.mcpVerify(mcpAudAccess, token, &claims); err != nil {
+	if err := api.verifyToken(mcpAudAccess, token, &claims); err != nil {
 		return nil
 	}
 	userId, err := strconv.Atoi(claims.Subject)
@@ -194,7 +166,7 @@ func (api *Api) MCPOAuthRegister(w http.ResponseWriter, r *http.Request) {
 	if len(req.ClientName) > mcpOAuthMaxClientNameLen {
 		req.ClientName = req.ClientName[:mcpOAuthMaxClientNameLen]
 	}
-	clientId, err := api.mcpSign(mcpClientClaims{
+	clientId, err := api.signToken(mcpClientClaims{
 		Name:         req.ClientName,
 		RedirectURIs: req.RedirectURIs,
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -248,7 +220,7 @@ func (api *Api) MCPOAuthAuthorize(w http.ResponseWriter, r *http.Request) {
 	req := parseAuthorizeRequest(r)
 
 	var client mcpClientClaims
-	if err := api.mcpVerify(mcpAudClient, req.ClientId, &client); err != nil {
+	if err := api.verifyToken(mcpAudClient, req.ClientId, &client); err != nil {
 		mcpOAuthError(w, http.StatusBadRequest, oauthErrUnauthorizedClient, "unknown client")
 		return
 	}
@@ -292,7 +264,7 @@ func (api *Api) mcpAuthorizeIssueCode(w http.ResponseWriter, r *http.Request, re
 		return
 	}
 
-	code, err := api.mcpSign(mcpCodeClaims{
+	code, err := api.signToken(mcpCodeClaims{
 		ClientId:      req.ClientId,
 		RedirectURI:   req.RedirectURI,
 		CodeChallenge: req.CodeChallenge,
@@ -379,7 +351,7 @@ func (api *Api) mcpExchangeCode(w http.ResponseWriter, r *http.Request) {
 	verifier := r.Form.Get("code_verifier")
 
 	var code mcpCodeClaims
-	if err := api.mcpVerify(mcpAudCode, codeStr, &code); err != nil {
+	if err := api.verifyToken(mcpAudCode, codeStr, &code); err != nil {
 		mcpOAuthError(w, http.StatusBadRequest, oauthErrInvalidGrant, "invalid code")
 		return
 	}
@@ -408,7 +380,7 @@ func (api *Api) mcpExchangeRefresh(w http.ResponseWriter, r *http.Request) {
 	clientId := r.Form.Get("client_id")
 
 	var claims mcpTokenClaims
-	if err := api.mcpVerify(mcpAudRefresh, refresh, &claims); err != nil {
+	if err := api.verifyToken(mcpAudRefresh, refresh, &claims); err != nil {
 		mcpOAuthError(w, http.StatusBadRequest, oauthErrInvalidGrant, "invalid refresh_token")
 		return
 	}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/rbac"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSilencesApi(t *testing.T) {
+	database, err := db.NewSqlite(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, database.Migrate())
+	p := &db.Project{Name: "p"}
+	require.NoError(t, database.SaveProject(p))
+	api := &Api{db: database, roles: rbac.NewStaticRoleManager()}
+	admin := &db.User{Id: 1, Name: "alice", Roles: []rbac.RoleName{rbac.RoleAdmin}}
+	viewer := &db.User{Id: 2, Name: "bob", Roles: []rbac.RoleName{rbac.RoleViewer}}
+
+	call := func(h func(http.ResponseWriter, *http.Request, *db.User), u *db.User, method, body string, vars map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/", strings.NewReader(body))
+		r = mux.SetURLVars(r, vars)
+		w := httptest.NewRecorder()
+		h(w, r, u)
+		return w
+	}
+	projectVars := map[string]string{"project": string(p.Id)}
+	now := timeseries.Now()
+	form := func(endsAt timeseries.Time, matchers string) string {
+		startsAt, _ := json.Marshal(now.Add(-timeseries.Minute))
+		end, _ := json.Marshal(endsAt)
+		return `{"starts_at": ` + string(startsAt) + `, "ends_at": ` + string(end) + `, "comment": " deploy ", "matchers": ` + matchers + `}`
+	}
+
+	w := call(api.Silences, admin, http.MethodPost, form(now.Add(timeseries.Hour), `{}`), projectVars)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "at least one matcher is required")
+	w = call(api.Silences, admin, http.MethodPost, form(now.Add(-timeseries.Hour), `{"rule_ids": ["cpu"]}`), projectVars)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "the silence must end after it starts")
+	w = call(api.Silences, viewer, http.MethodPost, form(now.Add(timeseries.Hour), `{"rule_ids": ["cpu"]}`), projectVars)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = call(api.Silences, admin, http.MethodPost, form(now.Add(timeseries.Hour), `{"application_id_patterns": ["default:*:api"]}`), projectVars)
+	require.Equal(t, http.StatusOK, w.Code)
+	var created model.Silence
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "alice", created.CreatedBy)
+	assert.Equal(t, "deploy", created.Comment)
+
+	w = call(api.Silences, viewer, http.MethodGet, "", projectVars)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []model.Silence
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+
+	silenceVars := map[string]string{"project": string(p.Id), "silence": created.Id}
+	w = call(api.Silence, viewer, http.MethodPut, form(now.Add(timeseries.Hour), `{"rule_ids": ["cpu"]}`), silenceVars)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = call(api.Silence, admin, http.MethodPut, form(now.Add(2*timeseries.Hour), `{"rule_ids": ["cpu"]}`), silenceVars)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	active, err := database.GetActiveSilences(p.Id, now)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	appId := model.NewApplicationId("c1", "default", model.ApplicationKindDeployment, "api")
+	assert.NotNil(t, active.FindForAlert(&model.Alert{RuleId: "cpu", ApplicationId: appId}, now))
+	assert.Nil(t, active.FindForAlert(&model.Alert{RuleId: "memory", ApplicationId: appId}, now), "the matchers have been replaced")
+
+	w = call(api.Silence, admin, http.MethodDelete, "", silenceVars)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	active, err = database.GetActiveSilences(p.Id, timeseries.Now())
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	w = call(api.Silence, admin, http.MethodGet, "", map[string]string{"project": string(p.Id), "silence": "unknown"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	audit, err := database.QueryAuditLog(db.AuditQuery{ProjectId: p.Id, ObjectType: db.AuditObjectSilence})
+	require.NoError(t, err)
+	assert.Equal(t, 3, audit.Total, "create, update, and delete are audited")
+}
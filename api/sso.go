@@ -0,0 +1,340 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coroot/coroot/api/forms"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/oidc"
+	"github.com/coroot/coroot/rbac"
+	"github.com/coroot/coroot/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"k8s.io/klog"
+)
+
+const (
+	ssoStateCookieName = "coroot_sso_state"
+	ssoStateTTL        = 10 * time.Minute
+	ssoAudState        = "sso:state"
+	ssoOIDCCallbackUri = "sso/oidc"
+)
+
+type ssoStateClaims struct {
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	Next         string `json:"next"`
+	jwt.RegisteredClaims
+}
+
+type oidcProviderCache struct {
+	lock     sync.Mutex
+	provider *oidc.Provider
+}
+
+// get returns the provider for the given settings, running the discovery only if the issuer or the client changed.
+func (c *oidcProviderCache) get(ctx context.Context, settings db.SSOSettings) (*oidc.Provider, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	cfg := oidc.Config{
+		IssuerURL:    settings.IssuerURL,
+		ClientId:     settings.ClientId,
+		ClientSecret: settings.ClientSecret,
+		Scopes:       settings.Scopes,
+	}
+	if p := c.provider; p != nil {
+		pc := p.Config()
+		if pc.IssuerURL == cfg.IssuerURL && pc.ClientId == cfg.ClientId && pc.ClientSecret == cfg.ClientSecret && slices.Equal(pc.Scopes, cfg.Scopes) {
+			return p, nil
+		}
+	}
+	p, err := oidc.Discover(ctx, cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.provider = p
+	return p, nil
+}
+
+func (api *Api) SSO(w http.ResponseWriter, r *http.Request, u *db.User) {
+	settings, err := api.db.GetSSOSettings()
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	roles, err := api.roles.GetRoles()
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		if !api.IsAllowed(u, rbac.Actions.Users().Edit()) {
+			http.Error(w, "You are not allowed to configure SSO.", http.StatusForbidden)
+			return
+		}
+		if settings.Readonly {
+			http.Error(w, "SSO is configured through the config and cannot be modified via the UI.", http.StatusForbidden)
+			return
+		}
+		var form forms.SSOForm
+		if err = forms.ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid data.", http.StatusBadRequest)
+			return
+		}
+		before := settings
+		switch form.Action {
+		case forms.SSOActionDisable:
+			settings.Enabled = false
+		case forms.SSOActionSave:
+			if form.Provider != db.SSOProviderOIDC {
+				http.Error(w, "Only the OIDC provider is supported.", http.StatusBadRequest)
+				return
+			}
+			if !form.DefaultRole.Valid(roles) {
+				http.Error(w, "Invalid default role.", http.StatusBadRequest)
+				return
+			}
+			settings.Enabled = true
+			settings.Provider = form.Provider
+			settings.ForceSSO = form.ForceSSO
+			settings.DefaultRole = form.DefaultRole
+			settings.IssuerURL = form.OIDC.IssuerURL
+			settings.ClientId = form.OIDC.ClientId
+			if form.OIDC.ClientSecret != "" {
+				settings.ClientSecret = form.OIDC.ClientSecret
+			}
+			if form.OIDC.RolesClaim != "" {
+				settings.RolesClaim = form.OIDC.RolesClaim
+			}
+			if form.OIDC.RoleMapping != nil {
+				for _, role := range form.OIDC.RoleMapping {
+					if !role.Valid(roles) {
+						http.Error(w, "Invalid role mapping: unknown role "+string(role)+".", http.StatusBadRequest)
+						return
+					}
+				}
+				settings.RoleMapping = form.OIDC.RoleMapping
+			}
+			if err = settings.Validate(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+			defer cancel()
+			if _, err = api.oidc.get(ctx, settings); err != nil {
+				klog.Warningln("oidc discovery failed:", err)
+				http.Error(w, "Failed to discover the OIDC provider: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			http.Error(w, "SAML is not supported.", http.StatusBadRequest)
+			return
+		}
+		if err = api.db.SaveSSOSettings(settings); err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		api.audit(u, "", db.AuditObjectSSO, "", db.AuditActionUpdate, auditSSOSettings(before), auditSSOSettings(settings))
+		return
+	}
+
+	type oidcSettings struct {
+		IssuerURL   string                   `json:"issuer_url"`
+		ClientId    string                   `json:"client_id"`
+		RolesClaim  string                   `json:"roles_claim"`
+		RoleMapping map[string]rbac.RoleName `json:"role_mapping"`
+	}
+	res := struct {
+		Readonly    bool            `json:"readonly"`
+		Enabled     bool            `json:"enabled"`
+		ForceSSO    bool            `json:"force_sso"`
+		SSOProvider string          `json:"sso_provider"`
+		DefaultRole rbac.RoleName   `json:"default_role"`
+		Roles       []rbac.RoleName `json:"roles"`
+		OIDC        oidcSettings    `json:"oidc"`
+	}{
+		Readonly:    settings.Readonly,
+		Enabled:     settings.Enabled,
+		ForceSSO:    settings.ForceSSO,
+		SSOProvider: settings.Provider,
+		DefaultRole: settings.DefaultRole,
+		OIDC: oidcSettings{
+			IssuerURL:   settings.IssuerURL,
+			ClientId:    settings.ClientId,
+			RolesClaim:  settings.RolesClaim,
+			RoleMapping: settings.RoleMapping,
+		},
+	}
+	for _, role := range roles {
+		res.Roles = append(res.Roles, role.Name)
+	}
+	utils.WriteJson(w, res)
+}
+
+func (api *Api) SSOStatus(w http.ResponseWriter, r *http.Request) {
+	settings, err := api.db.GetSSOSettings()
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	res := struct {
+		Enabled  bool `json:"enabled"`
+		ForceSSO bool `json:"force_sso"`
+	}{
+		Enabled:  settings.Enabled,
+		ForceSSO: settings.Enabled && settings.ForceSSO,
+	}
+	utils.WriteJson(w, res)
+}
+
+func (api *Api) SSOLogin(w http.ResponseWriter, r *http.Request) {
+	settings, err := api.db.GetSSOSettings()
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if !settings.Enabled {
+		http.Error(w, "SSO is disabled.", http.StatusNotFound)
+		return
+	}
+	provider, err := api.oidc.get(r.Context(), settings)
+	if err != nil {
+		klog.Errorln("oidc discovery failed:", err)
+		api.ssoError(w, r)
+		return
+	}
+	var claims ssoStateClaims
+	for _, v := range []*string{&claims.State, &claims.Nonce, &claims.CodeVerifier} {
+		if *v, err = oidc.RandomString(); err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+	}
+	claims.Next = ssoNext(r.URL.Query().Get("next"))
+	claims.Audience = jwt.ClaimStrings{ssoAudState}
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(ssoStateTTL))
+	state, err := api.signToken(claims)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoStateCookieName,
+		Value:    state,
+		Path:     api.cfg.UrlBasePath,
+		Expires:  time.Now().Add(ssoStateTTL),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	redirectURL := api.GetAbsoluteUrl(r, path.Join(api.cfg.UrlBasePath, ssoOIDCCallbackUri)).String()
+	http.Redirect(w, r, provider.AuthCodeURL(redirectURL, claims.State, claims.Nonce, claims.CodeVerifier), http.StatusFound)
+}
+
+func (api *Api) SSOCallback(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoStateCookieName,
+		Path:     api.cfg.UrlBasePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+	userId, next, err := api.ssoAuthenticate(r)
+	if err != nil {
+		klog.Errorln("sso:", err)
+		api.ssoError(w, r)
+		return
+	}
+	if err = api.SetSessionCookie(w, userId, SessionCookieTTL); err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, api.cfg.UrlBasePath+strings.TrimPrefix(next, "/"), http.StatusFound)
+}
+
+func (api *Api) ssoAuthenticate(r *http.Request) (int, string, error) {
+	settings, err := api.db.GetSSOSettings()
+	if err != nil {
+		return 0, "", err
+	}
+	if !settings.Enabled {
+		return 0, "", errors.New("SSO is disabled")
+	}
+	q := r.URL.Query()
+	if e := q.Get("error"); e != "" {
+		return 0, "", errors.New("identity provider returned an error: " + e + ": " + q.Get("error_description"))
+	}
+	c, _ := r.Cookie(ssoStateCookieName)
+	if c == nil {
+		return 0, "", errors.New("no state cookie")
+	}
+	var state ssoStateClaims
+	if err = api.verifyToken(ssoAudState, c.Value, &state); err != nil {
+		return 0, "", err
+	}
+	if q.Get("state") == "" || q.Get("state") != state.State {
+		return 0, "", errors.New("state mismatch")
+	}
+	provider, err := api.oidc.get(r.Context(), settings)
+	if err != nil {
+		return 0, "", err
+	}
+	redirectURL := api.GetAbsoluteUrl(r, path.Join(api.cfg.UrlBasePath, ssoOIDCCallbackUri)).String()
+	rawIdToken, err := provider.Exchange(r.Context(), redirectURL, q.Get("code"), state.CodeVerifier)
+	if err != nil {
+		return 0, "", err
+	}
+	claims, err := provider.VerifyIDToken(r.Context(), rawIdToken, state.Nonce)
+	if err != nil {
+		return 0, "", err
+	}
+	email := claims.Email()
+	if email == "" {
+		return 0, "", oidc.ErrNoEmail
+	}
+	if !claims.EmailVerified() {
+		return 0, "", errors.New("email is not verified by the identity provider: " + email)
+	}
+	roles, matched := settings.MapRoles(claims.Strings(settings.RolesClaim))
+	userId, err := api.db.UpsertSSOUser(email, claims.Name(), roles, matched)
+	if err != nil {
+		return 0, "", err
+	}
+	return userId, state.Next, nil
+}
+
+func (api *Api) ssoError(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, api.cfg.UrlBasePath+"login?sso_error=1", http.StatusFound)
+}
+
+// auditSSOSettings returns the settings with the client secret masked.
+func auditSSOSettings(s db.SSOSettings) db.SSOSettings {
+	if s.ClientSecret != "" {
+		s.ClientSecret = "<hidden>"
+	}
+	return s
+}
+
+// ssoNext sanitizes the post-login redirect target to prevent open redirects.
+func ssoNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.Contains(next, "\\") {
+		return "/"
+	}
+	return next
+}
@@ -38,6 +38,10 @@ type Alert struct {
 	UpdatedAt          timeseries.Time       `json:"updated_at"`
 	Suppressed         bool                  `json:"suppressed"`
 	ResolvedBy         string                `json:"resolved_by,omitempty"`
+	AcknowledgedAt     timeseries.Time       `json:"acknowledged_at"`
+	AcknowledgedBy     string                `json:"acknowledged_by,omitempty"`
+	Silenced           bool                  `json:"silenced"`
+	Silence            *model.Silence        `json:"silence,omitempty"`
 	Report             model.AuditReportName `json:"report,omitempty"`
 	Duration           timeseries.Duration   `json:"duration"`
 	Notifications      []AlertNotification   `json:"notifications,omitempty"`
@@ -45,7 +49,7 @@ type Alert struct {
 	LogPatternHash     string                `json:"log_pattern_hash,omitempty"`
 }
 
-func Render(w *model.World, a *model.Alert, app *model.Application, rules []*model.AlertingRule, notifications []db.AlertNotification, chs clickhouse.Clients) Alert {
+func Render(w *model.World, a *model.Alert, app *model.Application, rules []*model.AlertingRule, notifications []db.AlertNotification, silences model.Silences, chs clickhouse.Clients) Alert {
 	rulesMap := make(map[string]string)
 	var matchedRule *model.AlertingRule
 	for _, r := range rules {
@@ -54,7 +58,7 @@ func Render(w *model.World, a *model.Alert, app *model.Application, rules []*mod
 			matchedRule = r
 		}
 	}
-	res := renderAlert(w, a, rulesMap, notifications)
+	res := renderAlert(w, a, rulesMap, notifications, silences)
 	if matchedRule != nil {
 		switch matchedRule.Source.Type {
 		case model.AlertSourceTypeCheck:
@@ -219,7 +223,7 @@ type AlertsListView struct {
 	Resolved int     `json:"resolved"`
 }
 
-func RenderList(w *model.World, result *db.AlertsResult, rules []*model.AlertingRule, notifications map[string][]db.AlertNotification) *AlertsListView {
+func RenderList(w *model.World, result *db.AlertsResult, rules []*model.AlertingRule, notifications map[string][]db.AlertNotification, silences model.Silences) *AlertsListView {
 	rulesMap := make(map[string]string)
 	for _, r := range rules {
 		rulesMap[string(r.Id)] = r.Name
@@ -227,7 +231,7 @@ func RenderList(w *model.World, result *db.AlertsResult, rules []*model.Alerting
 
 	alerts := make([]Alert, 0, len(result.Alerts))
 	for _, a := range result.Alerts {
-		alerts = append(alerts, renderAlert(w, a, rulesMap, notifications[a.Id]))
+		alerts = append(alerts, renderAlert(w, a, rulesMap, notifications[a.Id], silences))
 	}
 	return &AlertsListView{
 		Alerts:   alerts,
@@ -237,8 +241,9 @@ func RenderList(w *model.World, result *db.AlertsResult, rules []*model.Alerting
 	}
 }
 
-func renderAlert(w *model.World, a *model.Alert, rulesMap map[string]string, notifications []db.AlertNotification) Alert {
-	to := timeseries.Now()
+func renderAlert(w *model.World, a *model.Alert, rulesMap map[string]string, notifications []db.AlertNotification, silences model.Silences) Alert {
+	now := timeseries.Now()
+	to := now
 	if a.ResolvedAt > 0 {
 		to = a.ResolvedAt
 	}
@@ -261,7 +266,7 @@ func renderAlert(w *model.World, a *model.Alert, rulesMap map[string]string, not
 		})
 	}
 
-	return Alert{
+	res := Alert{
 		Id:                 a.Id,
 		Fingerprint:        a.Fingerprint,
 		RuleId:             a.RuleId,
@@ -278,8 +283,15 @@ func renderAlert(w *model.World, a *model.Alert, rulesMap map[string]string, not
 		UpdatedAt:          a.UpdatedAt,
 		Suppressed:         a.Suppressed,
 		ResolvedBy:         a.ResolvedBy,
+		AcknowledgedAt:     a.AcknowledgedAt,
+		AcknowledgedBy:     a.AcknowledgedBy,
 		Report:             a.Report,
 		Duration:           duration,
 		Notifications:      alertNotifications,
 	}
+	if a.IsFiring() {
+		res.Silence = silences.FindForAlert(a, now)
+	}
+	res.Silenced = a.SilenceId != "" || res.Silence != nil
+	return res
 }
@@ -39,12 +39,12 @@ func Incidents(w *model.World, incidents []*model.ApplicationIncident) []inciden
 	return incident.RenderList(w, incidents)
 }
 
-func Alert(w *model.World, a *model.Alert, app *model.Application, rules []*model.AlertingRule, notifications []db.AlertNotification, chs clickhouse.Clients) alert.Alert {
-	return alert.Render(w, a, app, rules, notifications, chs)
+func Alert(w *model.World, a *model.Alert, app *model.Application, rules []*model.AlertingRule, notifications []db.AlertNotification, silences model.Silences, chs clickhouse.Clients) alert.Alert {
+	return alert.Render(w, a, app, rules, notifications, silences, chs)
 }
 
-func Alerts(w *model.World, result *db.AlertsResult, rules []*model.AlertingRule, notifications map[string][]db.AlertNotification) *alert.AlertsListView {
-	return alert.RenderList(w, result, rules, notifications)
+func Alerts(w *model.World, result *db.AlertsResult, rules []*model.AlertingRule, notifications map[string][]db.AlertNotification, silences model.Silences) *alert.AlertsListView {
+	return alert.RenderList(w, result, rules, notifications, silences)
 }
 
 func Profiling(ctx context.Context, ch *clickhouse.Client, app *model.Application, q url.Values, w *model.World) *profiling.View {
@@ -61,6 +61,13 @@ func Audit(w *model.World, p *db.Project, generateDetailedReportFor *model.Appli
 		stages.stage("redis", a.redis)
 		stages.stage("mongodb", a.mongodb)
 		stages.stage("memcached", a.memcached)
+		stages.stage("kafka", a.kafka)
+		stages.stage("rabbitmq", a.rabbitmq)
+		stages.stage("nats", a.nats)
+		stages.stage("elasticsearch", a.elasticsearch)
+		stages.stage("cassandra", a.cassandra)
+		stages.stage("clickhouse", a.clickhouse)
+		stages.stage("proxy", a.proxy)
 		stages.stage("jvm", a.jvm)
 		stages.stage("dotnet", a.dotnet)
 		stages.stage("python", a.python)
@@ -83,7 +90,7 @@ func Audit(w *model.World, p *db.Project, generateDetailedReportFor *model.Appli
 				}
 			}
 			switch r.Name {
-			case model.AuditReportPostgres, model.AuditReportRedis, model.AuditReportInstances, model.AuditReportSLO:
+			case model.AuditReportPostgres, model.AuditReportRedis, model.AuditReportElasticsearch, model.AuditReportCassandra, model.AuditReportClickHouse, model.AuditReportProxy, model.AuditReportInstances, model.AuditReportSLO:
 				if app.Status < r.Status {
 					app.Status = r.Status
 				}
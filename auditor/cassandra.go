@@ -0,0 +1,145 @@
+package auditor
+
+import (
+	"math"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) cassandra() {
+	isCassandra := a.app.ApplicationTypes()[model.ApplicationTypeCassandra]
+
+	if !isCassandra && !a.app.IsCassandra() {
+		return
+	}
+
+	report := a.addReport(model.AuditReportCassandra)
+
+	report.Instrumentation = model.ApplicationTypeCassandra
+
+	if !a.app.IsCassandra() {
+		report.Status = model.UNKNOWN
+		return
+	}
+
+	availabilityCheck := report.CreateCheck(model.Checks.CassandraAvailability)
+	readLatencyCheck := report.CreateCheck(model.Checks.CassandraReadLatency)
+	writeLatencyCheck := report.CreateCheck(model.Checks.CassandraWriteLatency)
+	compactionsCheck := report.CreateCheck(model.Checks.CassandraCompactions)
+	droppedMutationsCheck := report.CreateCheck(model.Checks.CassandraDroppedMutations)
+	hintsCheck := report.CreateCheck(model.Checks.CassandraHintedHandoffs)
+
+	table := report.GetOrCreateTable("Instance", "Status", "Reads", "Writes", "Read latency", "Write latency", "Pending compactions", "Version")
+	requestsChart := report.GetOrCreateChartGroup("Requests <selector>, per second", nil).Group("Requests", 1)
+	readLatencyChart := report.GetOrCreateChart("Average read latency, seconds", nil).Group("Requests", 1)
+	writeLatencyChart := report.GetOrCreateChart("Average write latency, seconds", nil).Group("Requests", 1)
+	compactionsChart := report.GetOrCreateChart("Pending compactions", nil).Group("Compactions", 2)
+	droppedChart := report.GetOrCreateChartGroup("Dropped messages <selector>, per second", nil).Group("Dropped messages", 3)
+	hintsChart := report.GetOrCreateChart("Hints, per second", nil).Group("Dropped messages", 3)
+
+	availabilityCheck.AddWidget(table.Widget())
+	readLatencyCheck.AddWidget(readLatencyChart.Widget())
+	writeLatencyCheck.AddWidget(writeLatencyChart.Widget())
+	compactionsCheck.AddWidget(compactionsChart.Widget())
+	droppedMutationsCheck.AddWidget(droppedChart.Widget())
+	hintsCheck.AddWidget(hintsChart.Widget())
+
+	events := func(ts *timeseries.TimeSeries) int64 {
+		if v := ts.Reduce(timeseries.NanSum); v > 0 {
+			return int64(math.Round(float64(v) * float64(a.w.Ctx.Step)))
+		}
+		return 0
+	}
+
+	for _, i := range a.app.Instances {
+		c := i.Cassandra
+		if c == nil {
+			continue
+		}
+		obsolete := i.IsObsolete()
+		if !obsolete && !c.IsUp() {
+			availabilityCheck.AddItem("%s", i.Name)
+		}
+		if obsolete {
+			continue
+		}
+
+		readLatency := timeseries.Div(c.RequestsTime["read"], c.Requests["read"])
+		writeLatency := timeseries.Div(c.RequestsTime["write"], c.Requests["write"])
+		if readLatency.Last() > readLatencyCheck.Threshold {
+			readLatencyCheck.AddItem("%s", i.Name)
+		}
+		if writeLatency.Last() > writeLatencyCheck.Threshold {
+			writeLatencyCheck.AddItem("%s", i.Name)
+		}
+		if c.PendingCompactions.Last() > compactionsCheck.Threshold {
+			compactionsCheck.AddItem("%s", i.Name)
+		}
+		droppedMutationsCheck.Inc(events(c.DroppedMessages["MUTATION"]))
+		hintsCheck.Inc(events(c.Hints))
+
+		if table != nil {
+			status := model.NewTableCell().SetStatus(model.OK, "up")
+			if !c.IsUp() {
+				msg := c.Error.Value()
+				if msg == "" {
+					msg = "down (no metrics)"
+				}
+				status.SetStatus(model.WARNING, msg)
+			}
+			table.AddRow(
+				model.NewTableCell(i.Name),
+				status,
+				cassandraRateCell(c.Requests["read"]),
+				cassandraRateCell(c.Requests["write"]),
+				cassandraLatencyCell(readLatency),
+				cassandraLatencyCell(writeLatency),
+				countCell(c.PendingCompactions),
+				model.NewTableCell(c.Version.Value()),
+			)
+		}
+		if requestsChart != nil {
+			requestsChart.GetOrCreateChart("overview").Feature().AddSeries(i.Name, timeseries.Sum(c.Requests["read"], c.Requests["write"]))
+			requestsChart.GetOrCreateChart(i.Name).Stacked().
+				AddSeries("read", c.Requests["read"]).
+				AddSeries("write", c.Requests["write"])
+		}
+		if readLatencyChart != nil {
+			readLatencyChart.AddSeries(i.Name, readLatency)
+		}
+		if writeLatencyChart != nil {
+			writeLatencyChart.AddSeries(i.Name, writeLatency)
+		}
+		if compactionsChart != nil {
+			compactionsChart.AddSeries(i.Name, c.PendingCompactions)
+		}
+		if droppedChart != nil {
+			byType := map[string]model.SeriesData{}
+			for t, ts := range c.DroppedMessages {
+				byType[t] = ts
+			}
+			droppedChart.GetOrCreateChart(i.Name).Stacked().AddMany(byType, 5, timeseries.NanSum)
+		}
+		if hintsChart != nil {
+			hintsChart.AddSeries(i.Name, c.Hints)
+		}
+	}
+}
+
+func cassandraRateCell(ts *timeseries.TimeSeries) *model.TableCell {
+	c := model.NewTableCell().SetUnit("/s")
+	if v := ts.Last(); !timeseries.IsNaN(v) {
+		c.SetValue(utils.FormatFloat(v))
+	}
+	return c
+}
+
+func cassandraLatencyCell(ts *timeseries.TimeSeries) *model.TableCell {
+	c := model.NewTableCell()
+	if v := ts.Last(); !timeseries.IsNaN(v) && !timeseries.IsInf(v, 0) {
+		c.SetValue(utils.FormatLatency(v))
+	}
+	return c
+}
@@ -0,0 +1,125 @@
+package auditor
+
+import (
+	"math"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) clickhouse() {
+	isClickHouse := a.app.ApplicationTypes()[model.ApplicationTypeClickHouse]
+
+	if !isClickHouse && !a.app.IsClickHouse() {
+		return
+	}
+
+	report := a.addReport(model.AuditReportClickHouse)
+
+	report.Instrumentation = model.ApplicationTypeClickHouse
+
+	if !a.app.IsClickHouse() {
+		report.Status = model.UNKNOWN
+		report.ConfigurationHint = &model.ConfigurationHint{
+			Message: "The agent scrapes the built-in Prometheus endpoint of ClickHouse. Enable it in the `prometheus` section of the server config (port 9363 by default) " +
+				"with `metrics`, `asynchronous_metrics`, and `events` enabled.",
+			ReadMoreLink: "https://clickhouse.com/docs/operations/server-configuration-parameters/settings#prometheus",
+		}
+		return
+	}
+
+	replicationQueueCheck := report.CreateCheck(model.Checks.ClickHouseReplicationQueue)
+	partsCheck := report.CreateCheck(model.Checks.ClickHouseParts)
+	rejectedInsertsCheck := report.CreateCheck(model.Checks.ClickHouseRejectedInserts)
+	keeperSessionCheck := report.CreateCheck(model.Checks.ClickHouseKeeperSession)
+
+	table := report.GetOrCreateTable("Instance", "Replication queue", "Replication delay", "Max parts per partition", "Running merges", "Keeper session")
+	replicationQueueChart := report.GetOrCreateChart("Replication queue size", nil).Group("Replication", 1)
+	replicationDelayChart := report.GetOrCreateChart("Replication delay, seconds", nil).Group("Replication", 1)
+	partsChart := report.GetOrCreateChart("Max parts per partition", nil).Group("Parts", 2)
+	mergesChart := report.GetOrCreateChart("Merges, per second", nil).Group("Parts", 2)
+	insertsChart := report.GetOrCreateChartGroup("Throttled inserts <selector>, per second", nil).Group("Inserts", 3)
+	keeperChart := report.GetOrCreateChart("ZooKeeper/Keeper hardware exceptions, per second", nil).Group("Keeper", 4)
+
+	replicationQueueCheck.AddWidget(replicationQueueChart.Widget())
+	replicationQueueCheck.AddWidget(table.Widget())
+	partsCheck.AddWidget(partsChart.Widget())
+	partsCheck.AddWidget(mergesChart.Widget())
+	rejectedInsertsCheck.AddWidget(insertsChart.Widget())
+	rejectedInsertsCheck.AddWidget(partsChart.Widget())
+	keeperSessionCheck.AddWidget(table.Widget())
+	keeperSessionCheck.AddWidget(keeperChart.Widget())
+
+	for _, i := range a.app.Instances {
+		ch := i.ClickHouse
+		if ch == nil || i.IsObsolete() {
+			continue
+		}
+
+		if ch.ReplicationQueueSize.Last() > replicationQueueCheck.Threshold {
+			replicationQueueCheck.AddItem("%s", i.Name)
+		}
+		if ch.MaxPartsPerPartition.Last() > partsCheck.Threshold {
+			partsCheck.AddItem("%s", i.Name)
+		}
+		if v := ch.RejectedInserts.Reduce(timeseries.NanSum); v > 0 {
+			rejectedInsertsCheck.Inc(int64(math.Round(float64(v) * float64(a.w.Ctx.Step))))
+		}
+		sessionLost := ch.ZooKeeperHardwareExceptions.Reduce(timeseries.NanSum) > 0 || ch.ReadonlyReplicas.Last() > 0
+		if sessionLost {
+			keeperSessionCheck.AddItem("%s", i.Name)
+		}
+
+		if table != nil {
+			delay := model.NewTableCell()
+			if v := ch.ReplicationDelay.Last(); !timeseries.IsNaN(v) {
+				delay.SetValue(utils.FormatDuration(timeseries.Duration(v), 1))
+			}
+			merges := model.NewTableCell()
+			if v := ch.MergesRunning.Last(); !timeseries.IsNaN(v) {
+				merges.SetValue(utils.FormatFloat(v))
+			}
+			session := model.NewTableCell()
+			switch {
+			case sessionLost:
+				session.SetStatus(model.WARNING, "lost")
+			case ch.ZooKeeperSessions.Last() > 0:
+				session.SetStatus(model.OK, "ok")
+			}
+			queue := model.NewTableCell()
+			if v := ch.ReplicationQueueSize.Last(); !timeseries.IsNaN(v) {
+				queue.SetValue(utils.FormatFloat(v))
+			}
+			parts := model.NewTableCell()
+			if v := ch.MaxPartsPerPartition.Last(); !timeseries.IsNaN(v) {
+				parts.SetValue(utils.FormatFloat(v))
+				if v > partsCheck.Threshold {
+					parts.UpdateStatus(model.WARNING)
+				}
+			}
+			table.AddRow(model.NewTableCell(i.Name), queue, delay, parts, merges, session)
+		}
+		if replicationQueueChart != nil {
+			replicationQueueChart.AddSeries(i.Name, ch.ReplicationQueueSize)
+		}
+		if replicationDelayChart != nil {
+			replicationDelayChart.AddSeries(i.Name, ch.ReplicationDelay)
+		}
+		if partsChart != nil {
+			partsChart.AddSeries(i.Name, ch.MaxPartsPerPartition).
+				SetThreshold("threshold", ch.MaxPartsPerPartition.WithNewValue(partsCheck.Threshold))
+		}
+		if mergesChart != nil {
+			mergesChart.AddSeries(i.Name, ch.Merges)
+		}
+		if insertsChart != nil {
+			insertsChart.GetOrCreateChart(i.Name).
+				AddSeries("rejected", ch.RejectedInserts, "red").
+				AddSeries("delayed", ch.DelayedInserts, "orange")
+		}
+		if keeperChart != nil {
+			keeperChart.AddSeries(i.Name, ch.ZooKeeperHardwareExceptions)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package auditor
+
+import (
+	"math"
+	"sort"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) elasticsearch() {
+	appTypes := a.app.ApplicationTypes()
+	isElasticsearch := appTypes[model.ApplicationTypeElasticsearch] || appTypes[model.ApplicationTypeOpensearch]
+
+	if !isElasticsearch && !a.app.IsElasticsearch() {
+		return
+	}
+
+	report := a.addReport(model.AuditReportElasticsearch)
+
+	if !a.app.IsElasticsearch() {
+		report.Status = model.UNKNOWN
+		report.ConfigurationHint = &model.ConfigurationHint{
+			Message:      "Scrape elasticsearch-exporter with the `address` label set to the address of the cluster to get cluster health, shard, and node metrics.",
+			ReadMoreLink: "https://github.com/prometheus-community/elasticsearch_exporter",
+		}
+		return
+	}
+
+	redCheck := report.CreateCheck(model.Checks.ElasticsearchClusterRed)
+	yellowCheck := report.CreateCheck(model.Checks.ElasticsearchClusterYellow)
+	unassignedCheck := report.CreateCheck(model.Checks.ElasticsearchUnassigned)
+	heapCheck := report.CreateCheck(model.Checks.ElasticsearchHeapUsage)
+	rejectedCheck := report.CreateCheck(model.Checks.ElasticsearchRejectedTasks)
+	indexingCheck := report.CreateCheck(model.Checks.ElasticsearchIndexingTime)
+	searchCheck := report.CreateCheck(model.Checks.ElasticsearchSearchTime)
+
+	clustersTable := report.GetOrCreateTable("Cluster", "Status", "Nodes", "Unassigned shards")
+	nodesTable := report.GetOrCreateTable("Node", "Heap", "Indexing latency", "Search latency", "Rejected tasks")
+	unassignedChart := report.GetOrCreateChart("Unassigned shards", nil).Group("Shards", 1)
+	heapChart := report.GetOrCreateChart("JVM heap usage, %", nil).Group("JVM", 2)
+	indexingChart := report.GetOrCreateChart("Average indexing latency, seconds", nil).Group("Latency", 3)
+	searchChart := report.GetOrCreateChart("Average search latency, seconds", nil).Group("Latency", 3)
+	rejectedChart := report.GetOrCreateChart("Rejected tasks by thread pool, per second", nil).Group("Thread pools", 4)
+
+	redCheck.AddWidget(clustersTable.Widget())
+	yellowCheck.AddWidget(clustersTable.Widget())
+	unassignedCheck.AddWidget(clustersTable.Widget())
+	unassignedCheck.AddWidget(unassignedChart.Widget())
+	heapCheck.AddWidget(nodesTable.Widget())
+	heapCheck.AddWidget(heapChart.Widget())
+	rejectedCheck.AddWidget(nodesTable.Widget())
+	rejectedCheck.AddWidget(rejectedChart.Widget())
+	indexingCheck.AddWidget(indexingChart.Widget())
+	searchCheck.AddWidget(searchChart.Widget())
+
+	type cluster struct {
+		status     string
+		nodes      *timeseries.Aggregate
+		unassigned *timeseries.Aggregate
+	}
+	// the cluster health is reported by every exporter of the cluster, so the values are deduplicated using max
+	clusters := map[string]*cluster{}
+	nodes := map[string]*model.ElasticsearchNode{}
+	rejectedByPool := map[string]*timeseries.Aggregate{}
+
+	for _, i := range a.app.Instances {
+		es := i.Elasticsearch
+		if es == nil || i.IsObsolete() {
+			continue
+		}
+		name := es.ClusterName.Value()
+		if name == "" {
+			name = a.app.Id.Name
+		}
+		c := clusters[name]
+		if c == nil {
+			c = &cluster{nodes: timeseries.NewAggregate(timeseries.Max), unassigned: timeseries.NewAggregate(timeseries.Max)}
+			clusters[name] = c
+		}
+		if status := es.ClusterStatus.Value(); esStatusSeverity(status) > esStatusSeverity(c.status) {
+			c.status = status
+		}
+		c.nodes.Add(es.ClusterNodes)
+		c.unassigned.Add(es.UnassignedShards)
+
+		for nodeName, n := range es.Nodes {
+			if nodeName == "" {
+				nodeName = i.Name
+			}
+			if nodes[nodeName] == nil {
+				nodes[nodeName] = n
+			}
+		}
+	}
+
+	var clusterNames []string
+	for name := range clusters {
+		clusterNames = append(clusterNames, name)
+	}
+	sort.Strings(clusterNames)
+	for _, name := range clusterNames {
+		c := clusters[name]
+		switch c.status {
+		case "red":
+			redCheck.AddItem("%s", name)
+		case "yellow":
+			yellowCheck.AddItem("%s", name)
+		}
+		unassigned := c.unassigned.Get()
+		if v := unassigned.Last(); v > 0 {
+			unassignedCheck.Inc(int64(v))
+		}
+		if unassignedChart != nil {
+			unassignedChart.AddSeries(name, unassigned)
+		}
+		if clustersTable != nil {
+			status := model.NewTableCell()
+			switch c.status {
+			case "":
+			case "green":
+				status.SetStatus(model.OK, c.status)
+			default:
+				status.SetStatus(model.WARNING, c.status)
+			}
+			nodesCell := model.NewTableCell()
+			if v := c.nodes.Get().Last(); !timeseries.IsNaN(v) {
+				nodesCell.SetValue(utils.FormatFloat(v))
+			}
+			clustersTable.AddRow(model.NewTableCell(name), status, nodesCell, countCell(unassigned))
+		}
+	}
+
+	var nodeNames []string
+	for name := range nodes {
+		nodeNames = append(nodeNames, name)
+	}
+	sort.Strings(nodeNames)
+	avg := func(time, ops *timeseries.TimeSeries) *timeseries.TimeSeries {
+		return timeseries.Aggregate2(time, ops, func(t, o float32) float32 {
+			if o > 0 {
+				return t / o
+			}
+			return timeseries.NaN
+		})
+	}
+	for _, name := range nodeNames {
+		n := nodes[name]
+		heap := timeseries.Aggregate2(n.HeapUsed, n.HeapMax, func(used, max float32) float32 { return used / max * 100 })
+		heapCell := model.NewTableCell()
+		if v := heap.Last(); !timeseries.IsNaN(v) {
+			heapCell.SetValue(utils.FormatFloat(v)).SetUnit("%")
+			if v > heapCheck.Threshold {
+				heapCheck.AddItem("%s", name)
+				heapCell.UpdateStatus(model.WARNING)
+			}
+		}
+
+		indexing := avg(n.IndexingTime, n.IndexingOps)
+		indexingCell := model.NewTableCell()
+		if v := indexing.Last(); !timeseries.IsNaN(v) {
+			indexingCell.SetValue(utils.FormatLatency(v))
+			if v > indexingCheck.Threshold {
+				indexingCheck.AddItem("%s", name)
+				indexingCell.UpdateStatus(model.WARNING)
+			}
+		}
+		search := avg(n.SearchTime, n.SearchOps)
+		searchCell := model.NewTableCell()
+		if v := search.Last(); !timeseries.IsNaN(v) {
+			searchCell.SetValue(utils.FormatLatency(v))
+			if v > searchCheck.Threshold {
+				searchCheck.AddItem("%s", name)
+				searchCell.UpdateStatus(model.WARNING)
+			}
+		}
+
+		var rejected float64
+		for pool, ts := range n.RejectedTasks {
+			if v := ts.Reduce(timeseries.NanSum); v > 0 {
+				rejected += float64(v) * float64(a.w.Ctx.Step)
+			}
+			if rejectedByPool[pool] == nil {
+				rejectedByPool[pool] = timeseries.NewAggregate(timeseries.NanSum)
+			}
+			rejectedByPool[pool].Add(ts)
+		}
+		rejectedCell := model.NewTableCell()
+		if rejected = math.Round(rejected); rejected > 0 {
+			rejectedCheck.Inc(int64(rejected))
+			rejectedCell.SetStatus(model.WARNING, utils.FormatFloat(float32(rejected)))
+		}
+
+		if heapChart != nil {
+			heapChart.AddSeries(name, heap)
+		}
+		if indexingChart != nil {
+			indexingChart.AddSeries(name, indexing)
+		}
+		if searchChart != nil {
+			searchChart.AddSeries(name, search)
+		}
+		if nodesTable != nil {
+			nodesTable.AddRow(model.NewTableCell(name), heapCell, indexingCell, searchCell, rejectedCell)
+		}
+	}
+	if rejectedChart != nil {
+		byPool := map[string]model.SeriesData{}
+		for pool, agg := range rejectedByPool {
+			byPool[pool] = agg
+		}
+		rejectedChart.Stacked().AddMany(byPool, 10, timeseries.NanSum)
+	}
+}
+
+func esStatusSeverity(status string) int {
+	switch status {
+	case "green":
+		return 1
+	case "yellow":
+		return 2
+	case "red":
+		return 3
+	}
+	return 0
+}
@@ -0,0 +1,313 @@
+package auditor
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) kafka() {
+	isKafka := a.app.ApplicationTypes()[model.ApplicationTypeKafka]
+
+	if !isKafka && !a.app.IsKafka() {
+		return
+	}
+
+	report := a.addReport(model.AuditReportKafka)
+
+	if !a.app.IsKafka() {
+		report.Status = model.UNKNOWN
+		report.ConfigurationHint = &model.ConfigurationHint{
+			Message:      "Scrape kafka-exporter and the JMX exporter of the brokers with the `address` label set to the address of a broker to get consumer lag, partition, and controller metrics.",
+			ReadMoreLink: "https://github.com/danielqsj/kafka_exporter",
+		}
+		return
+	}
+
+	lagTimeCheck := report.CreateCheck(model.Checks.KafkaConsumerLagTime)
+	lagMessagesCheck := report.CreateCheck(model.Checks.KafkaConsumerLagMessages)
+	underReplicatedCheck := report.CreateCheck(model.Checks.KafkaUnderReplicated)
+	offlineCheck := report.CreateCheck(model.Checks.KafkaOfflinePartitions)
+	controllersCheck := report.CreateCheck(model.Checks.KafkaActiveControllers)
+
+	brokersTable := report.GetOrCreateTable("Broker", "Controller", "Under-replicated partitions", "Offline partitions")
+	groupsTable := report.GetOrCreateTable("Consumer group", "Topics", "Lag", "Lag time", "Consumer")
+	lagChart := report.GetOrCreateChart("Consumer lag, messages", nil).Group("Consumers", 1)
+	lagTimeChart := report.GetOrCreateChart("Consumer lag, seconds", nil).Group("Consumers", 1)
+	underReplicatedChart := report.GetOrCreateChart("Under-replicated partitions", nil).Group("Partitions", 2)
+	offlineChart := report.GetOrCreateChart("Offline partitions", nil).Group("Partitions", 2)
+	controllersChart := report.GetOrCreateChart("Active controllers", nil).Group("Partitions", 2)
+	produceRateChart := report.GetOrCreateChart("Produced messages by topic, per second", nil).Group("Topics", 3)
+
+	lagTimeCheck.AddWidget(groupsTable.Widget())
+	lagTimeCheck.AddWidget(lagTimeChart.Widget())
+	lagMessagesCheck.AddWidget(groupsTable.Widget())
+	lagMessagesCheck.AddWidget(lagChart.Widget())
+	underReplicatedCheck.AddWidget(underReplicatedChart.Widget())
+	underReplicatedCheck.AddWidget(brokersTable.Widget())
+	offlineCheck.AddWidget(offlineChart.Widget())
+	offlineCheck.AddWidget(brokersTable.Widget())
+	controllersCheck.AddWidget(controllersChart.Widget())
+	controllersCheck.AddWidget(brokersTable.Widget())
+
+	controllers := timeseries.NewAggregate(timeseries.NanSum)
+	underReplicated := timeseries.NewAggregate(timeseries.NanSum)
+	offline := timeseries.NewAggregate(timeseries.NanSum)
+
+	// cluster-level metrics can be collected by several exporters, so they are deduplicated using max
+	topicUnderReplicated := map[string]*timeseries.Aggregate{}
+	topicOffline := map[string]*timeseries.Aggregate{}
+	produceRate := map[string]*timeseries.Aggregate{}
+	lag := map[model.KafkaConsumerGroupKey]*timeseries.Aggregate{}
+	lagSeconds := map[model.KafkaConsumerGroupKey]*timeseries.Aggregate{}
+	aggTopic := func(m map[string]*timeseries.Aggregate, k string, ts *timeseries.TimeSeries) {
+		if m[k] == nil {
+			m[k] = timeseries.NewAggregate(timeseries.Max)
+		}
+		m[k].Add(ts)
+	}
+	aggGroup := func(m map[model.KafkaConsumerGroupKey]*timeseries.Aggregate, k model.KafkaConsumerGroupKey, ts *timeseries.TimeSeries) {
+		if m[k] == nil {
+			m[k] = timeseries.NewAggregate(timeseries.Max)
+		}
+		m[k].Add(ts)
+	}
+
+	for _, i := range a.app.Instances {
+		if i.Kafka == nil || i.IsObsolete() {
+			continue
+		}
+		k := i.Kafka
+		controllers.Add(k.ActiveControllers)
+		underReplicated.Add(k.UnderReplicatedPartitions)
+		offline.Add(k.OfflinePartitions)
+		for topic, ts := range k.TopicUnderReplicatedPartitions {
+			aggTopic(topicUnderReplicated, topic, ts)
+		}
+		for topic, ts := range k.TopicOfflinePartitions {
+			aggTopic(topicOffline, topic, ts)
+		}
+		for topic, ts := range k.TopicProduceRate {
+			aggTopic(produceRate, topic, ts)
+		}
+		for key, ts := range k.ConsumerGroupLag {
+			aggGroup(lag, key, ts)
+		}
+		for key, ts := range k.ConsumerGroupLagSeconds {
+			aggGroup(lagSeconds, key, ts)
+		}
+
+		if brokersTable != nil && (!k.ActiveControllers.IsEmpty() || !k.UnderReplicatedPartitions.IsEmpty() || !k.OfflinePartitions.IsEmpty()) {
+			controller := model.NewTableCell()
+			if v := k.ActiveControllers.Last(); !timeseries.IsNaN(v) {
+				controller.SetValue("no")
+				if v > 0 {
+					controller.SetValue("active")
+				}
+			}
+			brokersTable.AddRow(
+				model.NewTableCell(i.Name),
+				controller,
+				countCell(k.UnderReplicatedPartitions),
+				countCell(k.OfflinePartitions),
+			)
+		}
+		if controllersChart != nil {
+			controllersChart.AddSeries(i.Name, k.ActiveControllers)
+		}
+	}
+
+	// the broker metrics are more accurate, the per-topic metrics of kafka-exporter are used if they are not available
+	underReplicatedTotal := underReplicated.Get()
+	if underReplicatedTotal.IsEmpty() {
+		underReplicatedTotal = sumAggregates(topicUnderReplicated)
+	}
+	offlineTotal := offline.Get()
+	if offlineTotal.IsEmpty() {
+		offlineTotal = sumAggregates(topicOffline)
+	}
+	if v := underReplicatedTotal.Last(); v > 0 {
+		underReplicatedCheck.Inc(int64(v))
+	}
+	if v := offlineTotal.Last(); v > 0 {
+		offlineCheck.Inc(int64(v))
+	}
+	if v := controllers.Get().Last(); !timeseries.IsNaN(v) {
+		controllersCheck.SetValue(v)
+		if v != controllersCheck.Threshold {
+			controllersCheck.Fire()
+		}
+	}
+	if underReplicatedChart != nil {
+		if len(topicUnderReplicated) > 0 {
+			byTopic := map[string]model.SeriesData{}
+			for topic, agg := range topicUnderReplicated {
+				byTopic[topic] = agg
+			}
+			underReplicatedChart.Stacked().AddMany(byTopic, 10, timeseries.Max)
+		} else {
+			underReplicatedChart.AddSeries("partitions", underReplicatedTotal)
+		}
+	}
+	if offlineChart != nil {
+		offlineChart.AddSeries("partitions", offlineTotal)
+	}
+	if produceRateChart != nil {
+		byTopic := map[string]model.SeriesData{}
+		for topic, agg := range produceRate {
+			byTopic[topic] = agg
+		}
+		produceRateChart.Stacked().AddMany(byTopic, 10, timeseries.NanSum)
+	}
+
+	groups := map[string]*kafkaConsumerGroup{}
+	getGroup := func(key model.KafkaConsumerGroupKey) *kafkaConsumerGroup {
+		g := groups[key.Group]
+		if g == nil {
+			g = &kafkaConsumerGroup{
+				topics:     utils.NewStringSet(),
+				lag:        timeseries.NewAggregate(timeseries.NanSum),
+				lagSeconds: timeseries.NewAggregate(timeseries.Max),
+			}
+			groups[key.Group] = g
+		}
+		g.topics.Add(key.Topic)
+		return g
+	}
+	for key, agg := range lag {
+		g := getGroup(key)
+		messages := agg.Get()
+		g.lag.Add(messages)
+		if lagSeconds[key] == nil {
+			// estimate the lag time using the rate at which messages are produced to the topic
+			g.lagSeconds.Add(timeseries.Aggregate2(messages, produceRate[key.Topic].Get(), func(lag, rate float32) float32 {
+				if rate > 0 {
+					return lag / rate
+				}
+				if lag == 0 {
+					return 0
+				}
+				return timeseries.NaN
+			}))
+		}
+	}
+	for key, agg := range lagSeconds {
+		getGroup(key).lagSeconds.Add(agg.Get())
+	}
+
+	groupNames := make([]string, 0, len(groups))
+	for name := range groups {
+		groupNames = append(groupNames, name)
+	}
+	sort.Strings(groupNames)
+	consumers := map[string]*model.Application{}
+	for _, conn := range a.app.Downstreams {
+		if conn.Application == nil {
+			continue
+		}
+		if name := model.GuessService(groupNames, a.w, conn.Application); name != "" {
+			consumers[name] = conn.Application
+		}
+	}
+
+	lagByGroup := map[string]model.SeriesData{}
+	lagSecondsByGroup := map[string]model.SeriesData{}
+	for _, name := range groupNames {
+		g := groups[name]
+		lagging := false
+		messages := g.lag.Get().Last()
+		if messages > lagMessagesCheck.Threshold {
+			lagMessagesCheck.AddItem("%s", name)
+			lagging = true
+		}
+		seconds := g.lagSeconds.Get().Last()
+		if seconds > lagTimeCheck.Threshold {
+			lagTimeCheck.AddItem("%s", name)
+			lagging = true
+		}
+		if !g.lag.IsEmpty() {
+			lagByGroup[name] = g.lag
+		}
+		if !g.lagSeconds.IsEmpty() {
+			lagSecondsByGroup[name] = g.lagSeconds
+		}
+
+		consumer := consumers[name]
+		if consumer != nil {
+			status := model.OK
+			if lagging {
+				status = model.WARNING
+			}
+			a.kafkaConsumerLinks(report, consumer, status)
+		}
+
+		if groupsTable == nil {
+			continue
+		}
+		groupCell := model.NewTableCell(name)
+		if lagging {
+			groupCell.SetStatus(model.WARNING, name)
+		}
+		lagCell := model.NewTableCell()
+		if !timeseries.IsNaN(messages) {
+			lagCell.SetValue(utils.FormatFloat(messages))
+		}
+		lagTimeCell := model.NewTableCell()
+		if !timeseries.IsNaN(seconds) {
+			lagTimeCell.SetValue(utils.FormatDuration(timeseries.Duration(seconds), 1))
+		}
+		consumerCell := model.NewTableCell()
+		if consumer != nil {
+			consumerCell.SetValue(consumer.Id.Name)
+			consumerCell.Link = model.NewRouterLink(consumer.Id.Name, "overview").
+				SetParam("view", "applications").
+				SetParam("id", consumer.Id)
+		}
+		groupsTable.AddRow(groupCell, model.NewTableCell(strings.Join(g.topics.Items(), ", ")), lagCell, lagTimeCell, consumerCell)
+	}
+	if lagChart != nil {
+		lagChart.AddMany(lagByGroup, 10, timeseries.Max)
+	}
+	if lagTimeChart != nil {
+		lagTimeChart.AddMany(lagSecondsByGroup, 10, timeseries.Max)
+	}
+}
+
+type kafkaConsumerGroup struct {
+	topics     *utils.StringSet
+	lag        *timeseries.Aggregate
+	lagSeconds *timeseries.Aggregate
+}
+
+// kafkaConsumerLinks adds the connections between the instances of the consumer and the brokers to the dependency map.
+func (a *appAuditor) kafkaConsumerLinks(report *model.AuditReport, consumer *model.Application, status model.Status) {
+	for _, i := range consumer.Instances {
+		if i.Node == nil {
+			continue
+		}
+		for _, u := range i.Upstreams {
+			if u.RemoteInstance == nil || u.RemoteInstance.Owner != a.app || u.RemoteInstance.Node == nil {
+				continue
+			}
+			sn, dn := i.Node, u.RemoteInstance.Node
+			report.GetOrCreateDependencyMap().UpdateLink(
+				model.DependencyMapInstance{Id: i.Name + "@" + sn.GetName(), Name: i.Name, Obsolete: i.IsObsolete()},
+				model.DependencyMapNode{Name: sn.GetName(), Provider: sn.CloudProvider.Value(), Region: sn.Region.Value(), AZ: sn.AvailabilityZone.Value()},
+				model.DependencyMapInstance{Id: u.RemoteInstance.Name + "@" + dn.GetName(), Name: u.RemoteInstance.Name, Obsolete: u.RemoteInstance.IsObsolete()},
+				model.DependencyMapNode{Name: dn.GetName(), Provider: dn.CloudProvider.Value(), Region: dn.Region.Value(), AZ: dn.AvailabilityZone.Value()},
+				status,
+			)
+		}
+	}
+}
+
+func sumAggregates(m map[string]*timeseries.Aggregate) *timeseries.TimeSeries {
+	total := timeseries.NewAggregate(timeseries.NanSum)
+	for _, agg := range m {
+		total.Add(agg.Get())
+	}
+	return total.Get()
+}
@@ -0,0 +1,88 @@
+package auditor
+
+import (
+	"math"
+	"sort"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) nats() {
+	isNats := a.app.ApplicationTypes()[model.ApplicationTypeNats]
+
+	if !isNats && !a.app.IsNats() {
+		return
+	}
+
+	report := a.addReport(model.AuditReportNats)
+
+	if !a.app.IsNats() {
+		report.Status = model.UNKNOWN
+		report.ConfigurationHint = &model.ConfigurationHint{
+			Message:      "Scrape prometheus-nats-exporter with the -varz and -jsz=consumers options to get slow consumer and JetStream metrics.",
+			ReadMoreLink: "https://github.com/nats-io/prometheus-nats-exporter",
+		}
+		return
+	}
+
+	slowConsumersCheck := report.CreateCheck(model.Checks.NatsSlowConsumers)
+	pendingCheck := report.CreateCheck(model.Checks.NatsJetstreamPending)
+
+	pendingTable := report.GetOrCreateTable("Stream", "Consumer", "Pending messages")
+	slowConsumersChart := report.GetOrCreateChart("Slow consumers, per second", nil)
+	pendingChart := report.GetOrCreateChart("JetStream pending messages by consumer", nil)
+
+	slowConsumersCheck.AddWidget(slowConsumersChart.Widget())
+	pendingCheck.AddWidget(pendingTable.Widget())
+	pendingCheck.AddWidget(pendingChart.Widget())
+
+	// a consumer is reported by each server hosting its replica, so the values are deduplicated using max
+	pending := map[model.NatsConsumerKey]*timeseries.Aggregate{}
+	for _, i := range a.app.Instances {
+		if i.Nats == nil || i.IsObsolete() {
+			continue
+		}
+		if v := i.Nats.SlowConsumers.Reduce(timeseries.NanSum); v > 0 {
+			slowConsumersCheck.Inc(int64(math.Round(float64(v) * float64(a.w.Ctx.Step))))
+		}
+		if slowConsumersChart != nil {
+			slowConsumersChart.AddSeries(i.Name, i.Nats.SlowConsumers)
+		}
+		for k, ts := range i.Nats.JetstreamPending {
+			if pending[k] == nil {
+				pending[k] = timeseries.NewAggregate(timeseries.Max)
+			}
+			pending[k].Add(ts)
+		}
+	}
+
+	keys := make([]model.NatsConsumerKey, 0, len(pending))
+	for k := range pending {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	pendingByConsumer := map[string]model.SeriesData{}
+	for _, k := range keys {
+		ts := pending[k].Get()
+		pendingByConsumer[k.String()] = ts
+		status := model.OK
+		last := ts.Last()
+		if last > pendingCheck.Threshold {
+			pendingCheck.AddItem("%s", k.String())
+			status = model.WARNING
+		}
+		if pendingTable != nil {
+			pendingCell := model.NewTableCell()
+			if !timeseries.IsNaN(last) {
+				pendingCell.SetValue(utils.FormatFloat(last))
+			}
+			pendingTable.AddRow(model.NewTableCell(k.Stream), model.NewTableCell(k.Consumer).UpdateStatus(status), pendingCell)
+		}
+	}
+	if pendingChart != nil {
+		pendingChart.AddMany(pendingByConsumer, 10, timeseries.Max)
+	}
+}
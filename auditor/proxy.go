@@ -0,0 +1,307 @@
+package auditor
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) proxy() {
+	t := a.app.ApplicationType()
+	isProxy := t == model.ApplicationTypeNginx || t == model.ApplicationTypeEnvoy
+
+	if !isProxy && !a.app.IsProxy() {
+		return
+	}
+
+	report := a.addReport(model.AuditReportProxy)
+
+	if !a.app.IsProxy() {
+		report.Status = model.UNKNOWN
+		hint := &model.ConfigurationHint{
+			Message: "Scrape nginx-prometheus-exporter or nginx-vts-exporter to get connection and upstream metrics. " +
+				"Note that with OSS Nginx, nginx-prometheus-exporter exposes only connection metrics: upstream metrics are available only for NGINX Plus, " +
+				"otherwise use nginx-module-vts. To check the connection saturation, export worker_processes * worker_connections as `nginx_worker_connections_limit`.",
+			ReadMoreLink: "https://github.com/nginx/nginx-prometheus-exporter",
+		}
+		if t == model.ApplicationTypeEnvoy {
+			hint = &model.ConfigurationHint{
+				Message: "Scrape the /stats/prometheus endpoint of the Envoy admin interface to get upstream cluster, connection, and circuit breaker metrics. " +
+					"To check the connection saturation, configure the global_downstream_max_connections resource monitor of the overload manager.",
+				ReadMoreLink: "https://www.envoyproxy.io/docs/envoy/latest/operations/admin#get--stats-prometheus",
+			}
+		}
+		report.ConfigurationHint = hint
+		return
+	}
+
+	errorsCheck := report.CreateCheck(model.Checks.ProxyUpstreamErrors)
+	connectFailuresCheck := report.CreateCheck(model.Checks.ProxyConnectFailures)
+	connectionLimitsCheck := report.CreateCheck(model.Checks.ProxyConnectionLimits)
+	connectionSaturationCheck := report.CreateCheck(model.Checks.ProxyConnectionSaturation)
+	circuitBreakersCheck := report.CreateCheck(model.Checks.ProxyCircuitBreakers)
+
+	upstreamsTable := report.GetOrCreateTable("Upstream", "Backend", "Requests", "Errors", "Connection failures", "Circuit breaker overflows")
+	instancesTable := report.GetOrCreateTable("Instance", "Active connections", "Connection limit usage", "Dropped connections")
+	errorsChart := report.GetOrCreateChart("Upstream 5xx errors, per second", nil).Group("Upstreams", 1)
+	connectFailuresChart := report.GetOrCreateChart("Upstream connection failures, per second", nil).Group("Upstreams", 1)
+	overflowsChart := report.GetOrCreateChart("Circuit breaker overflows, per second", nil).Group("Upstreams", 1)
+	activeConnectionsChart := report.GetOrCreateChart("Active connections", nil).Group("Connections", 2)
+	connectionsUsageChart := report.GetOrCreateChart("Connection limit usage, %", nil).Group("Connections", 2)
+	droppedConnectionsChart := report.GetOrCreateChart("Dropped connections, per second", nil).Group("Connections", 2)
+
+	errorsCheck.AddWidget(upstreamsTable.Widget())
+	errorsCheck.AddWidget(errorsChart.Widget())
+	connectFailuresCheck.AddWidget(upstreamsTable.Widget())
+	connectFailuresCheck.AddWidget(connectFailuresChart.Widget())
+	connectionLimitsCheck.AddWidget(instancesTable.Widget())
+	connectionLimitsCheck.AddWidget(droppedConnectionsChart.Widget())
+	connectionSaturationCheck.AddWidget(instancesTable.Widget())
+	connectionSaturationCheck.AddWidget(connectionsUsageChart.Widget())
+	circuitBreakersCheck.AddWidget(upstreamsTable.Widget())
+	circuitBreakersCheck.AddWidget(overflowsChart.Widget())
+
+	upstreams := map[string]*proxyUpstream{}
+	for _, i := range a.app.Instances {
+		p := i.Proxy
+		if p == nil || i.IsObsolete() {
+			continue
+		}
+		connectionLimitsCheck.Inc(proxyEvents(p.DroppedConnections, a.w.Ctx.Step))
+		usage := p.GetConnectionsUsage()
+		if v := usage.Last(); v > connectionSaturationCheck.Threshold {
+			connectionSaturationCheck.AddItem("%s", i.Name)
+			if v > connectionSaturationCheck.Value() {
+				connectionSaturationCheck.SetValue(v)
+			}
+		}
+		for name, u := range p.Upstreams {
+			pu := upstreams[name]
+			if pu == nil {
+				pu = &proxyUpstream{
+					requests:        timeseries.NewAggregate(timeseries.NanSum),
+					errors:          timeseries.NewAggregate(timeseries.NanSum),
+					connectFailures: timeseries.NewAggregate(timeseries.NanSum),
+					overflows:       timeseries.NewAggregate(timeseries.NanSum),
+				}
+				upstreams[name] = pu
+			}
+			pu.requests.Add(u.Requests)
+			pu.errors.Add(u.Errors)
+			pu.connectFailures.Add(u.ConnectFailures)
+			pu.overflows.Add(u.Overflows)
+		}
+
+		if instancesTable != nil {
+			active := model.NewTableCell()
+			if v := p.ActiveConnections.Last(); !timeseries.IsNaN(v) {
+				active.SetValue(utils.FormatFloat(v))
+			}
+			usageCell := model.NewTableCell().SetUnit("%")
+			if v := usage.Last(); !timeseries.IsNaN(v) {
+				usageCell.SetValue(utils.FormatFloat(v))
+				if v > connectionSaturationCheck.Threshold {
+					usageCell.UpdateStatus(model.WARNING)
+				}
+			}
+			dropped := model.NewTableCell().SetUnit("/s")
+			if v := p.DroppedConnections.Reduce(timeseries.NanSum); v > 0 {
+				dropped.SetValue(utils.FormatFloat(p.DroppedConnections.Last()))
+				dropped.UpdateStatus(model.WARNING)
+			}
+			instancesTable.AddRow(model.NewTableCell(i.Name), active, usageCell, dropped)
+		}
+		if activeConnectionsChart != nil {
+			activeConnectionsChart.AddSeries(i.Name, p.ActiveConnections)
+		}
+		if connectionsUsageChart != nil {
+			connectionsUsageChart.AddSeries(i.Name, usage)
+		}
+		if droppedConnectionsChart != nil {
+			droppedConnectionsChart.AddSeries(i.Name, p.DroppedConnections)
+		}
+	}
+
+	names := make([]string, 0, len(upstreams))
+	for name := range upstreams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	services := make([]string, 0, len(names))
+	byService := map[string]string{}
+	for _, name := range names {
+		s := proxyUpstreamService(name)
+		services = append(services, s)
+		byService[s] = name
+	}
+	backends := map[string]*model.Application{}
+	for _, conn := range a.app.Upstreams {
+		if conn.RemoteApplication == nil {
+			continue
+		}
+		if s := model.GuessService(services, a.w, conn.RemoteApplication); s != "" {
+			backends[byService[s]] = conn.RemoteApplication
+		}
+	}
+
+	errorsByUpstream := map[string]model.SeriesData{}
+	connectFailuresByUpstream := map[string]model.SeriesData{}
+	overflowsByUpstream := map[string]model.SeriesData{}
+	for _, name := range names {
+		u := upstreams[name]
+		backend := backends[name]
+		item := name
+		if backend != nil {
+			item = backend.Id.Name
+		}
+
+		failing := false
+		errorsPercent := timeseries.NaN
+		if requests := u.requests.Get().Last(); requests > 0 {
+			errorsPercent = u.errors.Get().Last() / requests * 100
+		}
+		if errorsPercent > errorsCheck.Threshold {
+			errorsCheck.AddItem("%s", item)
+			failing = true
+		}
+		connectFailures := proxyEvents(u.connectFailures.Get(), a.w.Ctx.Step)
+		if connectFailures > 0 {
+			connectFailuresCheck.Inc(connectFailures)
+			failing = true
+		}
+		overflows := proxyEvents(u.overflows.Get(), a.w.Ctx.Step)
+		if overflows > 0 {
+			circuitBreakersCheck.Inc(overflows)
+			failing = true
+		}
+
+		if !u.errors.IsEmpty() {
+			errorsByUpstream[name] = u.errors
+		}
+		if !u.connectFailures.IsEmpty() {
+			connectFailuresByUpstream[name] = u.connectFailures
+		}
+		if !u.overflows.IsEmpty() {
+			overflowsByUpstream[name] = u.overflows
+		}
+
+		if backend != nil {
+			status := model.OK
+			if failing {
+				status = model.WARNING
+			}
+			a.proxyBackendLinks(report, backend, status)
+		}
+
+		if upstreamsTable == nil {
+			continue
+		}
+		upstreamCell := model.NewTableCell(name)
+		if failing {
+			upstreamCell.SetStatus(model.WARNING, name)
+		}
+		backendCell := model.NewTableCell()
+		if backend != nil {
+			backendCell.SetValue(backend.Id.Name)
+			backendCell.Link = model.NewRouterLink(backend.Id.Name, "overview").
+				SetParam("view", "applications").
+				SetParam("id", backend.Id)
+		}
+		requestsCell := model.NewTableCell().SetUnit("/s")
+		if v := u.requests.Get().Last(); !timeseries.IsNaN(v) {
+			requestsCell.SetValue(utils.FormatFloat(v))
+		}
+		errorsCell := model.NewTableCell().SetUnit("%")
+		if !timeseries.IsNaN(errorsPercent) {
+			errorsCell.SetValue(utils.FormatFloat(errorsPercent))
+			if errorsPercent > errorsCheck.Threshold {
+				errorsCell.UpdateStatus(model.WARNING)
+			}
+		}
+		upstreamsTable.AddRow(
+			upstreamCell,
+			backendCell,
+			requestsCell,
+			errorsCell,
+			proxyEventsCell(connectFailures, !u.connectFailures.IsEmpty()),
+			proxyEventsCell(overflows, !u.overflows.IsEmpty()),
+		)
+	}
+	if errorsChart != nil {
+		errorsChart.AddMany(errorsByUpstream, 10, timeseries.NanSum)
+	}
+	if connectFailuresChart != nil {
+		connectFailuresChart.AddMany(connectFailuresByUpstream, 10, timeseries.NanSum)
+	}
+	if overflowsChart != nil {
+		overflowsChart.AddMany(overflowsByUpstream, 10, timeseries.NanSum)
+	}
+}
+
+type proxyUpstream struct {
+	requests        *timeseries.Aggregate
+	errors          *timeseries.Aggregate
+	connectFailures *timeseries.Aggregate
+	overflows       *timeseries.Aggregate
+}
+
+// proxyBackendLinks adds the connections between the instances of the proxy and the backend to the dependency map.
+func (a *appAuditor) proxyBackendLinks(report *model.AuditReport, backend *model.Application, status model.Status) {
+	for _, i := range a.app.Instances {
+		if i.Node == nil {
+			continue
+		}
+		for _, u := range i.Upstreams {
+			if u.RemoteInstance == nil || u.RemoteInstance.Owner != backend || u.RemoteInstance.Node == nil {
+				continue
+			}
+			sn, dn := i.Node, u.RemoteInstance.Node
+			report.GetOrCreateDependencyMap().UpdateLink(
+				model.DependencyMapInstance{Id: i.Name + "@" + sn.GetName(), Name: i.Name, Obsolete: i.IsObsolete()},
+				model.DependencyMapNode{Name: sn.GetName(), Provider: sn.CloudProvider.Value(), Region: sn.Region.Value(), AZ: sn.AvailabilityZone.Value()},
+				model.DependencyMapInstance{Id: u.RemoteInstance.Name + "@" + dn.GetName(), Name: u.RemoteInstance.Name, Obsolete: u.RemoteInstance.IsObsolete()},
+				model.DependencyMapNode{Name: dn.GetName(), Provider: dn.CloudProvider.Value(), Region: dn.Region.Value(), AZ: dn.AvailabilityZone.Value()},
+				status,
+			)
+		}
+	}
+}
+
+// proxyUpstreamService extracts the service name from an upstream or cluster name,
+// e.g., outbound|9080||reviews.default.svc.cluster.local -> reviews.
+func proxyUpstreamService(name string) string {
+	if i := strings.LastIndex(name, "|"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, ".svc"); i > 0 {
+		name = name[:i]
+		if j := strings.Index(name, "."); j > 0 {
+			name = name[:j]
+		}
+	}
+	return name
+}
+
+func proxyEventsCell(events int64, hasMetrics bool) *model.TableCell {
+	c := model.NewTableCell()
+	switch {
+	case !hasMetrics:
+	case events > 0:
+		c.SetStatus(model.WARNING, utils.FormatFloat(float32(events)))
+	default:
+		c.SetValue("0")
+	}
+	return c
+}
+
+func proxyEvents(ts *timeseries.TimeSeries, step timeseries.Duration) int64 {
+	if v := ts.Reduce(timeseries.NanSum); v > 0 {
+		return int64(math.Round(float64(v) * float64(step)))
+	}
+	return 0
+}
@@ -0,0 +1,186 @@
+package auditor
+
+import (
+	"sort"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) rabbitmq() {
+	isRabbitmq := a.app.ApplicationTypes()[model.ApplicationTypeRabbitmq]
+
+	if !isRabbitmq && !a.app.IsRabbitmq() {
+		return
+	}
+
+	report := a.addReport(model.AuditReportRabbitmq)
+
+	if !a.app.IsRabbitmq() {
+		report.Status = model.UNKNOWN
+		report.ConfigurationHint = &model.ConfigurationHint{
+			Message:      "Enable the rabbitmq_prometheus plugin and scrape its metrics to get queue, consumer, and alarm metrics.",
+			ReadMoreLink: "https://www.rabbitmq.com/docs/prometheus",
+		}
+		return
+	}
+
+	queueGrowthCheck := report.CreateCheck(model.Checks.RabbitmqQueueGrowth)
+	unackedCheck := report.CreateCheck(model.Checks.RabbitmqUnackedMessages)
+	noConsumersCheck := report.CreateCheck(model.Checks.RabbitmqNoConsumers)
+	alarmsCheck := report.CreateCheck(model.Checks.RabbitmqAlarms)
+	partitionsCheck := report.CreateCheck(model.Checks.RabbitmqPartitions)
+
+	nodesTable := report.GetOrCreateTable("Node", "Memory alarm", "Disk alarm", "Network partitions")
+	queuesTable := report.GetOrCreateTable("Queue", "Ready", "Unacknowledged", "Consumers")
+	readyChart := report.GetOrCreateChart("Ready messages by queue", nil).Group("Queues", 1)
+	unackedChart := report.GetOrCreateChart("Unacknowledged messages by queue", nil).Group("Queues", 1)
+	consumersChart := report.GetOrCreateChart("Consumers by queue", nil).Group("Queues", 1)
+
+	queueGrowthCheck.AddWidget(queuesTable.Widget())
+	queueGrowthCheck.AddWidget(readyChart.Widget())
+	unackedCheck.AddWidget(queuesTable.Widget())
+	unackedCheck.AddWidget(unackedChart.Widget())
+	noConsumersCheck.AddWidget(queuesTable.Widget())
+	noConsumersCheck.AddWidget(consumersChart.Widget())
+	alarmsCheck.AddWidget(nodesTable.Widget())
+	partitionsCheck.AddWidget(nodesTable.Widget())
+
+	// a queue is reported by each node hosting it, so the values are deduplicated using max
+	ready := map[model.RabbitmqQueueKey]*timeseries.Aggregate{}
+	unacked := map[model.RabbitmqQueueKey]*timeseries.Aggregate{}
+	consumers := map[model.RabbitmqQueueKey]*timeseries.Aggregate{}
+	agg := func(m map[model.RabbitmqQueueKey]*timeseries.Aggregate, k model.RabbitmqQueueKey, ts *timeseries.TimeSeries) {
+		if m[k] == nil {
+			m[k] = timeseries.NewAggregate(timeseries.Max)
+		}
+		m[k].Add(ts)
+	}
+	var partitions float32
+	perObjectMetrics := false
+
+	for _, i := range a.app.Instances {
+		if i.Rabbitmq == nil || i.IsObsolete() {
+			continue
+		}
+		r := i.Rabbitmq
+		for k, q := range r.Queues {
+			perObjectMetrics = true
+			agg(ready, k, q.Ready)
+			agg(unacked, k, q.Unacked)
+			agg(consumers, k, q.Consumers)
+		}
+
+		nodes := utils.NewStringSet()
+		for node := range r.MemoryAlarm {
+			nodes.Add(node)
+		}
+		for node := range r.DiskAlarm {
+			nodes.Add(node)
+		}
+		for node := range r.Partitions {
+			nodes.Add(node)
+		}
+		for _, node := range nodes.Items() {
+			name := node
+			if name == "" {
+				name = i.Name
+			}
+			memoryAlarm := r.MemoryAlarm[node].Last() > 0
+			diskAlarm := r.DiskAlarm[node].Last() > 0
+			if memoryAlarm || diskAlarm {
+				alarmsCheck.AddItem("%s", name)
+			}
+			p := r.Partitions[node].Last()
+			if p > partitions {
+				partitions = p
+			}
+			if nodesTable != nil {
+				nodesTable.AddRow(
+					model.NewTableCell(name),
+					rabbitmqAlarmCell(r.MemoryAlarm[node]),
+					rabbitmqAlarmCell(r.DiskAlarm[node]),
+					countCell(r.Partitions[node]),
+				)
+			}
+		}
+	}
+	if partitions > 0 {
+		partitionsCheck.Inc(int64(partitions))
+	}
+
+	if !perObjectMetrics {
+		report.ConfigurationHint = &model.ConfigurationHint{
+			Message:      "Enable per-object metrics (prometheus.return_per_object_metrics = true) or scrape the /metrics/per-object endpoint to get per-queue metrics.",
+			ReadMoreLink: "https://www.rabbitmq.com/docs/prometheus",
+		}
+	}
+
+	keys := make([]model.RabbitmqQueueKey, 0, len(ready))
+	for k := range ready {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	readyByQueue := map[string]model.SeriesData{}
+	unackedByQueue := map[string]model.SeriesData{}
+	consumersByQueue := map[string]model.SeriesData{}
+	for _, k := range keys {
+		name := k.String()
+		r, u, c := ready[k].Get(), unacked[k].Get(), consumers[k].Get()
+		status := model.OK
+		if r.Last()-r.Reduce(timeseries.Min) > queueGrowthCheck.Threshold {
+			queueGrowthCheck.AddItem("%s", name)
+			status = model.WARNING
+		}
+		if u.Last() > unackedCheck.Threshold {
+			unackedCheck.AddItem("%s", name)
+			status = model.WARNING
+		}
+		if c.Reduce(timeseries.Max) > noConsumersCheck.Threshold && c.Last() <= noConsumersCheck.Threshold {
+			noConsumersCheck.AddItem("%s", name)
+			status = model.WARNING
+		}
+		readyByQueue[name] = r
+		unackedByQueue[name] = u
+		consumersByQueue[name] = c
+		if queuesTable != nil {
+			queuesTable.AddRow(
+				model.NewTableCell(name).UpdateStatus(status),
+				rabbitmqMessagesCell(r),
+				rabbitmqMessagesCell(u),
+				rabbitmqMessagesCell(c),
+			)
+		}
+	}
+	if readyChart != nil {
+		readyChart.Stacked().AddMany(readyByQueue, 10, timeseries.Max)
+	}
+	if unackedChart != nil {
+		unackedChart.Stacked().AddMany(unackedByQueue, 10, timeseries.Max)
+	}
+	if consumersChart != nil {
+		consumersChart.AddMany(consumersByQueue, 10, timeseries.Max)
+	}
+}
+
+func rabbitmqAlarmCell(ts *timeseries.TimeSeries) *model.TableCell {
+	c := model.NewTableCell()
+	switch v := ts.Last(); {
+	case timeseries.IsNaN(v):
+	case v > 0:
+		c.SetStatus(model.WARNING, "active")
+	default:
+		c.SetStatus(model.OK, "ok")
+	}
+	return c
+}
+
+func rabbitmqMessagesCell(ts *timeseries.TimeSeries) *model.TableCell {
+	c := model.NewTableCell()
+	if v := ts.Last(); !timeseries.IsNaN(v) {
+		c.SetValue(utils.FormatFloat(v))
+	}
+	return c
+}
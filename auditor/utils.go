@@ -3,6 +3,7 @@ package auditor
 import (
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
 )
 
 type nodeConsumers struct {
@@ -71,3 +72,16 @@ func cpuByModeChart(ch *model.Chart, modes map[string]*timeseries.TimeSeries) {
 		ch.AddSeries(mode, v, color)
 	}
 }
+
+func countCell(ts *timeseries.TimeSeries) *model.TableCell {
+	c := model.NewTableCell()
+	v := ts.Last()
+	switch {
+	case timeseries.IsNaN(v):
+	case v > 0:
+		c.SetStatus(model.WARNING, utils.FormatFloat(v))
+	default:
+		c.SetValue("0")
+	}
+	return c
+}
@@ -87,7 +87,7 @@ func (c *Cache) projectUpdateIteration(project *db.Project, step timeseries.Dura
 		return fmt.Errorf("could not get check configs: %w", err)
 	}
 
-	queries := slices.Clone(constructor.QUERIES)
+	queries := slices.Concat(constructor.QUERIES, constructor.SpanMetricsQueries)
 	for appId := range checkConfigs {
 		availabilityCfg, _ := checkConfigs.GetAvailability(appId)
 		if availabilityCfg.Custom {
@@ -291,6 +291,8 @@ SETTINGS index_granularity=8192, ttl_only_drop_parts = 1`,
 		`ALTER TABLE otel_traces @on_cluster ADD COLUMN IF NOT EXISTS NetSockPeerAddr LowCardinality(String) MATERIALIZED SpanAttributes['net.sock.peer.addr'] CODEC(ZSTD(1))`,
 
 		`ALTER TABLE otel_traces @on_cluster ADD INDEX IF NOT EXISTS idx_trace_id TraceId TYPE bloom_filter(0.001) GRANULARITY 1`,
+
+		`ALTER TABLE otel_traces @on_cluster ADD COLUMN IF NOT EXISTS SampleRate Float64 DEFAULT 1 CODEC(ZSTD(1))`,
 		`
 CREATE TABLE IF NOT EXISTS otel_traces_histogram @on_cluster (
      ServiceName LowCardinality(String) CODEC(ZSTD(1)),
@@ -310,8 +312,16 @@ PARTITION BY toDate(Timestamp)
 ORDER BY (ServiceName, SpanName, SpanKind, Root, Timestamp, NetSockPeerAddr, NetPeerName, NetPeerPort, Bucket)
 SETTINGS index_granularity=8192, ttl_only_drop_parts = 1`,
 
+		`ALTER TABLE otel_traces_histogram @on_cluster ADD COLUMN IF NOT EXISTS SampledTotal Float64 DEFAULT Total CODEC(ZSTD(1))`,
+		`ALTER TABLE otel_traces_histogram @on_cluster ADD COLUMN IF NOT EXISTS SampledFailed Float64 DEFAULT Failed CODEC(ZSTD(1))`,
+
+		// replaced by otel_traces_histogram_sampled_mv, which also extrapolates the counts of sampled spans.
+		// The old view is dropped before the new one is created: spans inserted in between aren't counted in the histogram,
+		// while having both views at the same time would count them twice.
+		`DROP VIEW IF EXISTS otel_traces_histogram_mv @on_cluster`,
+
 		`
-CREATE MATERIALIZED VIEW IF NOT EXISTS otel_traces_histogram_mv @on_cluster TO otel_traces_histogram AS
+CREATE MATERIALIZED VIEW IF NOT EXISTS otel_traces_histogram_sampled_mv @on_cluster TO otel_traces_histogram AS
 SELECT
     ServiceName, SpanName, SpanKind,
     ParentSpanId = '' AS Root,
@@ -321,7 +331,9 @@ SELECT
     toDateTime(toStartOfMinute(Timestamp)) AS Timestamp,
     roundDown(Duration/1000000, [0,5,10,25,50,100,250,500,1000,2500,5000,10000]) AS Bucket,
     count(1) AS Total,
-    countIf(StatusCode = 'STATUS_CODE_ERROR') AS Failed
+    countIf(StatusCode = 'STATUS_CODE_ERROR') AS Failed,
+    sum(SampleRate) AS SampledTotal,
+    sumIf(SampleRate, StatusCode = 'STATUS_CODE_ERROR') AS SampledFailed
 FROM otel_traces
 GROUP BY ServiceName, SpanName, SpanKind, Root, NetSockPeerAddr, NetPeerName, NetPeerPort, Timestamp, Bucket`,
 
@@ -446,6 +458,10 @@ SETTINGS index_granularity = 8192`,
 		`CREATE TABLE IF NOT EXISTS otel_traces_histogram_distributed ON CLUSTER @cluster AS otel_traces_histogram
 			ENGINE = Distributed(@cluster, currentDatabase(), otel_traces_histogram, rand())`,
 
+		`ALTER TABLE otel_traces_distributed ON CLUSTER @cluster ADD COLUMN IF NOT EXISTS SampleRate Float64 DEFAULT 1`,
+		`ALTER TABLE otel_traces_histogram_distributed ON CLUSTER @cluster ADD COLUMN IF NOT EXISTS SampledTotal Float64 DEFAULT Total`,
+		`ALTER TABLE otel_traces_histogram_distributed ON CLUSTER @cluster ADD COLUMN IF NOT EXISTS SampledFailed Float64 DEFAULT Failed`,
+
 		`CREATE TABLE IF NOT EXISTS otel_traces_service_name_distributed ON CLUSTER @cluster AS otel_traces_service_name
 			ENGINE = Distributed(@cluster, currentDatabase(), otel_traces_service_name)`,
 
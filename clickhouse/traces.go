@@ -24,6 +24,10 @@ var (
 	HistogramNextBucket = map[float32]float32{}
 )
 
+// sampledCounts extrapolates the number of spans and failed spans: each span kept by the collector's tail sampler
+// represents SampleRate spans (1 for the spans stored without sampling).
+const sampledCounts = "sum(SampleRate), sumIf(SampleRate, StatusCode = 'STATUS_CODE_ERROR')"
+
 func init() {
 	for i, b := range HistogramBuckets[:len(HistogramBuckets)-2] {
 		HistogramNextBucket[float32(b)] = float32(HistogramBuckets[i+1])
@@ -235,11 +239,11 @@ func (c *Client) getSpansHistogram(ctx context.Context, q SpanQuery, filters []s
 
 	var query string
 	if fromMV {
-		query = "SELECT toStartOfInterval(Timestamp, INTERVAL @step second), Bucket, sum(Total), sum(Failed)"
+		query = "SELECT toStartOfInterval(Timestamp, INTERVAL @step second), Bucket, sum(SampledTotal), sum(SampledFailed)"
 		query += " FROM @@table_otel_traces_histogram@@"
 	} else {
 		filterArgs = append(filterArgs, clickhouse.Named("buckets", HistogramBuckets[:len(HistogramBuckets)-1]))
-		query = "SELECT toStartOfInterval(Timestamp, INTERVAL @step second), roundDown(Duration/1000000, @buckets), count(1), countIf(StatusCode = 'STATUS_CODE_ERROR')"
+		query = "SELECT toStartOfInterval(Timestamp, INTERVAL @step second), roundDown(Duration/1000000, @buckets), " + sampledCounts
 		query += " FROM @@table_otel_traces@@"
 	}
 	query += " WHERE " + strings.Join(filters, " AND ")
@@ -252,9 +256,9 @@ func (c *Client) getSpansHistogram(ctx context.Context, q SpanQuery, filters []s
 	defer rows.Close()
 	var t time.Time
 	var bucket float64
-	var total, failed uint64
+	var total, failed float64
 	byBucket := map[float64]*timeseries.TimeSeries{}
-	errors := map[timeseries.Time]uint64{}
+	errors := map[timeseries.Time]float64{}
 	for rows.Next() {
 		if err = rows.Scan(&t, &bucket, &total, &failed); err != nil {
 			return nil, err
@@ -314,7 +318,7 @@ func (c *Client) getTraceSpanStats(ctx context.Context, q SpanQuery, filters []s
 
 	var query string
 	if fromMV {
-		query = "SELECT ServiceName, SpanName, Bucket, sum(Total), sum(Failed)"
+		query = "SELECT ServiceName, SpanName, Bucket, sum(SampledTotal), sum(SampledFailed)"
 		query += " FROM @@table_otel_traces_histogram@@"
 	} else {
 		filterArgs = append(filterArgs, clickhouse.Named("buckets", HistogramBuckets[:len(HistogramBuckets)-1]))
@@ -323,7 +327,7 @@ func (c *Client) getTraceSpanStats(ctx context.Context, q SpanQuery, filters []s
 			filters = append(filters, durFilter)
 			filterArgs = append(filterArgs, durFilterArgs...)
 		}
-		query = "SELECT ServiceName, SpanName, roundDown(Duration/1000000, @buckets), count(1), countIf(StatusCode = 'STATUS_CODE_ERROR')"
+		query = "SELECT ServiceName, SpanName, roundDown(Duration/1000000, @buckets), " + sampledCounts
 		query += " FROM @@table_otel_traces@@"
 	}
 	query += " WHERE " + strings.Join(filters, " AND ")
@@ -336,7 +340,7 @@ func (c *Client) getTraceSpanStats(ctx context.Context, q SpanQuery, filters []s
 	defer rows.Close()
 
 	var bucket float64
-	var total, failed uint64
+	var total, failed float64
 
 	res := map[model.TraceSpanKey]*model.TraceSpanStats{}
 
@@ -758,7 +762,7 @@ func (c *Client) tracesHistogramCreatedAt(ctx context.Context) time.Time {
 		return t
 	}
 	err := c.conn.QueryRow(ctx,
-		"SELECT metadata_modification_time FROM system.tables WHERE database = currentDatabase() AND name = 'otel_traces_histogram_mv'",
+		"SELECT metadata_modification_time FROM system.tables WHERE database = currentDatabase() AND name = 'otel_traces_histogram_sampled_mv'",
 	).Scan(&t)
 	if err != nil {
 		if !errors.Is(err, sql.ErrNoRows) {
@@ -4,6 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -14,6 +18,7 @@ import (
 	"github.com/coroot/coroot/config"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/grpc"
+	"github.com/coroot/coroot/timeseries"
 	"golang.org/x/exp/maps"
 	"k8s.io/klog"
 )
@@ -38,8 +43,11 @@ type Collector struct {
 	globalPrometheus *db.IntegrationPrometheus
 
 	projects     map[db.ProjectId]*db.Project
+	redactors    map[db.ProjectId]*Redactor
 	projectsLock sync.RWMutex
 
+	apiKeyUsage *db.ApiKeyUsageTracker
+
 	migrationDone     map[db.ProjectId]bool
 	migrationDoneLock sync.RWMutex
 
@@ -54,6 +62,18 @@ type Collector struct {
 	profileBatchesLock sync.Mutex
 	metricsBatches     map[db.ProjectId]*MetricsBatch
 	metricsBatchesLock sync.Mutex
+
+	tailSamplers     map[db.ProjectId]*TailSampler
+	tailSamplersLock sync.Mutex
+
+	spanMetrics     map[db.ProjectId]*SpanMetrics
+	spanMetricsLock sync.Mutex
+
+	quotas     map[db.ProjectId]*IngestionQuota
+	quotasLock sync.Mutex
+
+	spools     map[db.ProjectId]*Spool
+	spoolsLock sync.Mutex
 }
 
 func New(cfg config.CollectorConfig, database *db.DB, cache *cache.Cache, globalClickHouse *db.IntegrationClickhouse, globalPrometheus *db.IntegrationPrometheus, grpcServer *grpc.Server) *Collector {
@@ -69,8 +89,15 @@ func New(cfg config.CollectorConfig, database *db.DB, cache *cache.Cache, global
 		profileBatches:    map[db.ProjectId]*ProfilesBatch{},
 		logBatches:        map[db.ProjectId]*LogsBatch{},
 		metricsBatches:    map[db.ProjectId]*MetricsBatch{},
+		tailSamplers:      map[db.ProjectId]*TailSampler{},
+		spanMetrics:       map[db.ProjectId]*SpanMetrics{},
+		spools:            map[db.ProjectId]*Spool{},
+		apiKeyUsage:       db.NewApiKeyUsageTracker(database),
 	}
 
+	c.loadSpools()
+	go c.replaySpools()
+
 	c.updateProjects()
 	go func() {
 		ticker := time.NewTicker(10 * time.Second)
@@ -93,15 +120,31 @@ func (c *Collector) updateProjects() {
 		klog.Errorln(err)
 		return
 	}
+	redactors := newRedactors(maps.Values(projects))
+	c.updateTailSamplers(maps.Values(projects))
+	c.updateSpanMetrics(maps.Values(projects))
+	c.updateQuotas(maps.Values(projects))
 	c.projectsLock.Lock()
 	defer c.projectsLock.Unlock()
 	c.projects = map[db.ProjectId]*db.Project{}
 	for _, p := range projects {
 		c.projects[p.Id] = p
 	}
+	c.redactors = redactors
 }
 
-func (c *Collector) getProject(apiKey string) (*db.Project, error) {
+// requestApiKey returns the API key from the X-API-Key header or from the basic auth password,
+// since not all Loki, Zipkin, and Jaeger clients can send custom headers.
+func requestApiKey(r *http.Request) string {
+	if apiKey := r.Header.Get(ApiKeyHeader); apiKey != "" {
+		return apiKey
+	}
+	_, password, _ := r.BasicAuth()
+	return password
+}
+
+// getProject returns the project the API key belongs to, checking that the key hasn't expired and allows the scope.
+func (c *Collector) getProject(apiKey string, scope db.ApiKeyScope) (*db.Project, error) {
 	c.projectsLock.RLock()
 	defer c.projectsLock.RUnlock()
 
@@ -110,12 +153,13 @@ func (c *Collector) getProject(apiKey string) (*db.Project, error) {
 		apiKey = strings.Repeat("0", 32)
 	}
 
-	for _, p := range c.projects {
-		for _, k := range p.Settings.ApiKeys {
-			if k.Key == apiKey {
-				return p, nil
-			}
+	if p, k := db.FindApiKey(maps.Values(c.projects), apiKey); k != nil {
+		now := timeseries.Now()
+		if err := k.Check(scope, now); err != nil {
+			return nil, err
 		}
+		c.apiKeyUsage.Touch(p.Id, k.Key, now)
+		return p, nil
 	}
 	if isEmptyKey {
 		var project *db.Project
@@ -143,7 +187,30 @@ func (c *Collector) getProject(apiKey string) (*db.Project, error) {
 	return nil, ErrProjectNotFound
 }
 
+// projectErrorStatus returns the HTTP status code for an error returned by getProject.
+func projectErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrProjectNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrApiKeyExpired):
+		return http.StatusUnauthorized
+	case errors.Is(err, db.ErrApiKeyForbidden):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
 func (c *Collector) Close() {
+	c.tailSamplersLock.Lock()
+	for _, s := range c.tailSamplers {
+		s.Close()
+	}
+	c.tailSamplersLock.Unlock()
+	c.spanMetricsLock.Lock()
+	for _, m := range c.spanMetrics {
+		m.Close()
+	}
+	c.spanMetricsLock.Unlock()
 	c.traceBatchesLock.Lock()
 	defer c.traceBatchesLock.Unlock()
 	for _, b := range c.traceBatches {
@@ -237,13 +304,38 @@ func (c *Collector) clickhouseDo(ctx context.Context, project *db.Project, query
 	return nil
 }
 
+// insert writes the block to ClickHouse. If it fails with a retriable error, or older blocks are still waiting in the spool,
+// the block is written to the spool to be replayed later in the order of arrival.
+// Blocks rejected by ClickHouse are dropped, since they would fail the same way on replay.
+func (c *Collector) insert(project *db.Project, query chgo.Query) error {
+	s := c.getSpool(project.Id)
+	if s == nil {
+		return c.clickhouseDo(context.TODO(), project, query)
+	}
+	if s.Len() == 0 {
+		err := c.clickhouseDo(context.TODO(), project, query)
+		if err == nil || errors.Is(err, ErrClickhouseNotConfigured) {
+			return err
+		}
+		if !isRetriable(err) {
+			s.Reject(queryRows(query), err)
+			return fmt.Errorf("the block was rejected by clickhouse: %w", err)
+		}
+		klog.Warningf("failed to write to clickhouse, spooling the block to disk: %s", err)
+	}
+	if err := s.Write(query); err != nil {
+		return fmt.Errorf("failed to spool the block: %w", err)
+	}
+	return nil
+}
+
 func (c *Collector) getTracesBatch(project *db.Project) *TracesBatch {
 	c.traceBatchesLock.Lock()
 	defer c.traceBatchesLock.Unlock()
 	b := c.traceBatches[project.Id]
 	if b == nil {
 		b = NewTracesBatch(batchLimit, batchTimeout, func(query chgo.Query) error {
-			return c.clickhouseDo(context.TODO(), project, query)
+			return c.insert(project, query)
 		})
 		c.traceBatches[project.Id] = b
 	}
@@ -256,7 +348,7 @@ func (c *Collector) getLogsBatch(project *db.Project) *LogsBatch {
 	b := c.logBatches[project.Id]
 	if b == nil {
 		b = NewLogsBatch(batchLimit, batchTimeout, func(query chgo.Query) error {
-			return c.clickhouseDo(context.TODO(), project, query)
+			return c.insert(project, query)
 		})
 		c.logBatches[project.Id] = b
 	}
@@ -269,7 +361,7 @@ func (c *Collector) getProfilesBatch(project *db.Project) *ProfilesBatch {
 	b := c.profileBatches[project.Id]
 	if b == nil {
 		b = NewProfilesBatch(batchLimit, batchTimeout, func(query chgo.Query) error {
-			return c.clickhouseDo(context.TODO(), project, query)
+			return c.insert(project, query)
 		})
 		c.profileBatches[project.Id] = b
 	}
@@ -282,7 +374,7 @@ func (c *Collector) getMetricsBatch(project *db.Project) *MetricsBatch {
 	b := c.metricsBatches[project.Id]
 	if b == nil {
 		b = NewMetricsBatch(batchLimit, batchTimeout, func(query chgo.Query) error {
-			return c.clickhouseDo(context.TODO(), project, query)
+			return c.insert(project, query)
 		})
 		c.metricsBatches[project.Id] = b
 	}
@@ -296,3 +388,82 @@ func (c *Collector) GetClickhouseClusterInfo(project *db.Project) (ch.ClickHouse
 	}
 	return client.GetInfo()
 }
+
+func (c *Collector) getSpool(projectId db.ProjectId) *Spool {
+	if c.cfg.SpoolDir == "" {
+		return nil
+	}
+	c.spoolsLock.Lock()
+	defer c.spoolsLock.Unlock()
+	s := c.spools[projectId]
+	if s == nil {
+		var err error
+		s, err = NewSpool(filepath.Join(c.cfg.SpoolDir, string(projectId)), c.cfg.SpoolMaxSize, c.cfg.SpoolMaxAge.ToStandard())
+		if err != nil {
+			klog.Errorln("failed to create spool:", err)
+			return nil
+		}
+		c.spools[projectId] = s
+	}
+	return s
+}
+
+// loadSpools picks up the blocks spooled before a restart.
+func (c *Collector) loadSpools() {
+	if c.cfg.SpoolDir == "" {
+		return
+	}
+	dirs, err := os.ReadDir(c.cfg.SpoolDir)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			klog.Errorln(err)
+		}
+		return
+	}
+	for _, d := range dirs {
+		if d.IsDir() {
+			c.getSpool(db.ProjectId(d.Name()))
+		}
+	}
+}
+
+func (c *Collector) replaySpools() {
+	ticker := time.NewTicker(spoolReplayInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		c.spoolsLock.Lock()
+		spools := maps.Clone(c.spools)
+		c.spoolsLock.Unlock()
+		now := time.Now()
+		for projectId, s := range spools {
+			s.Expire(now)
+			if s.Len() == 0 {
+				continue
+			}
+			c.projectsLock.RLock()
+			project := c.projects[projectId]
+			c.projectsLock.RUnlock()
+			if project == nil {
+				continue
+			}
+			s.Replay(now, func(query chgo.Query) error {
+				return c.clickhouseDo(context.TODO(), project, query)
+			})
+		}
+	}
+}
+
+// GetSpoolStats returns the state of the project's spool, or nil if the spool is disabled.
+func (c *Collector) GetSpoolStats(projectId db.ProjectId) *SpoolStats {
+	if c.cfg.SpoolDir == "" {
+		return nil
+	}
+	c.spoolsLock.Lock()
+	s := c.spools[projectId]
+	c.spoolsLock.Unlock()
+	if s == nil {
+		return &SpoolStats{}
+	}
+	stats := s.Stats()
+	return &stats
+}
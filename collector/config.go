@@ -1,7 +1,6 @@
 package collector
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
 	"sort"
@@ -32,14 +31,10 @@ type ConfigData struct {
 }
 
 func (c *Collector) Config(w http.ResponseWriter, r *http.Request) {
-	project, err := c.getProject(r.Header.Get(ApiKeyHeader))
+	project, err := c.getProject(r.Header.Get(ApiKeyHeader), db.ApiKeyScopeIngestAny)
 	if err != nil {
 		klog.Errorln(err)
-		if errors.Is(err, ErrProjectNotFound) {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		http.Error(w, "", http.StatusInternalServerError)
+		http.Error(w, err.Error(), projectErrorStatus(err))
 		return
 	}
 	if project.Multicluster() {
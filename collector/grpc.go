@@ -2,18 +2,25 @@ package collector
 
 import (
 	"context"
+	"errors"
 
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/grpc"
 	logsv1 "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	metricsv1 "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	profilesv1 "go.opentelemetry.io/proto/otlp/collector/profiles/v1experimental"
 	tracesv1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"k8s.io/klog"
 )
 
 func (c *Collector) registerGRPCServices(server *grpc.Server) {
 	logsv1.RegisterLogsServiceServer(server, NewGRPCLogsService(c))
 	tracesv1.RegisterTraceServiceServer(server, NewGRPCTracesService(c))
+	metricsv1.RegisterMetricsServiceServer(server, NewGRPCMetricsService(c))
+	profilesv1.RegisterProfilesServiceServer(server, NewGRPCProfilesService(c))
 }
 
 type GRPCTracesService struct {
@@ -28,15 +35,20 @@ func NewGRPCTracesService(collector *Collector) *GRPCTracesService {
 }
 
 func (s *GRPCTracesService) Export(ctx context.Context, req *tracesv1.ExportTraceServiceRequest) (*tracesv1.ExportTraceServiceResponse, error) {
-	project, err := s.collector.getProjectFromGRPCMetadata(ctx)
+	project, err := s.collector.getProjectFromGRPCMetadata(ctx, db.ApiKeyScopeIngestTraces)
 	if err != nil {
 		klog.Errorln("failed to get project:", err)
 		return nil, err
 	}
+	if err = s.collector.checkQuota(project.Id, tracesIngestion(req)); err != nil {
+		return nil, quotaGRPCError(ctx, err)
+	}
 
-	s.collector.getTracesBatch(project).Add(req)
+	resp := rejectInvalidSpans(req)
+	s.collector.getRedactor(project.Id).Traces(req, nil)
+	s.collector.addTraces(project, req)
 
-	return &tracesv1.ExportTraceServiceResponse{}, nil
+	return resp, nil
 }
 
 type GRPCLogsService struct {
@@ -51,21 +63,68 @@ func NewGRPCLogsService(collector *Collector) *GRPCLogsService {
 }
 
 func (s *GRPCLogsService) Export(ctx context.Context, req *logsv1.ExportLogsServiceRequest) (*logsv1.ExportLogsServiceResponse, error) {
-	project, err := s.collector.getProjectFromGRPCMetadata(ctx)
+	project, err := s.collector.getProjectFromGRPCMetadata(ctx, db.ApiKeyScopeIngestLogs)
 	if err != nil {
 		klog.Errorln("failed to get project:", err)
 		return nil, err
 	}
+	if err = s.collector.checkQuota(project.Id, logsIngestion(req)); err != nil {
+		return nil, quotaGRPCError(ctx, err)
+	}
 
+	resp := rejectInvalidLogRecords(req)
+	s.collector.getRedactor(project.Id).Logs(req, nil)
 	s.collector.getLogsBatch(project).Add(req)
 
-	return &logsv1.ExportLogsServiceResponse{}, nil
+	return resp, nil
+}
+
+type GRPCMetricsService struct {
+	collector *Collector
+	metricsv1.UnimplementedMetricsServiceServer
+}
+
+func NewGRPCMetricsService(collector *Collector) *GRPCMetricsService {
+	return &GRPCMetricsService{
+		collector: collector,
+	}
 }
 
-func (c *Collector) getProjectFromGRPCMetadata(ctx context.Context) (*db.Project, error) {
+func (s *GRPCMetricsService) Export(ctx context.Context, req *metricsv1.ExportMetricsServiceRequest) (*metricsv1.ExportMetricsServiceResponse, error) {
+	project, err := s.collector.getProjectFromGRPCMetadata(ctx, db.ApiKeyScopeIngestMetrics)
+	if err != nil {
+		klog.Errorln("failed to get project:", err)
+		return nil, err
+	}
+
+	resp, err := s.collector.writeOTLPMetrics(ctx, project, req)
+	if errors.Is(err, ErrQuotaExceeded) {
+		return nil, quotaGRPCError(ctx, err)
+	}
+	if err != nil {
+		klog.Errorln("failed to write metrics:", err)
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	return resp, nil
+}
+
+func (c *Collector) getProjectFromGRPCMetadata(ctx context.Context, scope db.ApiKeyScope) (*db.Project, error) {
 	var apiKey string
 	if values := metadata.ValueFromIncomingContext(ctx, ApiKeyHeader); len(values) > 0 {
 		apiKey = values[0]
 	}
-	return c.getProject(apiKey)
+	project, err := c.getProject(apiKey, scope)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrProjectNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, db.ErrApiKeyExpired):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case errors.Is(err, db.ErrApiKeyForbidden):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, err
+	}
+	return project, nil
 }
@@ -0,0 +1,251 @@
+package collector
+
+import (
+	"net/http"
+
+	v1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	otlpcommon "go.opentelemetry.io/proto/otlp/common/v1"
+	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+const (
+	jaegerScopeName = "jaeger"
+
+	jaegerTagString = 0
+	jaegerTagDouble = 1
+	jaegerTagBool   = 2
+	jaegerTagLong   = 3
+	jaegerTagBinary = 4
+
+	jaegerRefChildOf = 0
+)
+
+// Jaeger accepts the spans sent by the Jaeger clients directly to the Jaeger collector
+// (the /api/traces endpoint, Thrift over HTTP).
+func (c *Collector) Jaeger(w http.ResponseWriter, r *http.Request) {
+	c.addLegacyTraces(w, r, func(contentType string, data []byte) (*v1.ExportTraceServiceRequest, error) {
+		switch contentType {
+		case "application/x-thrift", "application/vnd.apache.thrift.binary":
+			return unmarshalJaegerBatch(data)
+		}
+		return nil, unsupportedContentType(contentType)
+	})
+}
+
+type jaegerReference struct {
+	refType     int32
+	traceIdLow  uint64
+	traceIdHigh uint64
+	spanId      uint64
+}
+
+// unmarshalJaegerBatch decodes jaeger.thrift Batch:
+//
+//	Batch { 1: Process process; 2: list<Span> spans }
+//	Process { 1: string serviceName; 2: list<Tag> tags }
+//	Span { 1: i64 traceIdLow; 2: i64 traceIdHigh; 3: i64 spanId; 4: i64 parentSpanId; 5: string operationName;
+//	       6: list<SpanRef> references; 7: i32 flags; 8: i64 startTime; 9: i64 duration; 10: list<Tag> tags; 11: list<Log> logs }
+//	SpanRef { 1: SpanRefType refType; 2: i64 traceIdLow; 3: i64 traceIdHigh; 4: i64 spanId }
+//	Log { 1: i64 timestamp; 2: list<Tag> fields }
+//
+// Timestamps and durations are in microseconds.
+func unmarshalJaegerBatch(data []byte) (*v1.ExportTraceServiceRequest, error) {
+	r := &thriftReader{data: data}
+	var serviceName string
+	var processTags []*otlpcommon.KeyValue
+	var spans []*tracev1.Span
+	r.readStruct(func(id int16, typ byte) {
+		switch {
+		case id == 1 && typ == thriftStruct:
+			r.readStruct(func(id int16, typ byte) {
+				switch {
+				case id == 1 && typ == thriftString:
+					serviceName = r.string()
+				case id == 2 && typ == thriftList:
+					processTags = readJaegerTags(r)
+				default:
+					r.skip(typ)
+				}
+			})
+		case id == 2 && typ == thriftList:
+			r.readList(func(typ byte) {
+				if typ != thriftStruct {
+					r.skip(typ)
+					return
+				}
+				spans = append(spans, readJaegerSpan(r))
+			})
+		default:
+			r.skip(typ)
+		}
+	})
+	if r.err != nil {
+		return nil, r.err
+	}
+	l := newLegacyServiceSpans()
+	if len(spans) > 0 {
+		ss := l.resource(serviceName, processTags, jaegerScopeName)
+		ss.Spans = spans
+	}
+	return l.req, nil
+}
+
+func readJaegerSpan(r *thriftReader) *tracev1.Span {
+	s := &tracev1.Span{}
+	var traceIdLow, traceIdHigh, parentSpanId uint64
+	var startTime, duration int64
+	var refs []jaegerReference
+	r.readStruct(func(id int16, typ byte) {
+		switch {
+		case id == 1 && typ == thriftI64:
+			traceIdLow = uint64(r.i64())
+		case id == 2 && typ == thriftI64:
+			traceIdHigh = uint64(r.i64())
+		case id == 3 && typ == thriftI64:
+			s.SpanId = spanIdFromUint64(uint64(r.i64()))
+		case id == 4 && typ == thriftI64:
+			parentSpanId = uint64(r.i64())
+		case id == 5 && typ == thriftString:
+			s.Name = r.string()
+		case id == 6 && typ == thriftList:
+			r.readList(func(typ byte) {
+				if typ != thriftStruct {
+					r.skip(typ)
+					return
+				}
+				var ref jaegerReference
+				r.readStruct(func(id int16, typ byte) {
+					switch {
+					case id == 1 && typ == thriftI32:
+						ref.refType = r.i32()
+					case id == 2 && typ == thriftI64:
+						ref.traceIdLow = uint64(r.i64())
+					case id == 3 && typ == thriftI64:
+						ref.traceIdHigh = uint64(r.i64())
+					case id == 4 && typ == thriftI64:
+						ref.spanId = uint64(r.i64())
+					default:
+						r.skip(typ)
+					}
+				})
+				refs = append(refs, ref)
+			})
+		case id == 8 && typ == thriftI64:
+			startTime = r.i64()
+		case id == 9 && typ == thriftI64:
+			duration = r.i64()
+		case id == 10 && typ == thriftList:
+			s.Attributes = readJaegerTags(r)
+		case id == 11 && typ == thriftList:
+			r.readList(func(typ byte) {
+				if typ != thriftStruct {
+					r.skip(typ)
+					return
+				}
+				s.Events = append(s.Events, readJaegerLog(r))
+			})
+		default:
+			r.skip(typ)
+		}
+	})
+
+	s.TraceId = traceIdFromUint64(traceIdHigh, traceIdLow)
+	s.StartTimeUnixNano = uint64(startTime * 1000)
+	s.EndTimeUnixNano = uint64((startTime + duration) * 1000)
+	for _, ref := range refs {
+		if parentSpanId == 0 && ref.refType == jaegerRefChildOf && ref.traceIdLow == traceIdLow && ref.traceIdHigh == traceIdHigh {
+			parentSpanId = ref.spanId
+			continue
+		}
+		if ref.spanId == parentSpanId && ref.traceIdLow == traceIdLow && ref.traceIdHigh == traceIdHigh {
+			continue
+		}
+		s.Links = append(s.Links, &tracev1.Span_Link{
+			TraceId: traceIdFromUint64(ref.traceIdHigh, ref.traceIdLow),
+			SpanId:  spanIdFromUint64(ref.spanId),
+		})
+	}
+	s.ParentSpanId = spanIdFromUint64(parentSpanId)
+	s.Kind = tracev1.Span_SPAN_KIND_INTERNAL
+	applyLegacySpanTags(s)
+	return s
+}
+
+// readJaegerLog converts a span log to an event named after the "event" field, as the OpenTelemetry Collector does.
+func readJaegerLog(r *thriftReader) *tracev1.Span_Event {
+	e := &tracev1.Span_Event{}
+	r.readStruct(func(id int16, typ byte) {
+		switch {
+		case id == 1 && typ == thriftI64:
+			e.TimeUnixNano = uint64(r.i64() * 1000)
+		case id == 2 && typ == thriftList:
+			for _, kv := range readJaegerTags(r) {
+				if kv.Key == "event" && e.Name == "" {
+					e.Name = valueToString(kv.Value)
+					continue
+				}
+				e.Attributes = append(e.Attributes, kv)
+			}
+		default:
+			r.skip(typ)
+		}
+	})
+	return e
+}
+
+// readJaegerTags reads list<Tag>:
+//
+//	Tag { 1: string key; 2: TagType vType; 3: string vStr; 4: double vDouble; 5: bool vBool; 6: i64 vLong; 7: binary vBinary }
+func readJaegerTags(r *thriftReader) []*otlpcommon.KeyValue {
+	var res []*otlpcommon.KeyValue
+	r.readList(func(typ byte) {
+		if typ != thriftStruct {
+			r.skip(typ)
+			return
+		}
+		var key string
+		var vType int32
+		var vStr string
+		var vDouble float64
+		var vBool bool
+		var vLong int64
+		var vBinary []byte
+		r.readStruct(func(id int16, typ byte) {
+			switch {
+			case id == 1 && typ == thriftString:
+				key = r.string()
+			case id == 2 && typ == thriftI32:
+				vType = r.i32()
+			case id == 3 && typ == thriftString:
+				vStr = r.string()
+			case id == 4 && typ == thriftDouble:
+				vDouble = r.double()
+			case id == 5 && typ == thriftBool:
+				vBool = r.bool()
+			case id == 6 && typ == thriftI64:
+				vLong = r.i64()
+			case id == 7 && typ == thriftString:
+				vBinary = r.binary()
+			default:
+				r.skip(typ)
+			}
+		})
+		v := &otlpcommon.AnyValue{}
+		switch vType {
+		case jaegerTagString:
+			v.Value = &otlpcommon.AnyValue_StringValue{StringValue: vStr}
+		case jaegerTagDouble:
+			v.Value = &otlpcommon.AnyValue_DoubleValue{DoubleValue: vDouble}
+		case jaegerTagBool:
+			v.Value = &otlpcommon.AnyValue_BoolValue{BoolValue: vBool}
+		case jaegerTagLong:
+			v.Value = &otlpcommon.AnyValue_IntValue{IntValue: vLong}
+		case jaegerTagBinary:
+			v.Value = &otlpcommon.AnyValue_BytesValue{BytesValue: vBinary}
+		default:
+			return
+		}
+		res = append(res, &otlpcommon.KeyValue{Key: key, Value: v})
+	})
+	return res
+}
@@ -0,0 +1,229 @@
+package collector
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
+	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+type thriftWriter struct {
+	data []byte
+}
+
+func (w *thriftWriter) field(typ byte, id int16) {
+	w.data = append(w.data, typ)
+	w.data = binary.BigEndian.AppendUint16(w.data, uint16(id))
+}
+
+func (w *thriftWriter) stop() {
+	w.data = append(w.data, thriftStop)
+}
+
+func (w *thriftWriter) list(typ byte, size int) {
+	w.data = append(w.data, typ)
+	w.data = binary.BigEndian.AppendUint32(w.data, uint32(size))
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(thriftI32, id)
+	w.data = binary.BigEndian.AppendUint32(w.data, uint32(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(thriftI64, id)
+	w.data = binary.BigEndian.AppendUint64(w.data, uint64(v))
+}
+
+func (w *thriftWriter) string(id int16, v string) {
+	w.field(thriftString, id)
+	w.data = binary.BigEndian.AppendUint32(w.data, uint32(len(v)))
+	w.data = append(w.data, v...)
+}
+
+func (w *thriftWriter) stringTag(key, value string) {
+	w.string(1, key)
+	w.i32(2, jaegerTagString)
+	w.string(3, value)
+	w.stop()
+}
+
+func (w *thriftWriter) longTag(key string, value int64) {
+	w.string(1, key)
+	w.i32(2, jaegerTagLong)
+	w.i64(6, value)
+	w.stop()
+}
+
+func (w *thriftWriter) boolTag(key string, value bool) {
+	w.string(1, key)
+	w.i32(2, jaegerTagBool)
+	w.field(thriftBool, 5)
+	if value {
+		w.data = append(w.data, 1)
+	} else {
+		w.data = append(w.data, 0)
+	}
+	w.stop()
+}
+
+func TestJaegerThrift(t *testing.T) {
+	w := &thriftWriter{}
+	w.field(thriftStruct, 1) // process
+	w.string(1, "legacy-api")
+	w.field(thriftList, 2)
+	w.list(thriftStruct, 1)
+	w.stringTag("hostname", "node-1")
+	w.stop()
+
+	w.field(thriftList, 2) // spans
+	w.list(thriftStruct, 2)
+
+	w.i64(1, 0x1122) // traceIdLow
+	w.i64(2, 0)      // traceIdHigh
+	w.i64(3, 0xaa)   // spanId
+	w.i64(4, 0)      // parentSpanId
+	w.string(5, "GET /users")
+	w.field(thriftList, 6) // references
+	w.list(thriftStruct, 1)
+	w.i32(1, jaegerRefChildOf)
+	w.i64(2, 0x1122)
+	w.i64(3, 0)
+	w.i64(4, 0xbb)
+	w.stop()
+	w.i32(7, 1)             // flags
+	w.i64(8, 1700000000000) // startTime, us
+	w.i64(9, 1500)          // duration, us
+	w.field(thriftList, 10) // tags
+	w.list(thriftStruct, 4)
+	w.stringTag("span.kind", "server")
+	w.boolTag("error", true)
+	w.longTag("http.status_code", 500)
+	w.stringTag("http.method", "GET")
+	w.field(thriftList, 11) // logs
+	w.list(thriftStruct, 1)
+	w.i64(1, 1700000000100)
+	w.field(thriftList, 2)
+	w.list(thriftStruct, 2)
+	w.stringTag("event", "exception")
+	w.stringTag("message", "timeout")
+	w.stop()
+	w.field(thriftDouble, 99) // unknown fields are skipped
+	w.data = binary.BigEndian.AppendUint64(w.data, 0)
+	w.stop()
+
+	w.i64(1, 0x1122)
+	w.i64(2, 0)
+	w.i64(3, 0xcc)
+	w.i64(4, 0xaa)
+	w.string(5, "SELECT")
+	w.i64(8, 1700000000200)
+	w.i64(9, 300)
+	w.field(thriftList, 10)
+	w.list(thriftStruct, 3)
+	w.stringTag("span.kind", "client")
+	w.longTag("peer.ipv4", 0x0a000001)
+	w.longTag("peer.port", 5432)
+	w.stop()
+
+	w.stop()
+
+	req, err := unmarshalJaegerBatch(w.data)
+	require.NoError(t, err)
+	require.Len(t, req.ResourceSpans, 1)
+	rs := req.ResourceSpans[0]
+	assert.Equal(t, map[string]string{semconv.AttributeServiceName: "legacy-api", "hostname": "node-1"}, attributesToMap(rs.Resource.Attributes))
+	require.Len(t, rs.ScopeSpans, 1)
+	assert.Equal(t, jaegerScopeName, rs.ScopeSpans[0].Scope.Name)
+	spans := rs.ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+
+	server := spans[0]
+	assert.Equal(t, "00000000000000000000000000001122", hex.EncodeToString(server.TraceId))
+	assert.Equal(t, "00000000000000aa", hex.EncodeToString(server.SpanId))
+	assert.Equal(t, "00000000000000bb", hex.EncodeToString(server.ParentSpanId), "the CHILD_OF reference is the parent")
+	assert.Empty(t, server.Links)
+	assert.Equal(t, "GET /users", server.Name)
+	assert.Equal(t, tracev1.Span_SPAN_KIND_SERVER, server.Kind)
+	assert.Equal(t, uint64(1700000000000000), server.StartTimeUnixNano)
+	assert.Equal(t, uint64(1700000001500000), server.EndTimeUnixNano)
+	assert.Equal(t, tracev1.Status_STATUS_CODE_ERROR, server.Status.Code)
+	assert.Equal(t, map[string]string{"http.status_code": "500", "http.method": "GET"}, attributesToMap(server.Attributes))
+	require.Len(t, server.Events, 1)
+	assert.Equal(t, "exception", server.Events[0].Name)
+	assert.Equal(t, map[string]string{"message": "timeout"}, attributesToMap(server.Events[0].Attributes))
+
+	client := spans[1]
+	assert.Equal(t, "00000000000000aa", hex.EncodeToString(client.ParentSpanId))
+	assert.Equal(t, tracev1.Span_SPAN_KIND_CLIENT, client.Kind)
+	assert.Nil(t, client.Status)
+	assert.Equal(t, "10.0.0.1", attributesToMap(client.Attributes)[semconv.AttributeNetPeerName])
+	assert.Equal(t, "5432", attributesToMap(client.Attributes)[semconv.AttributeNetPeerPort])
+
+	_, err = unmarshalJaegerBatch(w.data[:len(w.data)-10])
+	assert.Error(t, err)
+}
+
+// testdata/jaeger/batch.thrift is a Batch serialized by jaeger-client-go v2.30.0 (thrift-gen/jaeger, TBinaryProtocol),
+// the same encoding its HTTP sender uses.
+func TestJaegerThriftClientBatch(t *testing.T) {
+	data, err := os.ReadFile("testdata/jaeger/batch.thrift")
+	require.NoError(t, err)
+
+	req, err := unmarshalJaegerBatch(data)
+	require.NoError(t, err)
+	require.Len(t, req.ResourceSpans, 1)
+	rs := req.ResourceSpans[0]
+	assert.Equal(t, map[string]string{
+		semconv.AttributeServiceName: "frontend",
+		"hostname":                   "frontend-1",
+		"jaeger.version":             "Go-2.30.0",
+	}, attributesToMap(rs.Resource.Attributes))
+	require.Len(t, rs.ScopeSpans, 1)
+	spans := rs.ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+
+	server := spans[0]
+	assert.Equal(t, "01020304050607081122334455667788", hex.EncodeToString(server.TraceId))
+	assert.Equal(t, "0a0b0c0d0e0f1011", hex.EncodeToString(server.SpanId))
+	assert.Empty(t, server.ParentSpanId)
+	assert.Equal(t, "HTTP GET /dispatch", server.Name)
+	assert.Equal(t, tracev1.Span_SPAN_KIND_SERVER, server.Kind)
+	assert.Equal(t, uint64(1700000000000000000), server.StartTimeUnixNano)
+	assert.Equal(t, uint64(1700000000001500000), server.EndTimeUnixNano)
+	assert.Equal(t, tracev1.Status_STATUS_CODE_ERROR, server.Status.Code)
+	assert.Equal(t, map[string]string{"http.status_code": "500"}, attributesToMap(server.Attributes))
+	require.Len(t, server.Events, 1)
+	assert.Equal(t, "error", server.Events[0].Name)
+	assert.Equal(t, uint64(1700000000000500000), server.Events[0].TimeUnixNano)
+
+	client := spans[1]
+	assert.Equal(t, "01020304050607081122334455667788", hex.EncodeToString(client.TraceId))
+	assert.Equal(t, "2122232425262728", hex.EncodeToString(client.SpanId))
+	assert.Equal(t, "0a0b0c0d0e0f1011", hex.EncodeToString(client.ParentSpanId))
+	assert.Empty(t, client.Links)
+	assert.Equal(t, "SQL SELECT", client.Name)
+	assert.Equal(t, tracev1.Span_SPAN_KIND_CLIENT, client.Kind)
+}
+
+func TestThriftReaderLimits(t *testing.T) {
+	w := &thriftWriter{}
+	w.field(thriftList, 1)
+	w.list(thriftStruct, 1<<30)
+	r := &thriftReader{data: w.data}
+	r.skip(thriftStruct)
+	assert.Error(t, r.err, "the list size is validated before reading")
+
+	var nested []byte
+	for i := 0; i < thriftMaxDepth+1; i++ {
+		nested = append(nested, thriftStruct, 0, 1)
+	}
+	r = &thriftReader{data: nested}
+	r.skip(thriftStruct)
+	assert.Error(t, r.err)
+}
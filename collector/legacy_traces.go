@@ -0,0 +1,202 @@
+package collector
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/coroot/coroot/db"
+	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
+	v1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	otlpcommon "go.opentelemetry.io/proto/otlp/common/v1"
+	otlpresource "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"k8s.io/klog"
+)
+
+// The spans received using the Zipkin and Jaeger protocols are converted to OTLP,
+// so they go through the same quotas, redaction, sampling, and storage as the OTLP spans.
+
+// addLegacyTraces handles the requests of the Zipkin and Jaeger endpoints:
+// decode converts the request body to OTLP.
+func (c *Collector) addLegacyTraces(w http.ResponseWriter, r *http.Request, decode func(contentType string, data []byte) (*v1.ExportTraceServiceRequest, error)) {
+	project, err := c.getProject(requestApiKey(r), db.ApiKeyScopeIngestTraces)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, err.Error(), projectErrorStatus(err))
+		return
+	}
+
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	decoder, err := getDecoder(r.Header.Get("Content-Encoding"), r.Body)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data, err := io.ReadAll(decoder)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req, err := decode(contentType, data)
+	if err != nil {
+		klog.Errorln(err)
+		status := http.StatusBadRequest
+		if errors.Is(err, errUnsupportedContentType) {
+			status = http.StatusUnsupportedMediaType
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if err = c.checkQuota(project.Id, tracesIngestion(req)); err != nil {
+		writeQuotaError(w, "", err)
+		return
+	}
+
+	rejectInvalidSpans(req)
+	c.getRedactor(project.Id).Traces(req, nil)
+	c.addTraces(project, req)
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// legacyServiceSpans groups the converted spans by the service.
+type legacyServiceSpans struct {
+	req      *v1.ExportTraceServiceRequest
+	services map[string]*tracev1.ScopeSpans
+}
+
+func newLegacyServiceSpans() *legacyServiceSpans {
+	return &legacyServiceSpans{req: &v1.ExportTraceServiceRequest{}, services: map[string]*tracev1.ScopeSpans{}}
+}
+
+func (l *legacyServiceSpans) resource(serviceName string, attributes []*otlpcommon.KeyValue, scope string) *tracev1.ScopeSpans {
+	if ss := l.services[serviceName]; ss != nil {
+		return ss
+	}
+	rs := &tracev1.ResourceSpans{
+		ScopeSpans: []*tracev1.ScopeSpans{{Scope: &otlpcommon.InstrumentationScope{Name: scope}}},
+	}
+	rs.Resource = newResource(serviceName, attributes)
+	l.req.ResourceSpans = append(l.req.ResourceSpans, rs)
+	l.services[serviceName] = rs.ScopeSpans[0]
+	return rs.ScopeSpans[0]
+}
+
+// traceIdFromUint64 builds an OTLP trace id from the high and low parts. 64-bit trace ids are left-padded with zeros.
+func traceIdFromUint64(high, low uint64) []byte {
+	id := make([]byte, 16)
+	binary.BigEndian.PutUint64(id[:8], high)
+	binary.BigEndian.PutUint64(id[8:], low)
+	return id
+}
+
+func spanIdFromUint64(id uint64) []byte {
+	if id == 0 {
+		return nil
+	}
+	res := make([]byte, 8)
+	binary.BigEndian.PutUint64(res, id)
+	return res
+}
+
+// padId left-pads a 64-bit trace id to 128 bits.
+func padId(id []byte, size int) []byte {
+	if len(id) == 0 || len(id) >= size {
+		return id
+	}
+	res := make([]byte, size)
+	copy(res[size-len(id):], id)
+	return res
+}
+
+func spanKindFromString(kind string) tracev1.Span_SpanKind {
+	switch strings.ToLower(kind) {
+	case "client":
+		return tracev1.Span_SPAN_KIND_CLIENT
+	case "server":
+		return tracev1.Span_SPAN_KIND_SERVER
+	case "producer":
+		return tracev1.Span_SPAN_KIND_PRODUCER
+	case "consumer":
+		return tracev1.Span_SPAN_KIND_CONSUMER
+	}
+	return tracev1.Span_SPAN_KIND_INTERNAL
+}
+
+// applyLegacySpanTags moves the conventional Zipkin, Jaeger and OpenTracing tags to the OTLP span fields:
+// the span kind, the status, and the peer address used to build the service map.
+func applyLegacySpanTags(s *tracev1.Span) {
+	var statusCode, statusMessage, errorTag string
+	attrs := map[string]string{}
+	s.Attributes = slices.DeleteFunc(s.Attributes, func(kv *otlpcommon.KeyValue) bool {
+		v := valueToString(kv.Value)
+		switch kv.Key {
+		case "span.kind":
+			s.Kind = spanKindFromString(v)
+		case "otel.status_code":
+			statusCode = v
+		case "otel.status_description":
+			statusMessage = v
+		case "error":
+			errorTag = v
+		default:
+			attrs[kv.Key] = v
+			return false
+		}
+		return true
+	})
+
+	switch {
+	case strings.EqualFold(statusCode, "ERROR"):
+		s.Status = &tracev1.Status{Code: tracev1.Status_STATUS_CODE_ERROR, Message: statusMessage}
+	case strings.EqualFold(statusCode, "OK"):
+		s.Status = &tracev1.Status{Code: tracev1.Status_STATUS_CODE_OK}
+	case errorTag != "" && errorTag != "false":
+		// Zipkin puts the error message in the error tag, Jaeger sets it to true.
+		s.Status = &tracev1.Status{Code: tracev1.Status_STATUS_CODE_ERROR}
+		if errorTag != "true" {
+			s.Status.Message = errorTag
+		}
+	}
+
+	if attrs[semconv.AttributeNetPeerName] == "" {
+		peer := attrs["peer.hostname"]
+		if peer == "" {
+			peer = attrs["peer.ipv4"]
+			// OpenTracing allows the IPv4 address as an integer
+			if n, err := strconv.ParseUint(peer, 10, 32); err == nil {
+				ip := make(net.IP, 4)
+				binary.BigEndian.PutUint32(ip, uint32(n))
+				peer = ip.String()
+			}
+		}
+		if peer == "" {
+			peer = attrs["peer.ipv6"]
+		}
+		if peer != "" {
+			s.Attributes = append(s.Attributes, otlpStringAttr(semconv.AttributeNetPeerName, peer))
+		}
+	}
+	if attrs[semconv.AttributeNetPeerPort] == "" && attrs["peer.port"] != "" {
+		s.Attributes = append(s.Attributes, otlpStringAttr(semconv.AttributeNetPeerPort, attrs["peer.port"]))
+	}
+}
+
+func newResource(serviceName string, attributes []*otlpcommon.KeyValue) *otlpresource.Resource {
+	res := &otlpresource.Resource{Attributes: []*otlpcommon.KeyValue{otlpStringAttr(semconv.AttributeServiceName, serviceName)}}
+	for _, kv := range attributes {
+		if kv.Key != semconv.AttributeServiceName {
+			res.Attributes = append(res.Attributes, kv)
+		}
+	}
+	return res
+}
@@ -2,66 +2,68 @@ package collector
 
 import (
 	"encoding/hex"
-	"io"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/ClickHouse/ch-go"
 	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/coroot/coroot/db"
 	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
 	v1 "go.opentelemetry.io/proto/otlp/collector/logs/v1"
-	"google.golang.org/protobuf/proto"
+	logsv1 "go.opentelemetry.io/proto/otlp/logs/v1"
 	"k8s.io/klog"
 )
 
 func (c *Collector) Logs(w http.ResponseWriter, r *http.Request) {
-	project, err := c.getProject(r.Header.Get(ApiKeyHeader))
+	project, err := c.getProject(r.Header.Get(ApiKeyHeader), db.ApiKeyScopeIngestLogs)
 	if err != nil {
 		klog.Errorln(err)
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), projectErrorStatus(err))
 		return
 	}
 
-	contentType := r.Header.Get("Content-Type")
-	switch contentType {
-	case "application/x-protobuf":
-	default:
-		http.Error(w, "unsupported content type: "+contentType, http.StatusBadRequest)
-		return
-	}
-
-	decoder, err := getDecoder(r.Header.Get("Content-Encoding"), r.Body)
+	req := &v1.ExportLogsServiceRequest{}
+	contentType, err := readOTLPRequest(w, r, req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	data, err := io.ReadAll(decoder)
-	if err != nil {
-		klog.Errorln(err)
-		http.Error(w, "", http.StatusInternalServerError)
-		return
-	}
-	req := &v1.ExportLogsServiceRequest{}
-	err = proto.Unmarshal(data, req)
-	if err != nil {
-		klog.Errorln(err)
-		http.Error(w, "", http.StatusBadRequest)
+	if err = c.checkQuota(project.Id, logsIngestion(req)); err != nil {
+		writeQuotaError(w, contentType, err)
 		return
 	}
 
+	resp := rejectInvalidLogRecords(req)
+	c.getRedactor(project.Id).Logs(req, nil)
 	c.getLogsBatch(project).Add(req)
 
+	writeOTLPResponse(w, contentType, resp)
+}
+
+// rejectInvalidLogRecords removes the log records with malformed trace context.
+// Log records without trace context are valid.
+func rejectInvalidLogRecords(req *v1.ExportLogsServiceRequest) *v1.ExportLogsServiceResponse {
+	var rejected int64
+	for _, rl := range req.GetResourceLogs() {
+		for _, sl := range rl.GetScopeLogs() {
+			sl.LogRecords = slices.DeleteFunc(sl.LogRecords, func(lr *logsv1.LogRecord) bool {
+				invalid := (len(lr.TraceId) > 0 && !validTraceId(lr.TraceId)) || (len(lr.SpanId) > 0 && !validSpanId(lr.SpanId))
+				if invalid {
+					rejected++
+				}
+				return invalid
+			})
+		}
+	}
 	resp := &v1.ExportLogsServiceResponse{}
-	w.Header().Set("Content-Type", contentType)
-	data, err = proto.Marshal(resp)
-	if err != nil {
-		klog.Errorln(err)
-		http.Error(w, "", http.StatusInternalServerError)
-		return
+	if rejected > 0 {
+		resp.PartialSuccess = &v1.ExportLogsPartialSuccess{
+			RejectedLogRecords: rejected,
+			ErrorMessage:       "log records with invalid trace or span ids were rejected",
+		}
 	}
-	_, _ = w.Write(data)
+	return resp
 }
 
 type LogsBatch struct {
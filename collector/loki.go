@@ -0,0 +1,334 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/logparser"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/promql/parser"
+	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
+	v1 "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	otlpcommon "go.opentelemetry.io/proto/otlp/common/v1"
+	logsv1 "go.opentelemetry.io/proto/otlp/logs/v1"
+	otlpresource "go.opentelemetry.io/proto/otlp/resource/v1"
+	"k8s.io/klog"
+)
+
+const (
+	lokiScopeName = "loki"
+)
+
+var (
+	// lokiServiceNameLabels are the stream labels used as the service name, in order of preference.
+	// Loki itself uses the same labels to populate service_name.
+	lokiServiceNameLabels = []string{"service_name", "service", "app", "application", "name", "app_kubernetes_io_name", "container", "k8s_container_name", "container_name", "job"}
+	lokiLevelLabels       = []string{"level", "severity", "detected_level", "lvl", "loglevel"}
+)
+
+type lokiEntry struct {
+	timestamp int64
+	line      string
+	metadata  map[string]string
+}
+
+type lokiStream struct {
+	labels  map[string]string
+	entries []lokiEntry
+}
+
+// LokiPush accepts the Loki push API requests sent by Promtail, Grafana Agent, Fluent Bit, and other Loki clients.
+func (c *Collector) LokiPush(w http.ResponseWriter, r *http.Request) {
+	project, err := c.getProject(requestApiKey(r), db.ApiKeyScopeIngestLogs)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, err.Error(), projectErrorStatus(err))
+		return
+	}
+
+	streams, err := readLokiRequest(r)
+	if err != nil {
+		klog.Errorln(err)
+		status := http.StatusBadRequest
+		if errors.Is(err, errUnsupportedContentType) {
+			status = http.StatusUnsupportedMediaType
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	req := lokiToOTLP(streams)
+	if err = c.checkQuota(project.Id, logsIngestion(req)); err != nil {
+		writeQuotaError(w, "", err)
+		return
+	}
+	c.getRedactor(project.Id).Logs(req, nil)
+	c.getLogsBatch(project).Add(req)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func readLokiRequest(r *http.Request) ([]lokiStream, error) {
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch contentType {
+	case "", "application/x-protobuf":
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		data, err := snappy.Decode(nil, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress snappy: %w", err)
+		}
+		return unmarshalLokiProtobuf(data)
+	case "application/json":
+		decoder, err := getDecoder(r.Header.Get("Content-Encoding"), r.Body)
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(decoder)
+		if err != nil {
+			return nil, err
+		}
+		return unmarshalLokiJson(data)
+	}
+	return nil, unsupportedContentType(r.Header.Get("Content-Type"))
+}
+
+func parseLokiLabels(s string) (map[string]string, error) {
+	ls, err := parser.ParseMetric(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stream labels %s: %w", s, err)
+	}
+	return ls.Map(), nil
+}
+
+func unmarshalLokiJson(data []byte) ([]lokiStream, error) {
+	var req struct {
+		Streams []struct {
+			Stream map[string]string   `json:"stream"`
+			Labels string              `json:"labels"`
+			Values [][]json.RawMessage `json:"values"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	res := make([]lokiStream, 0, len(req.Streams))
+	for _, s := range req.Streams {
+		stream := lokiStream{labels: s.Stream}
+		if stream.labels == nil && s.Labels != "" {
+			labels, err := parseLokiLabels(s.Labels)
+			if err != nil {
+				return nil, err
+			}
+			stream.labels = labels
+		}
+		for _, v := range s.Values {
+			if len(v) < 2 {
+				return nil, fmt.Errorf("invalid entry: expected [timestamp, line]")
+			}
+			var ts, line string
+			if err := json.Unmarshal(v[0], &ts); err != nil {
+				return nil, fmt.Errorf("invalid entry timestamp: %w", err)
+			}
+			if err := json.Unmarshal(v[1], &line); err != nil {
+				return nil, fmt.Errorf("invalid entry line: %w", err)
+			}
+			e := lokiEntry{line: line}
+			var err error
+			if e.timestamp, err = strconv.ParseInt(ts, 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid entry timestamp: %w", err)
+			}
+			if len(v) > 2 {
+				if err = json.Unmarshal(v[2], &e.metadata); err != nil {
+					return nil, fmt.Errorf("invalid entry structured metadata: %w", err)
+				}
+			}
+			stream.entries = append(stream.entries, e)
+		}
+		res = append(res, stream)
+	}
+	return res, nil
+}
+
+// unmarshalLokiProtobuf decodes logproto.PushRequest:
+//
+//	PushRequest { repeated Stream streams = 1; }
+//	Stream { string labels = 1; repeated Entry entries = 2; }
+//	Entry { google.protobuf.Timestamp timestamp = 1; string line = 2; repeated LabelPair structuredMetadata = 3; }
+//	LabelPair { string name = 1; string value = 2; }
+func unmarshalLokiProtobuf(data []byte) ([]lokiStream, error) {
+	var res []lokiStream
+	err := walkProto(data, func(f protoField) error {
+		if f.num != 1 {
+			return nil
+		}
+		var stream lokiStream
+		var labels string
+		err := walkProto(f.bytes, func(f protoField) error {
+			switch f.num {
+			case 1:
+				labels = string(f.bytes)
+			case 2:
+				e, err := unmarshalLokiEntry(f.bytes)
+				if err != nil {
+					return err
+				}
+				stream.entries = append(stream.entries, e)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if stream.labels, err = parseLokiLabels(labels); err != nil {
+			return err
+		}
+		res = append(res, stream)
+		return nil
+	})
+	return res, err
+}
+
+func unmarshalLokiEntry(data []byte) (lokiEntry, error) {
+	var e lokiEntry
+	err := walkProto(data, func(f protoField) error {
+		switch f.num {
+		case 1:
+			var seconds, nanos uint64
+			err := walkProto(f.bytes, func(f protoField) error {
+				switch f.num {
+				case 1:
+					seconds = f.uint
+				case 2:
+					nanos = f.uint
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			e.timestamp = int64(seconds)*1e9 + int64(int32(nanos))
+		case 2:
+			e.line = string(f.bytes)
+		case 3:
+			var name, value string
+			err := walkProto(f.bytes, func(f protoField) error {
+				switch f.num {
+				case 1:
+					name = string(f.bytes)
+				case 2:
+					value = string(f.bytes)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if e.metadata == nil {
+				e.metadata = map[string]string{}
+			}
+			e.metadata[name] = value
+		}
+		return nil
+	})
+	return e, err
+}
+
+// lokiToOTLP converts Loki streams to OTLP logs: the stream labels become the resource attributes,
+// and the structured metadata becomes the log record attributes.
+func lokiToOTLP(streams []lokiStream) *v1.ExportLogsServiceRequest {
+	req := &v1.ExportLogsServiceRequest{}
+	for _, s := range streams {
+		if len(s.entries) == 0 {
+			continue
+		}
+		resource := &otlpresource.Resource{}
+		if _, ok := s.labels[semconv.AttributeServiceName]; !ok {
+			if name := lokiServiceName(s.labels); name != "" {
+				resource.Attributes = append(resource.Attributes, otlpStringAttr(semconv.AttributeServiceName, name))
+			}
+		}
+		for k, v := range s.labels {
+			resource.Attributes = append(resource.Attributes, otlpStringAttr(k, v))
+		}
+		streamSeverity := lokiSeverity(s.labels)
+		sl := &logsv1.ScopeLogs{Scope: &otlpcommon.InstrumentationScope{Name: lokiScopeName}}
+		for _, e := range s.entries {
+			lr := &logsv1.LogRecord{
+				TimeUnixNano: uint64(e.timestamp),
+				Body:         &otlpcommon.AnyValue{Value: &otlpcommon.AnyValue_StringValue{StringValue: e.line}},
+			}
+			for k, v := range e.metadata {
+				lr.Attributes = append(lr.Attributes, otlpStringAttr(k, v))
+			}
+			severity := lokiSeverity(e.metadata)
+			if severity == model.SeverityUnknown {
+				severity = streamSeverity
+			}
+			if severity == model.SeverityUnknown {
+				severity = guessSeverity(e.line)
+			}
+			if severity != model.SeverityUnknown {
+				n, _ := severity.Range()
+				lr.SeverityNumber = logsv1.SeverityNumber(n)
+				lr.SeverityText = severity.String()
+			}
+			sl.LogRecords = append(sl.LogRecords, lr)
+		}
+		req.ResourceLogs = append(req.ResourceLogs, &logsv1.ResourceLogs{Resource: resource, ScopeLogs: []*logsv1.ScopeLogs{sl}})
+	}
+	return req
+}
+
+func lokiServiceName(labels map[string]string) string {
+	for _, l := range lokiServiceNameLabels {
+		if v := labels[l]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func lokiSeverity(labels map[string]string) model.Severity {
+	for _, l := range lokiLevelLabels {
+		v := labels[l]
+		if v == "" {
+			continue
+		}
+		if s := model.SeverityFromString(strings.ToLower(v)); s > model.SeverityUnknown && s <= model.SeverityFatal {
+			return s
+		}
+		return guessSeverity(v)
+	}
+	return model.SeverityUnknown
+}
+
+func guessSeverity(line string) model.Severity {
+	switch logparser.GuessLevel(line) {
+	case logparser.LevelDebug:
+		return model.SeverityDebug
+	case logparser.LevelInfo:
+		return model.SeverityInfo
+	case logparser.LevelWarning:
+		return model.SeverityWarning
+	case logparser.LevelError:
+		return model.SeverityError
+	case logparser.LevelCritical:
+		return model.SeverityFatal
+	}
+	return model.SeverityUnknown
+}
+
+func otlpStringAttr(key, value string) *otlpcommon.KeyValue {
+	return &otlpcommon.KeyValue{Key: key, Value: &otlpcommon.AnyValue{Value: &otlpcommon.AnyValue_StringValue{StringValue: value}}}
+}
@@ -0,0 +1,134 @@
+package collector
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coroot/coroot/model"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
+	logsv1 "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func lokiProtobufEntry(seconds, nanos uint64, line string, metadata ...string) []byte {
+	var ts []byte
+	ts = protowire.AppendTag(ts, 1, protowire.VarintType)
+	ts = protowire.AppendVarint(ts, seconds)
+	ts = protowire.AppendTag(ts, 2, protowire.VarintType)
+	ts = protowire.AppendVarint(ts, nanos)
+
+	var e []byte
+	e = protowire.AppendTag(e, 1, protowire.BytesType)
+	e = protowire.AppendBytes(e, ts)
+	e = protowire.AppendTag(e, 2, protowire.BytesType)
+	e = protowire.AppendString(e, line)
+	for i := 0; i < len(metadata); i += 2 {
+		var kv []byte
+		kv = protowire.AppendTag(kv, 1, protowire.BytesType)
+		kv = protowire.AppendString(kv, metadata[i])
+		kv = protowire.AppendTag(kv, 2, protowire.BytesType)
+		kv = protowire.AppendString(kv, metadata[i+1])
+		e = protowire.AppendTag(e, 3, protowire.BytesType)
+		e = protowire.AppendBytes(e, kv)
+	}
+	return e
+}
+
+func TestLokiProtobuf(t *testing.T) {
+	var stream []byte
+	stream = protowire.AppendTag(stream, 1, protowire.BytesType)
+	stream = protowire.AppendString(stream, `{app="frontend", namespace="shop"}`)
+	stream = protowire.AppendTag(stream, 2, protowire.BytesType)
+	stream = protowire.AppendBytes(stream, lokiProtobufEntry(1700000000, 5, "GET / 200"))
+	stream = protowire.AppendTag(stream, 2, protowire.BytesType)
+	stream = protowire.AppendBytes(stream, lokiProtobufEntry(1700000001, 0, "connection reset", "trace_id", "abc"))
+	stream = protowire.AppendTag(stream, 3, protowire.VarintType)
+	stream = protowire.AppendVarint(stream, 12345)
+	var req []byte
+	req = protowire.AppendTag(req, 1, protowire.BytesType)
+	req = protowire.AppendBytes(req, stream)
+
+	r := httptest.NewRequest("POST", "/loki/api/v1/push", strings.NewReader(string(snappy.Encode(nil, req))))
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	streams, err := readLokiRequest(r)
+	require.NoError(t, err)
+	require.Len(t, streams, 1)
+	assert.Equal(t, map[string]string{"app": "frontend", "namespace": "shop"}, streams[0].labels)
+	assert.Equal(t, []lokiEntry{
+		{timestamp: 1700000000*1e9 + 5, line: "GET / 200"},
+		{timestamp: 1700000001 * 1e9, line: "connection reset", metadata: map[string]string{"trace_id": "abc"}},
+	}, streams[0].entries)
+
+	r = httptest.NewRequest("POST", "/loki/api/v1/push", strings.NewReader("not snappy"))
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	_, err = readLokiRequest(r)
+	assert.Error(t, err)
+}
+
+func TestLokiJson(t *testing.T) {
+	body := `{"streams": [
+		{"stream": {"service_name": "checkout"}, "values": [["1700000000000000000", "level=warn msg=\"slow query\""], ["1700000000000000001", "done", {"level": "DEBUG"}]]},
+		{"stream": {"job": "varlogs"}, "values": [["1700000000000000002", "2024-01-01 12:00:00 ERROR failed to connect"]]},
+		{"stream": {"container": "db", "level": "wrn"}, "values": [["1700000000000000003", "checkpoint"]]}
+	]}`
+	r := httptest.NewRequest("POST", "/loki/api/v1/push", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	streams, err := readLokiRequest(r)
+	require.NoError(t, err)
+	require.Len(t, streams, 3)
+
+	req := lokiToOTLP(streams)
+	require.Len(t, req.ResourceLogs, 3)
+
+	type record struct {
+		service  string
+		body     string
+		severity model.Severity
+		number   logsv1.SeverityNumber
+	}
+	var records []record
+	for _, rl := range req.ResourceLogs {
+		service := attributesToMap(rl.Resource.Attributes)[semconv.AttributeServiceName]
+		for _, sl := range rl.ScopeLogs {
+			assert.Equal(t, lokiScopeName, sl.Scope.Name)
+			for _, lr := range sl.LogRecords {
+				records = append(records, record{
+					service:  service,
+					body:     lr.Body.GetStringValue(),
+					severity: model.SeverityFromString(lr.SeverityText),
+					number:   lr.SeverityNumber,
+				})
+			}
+		}
+	}
+	assert.Equal(t, []record{
+		{service: "checkout", body: `level=warn msg="slow query"`, severity: model.SeverityWarning, number: logsv1.SeverityNumber_SEVERITY_NUMBER_WARN},
+		{service: "checkout", body: "done", severity: model.SeverityDebug, number: logsv1.SeverityNumber_SEVERITY_NUMBER_DEBUG},
+		{service: "varlogs", body: "2024-01-01 12:00:00 ERROR failed to connect", severity: model.SeverityError, number: logsv1.SeverityNumber_SEVERITY_NUMBER_ERROR},
+		{service: "db", body: "checkpoint", severity: model.SeverityWarning, number: logsv1.SeverityNumber_SEVERITY_NUMBER_WARN},
+	}, records)
+	assert.Equal(t, uint64(1700000000000000001), req.ResourceLogs[0].ScopeLogs[0].LogRecords[1].TimeUnixNano)
+	assert.Equal(t, "DEBUG", attributesToMap(req.ResourceLogs[0].ScopeLogs[0].LogRecords[1].Attributes)["level"])
+
+	r = httptest.NewRequest("POST", "/loki/api/v1/push", strings.NewReader(`{"streams": [{"stream": {}, "values": [["now", "line"]]}]}`))
+	r.Header.Set("Content-Type", "application/json")
+	_, err = readLokiRequest(r)
+	assert.Error(t, err)
+
+	r = httptest.NewRequest("POST", "/loki/api/v1/push", strings.NewReader(""))
+	r.Header.Set("Content-Type", "text/plain")
+	_, err = readLokiRequest(r)
+	assert.ErrorIs(t, err, errUnsupportedContentType)
+}
+
+func TestRequestApiKey(t *testing.T) {
+	r := httptest.NewRequest("POST", "/loki/api/v1/push", nil)
+	r.SetBasicAuth("coroot", "secret")
+	assert.Equal(t, "secret", requestApiKey(r))
+	r.Header.Set(ApiKeyHeader, "key")
+	assert.Equal(t, "key", requestApiKey(r))
+}
@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"bytes"
 	"crypto/tls"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,6 +14,7 @@ import (
 
 	"github.com/ClickHouse/ch-go"
 	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/coroot/coroot/db"
 	"github.com/gogo/protobuf/proto"
 	"github.com/golang/snappy"
 	promModel "github.com/prometheus/common/model"
@@ -40,27 +40,57 @@ func addLabelsIfNeeded(r *http.Request, body []byte, extraLabels map[string]stri
 	if err != nil {
 		return nil, err
 	}
+	addExtraLabels(req, extraLabels)
+	decompressed, err := proto.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	return snappy.Encode(nil, decompressed), nil
+}
+
+func addExtraLabels(req *prompb.WriteRequest, extraLabels map[string]string) {
 	for i := range req.Timeseries {
 		for k, v := range extraLabels {
 			req.Timeseries[i].Labels = append(req.Timeseries[i].Labels, prompb.Label{Name: k, Value: v})
 		}
 	}
-	decompressed, err := proto.Marshal(req)
-	if err != nil {
-		return nil, err
+}
+
+func remoteWriteUrl(cfg *db.IntegrationPrometheus) (*url.URL, error) {
+	var u *url.URL
+	var err error
+	if cfg.RemoteWriteUrl == "" {
+		if u, err = url.Parse(cfg.Url); err != nil {
+			return nil, err
+		}
+		u = u.JoinPath("/api/v1/write")
+	} else {
+		if u, err = url.Parse(cfg.RemoteWriteUrl); err != nil {
+			return nil, err
+		}
 	}
-	return snappy.Encode(nil, decompressed), nil
+	if cfg.BasicAuth != nil {
+		u.User = url.UserPassword(cfg.BasicAuth.User, cfg.BasicAuth.Password)
+	}
+	return u, nil
+}
+
+func remoteWriteClient(cfg *db.IntegrationPrometheus) *http.Client {
+	if cfg.TlsSkipVerify {
+		return insecureClient
+	}
+	return secureClient
 }
 
 func (c *Collector) Metrics(w http.ResponseWriter, r *http.Request) {
-	project, err := c.getProject(r.Header.Get(ApiKeyHeader))
+	project, err := c.getProject(r.Header.Get(ApiKeyHeader), db.ApiKeyScopeIngestMetrics)
 	if err != nil {
 		klog.Errorln(err)
-		if errors.Is(err, ErrProjectNotFound) {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		http.Error(w, "", http.StatusInternalServerError)
+		http.Error(w, err.Error(), projectErrorStatus(err))
+		return
+	}
+	if isOTLPMetricsRequest(r) {
+		c.otlpMetrics(w, r, project)
 		return
 	}
 	cfg := project.PrometheusConfig(c.globalPrometheus)
@@ -70,37 +100,28 @@ func (c *Collector) Metrics(w http.ResponseWriter, r *http.Request) {
 		klog.Errorln(err)
 		http.Error(w, "", http.StatusBadRequest)
 	}
-	if cfg.UseClickHouse {
+	if cfg.UseClickHouse || c.getQuota(project.Id) != nil {
 		req, err := parseMetricsRequestBody(r, body)
 		if err != nil {
 			klog.Errorln(err)
 			http.Error(w, "", http.StatusBadRequest)
 			return
 		}
-		c.getMetricsBatch(project).Add(req)
-		return
-	}
-
-	var u *url.URL
-	if cfg.RemoteWriteUrl == "" {
-		u, err = url.Parse(cfg.Url)
-		if err != nil {
-			klog.Errorln(err)
-			http.Error(w, "", http.StatusInternalServerError)
+		if err = c.checkQuota(project.Id, metricsIngestion(req, req.Size())); err != nil {
+			writeQuotaError(w, "", err)
 			return
 		}
-		u = u.JoinPath("/api/v1/write")
-	} else {
-		u, err = url.Parse(cfg.RemoteWriteUrl)
-		if err != nil {
-			klog.Errorln(err)
-			http.Error(w, "", http.StatusInternalServerError)
+		if cfg.UseClickHouse {
+			c.getMetricsBatch(project).Add(req)
 			return
 		}
 	}
 
-	if cfg.BasicAuth != nil {
-		u.User = url.UserPassword(cfg.BasicAuth.User, cfg.BasicAuth.Password)
+	u, err := remoteWriteUrl(cfg)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
 	}
 	body, err = addLabelsIfNeeded(r, body, cfg.ExtraLabels)
 	if err != nil {
@@ -127,11 +148,7 @@ func (c *Collector) Metrics(w http.ResponseWriter, r *http.Request) {
 			req.Header.Add(k, v)
 		}
 	}
-	httpClient := secureClient
-	if cfg.TlsSkipVerify {
-		httpClient = insecureClient
-	}
-	res, err := httpClient.Do(req)
+	res, err := remoteWriteClient(cfg).Do(req)
 	if err != nil {
 		klog.Errorln(err)
 		http.Error(w, "", http.StatusInternalServerError)
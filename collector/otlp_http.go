@@ -0,0 +1,181 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog"
+)
+
+const (
+	otlpContentTypeProtobuf = "application/x-protobuf"
+	otlpContentTypeJson     = "application/json"
+)
+
+// In OTLP/JSON, trace and span ids are hex-encoded instead of the base64 encoding protojson uses for bytes fields.
+var otlpJsonHexFields = map[string]bool{
+	"traceId":        true,
+	"trace_id":       true,
+	"spanId":         true,
+	"span_id":        true,
+	"parentSpanId":   true,
+	"parent_span_id": true,
+	"profileId":      true,
+	"profile_id":     true,
+}
+
+// readOTLPRequest decodes the body of an OTLP/HTTP request encoded either as binary protobuf or as JSON.
+// It returns the content type the response must be encoded with, or an error if the request has already been responded to.
+func readOTLPRequest(w http.ResponseWriter, r *http.Request, req proto.Message) (string, error) {
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch contentType {
+	case otlpContentTypeProtobuf, otlpContentTypeJson:
+	default:
+		err := fmt.Errorf("unsupported content type: %s", r.Header.Get("Content-Type"))
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return "", err
+	}
+
+	decoder, err := getDecoder(r.Header.Get("Content-Encoding"), r.Body)
+	if err != nil {
+		writeOTLPError(w, contentType, http.StatusBadRequest, err)
+		return "", err
+	}
+
+	data, err := io.ReadAll(decoder)
+	if err != nil {
+		klog.Errorln(err)
+		writeOTLPError(w, contentType, http.StatusBadRequest, err)
+		return "", err
+	}
+	if contentType == otlpContentTypeJson {
+		err = unmarshalOTLPJson(data, req)
+	} else {
+		err = proto.Unmarshal(data, req)
+	}
+	if err != nil {
+		klog.Errorln(err)
+		writeOTLPError(w, contentType, http.StatusBadRequest, err)
+		return "", err
+	}
+	return contentType, nil
+}
+
+func writeOTLPResponse(w http.ResponseWriter, contentType string, resp proto.Message) {
+	data, err := marshalOTLP(contentType, resp)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(data)
+}
+
+// writeOTLPError responds with a google.rpc.Status message as required by the OTLP/HTTP specification.
+func writeOTLPError(w http.ResponseWriter, contentType string, httpStatus int, err error) {
+	code := codes.InvalidArgument
+	if httpStatus >= http.StatusInternalServerError {
+		code = codes.Unavailable
+	}
+	data, mErr := marshalOTLP(contentType, status.New(code, err.Error()).Proto())
+	if mErr != nil {
+		klog.Errorln(mErr)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(httpStatus)
+	_, _ = w.Write(data)
+}
+
+func marshalOTLP(contentType string, m proto.Message) ([]byte, error) {
+	if contentType == otlpContentTypeJson {
+		return marshalOTLPJson(m)
+	}
+	return proto.Marshal(m)
+}
+
+func unmarshalOTLPJson(data []byte, m proto.Message) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return err
+	}
+	err := convertOTLPJsonIds(v, func(s string) (string, error) {
+		id, err := hex.DecodeString(s)
+		if err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(id), nil
+	})
+	if err != nil {
+		return err
+	}
+	data, err = json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, m)
+}
+
+func marshalOTLPJson(m proto.Message) ([]byte, error) {
+	data, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v any
+	if err = d.Decode(&v); err != nil {
+		return nil, err
+	}
+	err = convertOTLPJsonIds(v, func(s string) (string, error) {
+		id, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(id), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func convertOTLPJsonIds(v any, convert func(string) (string, error)) error {
+	switch vv := v.(type) {
+	case map[string]any:
+		for k, f := range vv {
+			if s, ok := f.(string); ok && otlpJsonHexFields[k] {
+				id, err := convert(s)
+				if err != nil {
+					return fmt.Errorf("invalid %s %q: %w", k, s, err)
+				}
+				vv[k] = id
+				continue
+			}
+			if err := convertOTLPJsonIds(f, convert); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, f := range vv {
+			if err := convertOTLPJsonIds(f, convert); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
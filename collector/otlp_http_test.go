@@ -0,0 +1,138 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	logsv1 "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	metricsv1 "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	tracesv1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func otlpHttpRequest(contentType string, body []byte) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/v1/traces", bytes.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	return r
+}
+
+func readTestdata(t *testing.T, name string) []byte {
+	data, err := os.ReadFile("testdata/otlp/" + name)
+	require.NoError(t, err)
+	return data
+}
+
+func TestOTLPJsonTraces(t *testing.T) {
+	w := httptest.NewRecorder()
+	req := &tracesv1.ExportTraceServiceRequest{}
+	contentType, err := readOTLPRequest(w, otlpHttpRequest("application/json; charset=utf-8", readTestdata(t, "trace.json")), req)
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", contentType)
+
+	span := req.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	assert.Equal(t, "5b8efff798038103d269b633813fc60c", hex.EncodeToString(span.TraceId))
+	assert.Equal(t, "eee19b7ec3c1b174", hex.EncodeToString(span.SpanId))
+	assert.Equal(t, "eee19b7ec3c1b173", hex.EncodeToString(span.ParentSpanId))
+	assert.Equal(t, "I'm a server span", span.Name)
+	assert.Equal(t, uint64(1544712660000000000), span.StartTimeUnixNano)
+	assert.Equal(t, "SPAN_KIND_SERVER", span.Kind.String())
+	assert.Equal(t, map[string]string{"my.span.attr": "some value"}, attributesToMap(span.Attributes))
+
+	resp := rejectInvalidSpans(req)
+	assert.Nil(t, resp.PartialSuccess)
+	writeOTLPResponse(w, contentType, resp)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "{}", w.Body.String())
+
+	// the same request encoded as protobuf must get a protobuf response
+	data, err := proto.Marshal(req)
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	decoded := &tracesv1.ExportTraceServiceRequest{}
+	contentType, err = readOTLPRequest(w, otlpHttpRequest("application/x-protobuf", data), decoded)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(req, decoded))
+	writeOTLPResponse(w, contentType, &tracesv1.ExportTraceServiceResponse{})
+	assert.Equal(t, "application/x-protobuf", w.Header().Get("Content-Type"))
+	assert.Equal(t, 0, w.Body.Len())
+}
+
+func TestOTLPJsonLogs(t *testing.T) {
+	w := httptest.NewRecorder()
+	req := &logsv1.ExportLogsServiceRequest{}
+	_, err := readOTLPRequest(w, otlpHttpRequest("application/json", readTestdata(t, "logs.json")), req)
+	require.NoError(t, err)
+
+	lr := req.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	assert.Equal(t, "5b8efff798038103d269b633813fc60c", hex.EncodeToString(lr.TraceId))
+	assert.Equal(t, "eee19b7ec3c1b174", hex.EncodeToString(lr.SpanId))
+	assert.Equal(t, "Example log record", lr.Body.GetStringValue())
+	assert.Equal(t, "Information", lr.SeverityText)
+	assert.Equal(t, map[string]string{
+		"string.attribute":  "some string",
+		"boolean.attribute": "true",
+		"int.attribute":     "10",
+		"double.attribute":  "637.704",
+		"array.attribute":   `["many","values"]`,
+		"map.attribute":     `{"some.map.key":"some value"}`,
+	}, attributesToMap(lr.Attributes))
+	assert.Nil(t, rejectInvalidLogRecords(req).PartialSuccess)
+}
+
+func TestOTLPJsonMetrics(t *testing.T) {
+	w := httptest.NewRecorder()
+	req := &metricsv1.ExportMetricsServiceRequest{}
+	_, err := readOTLPRequest(w, otlpHttpRequest("application/json", readTestdata(t, "metrics.json")), req)
+	require.NoError(t, err)
+
+	wr, rejected := otlpMetricsToPrometheus(req)
+	assert.Equal(t, int64(3), rejected, "the sum and the histograms in the sample have delta temporality")
+	assert.Equal(t, []string{
+		"my_gauge_ratio{job=my.service,my_gauge_attr=some value} 10",
+	}, seriesToStrings(wr))
+}
+
+func TestOTLPPartialSuccess(t *testing.T) {
+	req := &tracesv1.ExportTraceServiceRequest{}
+	require.NoError(t, unmarshalOTLPJson(readTestdata(t, "trace.json"), req))
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	invalid := proto.Clone(spans[0]).(*tracev1.Span)
+	invalid.TraceId = make([]byte, 16)
+	req.ResourceSpans[0].ScopeSpans[0].Spans = append(spans, invalid)
+
+	resp := rejectInvalidSpans(req)
+	require.NotNil(t, resp.PartialSuccess)
+	assert.Equal(t, int64(1), resp.PartialSuccess.RejectedSpans)
+	assert.Len(t, req.ResourceSpans[0].ScopeSpans[0].Spans, 1)
+
+	w := httptest.NewRecorder()
+	writeOTLPResponse(w, otlpContentTypeJson, resp)
+	assert.JSONEq(t, `{"partialSuccess":{"rejectedSpans":"1","errorMessage":"spans with invalid trace or span ids were rejected"}}`, w.Body.String())
+}
+
+func TestOTLPHttpErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+	_, err := readOTLPRequest(w, otlpHttpRequest("text/plain", nil), &tracesv1.ExportTraceServiceRequest{})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	w = httptest.NewRecorder()
+	body := strings.Replace(string(readTestdata(t, "trace.json")), "5B8EFFF798038103D269B633813FC60C", "not-a-hex-id", 1)
+	_, err = readOTLPRequest(w, otlpHttpRequest("application/json", []byte(body)), &tracesv1.ExportTraceServiceRequest{})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	st := &status.Status{}
+	require.NoError(t, protojson.Unmarshal(w.Body.Bytes(), st))
+	assert.Contains(t, st.Message, "invalid traceId")
+}
@@ -0,0 +1,480 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/coroot/coroot/db"
+	gogoproto "github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	promModel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
+	metricsv1 "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	otlpcommon "go.opentelemetry.io/proto/otlp/common/v1"
+	otlpmetrics "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog"
+)
+
+// The OTLP units are converted to the Prometheus ones in the same way as the Prometheus OTLP receiver does.
+var (
+	otlpUnits = map[string]string{
+		"d":    "days",
+		"h":    "hours",
+		"min":  "minutes",
+		"s":    "seconds",
+		"ms":   "milliseconds",
+		"us":   "microseconds",
+		"ns":   "nanoseconds",
+		"By":   "bytes",
+		"KiBy": "kibibytes",
+		"MiBy": "mebibytes",
+		"GiBy": "gibibytes",
+		"TiBy": "tibibytes",
+		"KBy":  "kilobytes",
+		"MBy":  "megabytes",
+		"GBy":  "gigabytes",
+		"TBy":  "terabytes",
+		"m":    "meters",
+		"V":    "volts",
+		"A":    "amperes",
+		"J":    "joules",
+		"W":    "watts",
+		"g":    "grams",
+		"Cel":  "celsius",
+		"Hz":   "hertz",
+		"1":    "",
+		"%":    "percent",
+	}
+	otlpPerUnits = map[string]string{
+		"s":  "second",
+		"m":  "minute",
+		"h":  "hour",
+		"d":  "day",
+		"w":  "week",
+		"mo": "month",
+		"y":  "year",
+	}
+)
+
+// OTLP exporters use the same /v1/metrics path as the Prometheus remote write, but never compress the payload with snappy.
+func isOTLPMetricsRequest(r *http.Request) bool {
+	return r.Header.Get("Content-Encoding") != "snappy"
+}
+
+func (c *Collector) otlpMetrics(w http.ResponseWriter, r *http.Request, project *db.Project) {
+	req := &metricsv1.ExportMetricsServiceRequest{}
+	contentType, err := readOTLPRequest(w, r, req)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.writeOTLPMetrics(r.Context(), project, req)
+	if errors.Is(err, ErrQuotaExceeded) {
+		writeQuotaError(w, contentType, err)
+		return
+	}
+	if err != nil {
+		klog.Errorln("failed to write metrics:", err)
+		writeOTLPError(w, contentType, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	writeOTLPResponse(w, contentType, resp)
+}
+
+func (c *Collector) writeOTLPMetrics(ctx context.Context, project *db.Project, req *metricsv1.ExportMetricsServiceRequest) (*metricsv1.ExportMetricsServiceResponse, error) {
+	wr, rejected := otlpMetricsToPrometheus(req)
+	resp := &metricsv1.ExportMetricsServiceResponse{}
+	if rejected > 0 {
+		resp.PartialSuccess = &metricsv1.ExportMetricsPartialSuccess{
+			RejectedDataPoints: rejected,
+			ErrorMessage:       "data points with delta temporality are not supported",
+		}
+	}
+	if len(wr.Timeseries) == 0 {
+		return resp, nil
+	}
+	if err := c.checkQuota(project.Id, metricsIngestion(wr, proto.Size(req))); err != nil {
+		return nil, err
+	}
+	cfg := project.PrometheusConfig(c.globalPrometheus)
+	addExtraLabels(wr, cfg.ExtraLabels)
+
+	if cfg.UseClickHouse {
+		c.getMetricsBatch(project).Add(wr)
+		return resp, nil
+	}
+	return resp, remoteWrite(ctx, cfg, wr)
+}
+
+func remoteWrite(ctx context.Context, cfg *db.IntegrationPrometheus, wr *prompb.WriteRequest) error {
+	u, err := remoteWriteUrl(cfg)
+	if err != nil {
+		return err
+	}
+	data, err := gogoproto.Marshal(wr)
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(snappy.Encode(nil, data)))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	r.Header.Set("Content-Encoding", "snappy")
+	r.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	for _, h := range cfg.CustomHeaders {
+		r.Header.Add(h.Key, h.Value)
+	}
+	res, err := remoteWriteClient(cfg).Do(r)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("got %d (%s) from prometheus", res.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+type otlpConverter struct {
+	wr       *prompb.WriteRequest
+	metadata map[string]bool
+	skipped  map[string]bool
+	rejected int64
+}
+
+// otlpMetricsToPrometheus returns the converted series and the number of rejected data points.
+func otlpMetricsToPrometheus(req *metricsv1.ExportMetricsServiceRequest) (*prompb.WriteRequest, int64) {
+	c := &otlpConverter{
+		wr:       &prompb.WriteRequest{},
+		metadata: map[string]bool{},
+		skipped:  map[string]bool{},
+	}
+	for _, rm := range req.GetResourceMetrics() {
+		c.resourceMetrics(rm)
+	}
+	for name := range c.skipped {
+		klog.Warningf("skipping %s: delta temporality is not supported", name)
+	}
+	return c.wr, c.rejected
+}
+
+func (c *otlpConverter) resourceMetrics(rm *otlpmetrics.ResourceMetrics) {
+	resourceAttrs := attributesToMap(rm.GetResource().GetAttributes())
+	var resourceLabels []prompb.Label
+	job := resourceAttrs[semconv.AttributeServiceName]
+	if ns := resourceAttrs[semconv.AttributeServiceNamespace]; ns != "" && job != "" {
+		job = ns + "/" + job
+	}
+	if job != "" {
+		resourceLabels = append(resourceLabels, prompb.Label{Name: promModel.JobLabel, Value: job})
+	}
+	if instance := resourceAttrs[semconv.AttributeServiceInstanceID]; instance != "" {
+		resourceLabels = append(resourceLabels, prompb.Label{Name: promModel.InstanceLabel, Value: instance})
+	}
+
+	var latest int64
+	for _, sm := range rm.GetScopeMetrics() {
+		for _, m := range sm.GetMetrics() {
+			if ts := c.metric(m, resourceLabels); ts > latest {
+				latest = ts
+			}
+		}
+	}
+
+	delete(resourceAttrs, semconv.AttributeServiceName)
+	delete(resourceAttrs, semconv.AttributeServiceNamespace)
+	delete(resourceAttrs, semconv.AttributeServiceInstanceID)
+	if len(resourceAttrs) == 0 || latest == 0 {
+		return
+	}
+	labels := append(otlpLabels(resourceAttrs), resourceLabels...)
+	c.addMetadata("target_info", prompb.MetricMetadata_GAUGE, "Target metadata", "")
+	c.addSample("target_info", labels, nil, 1, latest)
+}
+
+// metric converts the metric data points and returns the latest timestamp in milliseconds.
+func (c *otlpConverter) metric(m *otlpmetrics.Metric, resourceLabels []prompb.Label) int64 {
+	name := otlpMetricName(m)
+	var latest int64
+	sample := func(name string, labels []prompb.Label, extra []prompb.Label, v float64, timeNano uint64, flags uint32) {
+		ts := int64(timeNano / 1e6)
+		if flags&uint32(otlpmetrics.DataPointFlags_DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK) != 0 {
+			v = math.Float64frombits(value.StaleNaN)
+		}
+		c.addSample(name, labels, extra, v, ts)
+		latest = max(latest, ts)
+	}
+
+	switch d := m.Data.(type) {
+	case *otlpmetrics.Metric_Gauge:
+		c.addMetadata(name, prompb.MetricMetadata_GAUGE, m.Description, m.Unit)
+		for _, p := range d.Gauge.GetDataPoints() {
+			sample(name, dataPointLabels(p.Attributes, resourceLabels), nil, numberValue(p), p.TimeUnixNano, p.Flags)
+		}
+	case *otlpmetrics.Metric_Sum:
+		if d.Sum.AggregationTemporality == otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA {
+			c.skipped[m.Name] = true
+			c.rejected += int64(len(d.Sum.DataPoints))
+			return 0
+		}
+		typ := prompb.MetricMetadata_GAUGE
+		if d.Sum.IsMonotonic {
+			typ = prompb.MetricMetadata_COUNTER
+		}
+		c.addMetadata(name, typ, m.Description, m.Unit)
+		for _, p := range d.Sum.GetDataPoints() {
+			sample(name, dataPointLabels(p.Attributes, resourceLabels), nil, numberValue(p), p.TimeUnixNano, p.Flags)
+		}
+	case *otlpmetrics.Metric_Histogram:
+		if d.Histogram.AggregationTemporality == otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA {
+			c.skipped[m.Name] = true
+			c.rejected += int64(len(d.Histogram.DataPoints))
+			return 0
+		}
+		c.addMetadata(name, prompb.MetricMetadata_HISTOGRAM, m.Description, m.Unit)
+		for _, p := range d.Histogram.GetDataPoints() {
+			labels := dataPointLabels(p.Attributes, resourceLabels)
+			var cumulative uint64
+			for i, bound := range p.ExplicitBounds {
+				if i < len(p.BucketCounts) {
+					cumulative += p.BucketCounts[i]
+				}
+				sample(name+"_bucket", labels, leLabel(bound), float64(cumulative), p.TimeUnixNano, p.Flags)
+			}
+			sample(name+"_bucket", labels, leLabel(math.Inf(1)), float64(p.Count), p.TimeUnixNano, p.Flags)
+			if p.Sum != nil {
+				sample(name+"_sum", labels, nil, *p.Sum, p.TimeUnixNano, p.Flags)
+			}
+			sample(name+"_count", labels, nil, float64(p.Count), p.TimeUnixNano, p.Flags)
+		}
+	case *otlpmetrics.Metric_ExponentialHistogram:
+		if d.ExponentialHistogram.AggregationTemporality == otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA {
+			c.skipped[m.Name] = true
+			c.rejected += int64(len(d.ExponentialHistogram.DataPoints))
+			return 0
+		}
+		c.addMetadata(name, prompb.MetricMetadata_HISTOGRAM, m.Description, m.Unit)
+		for _, p := range d.ExponentialHistogram.GetDataPoints() {
+			labels := dataPointLabels(p.Attributes, resourceLabels)
+			for _, b := range exponentialBuckets(p) {
+				sample(name+"_bucket", labels, leLabel(b.le), float64(b.count), p.TimeUnixNano, p.Flags)
+			}
+			sample(name+"_bucket", labels, leLabel(math.Inf(1)), float64(p.Count), p.TimeUnixNano, p.Flags)
+			if p.Sum != nil {
+				sample(name+"_sum", labels, nil, *p.Sum, p.TimeUnixNano, p.Flags)
+			}
+			sample(name+"_count", labels, nil, float64(p.Count), p.TimeUnixNano, p.Flags)
+		}
+	case *otlpmetrics.Metric_Summary:
+		c.addMetadata(name, prompb.MetricMetadata_SUMMARY, m.Description, m.Unit)
+		for _, p := range d.Summary.GetDataPoints() {
+			labels := dataPointLabels(p.Attributes, resourceLabels)
+			for _, q := range p.QuantileValues {
+				quantile := []prompb.Label{{Name: promModel.QuantileLabel, Value: formatFloat(q.Quantile)}}
+				sample(name, labels, quantile, q.Value, p.TimeUnixNano, p.Flags)
+			}
+			sample(name+"_sum", labels, nil, p.Sum, p.TimeUnixNano, p.Flags)
+			sample(name+"_count", labels, nil, float64(p.Count), p.TimeUnixNano, p.Flags)
+		}
+	}
+	return latest
+}
+
+func dataPointLabels(attrs []*otlpcommon.KeyValue, resourceLabels []prompb.Label) []prompb.Label {
+	return append(otlpLabels(attributesToMap(attrs)), resourceLabels...)
+}
+
+func (c *otlpConverter) addSample(name string, labels []prompb.Label, extra []prompb.Label, v float64, ts int64) {
+	ls := make([]prompb.Label, 0, len(labels)+len(extra)+1)
+	ls = append(ls, prompb.Label{Name: promModel.MetricNameLabel, Value: name})
+	ls = append(ls, labels...)
+	ls = append(ls, extra...)
+	sort.Slice(ls, func(i, j int) bool {
+		return ls[i].Name < ls[j].Name
+	})
+	c.wr.Timeseries = append(c.wr.Timeseries, prompb.TimeSeries{
+		Labels:  ls,
+		Samples: []prompb.Sample{{Value: v, Timestamp: ts}},
+	})
+}
+
+func (c *otlpConverter) addMetadata(name string, typ prompb.MetricMetadata_MetricType, help, unit string) {
+	if c.metadata[name] {
+		return
+	}
+	c.metadata[name] = true
+	c.wr.Metadata = append(c.wr.Metadata, prompb.MetricMetadata{
+		Type:             typ,
+		MetricFamilyName: name,
+		Help:             help,
+		Unit:             unit,
+	})
+}
+
+func numberValue(p *otlpmetrics.NumberDataPoint) float64 {
+	switch v := p.Value.(type) {
+	case *otlpmetrics.NumberDataPoint_AsDouble:
+		return v.AsDouble
+	case *otlpmetrics.NumberDataPoint_AsInt:
+		return float64(v.AsInt)
+	}
+	return 0
+}
+
+type bucket struct {
+	le    float64
+	count uint64
+}
+
+// exponentialBuckets converts the buckets of an exponential histogram into cumulative classic buckets.
+// The bucket with the index i covers the range (base^i, base^(i+1)], where base = 2^(2^-scale).
+func exponentialBuckets(p *otlpmetrics.ExponentialHistogramDataPoint) []bucket {
+	bound := func(index int) float64 {
+		return math.Exp2(float64(index) * math.Exp2(-float64(p.Scale)))
+	}
+	var res []bucket
+	var cumulative uint64
+	if n := p.Negative; n != nil {
+		for i := len(n.BucketCounts) - 1; i >= 0; i-- {
+			cumulative += n.BucketCounts[i]
+			res = append(res, bucket{le: -bound(int(n.Offset) + i), count: cumulative})
+		}
+	}
+	cumulative += p.ZeroCount
+	res = append(res, bucket{le: p.ZeroThreshold, count: cumulative})
+	if pos := p.Positive; pos != nil {
+		for i, count := range pos.BucketCounts {
+			cumulative += count
+			res = append(res, bucket{le: bound(int(pos.Offset) + i + 1), count: cumulative})
+		}
+	}
+	return res
+}
+
+func leLabel(bound float64) []prompb.Label {
+	return []prompb.Label{{Name: promModel.BucketLabel, Value: formatFloat(bound)}}
+}
+
+func formatFloat(v float64) string {
+	return promModel.SampleValue(v).String()
+}
+
+// otlpMetricName builds the Prometheus metric name by adding the unit and type suffixes to the sanitized OTLP metric name:
+// e.g., http.server.duration (s) -> http_server_duration_seconds.
+func otlpMetricName(m *otlpmetrics.Metric) string {
+	tokens := strings.FieldsFunc(m.Name, func(r rune) bool {
+		return !isMetricNameRune(r)
+	})
+
+	mainUnit, perUnit, _ := strings.Cut(otlpCleanUnit(m.Unit), "/")
+	if u, ok := otlpUnits[mainUnit]; ok {
+		mainUnit = u
+	}
+	if mainUnit != "" && !slices.Contains(tokens, mainUnit) {
+		tokens = append(tokens, mainUnit)
+	}
+	if u, ok := otlpPerUnits[perUnit]; ok {
+		perUnit = u
+	}
+	if perUnit != "" && !slices.Contains(tokens, perUnit) {
+		tokens = append(tokens, "per", perUnit)
+	}
+
+	switch d := m.Data.(type) {
+	case *otlpmetrics.Metric_Sum:
+		if d.Sum.IsMonotonic {
+			tokens = append(slices.DeleteFunc(tokens, func(t string) bool { return t == "total" }), "total")
+		}
+	case *otlpmetrics.Metric_Gauge:
+		if m.Unit == "1" {
+			tokens = append(slices.DeleteFunc(tokens, func(t string) bool { return t == "ratio" }), "ratio")
+		}
+	}
+
+	name := strings.Join(tokens, "_")
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	return name
+}
+
+// otlpCleanUnit removes the annotations in curly braces (e.g., {requests}/s -> /s) and unsupported characters.
+func otlpCleanUnit(unit string) string {
+	var sb strings.Builder
+	depth := 0
+	for _, r := range unit {
+		switch {
+		case r == '{':
+			depth++
+		case r == '}':
+			depth = max(depth-1, 0)
+		case depth > 0:
+		case r == '/' || r == '%' || isMetricNameRune(r):
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func otlpLabels(attrs map[string]string) []prompb.Label {
+	byName := make(map[string][]string, len(attrs))
+	var names []string
+	for k, v := range attrs {
+		name := otlpLabelName(k)
+		if name == "" {
+			continue
+		}
+		if _, ok := byName[name]; !ok {
+			names = append(names, name)
+		}
+		byName[name] = append(byName[name], v)
+	}
+	res := make([]prompb.Label, 0, len(names))
+	for _, name := range names {
+		values := byName[name]
+		sort.Strings(values)
+		res = append(res, prompb.Label{Name: name, Value: strings.Join(values, ";")})
+	}
+	return res
+}
+
+func otlpLabelName(name string) string {
+	if name == "" {
+		return ""
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return '_'
+	}, name)
+	switch {
+	case unicode.IsDigit(rune(name[0])):
+		name = "key_" + name
+	case strings.HasPrefix(name, "_") && !strings.HasPrefix(name, "__"):
+		name = "key" + name
+	}
+	return name
+}
+
+func isMetricNameRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == ':')
+}
@@ -0,0 +1,218 @@
+package collector
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	metricsv1 "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	otlpcommon "go.opentelemetry.io/proto/otlp/common/v1"
+	otlpmetrics "go.opentelemetry.io/proto/otlp/metrics/v1"
+	otlpresource "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+func otlpAttrs(kv ...string) []*otlpcommon.KeyValue {
+	var res []*otlpcommon.KeyValue
+	for i := 0; i < len(kv); i += 2 {
+		res = append(res, &otlpcommon.KeyValue{Key: kv[i], Value: &otlpcommon.AnyValue{Value: &otlpcommon.AnyValue_StringValue{StringValue: kv[i+1]}}})
+	}
+	return res
+}
+
+func seriesToStrings(wr *prompb.WriteRequest) []string {
+	var res []string
+	for _, ts := range wr.Timeseries {
+		var name string
+		var labels []string
+		for _, l := range ts.Labels {
+			if l.Name == "__name__" {
+				name = l.Value
+				continue
+			}
+			labels = append(labels, l.Name+"="+l.Value)
+		}
+		v := ts.Samples[0].Value
+		s := formatFloat(v)
+		if value.IsStaleNaN(v) {
+			s = "stale"
+		}
+		res = append(res, name+"{"+strings.Join(labels, ",")+"} "+s)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func TestOTLPMetricName(t *testing.T) {
+	sum := func(monotonic bool) *otlpmetrics.Metric_Sum {
+		return &otlpmetrics.Metric_Sum{Sum: &otlpmetrics.Sum{IsMonotonic: monotonic}}
+	}
+	gauge := &otlpmetrics.Metric_Gauge{Gauge: &otlpmetrics.Gauge{}}
+	for _, c := range []struct {
+		name     string
+		unit     string
+		data     any
+		expected string
+	}{
+		{"http.server.duration", "s", &otlpmetrics.Metric_Histogram{}, "http_server_duration_seconds"},
+		{"http.server.request.size", "By", &otlpmetrics.Metric_Histogram{}, "http_server_request_size_bytes"},
+		{"system.network.io", "By", sum(true), "system_network_io_bytes_total"},
+		{"requests.total", "{request}", sum(true), "requests_total"},
+		{"queue.size", "{item}", sum(false), "queue_size"},
+		{"throughput", "By/s", gauge, "throughput_bytes_per_second"},
+		{"cpu.utilization", "1", gauge, "cpu_utilization_ratio"},
+		{"memory.usage.bytes", "By", gauge, "memory_usage_bytes"},
+		{"disk.free", "%", gauge, "disk_free_percent"},
+		{"2xx.count", "", sum(true), "_2xx_count_total"},
+	} {
+		m := &otlpmetrics.Metric{Name: c.name, Unit: c.unit}
+		switch d := c.data.(type) {
+		case *otlpmetrics.Metric_Sum:
+			m.Data = d
+		case *otlpmetrics.Metric_Gauge:
+			m.Data = d
+		case *otlpmetrics.Metric_Histogram:
+			m.Data = d
+		}
+		assert.Equal(t, c.expected, otlpMetricName(m), c.name)
+	}
+}
+
+func TestOTLPLabelName(t *testing.T) {
+	assert.Equal(t, "http_method", otlpLabelName("http.method"))
+	assert.Equal(t, "key_0", otlpLabelName("0"))
+	assert.Equal(t, "key_tag", otlpLabelName("_tag"))
+	assert.Equal(t, "__tag", otlpLabelName("__tag"))
+	assert.Equal(t, "", otlpLabelName(""))
+}
+
+func TestOTLPMetricsToPrometheus(t *testing.T) {
+	const ts = uint64(1700000000123 * 1e6)
+	sum := 10.5
+	req := &metricsv1.ExportMetricsServiceRequest{
+		ResourceMetrics: []*otlpmetrics.ResourceMetrics{{
+			Resource: &otlpresource.Resource{Attributes: otlpAttrs(
+				"service.name", "checkout",
+				"service.namespace", "shop",
+				"service.instance.id", "pod-1",
+				"host.name", "node-1",
+			)},
+			ScopeMetrics: []*otlpmetrics.ScopeMetrics{{Metrics: []*otlpmetrics.Metric{
+				{
+					Name: "requests", Unit: "{request}",
+					Data: &otlpmetrics.Metric_Sum{Sum: &otlpmetrics.Sum{
+						IsMonotonic:            true,
+						AggregationTemporality: otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+						DataPoints: []*otlpmetrics.NumberDataPoint{
+							{Attributes: otlpAttrs("http.method", "GET"), TimeUnixNano: ts, Value: &otlpmetrics.NumberDataPoint_AsInt{AsInt: 5}},
+							{Attributes: otlpAttrs("http.method", "POST"), TimeUnixNano: ts, Flags: 1},
+						},
+					}},
+				},
+				{
+					Name: "deltas",
+					Data: &otlpmetrics.Metric_Sum{Sum: &otlpmetrics.Sum{
+						IsMonotonic:            true,
+						AggregationTemporality: otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+						DataPoints:             []*otlpmetrics.NumberDataPoint{{TimeUnixNano: ts, Value: &otlpmetrics.NumberDataPoint_AsInt{AsInt: 1}}},
+					}},
+				},
+				{
+					Name: "latency", Unit: "s",
+					Data: &otlpmetrics.Metric_Histogram{Histogram: &otlpmetrics.Histogram{
+						AggregationTemporality: otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+						DataPoints: []*otlpmetrics.HistogramDataPoint{{
+							TimeUnixNano:   ts,
+							Count:          6,
+							Sum:            &sum,
+							ExplicitBounds: []float64{0.1, 1},
+							BucketCounts:   []uint64{1, 2, 3},
+						}},
+					}},
+				},
+				{
+					Name: "size", Unit: "By",
+					Data: &otlpmetrics.Metric_ExponentialHistogram{ExponentialHistogram: &otlpmetrics.ExponentialHistogram{
+						AggregationTemporality: otlpmetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+						DataPoints: []*otlpmetrics.ExponentialHistogramDataPoint{{
+							TimeUnixNano: ts,
+							Count:        7,
+							Sum:          &sum,
+							Scale:        0,
+							ZeroCount:    1,
+							Positive:     &otlpmetrics.ExponentialHistogramDataPoint_Buckets{Offset: 1, BucketCounts: []uint64{2, 3}},
+							Negative:     &otlpmetrics.ExponentialHistogramDataPoint_Buckets{Offset: 0, BucketCounts: []uint64{1}},
+						}},
+					}},
+				},
+				{
+					Name: "gc.pause", Unit: "ms",
+					Data: &otlpmetrics.Metric_Summary{Summary: &otlpmetrics.Summary{
+						DataPoints: []*otlpmetrics.SummaryDataPoint{{
+							TimeUnixNano:   ts,
+							Count:          3,
+							Sum:            sum,
+							QuantileValues: []*otlpmetrics.SummaryDataPoint_ValueAtQuantile{{Quantile: 0.99, Value: 7}},
+						}},
+					}},
+				},
+			}}},
+		}},
+	}
+
+	wr, rejected := otlpMetricsToPrometheus(req)
+	assert.Equal(t, int64(1), rejected)
+	for _, ts := range wr.Timeseries {
+		assert.Equal(t, int64(1700000000123), ts.Samples[0].Timestamp)
+		assert.True(t, sort.SliceIsSorted(ts.Labels, func(i, j int) bool { return ts.Labels[i].Name < ts.Labels[j].Name }))
+	}
+	const rl = "instance=pod-1,job=shop/checkout"
+	assert.Equal(t, []string{
+		"gc_pause_milliseconds_count{" + rl + "} 3",
+		"gc_pause_milliseconds_sum{" + rl + "} 10.5",
+		"gc_pause_milliseconds{" + rl + ",quantile=0.99} 7",
+		"latency_seconds_bucket{" + rl + ",le=+Inf} 6",
+		"latency_seconds_bucket{" + rl + ",le=0.1} 1",
+		"latency_seconds_bucket{" + rl + ",le=1} 3",
+		"latency_seconds_count{" + rl + "} 6",
+		"latency_seconds_sum{" + rl + "} 10.5",
+		"requests_total{http_method=GET," + rl + "} 5",
+		"requests_total{http_method=POST," + rl + "} stale",
+		"size_bytes_bucket{" + rl + ",le=+Inf} 7",
+		"size_bytes_bucket{" + rl + ",le=-1} 1",
+		"size_bytes_bucket{" + rl + ",le=0} 2",
+		"size_bytes_bucket{" + rl + ",le=4} 4",
+		"size_bytes_bucket{" + rl + ",le=8} 7",
+		"size_bytes_count{" + rl + "} 7",
+		"size_bytes_sum{" + rl + "} 10.5",
+		"target_info{host_name=node-1," + rl + "} 1",
+	}, seriesToStrings(wr))
+
+	types := map[string]prompb.MetricMetadata_MetricType{}
+	for _, md := range wr.Metadata {
+		types[md.MetricFamilyName] = md.Type
+	}
+	assert.Equal(t, map[string]prompb.MetricMetadata_MetricType{
+		"requests_total":        prompb.MetricMetadata_COUNTER,
+		"latency_seconds":       prompb.MetricMetadata_HISTOGRAM,
+		"size_bytes":            prompb.MetricMetadata_HISTOGRAM,
+		"gc_pause_milliseconds": prompb.MetricMetadata_SUMMARY,
+		"target_info":           prompb.MetricMetadata_GAUGE,
+	}, types)
+}
+
+func TestExponentialBuckets(t *testing.T) {
+	p := &otlpmetrics.ExponentialHistogramDataPoint{
+		Scale:    1,
+		Positive: &otlpmetrics.ExponentialHistogramDataPoint_Buckets{Offset: 0, BucketCounts: []uint64{1, 1}},
+	}
+	buckets := exponentialBuckets(p)
+	assert.Len(t, buckets, 3)
+	assert.Equal(t, bucket{le: 0, count: 0}, buckets[0])
+	assert.InDelta(t, math.Sqrt2, buckets[1].le, 1e-9)
+	assert.InDelta(t, 2, buckets[2].le, 1e-9)
+	assert.Equal(t, uint64(2), buckets[2].count)
+}
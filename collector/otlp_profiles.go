@@ -0,0 +1,218 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/google/pprof/profile"
+	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
+	v1 "go.opentelemetry.io/proto/otlp/collector/profiles/v1experimental"
+	profilesv1 "go.opentelemetry.io/proto/otlp/profiles/v1experimental"
+	"k8s.io/klog"
+)
+
+// OTLPProfiles accepts profiles using the OTLP/HTTP protocol. Profiles are a development signal of OpenTelemetry,
+// so the endpoint follows the v1experimental version of the protocol.
+func (c *Collector) OTLPProfiles(w http.ResponseWriter, r *http.Request) {
+	project, err := c.getProject(r.Header.Get(ApiKeyHeader), db.ApiKeyScopeIngestProfiles)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, err.Error(), projectErrorStatus(err))
+		return
+	}
+
+	req := &v1.ExportProfilesServiceRequest{}
+	contentType, err := readOTLPRequest(w, r, req)
+	if err != nil {
+		return
+	}
+	if err = c.checkQuota(project.Id, profilesIngestion(req)); err != nil {
+		writeQuotaError(w, contentType, err)
+		return
+	}
+
+	writeOTLPResponse(w, contentType, c.addOTLPProfiles(project, req))
+}
+
+type GRPCProfilesService struct {
+	collector *Collector
+	v1.UnimplementedProfilesServiceServer
+}
+
+func NewGRPCProfilesService(collector *Collector) *GRPCProfilesService {
+	return &GRPCProfilesService{
+		collector: collector,
+	}
+}
+
+func (s *GRPCProfilesService) Export(ctx context.Context, req *v1.ExportProfilesServiceRequest) (*v1.ExportProfilesServiceResponse, error) {
+	project, err := s.collector.getProjectFromGRPCMetadata(ctx, db.ApiKeyScopeIngestProfiles)
+	if err != nil {
+		klog.Errorln("failed to get project:", err)
+		return nil, err
+	}
+	if err = s.collector.checkQuota(project.Id, profilesIngestion(req)); err != nil {
+		return nil, quotaGRPCError(ctx, err)
+	}
+	return s.collector.addOTLPProfiles(project, req), nil
+}
+
+// addOTLPProfiles converts OTLP profiles to pprof and adds them to the project's batch.
+// Profiles without the service name or that can't be converted are rejected.
+func (c *Collector) addOTLPProfiles(project *db.Project, req *v1.ExportProfilesServiceRequest) *v1.ExportProfilesServiceResponse {
+	var rejected int64
+	var lastErr error
+	batch := c.getProfilesBatch(project)
+	for _, rp := range req.GetResourceProfiles() {
+		resourceAttributes := attributesToMap(rp.GetResource().GetAttributes())
+		serviceName := resourceAttributes[semconv.AttributeServiceName]
+		language := resourceAttributes[semconv.AttributeTelemetrySDKLanguage]
+		for _, sp := range rp.GetScopeProfiles() {
+			for _, pc := range sp.GetProfiles() {
+				if serviceName == "" {
+					rejected++
+					lastErr = fmt.Errorf("service.name is empty")
+					continue
+				}
+				p, err := otlpProfileToPprof(pc)
+				if err != nil {
+					rejected++
+					lastErr = err
+					continue
+				}
+				labels := model.Labels{}
+				for k, v := range resourceAttributes {
+					if k != semconv.AttributeServiceName {
+						labels[k] = v
+					}
+				}
+				for k, v := range attributesToMap(pc.GetAttributes()) {
+					labels[k] = v
+				}
+				normalizeProfileTypes(p, profileLanguage(language), "")
+				batch.Add(serviceName, labels, p)
+			}
+		}
+	}
+	resp := &v1.ExportProfilesServiceResponse{}
+	if rejected > 0 {
+		klog.Errorln(lastErr)
+		resp.PartialSuccess = &v1.ExportProfilesPartialSuccess{
+			RejectedProfiles: rejected,
+			ErrorMessage:     lastErr.Error(),
+		}
+	}
+	return resp
+}
+
+// otlpProfileToPprof converts an OTLP profile to pprof. The profiles originally collected in the pprof format
+// can be sent as is in the original payload.
+// ProfilesBatch.Add treats TimeNanos as the end of the profile, so it is set to the end time of the container if known.
+func otlpProfileToPprof(pc *profilesv1.ProfileContainer) (*profile.Profile, error) {
+	var p *profile.Profile
+	switch {
+	case pc.GetProfile() != nil:
+		var err error
+		if p, err = convertOTLPProfile(pc.GetProfile()); err != nil {
+			return nil, err
+		}
+	case pc.GetOriginalPayloadFormat() == "pprof" && len(pc.GetOriginalPayload()) > 0:
+		var err error
+		if p, err = profile.ParseData(pc.GetOriginalPayload()); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("empty profile")
+	}
+	if start, end := pc.GetStartTimeUnixNano(), pc.GetEndTimeUnixNano(); end > 0 && end >= start {
+		p.TimeNanos = int64(end)
+		p.DurationNanos = int64(end - start)
+	}
+	return p, nil
+}
+
+func convertOTLPProfile(op *profilesv1.Profile) (*profile.Profile, error) {
+	str := func(i int64) string {
+		if i < 0 || int(i) >= len(op.StringTable) {
+			return ""
+		}
+		return op.StringTable[i]
+	}
+	p := &profile.Profile{
+		TimeNanos:     op.TimeNanos + op.DurationNanos,
+		DurationNanos: op.DurationNanos,
+		Period:        op.Period,
+	}
+	for _, st := range op.SampleType {
+		p.SampleType = append(p.SampleType, &profile.ValueType{Type: str(st.Type), Unit: str(st.Unit)})
+	}
+	if op.PeriodType != nil {
+		p.PeriodType = &profile.ValueType{Type: str(op.PeriodType.Type), Unit: str(op.PeriodType.Unit)}
+	}
+	for i, f := range op.Function {
+		p.Function = append(p.Function, &profile.Function{
+			ID:         uint64(i + 1),
+			Name:       str(f.Name),
+			SystemName: str(f.SystemName),
+			Filename:   str(f.Filename),
+			StartLine:  f.StartLine,
+		})
+	}
+	for i, l := range op.Location {
+		loc := &profile.Location{ID: uint64(i + 1), Address: l.Address, IsFolded: l.IsFolded}
+		for _, line := range l.Line {
+			if line.FunctionIndex >= uint64(len(p.Function)) {
+				return nil, fmt.Errorf("invalid function index %d", line.FunctionIndex)
+			}
+			loc.Line = append(loc.Line, profile.Line{Function: p.Function[line.FunctionIndex], Line: line.Line})
+		}
+		p.Location = append(p.Location, loc)
+	}
+	location := func(i uint64) (*profile.Location, error) {
+		if i >= uint64(len(p.Location)) {
+			return nil, fmt.Errorf("invalid location index %d", i)
+		}
+		return p.Location[i], nil
+	}
+	for _, sample := range op.Sample {
+		if len(sample.Value) != len(p.SampleType) {
+			return nil, fmt.Errorf("sample has %d values, expected %d", len(sample.Value), len(p.SampleType))
+		}
+		s := &profile.Sample{Value: sample.Value}
+		if sample.LocationsLength > 0 {
+			// the bounds are checked without adding them, since the sum can overflow
+			start, length, n := sample.LocationsStartIndex, sample.LocationsLength, uint64(len(op.LocationIndices))
+			if start > n || length > n-start {
+				return nil, fmt.Errorf("invalid locations range")
+			}
+			for _, idx := range op.LocationIndices[start : start+length] {
+				l, err := location(uint64(idx))
+				if err != nil {
+					return nil, err
+				}
+				s.Location = append(s.Location, l)
+			}
+		} else {
+			for _, idx := range sample.LocationIndex {
+				l, err := location(idx)
+				if err != nil {
+					return nil, err
+				}
+				s.Location = append(s.Location, l)
+			}
+		}
+		for _, l := range sample.Label {
+			if l.Str != 0 {
+				if s.Label == nil {
+					s.Label = map[string][]string{}
+				}
+				s.Label[str(l.Key)] = append(s.Label[str(l.Key)], str(l.Str))
+			}
+		}
+		p.Sample = append(p.Sample, s)
+	}
+	return p, nil
+}
@@ -0,0 +1,67 @@
+package collector
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	profilesv1 "go.opentelemetry.io/proto/otlp/profiles/v1experimental"
+)
+
+func TestOTLPProfileToPprof(t *testing.T) {
+	op := &profilesv1.Profile{
+		StringTable: []string{"", "cpu", "nanoseconds", "main", "compute", "main.go", "thread", "worker"},
+		SampleType:  []*profilesv1.ValueType{{Type: 1, Unit: 2}},
+		PeriodType:  &profilesv1.ValueType{Type: 1, Unit: 2},
+		Period:      10000000,
+		TimeNanos:   1700000000000000000,
+		Function: []*profilesv1.Function{
+			{Name: 3, Filename: 5},
+			{Name: 4, Filename: 5},
+		},
+		Location: []*profilesv1.Location{
+			{Line: []*profilesv1.Line{{FunctionIndex: 0, Line: 10}}},
+			{Line: []*profilesv1.Line{{FunctionIndex: 1, Line: 20}}},
+		},
+		LocationIndices: []int64{1, 0},
+		Sample: []*profilesv1.Sample{
+			{LocationsStartIndex: 0, LocationsLength: 2, Value: []int64{20000000}, Label: []*profilesv1.Label{{Key: 6, Str: 7}}},
+			{LocationIndex: []uint64{0}, Value: []int64{10000000}},
+		},
+	}
+	pc := &profilesv1.ProfileContainer{StartTimeUnixNano: 1700000000000000000, EndTimeUnixNano: 1700000015000000000, Profile: op}
+	p, err := otlpProfileToPprof(pc)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1700000015000000000), p.TimeNanos, "TimeNanos is the end of the profile")
+	assert.Equal(t, int64(15000000000), p.DurationNanos)
+	assert.Equal(t, "cpu", p.SampleType[0].Type)
+	assert.Equal(t, int64(10000000), p.Period)
+	require.Len(t, p.Sample, 2)
+	require.Len(t, p.Sample[0].Location, 2)
+	assert.Equal(t, "compute", p.Sample[0].Location[0].Line[0].Function.Name)
+	assert.Equal(t, int64(20), p.Sample[0].Location[0].Line[0].Line)
+	assert.Equal(t, "main", p.Sample[0].Location[1].Line[0].Function.Name)
+	assert.Equal(t, map[string][]string{"thread": {"worker"}}, p.Sample[0].Label)
+	assert.Equal(t, "main", p.Sample[1].Location[0].Line[0].Function.Name)
+	require.NoError(t, p.CheckValid())
+
+	normalizeProfileTypes(p, profileLanguage("go"), "")
+	assert.Equal(t, "go:profile_cpu:nanoseconds", p.SampleType[0].Type)
+
+	op.LocationIndices = []int64{5}
+	op.Sample = op.Sample[:1]
+	op.Sample[0].LocationsLength = 1
+	_, err = otlpProfileToPprof(pc)
+	assert.Error(t, err)
+
+	// start+length overflows uint64
+	op.LocationIndices = []int64{0}
+	op.Sample[0].LocationsStartIndex = math.MaxUint64
+	op.Sample[0].LocationsLength = 2
+	_, err = otlpProfileToPprof(pc)
+	assert.Error(t, err)
+
+	_, err = otlpProfileToPprof(&profilesv1.ProfileContainer{})
+	assert.Error(t, err)
+}
@@ -4,21 +4,23 @@ import (
 	"fmt"
 	"hash/fnv"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ClickHouse/ch-go"
 	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
 	"github.com/google/pprof/profile"
 	"k8s.io/klog"
 )
 
 func (c *Collector) Profiles(w http.ResponseWriter, r *http.Request) {
-	project, err := c.getProject(r.Header.Get(ApiKeyHeader))
+	project, err := c.getProject(r.Header.Get(ApiKeyHeader), db.ApiKeyScopeIngestProfiles)
 	if err != nil {
 		klog.Errorln(err)
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), projectErrorStatus(err))
 		return
 	}
 
@@ -41,12 +43,18 @@ func (c *Collector) Profiles(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "service.name is empty", http.StatusBadRequest)
 		return
 	}
-	p, err := profile.Parse(r.Body)
+	body := &countingReader{r: r.Body}
+	p, err := profile.Parse(body)
 	if err != nil {
 		klog.Errorln(err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	in := ingestion{bytes: body.n, records: len(p.Sample), services: func() []string { return []string{serviceName} }}
+	if err = c.checkQuota(project.Id, in); err != nil {
+		writeQuotaError(w, "", err)
+		return
+	}
 
 	c.getProfilesBatch(project).Add(serviceName, labels, p)
 }
@@ -195,3 +203,89 @@ func StackHash(s []string) uint64 {
 	}
 	return h.Sum64()
 }
+
+var goProfileTypes = map[string]string{
+	"cpu":           "profile_cpu",
+	"alloc_objects": "heap_alloc_objects",
+	"alloc_space":   "heap_alloc_space",
+	"inuse_objects": "heap_inuse_objects",
+	"inuse_space":   "heap_inuse_space",
+	"goroutine":     "goroutine_goroutine",
+	"goroutines":    "goroutine_goroutine",
+}
+
+// normalizeProfileTypes renames the pprof sample types to the <language>:<type>:<unit> form the profiling views use
+// (e.g., cpu/nanoseconds of a Go profile becomes go:profile_cpu:nanoseconds). CPU profiles containing only the number of samples
+// are converted to nanoseconds using the sampling period. hint is the profile kind reported by the client
+// (e.g., block or mutex), since Go block and mutex profiles have the same sample types.
+// The sample types already in the normalized form are kept as is.
+func normalizeProfileTypes(p *profile.Profile, language, hint string) {
+	if language == "" {
+		language = "unknown"
+	}
+	hasCPU := false
+	for _, st := range p.SampleType {
+		if st.Type == "cpu" {
+			hasCPU = true
+		}
+	}
+	for i, st := range p.SampleType {
+		if strings.Count(st.Type, ":") == 2 {
+			continue
+		}
+		typ, unit := st.Type, st.Unit
+		if typ == "samples" && unit == "count" && p.PeriodType != nil && p.PeriodType.Type == "cpu" && p.PeriodType.Unit == "nanoseconds" {
+			if hasCPU || p.Period <= 0 {
+				st.Type = ""
+				continue
+			}
+			for _, s := range p.Sample {
+				s.Value[i] *= p.Period
+			}
+			typ, unit = "cpu", "nanoseconds"
+		}
+		if language == "go" {
+			switch typ {
+			case "contentions", "delay":
+				kind := "mutex"
+				if strings.Contains(hint, "block") {
+					kind = "block"
+				}
+				typ = kind + "_" + typ
+			default:
+				if t, ok := goProfileTypes[typ]; ok {
+					typ = t
+				}
+			}
+		}
+		if unit == "" {
+			unit = "count"
+		}
+		st.Type = fmt.Sprintf("%s:%s:%s", language, typ, unit)
+		st.Unit = unit
+	}
+}
+
+// profileLanguage maps the language reported by an OpenTelemetry SDK or a Pyroscope client to the prefix of the profile types.
+func profileLanguage(language string) string {
+	language = strings.ToLower(language)
+	switch language {
+	case "gospy", "golang":
+		return "go"
+	case "javaspy", "jfr":
+		return "java"
+	case "pyspy":
+		return "python"
+	case "rbspy":
+		return "ruby"
+	case "phpspy":
+		return "php"
+	case "dotnetspy":
+		return "dotnet"
+	case "nodespy", "node":
+		return "nodejs"
+	case "ebpfspy":
+		return "ebpf"
+	}
+	return strings.TrimSuffix(language, "spy")
+}
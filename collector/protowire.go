@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protoField is a field of a protobuf message decoded without the generated code:
+// bytes holds length-delimited values, and uint holds varint and fixed-size values.
+type protoField struct {
+	num   protowire.Number
+	typ   protowire.Type
+	bytes []byte
+	uint  uint64
+}
+
+// walkProto calls f for each field of the protobuf message. Groups are skipped.
+func walkProto(data []byte, f func(field protoField) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		field := protoField{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			field.uint, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(data)
+			field.uint = uint64(v)
+		case protowire.Fixed64Type:
+			field.uint, n = protowire.ConsumeFixed64(data)
+		case protowire.BytesType:
+			field.bytes, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if typ == protowire.StartGroupType {
+			continue
+		}
+		if err := f(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/google/pprof/profile"
+	"k8s.io/klog"
+)
+
+const (
+	pyroscopeDefaultSampleRate = 100
+	pyroscopeMaxFormBytes      = 32 << 20
+)
+
+// pyroscopeProfileKinds are the suffixes the Pyroscope clients append to the application name to specify the profile kind.
+var pyroscopeProfileKinds = map[string]bool{
+	"cpu": true, "itimer": true, "wall": true,
+	"alloc_objects": true, "alloc_space": true, "inuse_objects": true, "inuse_space": true,
+	"goroutines": true, "mutex_count": true, "mutex_duration": true, "block_count": true, "block_duration": true,
+}
+
+// PyroscopeIngest accepts profiles sent by the Pyroscope SDKs and agents (the /ingest endpoint) in the pprof and folded formats.
+func (c *Collector) PyroscopeIngest(w http.ResponseWriter, r *http.Request) {
+	project, err := c.getProject(requestApiKey(r), db.ApiKeyScopeIngestProfiles)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, err.Error(), projectErrorStatus(err))
+		return
+	}
+
+	body := &countingReader{r: r.Body}
+	r.Body = io.NopCloser(body)
+	serviceName, labels, p, err := readPyroscopeRequest(r)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	in := ingestion{bytes: body.n, records: len(p.Sample), services: func() []string { return []string{serviceName} }}
+	if err = c.checkQuota(project.Id, in); err != nil {
+		writeQuotaError(w, "", err)
+		return
+	}
+
+	c.getProfilesBatch(project).Add(serviceName, labels, p)
+}
+
+func readPyroscopeRequest(r *http.Request) (string, model.Labels, *profile.Profile, error) {
+	query := r.URL.Query()
+	serviceName, labels, kind, err := parsePyroscopeName(query.Get("name"))
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	var p *profile.Profile
+	contentType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch format := query.Get("format"); {
+	case contentType == "multipart/form-data":
+		data, err := readMultipartFile(r.Body, params["boundary"], "profile")
+		if err != nil {
+			return "", nil, nil, err
+		}
+		if p, err = profile.ParseData(data); err != nil {
+			return "", nil, nil, err
+		}
+	case format == "pprof":
+		if p, err = profile.Parse(r.Body); err != nil {
+			return "", nil, nil, err
+		}
+	case format == "" || format == "folded" || format == "lines":
+		sampleRate, _ := strconv.ParseInt(query.Get("sampleRate"), 10, 64)
+		if sampleRate <= 0 {
+			sampleRate = pyroscopeDefaultSampleRate
+		}
+		if p, err = parseFoldedProfile(r.Body, format == "lines", kind, query.Get("units"), sampleRate); err != nil {
+			return "", nil, nil, err
+		}
+	default:
+		return "", nil, nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	from, _ := strconv.ParseInt(query.Get("from"), 10, 64)
+	until, _ := strconv.ParseInt(query.Get("until"), 10, 64)
+	if until > 0 && until >= from {
+		p.TimeNanos = time.Unix(until, 0).UnixNano()
+		p.DurationNanos = (until - from) * int64(time.Second)
+	} else if p.TimeNanos == 0 {
+		p.TimeNanos = time.Now().UnixNano()
+	}
+	normalizeProfileTypes(p, profileLanguage(query.Get("spyName")), kind)
+	return serviceName, labels, p, nil
+}
+
+// parsePyroscopeName parses the application name with the optional profile kind suffix and labels,
+// e.g., checkout.cpu{env=production,region=us-east-1}.
+func parsePyroscopeName(name string) (string, model.Labels, string, error) {
+	labels := model.Labels{}
+	if i := strings.IndexByte(name, '{'); i >= 0 {
+		if !strings.HasSuffix(name, "}") {
+			return "", nil, "", fmt.Errorf("invalid name: %s", name)
+		}
+		for _, kv := range strings.Split(name[i+1:len(name)-1], ",") {
+			kv = strings.TrimSpace(kv)
+			if kv == "" {
+				continue
+			}
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return "", nil, "", fmt.Errorf("invalid label: %s", kv)
+			}
+			k = strings.TrimSpace(k)
+			if k == "__name__" || k == "service_name" {
+				continue
+			}
+			labels[k] = strings.TrimSpace(v)
+		}
+		name = name[:i]
+	}
+	var kind string
+	if i := strings.LastIndexByte(name, '.'); i > 0 && pyroscopeProfileKinds[name[i+1:]] {
+		name, kind = name[:i], name[i+1:]
+	}
+	if name == "" {
+		return "", nil, "", fmt.Errorf("name is empty")
+	}
+	return name, labels, kind, nil
+}
+
+func readMultipartFile(body io.Reader, boundary, name string) ([]byte, error) {
+	mr := multipart.NewReader(body, boundary)
+	form, err := mr.ReadForm(pyroscopeMaxFormBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer form.RemoveAll()
+	files := form.File[name]
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no %s in the form", name)
+	}
+	f, err := files[0].Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// parseFoldedProfile parses profiles in the collapsed stack format ("main;handler;compute 42" per line, root first).
+// In the lines format, each line is a single sample without a value.
+// The number of samples of CPU profiles is converted to nanoseconds using the sample rate.
+func parseFoldedProfile(r io.Reader, lines bool, kind, units string, sampleRate int64) (*profile.Profile, error) {
+	st := &profile.ValueType{Type: "cpu", Unit: "nanoseconds"}
+	scale := int64(time.Second) / sampleRate
+	switch units {
+	case "objects":
+		st.Type, st.Unit, scale = "alloc_objects", "count", 1
+	case "bytes":
+		st.Type, st.Unit, scale = "alloc_space", "bytes", 1
+	case "goroutines":
+		st.Type, st.Unit, scale = "goroutine", "count", 1
+	case "lock_samples":
+		st.Type, st.Unit, scale = "contentions", "count", 1
+	case "lock_nanoseconds":
+		st.Type, st.Unit, scale = "delay", "nanoseconds", 1
+	}
+	switch kind {
+	case "alloc_objects", "inuse_objects", "alloc_space", "inuse_space":
+		st.Type = kind
+	}
+
+	p := &profile.Profile{SampleType: []*profile.ValueType{st}}
+	functions := map[string]*profile.Location{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		stack, value := string(line), int64(1)
+		if !lines {
+			i := bytes.LastIndexByte(line, ' ')
+			if i < 0 {
+				return nil, fmt.Errorf("invalid line: %s", line)
+			}
+			v, err := strconv.ParseInt(string(line[i+1:]), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value in line: %s", line)
+			}
+			stack, value = string(line[:i]), v
+		}
+		frames := strings.Split(stack, ";")
+		s := &profile.Sample{Value: []int64{value * scale}}
+		for i := len(frames) - 1; i >= 0; i-- {
+			loc := functions[frames[i]]
+			if loc == nil {
+				f := &profile.Function{ID: uint64(len(p.Function) + 1), Name: frames[i]}
+				loc = &profile.Location{ID: uint64(len(p.Location) + 1), Line: []profile.Line{{Function: f}}}
+				p.Function = append(p.Function, f)
+				p.Location = append(p.Location, loc)
+				functions[frames[i]] = loc
+			}
+			s.Location = append(s.Location, loc)
+		}
+		p.Sample = append(p.Sample, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coroot/coroot/model"
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePyroscopeName(t *testing.T) {
+	name, labels, kind, err := parsePyroscopeName("checkout.alloc_space{env=production, region=us-east-1,service_name=x}")
+	require.NoError(t, err)
+	assert.Equal(t, "checkout", name)
+	assert.Equal(t, "alloc_space", kind)
+	assert.Equal(t, model.Labels{"env": "production", "region": "us-east-1"}, labels)
+
+	name, labels, kind, err = parsePyroscopeName("my.app")
+	require.NoError(t, err)
+	assert.Equal(t, "my.app", name, "unknown suffixes are part of the name")
+	assert.Equal(t, "", kind)
+	assert.Empty(t, labels)
+
+	_, _, _, err = parsePyroscopeName("")
+	assert.Error(t, err)
+	_, _, _, err = parsePyroscopeName("app{env=production")
+	assert.Error(t, err)
+	_, _, _, err = parsePyroscopeName("app{env}")
+	assert.Error(t, err)
+}
+
+func TestPyroscopeFolded(t *testing.T) {
+	body := "main;handler;compute 3\nmain;handler 1\n\n"
+	r := httptest.NewRequest("POST", "/ingest?name=checkout.cpu{env=dev}&from=1700000000&until=1700000010&spyName=gospy&sampleRate=100", strings.NewReader(body))
+	serviceName, labels, p, err := readPyroscopeRequest(r)
+	require.NoError(t, err)
+	assert.Equal(t, "checkout", serviceName)
+	assert.Equal(t, model.Labels{"env": "dev"}, labels)
+	assert.Equal(t, int64(1700000010000000000), p.TimeNanos)
+	assert.Equal(t, int64(10000000000), p.DurationNanos)
+	require.Len(t, p.SampleType, 1)
+	assert.Equal(t, "go:profile_cpu:nanoseconds", p.SampleType[0].Type)
+
+	require.Len(t, p.Sample, 2)
+	assert.Equal(t, []int64{30000000}, p.Sample[0].Value, "3 samples at 100Hz")
+	var frames []string
+	for _, l := range p.Sample[0].Location {
+		frames = append(frames, l.Line[0].Function.Name)
+	}
+	assert.Equal(t, []string{"compute", "handler", "main"}, frames, "the leaf goes first")
+	assert.Len(t, p.Function, 3, "functions are deduplicated")
+	assert.Same(t, p.Sample[0].Location[1], p.Sample[1].Location[0])
+
+	r = httptest.NewRequest("POST", "/ingest?name=checkout&units=bytes&spyName=pyspy", strings.NewReader("main;alloc 1024"))
+	_, _, p, err = readPyroscopeRequest(r)
+	require.NoError(t, err)
+	assert.Equal(t, "python:alloc_space:bytes", p.SampleType[0].Type)
+	assert.Equal(t, []int64{1024}, p.Sample[0].Value)
+
+	r = httptest.NewRequest("POST", "/ingest?name=checkout", strings.NewReader("main;handler"))
+	_, _, _, err = readPyroscopeRequest(r)
+	assert.Error(t, err)
+
+	r = httptest.NewRequest("POST", "/ingest?name=checkout&format=jfr", strings.NewReader(""))
+	_, _, _, err = readPyroscopeRequest(r)
+	assert.Error(t, err)
+}
+
+func TestPyroscopePprofMultipart(t *testing.T) {
+	f := &profile.Function{ID: 1, Name: "main"}
+	src := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "contentions", Unit: "count"}, {Type: "delay", Unit: "nanoseconds"}},
+		Function:   []*profile.Function{f},
+		Location:   []*profile.Location{{ID: 1, Line: []profile.Line{{Function: f}}}},
+	}
+	src.Sample = []*profile.Sample{{Value: []int64{2, 500}, Location: src.Location}}
+	pprof := &bytes.Buffer{}
+	require.NoError(t, src.Write(pprof))
+
+	form := &bytes.Buffer{}
+	mw := multipart.NewWriter(form)
+	fw, err := mw.CreateFormFile("profile", "profile.pprof")
+	require.NoError(t, err)
+	_, err = fw.Write(pprof.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	r := httptest.NewRequest("POST", "/ingest?name=checkout.block_count&from=1700000000&until=1700000015&spyName=gospy", form)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	_, _, p, err := readPyroscopeRequest(r)
+	require.NoError(t, err)
+	assert.Equal(t, "go:block_contentions:count", p.SampleType[0].Type)
+	assert.Equal(t, "go:block_delay:nanoseconds", p.SampleType[1].Type)
+	assert.Equal(t, int64(15000000000), p.DurationNanos)
+	assert.Equal(t, []int64{2, 500}, p.Sample[0].Value)
+}
+
+func TestNormalizeProfileTypes(t *testing.T) {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}},
+		PeriodType: &profile.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:     10000000,
+		Sample:     []*profile.Sample{{Value: []int64{3}}},
+	}
+	normalizeProfileTypes(p, "java", "")
+	assert.Equal(t, "java:cpu:nanoseconds", p.SampleType[0].Type)
+	assert.Equal(t, []int64{30000000}, p.Sample[0].Value)
+
+	p = &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}},
+		PeriodType: &profile.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:     10000000,
+		Sample:     []*profile.Sample{{Value: []int64{3, 30000000}}},
+	}
+	normalizeProfileTypes(p, "go", "")
+	assert.Equal(t, "", p.SampleType[0].Type, "samples are skipped if cpu is present")
+	assert.Equal(t, "go:profile_cpu:nanoseconds", p.SampleType[1].Type)
+
+	p = &profile.Profile{SampleType: []*profile.ValueType{{Type: "ebpf:cpu:nanoseconds", Unit: "nanoseconds"}, {Type: "wall"}}}
+	normalizeProfileTypes(p, "", "")
+	assert.Equal(t, "ebpf:cpu:nanoseconds", p.SampleType[0].Type)
+	assert.Equal(t, "unknown:wall:count", p.SampleType[1].Type)
+
+	assert.Equal(t, "go", profileLanguage("gospy"))
+	assert.Equal(t, "nodejs", profileLanguage("nodespy"))
+	assert.Equal(t, "python", profileLanguage("python"))
+	assert.Equal(t, "erlang", profileLanguage("erlangspy"))
+}
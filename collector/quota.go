@@ -0,0 +1,469 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coroot/coroot/db"
+	promModel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
+	logsv1 "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	profilesv1 "go.opentelemetry.io/proto/otlp/collector/profiles/v1experimental"
+	tracesv1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	otlpcommon "go.opentelemetry.io/proto/otlp/common/v1"
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"k8s.io/klog"
+)
+
+const (
+	// quotaBurst allows short spikes of up to the given number of seconds' worth of the rate limits.
+	quotaBurst = 10
+
+	// quotaDistinctWindow is how long a series or a service counts towards the limit after it was last seen.
+	quotaDistinctWindow      = time.Hour
+	quotaDistinctPruneEvery  = time.Minute
+	quotaDistinctRetryAfter  = time.Minute
+	quotaMaxRetryAfter       = time.Minute
+	quotaUsageRateWindowSize = time.Minute
+)
+
+var ErrQuotaExceeded = errors.New("ingestion quota exceeded")
+
+// QuotaError is returned when a request exceeds the project's ingestion limits.
+// RetryAfter tells the client when the request is likely to be accepted.
+type QuotaError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrQuotaExceeded, e.Reason)
+}
+
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// retryAfterSeconds rounds the delay up, since clients can't retry sooner than in a second.
+func (e *QuotaError) retryAfterSeconds() string {
+	return strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// writeQuotaError responds with 429 Too Many Requests and the Retry-After header.
+// The error is encoded as an OTLP status if the content type of an OTLP request is given.
+func writeQuotaError(w http.ResponseWriter, contentType string, err error) {
+	var qe *QuotaError
+	if errors.As(err, &qe) {
+		w.Header().Set("Retry-After", qe.retryAfterSeconds())
+	}
+	if contentType == "" {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	writeOTLPError(w, contentType, http.StatusTooManyRequests, err)
+}
+
+// quotaGRPCError returns ResourceExhausted with the retry delay in both the RetryInfo details, as OTLP exporters expect,
+// and the Retry-After header.
+func quotaGRPCError(ctx context.Context, err error) error {
+	var qe *QuotaError
+	if !errors.As(err, &qe) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	if err := grpc.SetHeader(ctx, metadata.Pairs("retry-after", qe.retryAfterSeconds())); err != nil {
+		klog.Warningln(err)
+	}
+	st, err := status.New(codes.ResourceExhausted, qe.Error()).WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(qe.RetryAfter)})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, qe.Error())
+	}
+	return st.Err()
+}
+
+// IngestionUsage shows how close a project is to its ingestion limits.
+type IngestionUsage struct {
+	Limits           db.IngestionLimits `json:"limits"`
+	BytesPerSecond   float64            `json:"bytes_per_second"`
+	RecordsPerSecond float64            `json:"records_per_second"`
+	Series           int                `json:"series"`
+	Services         int                `json:"services"`
+	RejectedRequests int64              `json:"rejected_requests"`
+}
+
+// ingestion describes a request to be checked against the quota.
+// Series and services are only computed if the corresponding limits are configured.
+type ingestion struct {
+	bytes    int
+	records  int
+	series   func() []uint64
+	services func() []string
+}
+
+func metricsIngestion(req *prompb.WriteRequest, bytes int) ingestion {
+	in := ingestion{
+		bytes: bytes,
+		series: func() []uint64 {
+			res := make([]uint64, 0, len(req.Timeseries))
+			for _, ts := range req.Timeseries {
+				labels := make(map[string]string, len(ts.Labels))
+				for _, l := range ts.Labels {
+					labels[l.Name] = l.Value
+				}
+				res = append(res, promModel.LabelsToSignature(labels))
+			}
+			return res
+		},
+	}
+	for _, ts := range req.Timeseries {
+		in.records += len(ts.Samples) + len(ts.Histograms)
+	}
+	return in
+}
+
+func tracesIngestion(req *tracesv1.ExportTraceServiceRequest) ingestion {
+	in := ingestion{bytes: proto.Size(req)}
+	var resources [][]*otlpcommon.KeyValue
+	for _, rs := range req.GetResourceSpans() {
+		resources = append(resources, rs.GetResource().GetAttributes())
+		for _, ss := range rs.GetScopeSpans() {
+			in.records += len(ss.GetSpans())
+		}
+	}
+	in.services = func() []string {
+		return serviceNames(resources)
+	}
+	return in
+}
+
+func logsIngestion(req *logsv1.ExportLogsServiceRequest) ingestion {
+	in := ingestion{bytes: proto.Size(req)}
+	var resources [][]*otlpcommon.KeyValue
+	for _, rl := range req.GetResourceLogs() {
+		resources = append(resources, rl.GetResource().GetAttributes())
+		for _, sl := range rl.GetScopeLogs() {
+			in.records += len(sl.GetLogRecords())
+		}
+	}
+	in.services = func() []string {
+		return serviceNames(resources)
+	}
+	return in
+}
+
+func profilesIngestion(req *profilesv1.ExportProfilesServiceRequest) ingestion {
+	in := ingestion{bytes: proto.Size(req)}
+	var resources [][]*otlpcommon.KeyValue
+	for _, rp := range req.GetResourceProfiles() {
+		resources = append(resources, rp.GetResource().GetAttributes())
+		for _, sp := range rp.GetScopeProfiles() {
+			for _, pc := range sp.GetProfiles() {
+				in.records += len(pc.GetProfile().GetSample())
+			}
+		}
+	}
+	in.services = func() []string {
+		return serviceNames(resources)
+	}
+	return in
+}
+
+func serviceNames(resources [][]*otlpcommon.KeyValue) []string {
+	var res []string
+	for _, attrs := range resources {
+		for _, kv := range attrs {
+			if kv.Key == semconv.AttributeServiceName {
+				res = append(res, valueToString(kv.Value))
+			}
+		}
+	}
+	return res
+}
+
+// countingReader counts the bytes of the requests that are parsed from a stream.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += n
+	return n, err
+}
+
+type IngestionQuota struct {
+	lock     sync.Mutex
+	limits   db.IngestionLimits
+	bytes    *rate.Limiter
+	records  *rate.Limiter
+	series   *distinctTracker[uint64]
+	services *distinctTracker[string]
+
+	bytesRate   rateCounter
+	recordsRate rateCounter
+	rejected    int64
+}
+
+func NewIngestionQuota(limits db.IngestionLimits) *IngestionQuota {
+	q := &IngestionQuota{
+		series:   newDistinctTracker[uint64](),
+		services: newDistinctTracker[string](),
+	}
+	q.setLimits(limits)
+	return q
+}
+
+func (q *IngestionQuota) Update(limits db.IngestionLimits) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if limits != q.limits {
+		q.setLimits(limits)
+	}
+}
+
+func (q *IngestionQuota) setLimits(limits db.IngestionLimits) {
+	q.limits = limits
+	q.bytes = newLimiter(limits.BytesPerSecond)
+	q.records = newLimiter(limits.RecordsPerSecond)
+}
+
+func newLimiter(perSecond int64) *rate.Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(perSecond), int(perSecond)*quotaBurst)
+}
+
+// Allow checks the request against the limits and accounts for it if it's accepted.
+// A nil quota allows everything.
+func (q *IngestionQuota) Allow(now time.Time, in ingestion) error {
+	if q == nil {
+		return nil
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	err := q.allow(now, in)
+	if err != nil {
+		q.rejected++
+		return err
+	}
+	q.bytesRate.add(now, float64(in.bytes))
+	q.recordsRate.add(now, float64(in.records))
+	return nil
+}
+
+func (q *IngestionQuota) allow(now time.Time, in ingestion) error {
+	var series []uint64
+	if q.limits.MaxSeries > 0 && in.series != nil {
+		series = in.series()
+		if !q.series.fits(now, series, q.limits.MaxSeries) {
+			return &QuotaError{Reason: fmt.Sprintf("the limit of %d series is reached", q.limits.MaxSeries), RetryAfter: quotaDistinctRetryAfter}
+		}
+	}
+	var services []string
+	if q.limits.MaxServices > 0 && in.services != nil {
+		services = in.services()
+		if !q.services.fits(now, services, q.limits.MaxServices) {
+			return &QuotaError{Reason: fmt.Sprintf("the limit of %d services is reached", q.limits.MaxServices), RetryAfter: quotaDistinctRetryAfter}
+		}
+	}
+
+	bytes, err := reserve(now, q.bytes, in.bytes, "bytes")
+	if err != nil {
+		return err
+	}
+	if _, err = reserve(now, q.records, in.records, "records"); err != nil {
+		if bytes != nil {
+			bytes.CancelAt(now)
+		}
+		return err
+	}
+
+	q.series.add(now, series)
+	q.services.add(now, services)
+	return nil
+}
+
+func reserve(now time.Time, l *rate.Limiter, n int, what string) (*rate.Reservation, error) {
+	if l == nil || n == 0 {
+		return nil, nil
+	}
+	r := l.ReserveN(now, n)
+	if !r.OK() {
+		return nil, &QuotaError{
+			Reason:     fmt.Sprintf("the request of %d %s exceeds the burst of %d %s", n, what, l.Burst(), what),
+			RetryAfter: quotaMaxRetryAfter,
+		}
+	}
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return nil, &QuotaError{
+			Reason:     fmt.Sprintf("the limit of %d %s per second is exceeded", int64(l.Limit()), what),
+			RetryAfter: min(delay, quotaMaxRetryAfter),
+		}
+	}
+	return r, nil
+}
+
+func (q *IngestionQuota) Usage(now time.Time) *IngestionUsage {
+	if q == nil {
+		return nil
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return &IngestionUsage{
+		Limits:           q.limits,
+		BytesPerSecond:   q.bytesRate.rate(now),
+		RecordsPerSecond: q.recordsRate.rate(now),
+		Series:           q.series.count(now),
+		Services:         q.services.count(now),
+		RejectedRequests: q.rejected,
+	}
+}
+
+// distinctTracker counts the distinct series or services seen within quotaDistinctWindow.
+type distinctTracker[K comparable] struct {
+	lastSeen   map[K]time.Time
+	prunedAt   time.Time
+	windowSize time.Duration
+}
+
+func newDistinctTracker[K comparable]() *distinctTracker[K] {
+	return &distinctTracker[K]{lastSeen: map[K]time.Time{}, windowSize: quotaDistinctWindow}
+}
+
+func (t *distinctTracker[K]) prune(now time.Time) {
+	if now.Sub(t.prunedAt) < quotaDistinctPruneEvery {
+		return
+	}
+	t.prunedAt = now
+	for k, ts := range t.lastSeen {
+		if now.Sub(ts) > t.windowSize {
+			delete(t.lastSeen, k)
+		}
+	}
+}
+
+func (t *distinctTracker[K]) fits(now time.Time, keys []K, limit int) bool {
+	t.prune(now)
+	n := len(t.lastSeen)
+	seen := map[K]bool{}
+	for _, k := range keys {
+		if _, ok := t.lastSeen[k]; ok || seen[k] {
+			continue
+		}
+		seen[k] = true
+		n++
+		if n > limit {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *distinctTracker[K]) add(now time.Time, keys []K) {
+	for _, k := range keys {
+		t.lastSeen[k] = now
+	}
+}
+
+func (t *distinctTracker[K]) count(now time.Time) int {
+	t.prune(now)
+	return len(t.lastSeen)
+}
+
+// rateCounter reports the per-second rate over the last complete window, or over the current one if there is none yet.
+type rateCounter struct {
+	start    time.Time
+	current  float64
+	previous float64
+	complete bool
+}
+
+func (c *rateCounter) add(now time.Time, v float64) {
+	c.roll(now)
+	c.current += v
+}
+
+func (c *rateCounter) roll(now time.Time) {
+	if c.start.IsZero() {
+		c.start = now
+		return
+	}
+	switch elapsed := now.Sub(c.start); {
+	case elapsed >= 2*quotaUsageRateWindowSize:
+		c.previous, c.current = 0, 0
+		c.start = now
+		c.complete = true
+	case elapsed >= quotaUsageRateWindowSize:
+		c.previous, c.current = c.current, 0
+		c.start = c.start.Add(quotaUsageRateWindowSize)
+		c.complete = true
+	}
+}
+
+func (c *rateCounter) rate(now time.Time) float64 {
+	c.roll(now)
+	if c.complete {
+		return c.previous / quotaUsageRateWindowSize.Seconds()
+	}
+	if elapsed := now.Sub(c.start).Seconds(); elapsed >= 1 {
+		return c.current / elapsed
+	}
+	return 0
+}
+
+func (c *Collector) getQuota(projectId db.ProjectId) *IngestionQuota {
+	c.quotasLock.Lock()
+	defer c.quotasLock.Unlock()
+	return c.quotas[projectId]
+}
+
+// checkQuota returns a QuotaError if the request exceeds the project's ingestion limits.
+func (c *Collector) checkQuota(projectId db.ProjectId, in ingestion) error {
+	err := c.getQuota(projectId).Allow(time.Now(), in)
+	if err != nil {
+		klog.Warningf("project %s: %s", projectId, err)
+	}
+	return err
+}
+
+// GetIngestionUsage returns nil if the project has no ingestion limits.
+func (c *Collector) GetIngestionUsage(projectId db.ProjectId) *IngestionUsage {
+	return c.getQuota(projectId).Usage(time.Now())
+}
+
+// updateQuotas keeps the usage of the projects whose limits haven't changed.
+func (c *Collector) updateQuotas(projects []*db.Project) {
+	c.quotasLock.Lock()
+	defer c.quotasLock.Unlock()
+	quotas := map[db.ProjectId]*IngestionQuota{}
+	for _, p := range projects {
+		limits := p.Settings.IngestionLimits
+		if limits.IsEmpty() {
+			continue
+		}
+		if q := c.quotas[p.Id]; q != nil {
+			q.Update(*limits)
+			quotas[p.Id] = q
+		} else {
+			quotas[p.Id] = NewIngestionQuota(*limits)
+		}
+	}
+	c.quotas = quotas
+}
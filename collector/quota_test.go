@@ -0,0 +1,112 @@
+package collector
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coroot/coroot/db"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIngestionQuotaRates(t *testing.T) {
+	now := time.Now()
+	q := NewIngestionQuota(db.IngestionLimits{BytesPerSecond: 100, RecordsPerSecond: 10})
+
+	require.NoError(t, q.Allow(now, ingestion{bytes: 1000, records: 10}), "the burst allows spikes")
+	err := q.Allow(now, ingestion{bytes: 1, records: 1})
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+	var qe *QuotaError
+	require.True(t, errors.As(err, &qe))
+	assert.Equal(t, 10*time.Millisecond, qe.RetryAfter)
+	assert.Equal(t, "1", qe.retryAfterSeconds())
+
+	later := now.Add(time.Second)
+	require.NoError(t, q.Allow(later, ingestion{bytes: 100, records: 10}))
+	assert.ErrorIs(t, q.Allow(later, ingestion{bytes: 1, records: 1}), ErrQuotaExceeded)
+
+	// the rejected request doesn't consume the bytes if it exceeds the records limit
+	q = NewIngestionQuota(db.IngestionLimits{BytesPerSecond: 100, RecordsPerSecond: 1})
+	assert.ErrorIs(t, q.Allow(now, ingestion{bytes: 500, records: 100}), ErrQuotaExceeded)
+	assert.NoError(t, q.Allow(now, ingestion{bytes: 1000, records: 1}))
+
+	usage := q.Usage(now)
+	assert.Equal(t, int64(1), usage.RejectedRequests)
+	assert.Equal(t, int64(100), usage.Limits.BytesPerSecond)
+
+	var nilQuota *IngestionQuota
+	assert.NoError(t, nilQuota.Allow(now, ingestion{bytes: 1 << 30}))
+	assert.Nil(t, nilQuota.Usage(now))
+}
+
+func TestIngestionQuotaDistinct(t *testing.T) {
+	now := time.Now()
+	q := NewIngestionQuota(db.IngestionLimits{MaxSeries: 2, MaxServices: 1})
+
+	series := func(names ...string) ingestion {
+		wr := &prompb.WriteRequest{}
+		for _, n := range names {
+			wr.Timeseries = append(wr.Timeseries, prompb.TimeSeries{
+				Labels:  []prompb.Label{{Name: "__name__", Value: n}},
+				Samples: []prompb.Sample{{Value: 1}},
+			})
+		}
+		return metricsIngestion(wr, wr.Size())
+	}
+	services := func(names ...string) ingestion {
+		return ingestion{records: 1, services: func() []string { return names }}
+	}
+
+	require.NoError(t, q.Allow(now, series("a", "b")))
+	require.NoError(t, q.Allow(now, series("a", "b", "a")), "known series are always accepted")
+	assert.ErrorIs(t, q.Allow(now, series("a", "c")), ErrQuotaExceeded)
+	require.NoError(t, q.Allow(now, services("frontend")))
+	assert.ErrorIs(t, q.Allow(now, services("backend")), ErrQuotaExceeded)
+
+	usage := q.Usage(now)
+	assert.Equal(t, 2, usage.Series)
+	assert.Equal(t, 1, usage.Services)
+
+	// series and services stop counting towards the limits after they disappear
+	later := now.Add(quotaDistinctWindow + time.Minute)
+	assert.NoError(t, q.Allow(later, series("c", "d")))
+	assert.NoError(t, q.Allow(later, services("backend")))
+}
+
+func TestRateCounter(t *testing.T) {
+	now := time.Now()
+	c := rateCounter{}
+	c.add(now, 0)
+	c.add(now.Add(10*time.Second), 100)
+	assert.Equal(t, float64(10), c.rate(now.Add(10*time.Second)))
+	c.add(now.Add(30*time.Second), 500)
+	assert.Equal(t, float64(10), c.rate(now.Add(time.Minute)), "the rate over the last complete minute")
+	assert.Equal(t, float64(0), c.rate(now.Add(5*time.Minute)))
+}
+
+func TestQuotaErrors(t *testing.T) {
+	err := &QuotaError{Reason: "test", RetryAfter: 1500 * time.Millisecond}
+
+	w := httptest.NewRecorder()
+	writeQuotaError(w, "", err)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	w = httptest.NewRecorder()
+	writeQuotaError(w, otlpContentTypeJson, err)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Equal(t, otlpContentTypeJson, w.Header().Get("Content-Type"))
+
+	st := status.Convert(quotaGRPCError(t.Context(), err))
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Len(t, st.Details(), 1)
+	assert.Equal(t, 1500*time.Millisecond, st.Details()[0].(*errdetails.RetryInfo).RetryDelay.AsDuration())
+}
@@ -0,0 +1,261 @@
+package collector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/utils"
+	logsv1 "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	tracesv1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	otlpcommon "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog"
+)
+
+const (
+	RedactionTargetResourceAttributes = "resource.attributes"
+	RedactionTargetSpanAttributes     = "span.attributes"
+	RedactionTargetSpanStatusMessage  = "span.status_message"
+	RedactionTargetSpanEvents         = "span.events.attributes"
+	RedactionTargetSpanLinks          = "span.links.attributes"
+	RedactionTargetLogAttributes      = "log.attributes"
+	RedactionTargetLogBody            = "log.body"
+)
+
+// Redactor applies the project's redaction rules to spans and log records before they are batched.
+type Redactor struct {
+	rules []redactionRule
+}
+
+type redactionRule struct {
+	db.RedactionRule
+	pattern *regexp.Regexp
+}
+
+type RedactionHit struct {
+	Rule   string `json:"rule"`
+	Target string `json:"target"`
+	Key    string `json:"key,omitempty"`
+	Count  int    `json:"count"`
+}
+
+// RedactionReport counts the values changed by each rule. It is only collected for dry runs.
+type RedactionReport struct {
+	Hits []*RedactionHit `json:"hits"`
+}
+
+func (r *RedactionReport) add(rule, target, key string) {
+	if r == nil {
+		return
+	}
+	for _, h := range r.Hits {
+		if h.Rule == rule && h.Target == target && h.Key == key {
+			h.Count++
+			return
+		}
+	}
+	r.Hits = append(r.Hits, &RedactionHit{Rule: rule, Target: target, Key: key, Count: 1})
+}
+
+func NewRedactor(rules []db.RedactionRule) (*Redactor, error) {
+	if err := db.ValidateRedactionRules(rules); err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	r := &Redactor{}
+	for _, rule := range rules {
+		rr := redactionRule{RedactionRule: rule}
+		if rule.Pattern != "" {
+			rr.pattern = regexp.MustCompile(rule.Pattern)
+		}
+		if rr.Replacement == "" {
+			rr.Replacement = db.DefaultRedactionReplacement
+		}
+		r.rules = append(r.rules, rr)
+	}
+	return r, nil
+}
+
+func (r *redactionRule) matches(key, value string) bool {
+	if r.AttributeKey != "" && (key == "" || !utils.GlobMatch(key, r.AttributeKey)) {
+		return false
+	}
+	return r.pattern == nil || r.pattern.MatchString(value)
+}
+
+// redact replaces the matched parts of the value or the whole value if the rule has no pattern.
+func (r *redactionRule) redact(value string) string {
+	replace := func(string) string {
+		return r.Replacement
+	}
+	if r.Action == db.RedactionActionHash {
+		replace = hashValue
+	}
+	if r.pattern == nil {
+		return replace(value)
+	}
+	return r.pattern.ReplaceAllStringFunc(value, replace)
+}
+
+// hashValue replaces a value with a short stable digest, so that the redacted values can still be grouped and compared.
+func hashValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Traces redacts the request in place.
+func (r *Redactor) Traces(req *tracesv1.ExportTraceServiceRequest, report *RedactionReport) {
+	if r == nil {
+		return
+	}
+	for _, rs := range req.GetResourceSpans() {
+		if rs.Resource != nil {
+			rs.Resource.Attributes = r.attributes(RedactionTargetResourceAttributes, rs.Resource.Attributes, report)
+		}
+		for _, ss := range rs.GetScopeSpans() {
+			for _, s := range ss.GetSpans() {
+				s.Attributes = r.attributes(RedactionTargetSpanAttributes, s.Attributes, report)
+				if s.Status != nil && s.Status.Message != "" {
+					s.Status.Message = r.text(RedactionTargetSpanStatusMessage, s.Status.Message, report)
+				}
+				for _, e := range s.Events {
+					e.Attributes = r.attributes(RedactionTargetSpanEvents, e.Attributes, report)
+				}
+				for _, l := range s.Links {
+					l.Attributes = r.attributes(RedactionTargetSpanLinks, l.Attributes, report)
+				}
+			}
+		}
+	}
+}
+
+// Logs redacts the request in place.
+func (r *Redactor) Logs(req *logsv1.ExportLogsServiceRequest, report *RedactionReport) {
+	if r == nil {
+		return
+	}
+	for _, rl := range req.GetResourceLogs() {
+		if rl.Resource != nil {
+			rl.Resource.Attributes = r.attributes(RedactionTargetResourceAttributes, rl.Resource.Attributes, report)
+		}
+		for _, sl := range rl.GetScopeLogs() {
+			for _, lr := range sl.GetLogRecords() {
+				lr.Attributes = r.attributes(RedactionTargetLogAttributes, lr.Attributes, report)
+				if lr.Body != nil {
+					r.value(RedactionTargetLogBody, "", lr.Body, report)
+				}
+			}
+		}
+	}
+}
+
+func (r *Redactor) attributes(target string, kvs []*otlpcommon.KeyValue, report *RedactionReport) []*otlpcommon.KeyValue {
+	return slices.DeleteFunc(kvs, func(kv *otlpcommon.KeyValue) bool {
+		return kv.Value != nil && r.value(target, kv.Key, kv.Value, report)
+	})
+}
+
+func (r *Redactor) text(target, s string, report *RedactionReport) string {
+	v := &otlpcommon.AnyValue{Value: &otlpcommon.AnyValue_StringValue{StringValue: s}}
+	r.value(target, "", v, report)
+	return v.GetStringValue()
+}
+
+// value redacts the value in place and reports whether the attribute holding it must be dropped.
+// Nested values are matched by the key of the innermost key-value pair.
+func (r *Redactor) value(target, key string, v *otlpcommon.AnyValue, report *RedactionReport) bool {
+	switch vv := v.Value.(type) {
+	case *otlpcommon.AnyValue_ArrayValue:
+		if vv.ArrayValue != nil {
+			vv.ArrayValue.Values = slices.DeleteFunc(vv.ArrayValue.Values, func(v *otlpcommon.AnyValue) bool {
+				return r.value(target, key, v, report)
+			})
+		}
+		return false
+	case *otlpcommon.AnyValue_KvlistValue:
+		if vv.KvlistValue != nil {
+			vv.KvlistValue.Values = r.attributes(target, vv.KvlistValue.Values, report)
+		}
+		return false
+	}
+
+	s := valueToString(v)
+	changed := false
+	for i := range r.rules {
+		rule := &r.rules[i]
+		if !rule.matches(key, s) {
+			continue
+		}
+		report.add(rule.Name, target, key)
+		if rule.Action == db.RedactionActionDrop {
+			return true
+		}
+		s = rule.redact(s)
+		changed = true
+	}
+	if changed {
+		v.Value = &otlpcommon.AnyValue_StringValue{StringValue: s}
+	}
+	return false
+}
+
+func (c *Collector) getRedactor(projectId db.ProjectId) *Redactor {
+	c.projectsLock.RLock()
+	defer c.projectsLock.RUnlock()
+	return c.redactors[projectId]
+}
+
+func newRedactors(projects []*db.Project) map[db.ProjectId]*Redactor {
+	res := map[db.ProjectId]*Redactor{}
+	for _, p := range projects {
+		r, err := NewRedactor(p.Settings.RedactionRules)
+		if err != nil {
+			klog.Errorf("invalid redaction rules in project %s: %s", p.Id, err)
+			continue
+		}
+		if r != nil {
+			res[p.Id] = r
+		}
+	}
+	return res
+}
+
+// RedactionDryRun applies the rules to a sample OTLP/JSON payload of the given signal (traces or logs)
+// and returns the rules that fired along with the redacted payload.
+func RedactionDryRun(rules []db.RedactionRule, signal string, payload []byte) (*RedactionReport, []byte, error) {
+	r, err := NewRedactor(rules)
+	if err != nil {
+		return nil, nil, err
+	}
+	report := &RedactionReport{Hits: []*RedactionHit{}}
+	var req proto.Message
+	switch signal {
+	case "traces":
+		tr := &tracesv1.ExportTraceServiceRequest{}
+		if err = unmarshalOTLPJson(payload, tr); err != nil {
+			return nil, nil, err
+		}
+		r.Traces(tr, report)
+		req = tr
+	case "logs":
+		lr := &logsv1.ExportLogsServiceRequest{}
+		if err = unmarshalOTLPJson(payload, lr); err != nil {
+			return nil, nil, err
+		}
+		r.Logs(lr, report)
+		req = lr
+	default:
+		return nil, nil, fmt.Errorf("unknown signal: %s", signal)
+	}
+	redacted, err := marshalOTLPJson(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return report, redacted, nil
+}
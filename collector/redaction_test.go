@@ -0,0 +1,119 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/coroot/coroot/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	logsv1 "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	tracesv1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	otlpcommon "go.opentelemetry.io/proto/otlp/common/v1"
+	otlplogs "go.opentelemetry.io/proto/otlp/logs/v1"
+	otlptrace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+var testRedactionRules = []db.RedactionRule{
+	{Name: "tokens", Action: db.RedactionActionDrop, AttributeKey: "*.token"},
+	{Name: "user ids", Action: db.RedactionActionHash, AttributeKey: "user.id"},
+	{Name: "emails", Action: db.RedactionActionMask, Pattern: `[\w.+-]+@[\w-]+\.[\w.]+`},
+	{Name: "cards", Action: db.RedactionActionMask, Pattern: `\b\d{4}-\d{4}-\d{4}-\d{4}\b`, Replacement: "[card]"},
+}
+
+func TestRedactorTraces(t *testing.T) {
+	r, err := NewRedactor(testRedactionRules)
+	require.NoError(t, err)
+
+	span := &otlptrace.Span{
+		Attributes: otlpAttrs(
+			"auth.token", "secret",
+			"user.id", "42",
+			"user.email", "john.doe@example.com",
+			"http.url", "/api/users?email=jane@example.com",
+			"http.method", "GET",
+		),
+		Status: &otlptrace.Status{Message: "payment failed for card 4111-1111-1111-1111"},
+		Events: []*otlptrace.Span_Event{{Attributes: otlpAttrs("exception.message", "unknown user bob@example.com")}},
+	}
+	req := &tracesv1.ExportTraceServiceRequest{ResourceSpans: []*otlptrace.ResourceSpans{{
+		ScopeSpans: []*otlptrace.ScopeSpans{{Spans: []*otlptrace.Span{span}}},
+	}}}
+	report := &RedactionReport{}
+	r.Traces(req, report)
+
+	assert.Equal(t, map[string]string{
+		"user.id":     hashValue("42"),
+		"user.email":  "***",
+		"http.url":    "/api/users?email=***",
+		"http.method": "GET",
+	}, attributesToMap(span.Attributes))
+	assert.Equal(t, "payment failed for card [card]", span.Status.Message)
+	assert.Equal(t, "unknown user ***", attributesToMap(span.Events[0].Attributes)["exception.message"])
+
+	hits := map[string]int{}
+	for _, h := range report.Hits {
+		hits[h.Rule+" "+h.Target+" "+h.Key] += h.Count
+	}
+	assert.Equal(t, map[string]int{
+		"tokens span.attributes auth.token":               1,
+		"user ids span.attributes user.id":                1,
+		"emails span.attributes user.email":               1,
+		"emails span.attributes http.url":                 1,
+		"emails span.events.attributes exception.message": 1,
+		"cards span.status_message ":                      1,
+	}, hits)
+}
+
+func TestRedactorLogs(t *testing.T) {
+	r, err := NewRedactor(testRedactionRules)
+	require.NoError(t, err)
+
+	structured := &otlpcommon.AnyValue{Value: &otlpcommon.AnyValue_KvlistValue{KvlistValue: &otlpcommon.KeyValueList{
+		Values: otlpAttrs("msg", "login", "session.token", "abc", "user.id", "7"),
+	}}}
+	req := &logsv1.ExportLogsServiceRequest{ResourceLogs: []*otlplogs.ResourceLogs{{
+		ScopeLogs: []*otlplogs.ScopeLogs{{LogRecords: []*otlplogs.LogRecord{
+			{Body: &otlpcommon.AnyValue{Value: &otlpcommon.AnyValue_StringValue{StringValue: "sent an invoice to jane@example.com"}}},
+			{Body: structured},
+		}}},
+	}}}
+	r.Logs(req, nil)
+
+	records := req.ResourceLogs[0].ScopeLogs[0].LogRecords
+	assert.Equal(t, "sent an invoice to ***", records[0].Body.GetStringValue())
+	assert.Equal(t, map[string]string{"msg": "login", "user.id": hashValue("7")}, attributesToMap(records[1].Body.GetKvlistValue().Values))
+}
+
+func TestRedactionDryRun(t *testing.T) {
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{
+		"traceId":"5B8EFFF798038103D269B633813FC60C",
+		"body":{"stringValue":"reset password for jane@example.com"},
+		"attributes":[{"key":"api.token","value":{"stringValue":"secret"}}]
+	}]}]}]}`
+	report, redacted, err := RedactionDryRun(testRedactionRules, "logs", []byte(payload))
+	require.NoError(t, err)
+	assert.Len(t, report.Hits, 2)
+
+	var res struct {
+		ResourceLogs []struct {
+			ScopeLogs []struct {
+				LogRecords []struct {
+					TraceId    string            `json:"traceId"`
+					Body       map[string]string `json:"body"`
+					Attributes []any             `json:"attributes"`
+				} `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	require.NoError(t, json.Unmarshal(redacted, &res))
+	lr := res.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	assert.Equal(t, "5b8efff798038103d269b633813fc60c", lr.TraceId)
+	assert.Equal(t, "reset password for ***", lr.Body["stringValue"])
+	assert.Empty(t, lr.Attributes)
+
+	_, _, err = RedactionDryRun(testRedactionRules, "metrics", []byte(payload))
+	assert.Error(t, err)
+	_, _, err = RedactionDryRun([]db.RedactionRule{{Name: "bad", Action: db.RedactionActionMask, Pattern: "("}}, "logs", []byte(payload))
+	assert.Error(t, err)
+}
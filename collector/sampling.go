@@ -0,0 +1,383 @@
+package collector
+
+import (
+	"encoding/binary"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/utils"
+	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
+	tracesv1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	otlpcommon "go.opentelemetry.io/proto/otlp/common/v1"
+	otlpresource "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"k8s.io/klog"
+)
+
+const (
+	tailSamplingMaxBufferedSpans = 100000
+	tailSamplingDecisionCacheTTL = 5 * time.Minute
+	tailSamplingMaxDecisions     = 1000000
+	tailSamplingTickInterval     = time.Second
+)
+
+// TailSampler buffers spans per trace for the decision window, then passes the whole traces matching
+// the sampling policies to the output along with their sample rate: the number of traces each kept trace represents.
+// Spans arriving after the decision has been made follow the decision made for their trace.
+type TailSampler struct {
+	output func(req *tracesv1.ExportTraceServiceRequest, sampleRate float64)
+
+	lock   sync.Mutex
+	done   chan struct{}
+	closed bool
+	policy *samplingPolicy
+	traces map[string]*sampledTrace
+	queue  []string
+	spans  int
+
+	decided          map[string]float64
+	decidedPrev      map[string]float64
+	decidedRotatedAt time.Time
+}
+
+type sampledTrace struct {
+	deadline   time.Time
+	spans      []bufferedSpan
+	sampleRate float64
+}
+
+type bufferedSpan struct {
+	resource *otlpresource.Resource
+	scope    *otlpcommon.InstrumentationScope
+	span     *tracev1.Span
+}
+
+type samplingPolicy struct {
+	decisionWait     time.Duration
+	keepErrors       bool
+	latencyThreshold time.Duration
+	attributes       []samplingAttribute
+	probability      float64
+	services         []db.TraceSamplingServiceSettings
+}
+
+type samplingAttribute struct {
+	key     string
+	pattern *regexp.Regexp
+}
+
+func newSamplingPolicy(cfg *db.TraceSampling) (*samplingPolicy, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	p := &samplingPolicy{
+		decisionWait:     cfg.GetDecisionWait().ToStandard(),
+		keepErrors:       cfg.KeepErrors,
+		latencyThreshold: time.Duration(cfg.LatencyThresholdMs) * time.Millisecond,
+		probability:      cfg.Probability,
+		services:         cfg.Services,
+	}
+	for _, a := range cfg.Attributes {
+		sa := samplingAttribute{key: a.Key}
+		if a.Pattern != "" {
+			sa.pattern = regexp.MustCompile(a.Pattern)
+		}
+		p.attributes = append(p.attributes, sa)
+	}
+	return p, nil
+}
+
+func NewTailSampler(cfg *db.TraceSampling, output func(req *tracesv1.ExportTraceServiceRequest, sampleRate float64)) (*TailSampler, error) {
+	policy, err := newSamplingPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s := &TailSampler{
+		output:           output,
+		done:             make(chan struct{}),
+		policy:           policy,
+		traces:           map[string]*sampledTrace{},
+		decided:          map[string]float64{},
+		decidedPrev:      map[string]float64{},
+		decidedRotatedAt: time.Now(),
+	}
+
+	go func() {
+		ticker := time.NewTicker(tailSamplingTickInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case now := <-ticker.C:
+				s.lock.Lock()
+				kept := s.flush(now, false)
+				s.lock.Unlock()
+				s.emit(kept)
+			}
+		}
+	}()
+
+	return s, nil
+}
+
+// Update applies the new settings. The traces already buffered keep their decision deadlines.
+func (s *TailSampler) Update(cfg *db.TraceSampling) error {
+	policy, err := newSamplingPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.policy = policy
+	return nil
+}
+
+// Close makes decisions for all the buffered traces. The spans added after that are passed through unsampled.
+func (s *TailSampler) Close() {
+	s.done <- struct{}{}
+	s.lock.Lock()
+	s.closed = true
+	kept := s.flush(time.Now(), true)
+	s.lock.Unlock()
+	s.emit(kept)
+}
+
+func (s *TailSampler) Add(req *tracesv1.ExportTraceServiceRequest) {
+	now := time.Now()
+	late := map[string]*sampledTrace{}
+
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		s.output(req, 1)
+		return
+	}
+	for _, rs := range req.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			for _, span := range ss.GetSpans() {
+				bs := bufferedSpan{resource: rs.Resource, scope: ss.Scope, span: span}
+				id := string(span.TraceId)
+				if sampleRate, ok := s.decision(id); ok {
+					if sampleRate > 0 {
+						t := late[id]
+						if t == nil {
+							t = &sampledTrace{sampleRate: sampleRate}
+							late[id] = t
+						}
+						t.spans = append(t.spans, bs)
+					}
+					continue
+				}
+				t := s.traces[id]
+				if t == nil {
+					t = &sampledTrace{deadline: now.Add(s.policy.decisionWait)}
+					s.traces[id] = t
+					s.queue = append(s.queue, id)
+				}
+				t.spans = append(t.spans, bs)
+				s.spans++
+			}
+		}
+	}
+	kept := s.flush(now, false)
+	s.lock.Unlock()
+
+	for _, t := range late {
+		kept = append(kept, t)
+	}
+	s.emit(kept)
+}
+
+// flush makes decisions for the traces whose decision window has expired, or for all the buffered traces if force is set.
+// If the buffer is full, the oldest traces are decided before their deadline.
+func (s *TailSampler) flush(now time.Time, force bool) []*sampledTrace {
+	var kept []*sampledTrace
+	for len(s.queue) > 0 {
+		id := s.queue[0]
+		t := s.traces[id]
+		if !force && s.spans <= tailSamplingMaxBufferedSpans && now.Before(t.deadline) {
+			break
+		}
+		s.queue = s.queue[1:]
+		delete(s.traces, id)
+		s.spans -= len(t.spans)
+		t.sampleRate = s.policy.sampleRate([]byte(id), t.spans)
+		s.remember(now, id, t.sampleRate)
+		if t.sampleRate > 0 {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+func (s *TailSampler) emit(traces []*sampledTrace) {
+	for _, t := range traces {
+		req := &tracesv1.ExportTraceServiceRequest{}
+		for _, bs := range t.spans {
+			req.ResourceSpans = append(req.ResourceSpans, &tracev1.ResourceSpans{
+				Resource:   bs.resource,
+				ScopeSpans: []*tracev1.ScopeSpans{{Scope: bs.scope, Spans: []*tracev1.Span{bs.span}}},
+			})
+		}
+		s.output(req, t.sampleRate)
+	}
+}
+
+// remember keeps the decisions for at least tailSamplingDecisionCacheTTL to handle spans arriving late.
+// To bound memory usage, the cache is rotated earlier once it holds tailSamplingMaxDecisions decisions,
+// so at most twice as many decisions are kept.
+func (s *TailSampler) remember(now time.Time, traceId string, sampleRate float64) {
+	if now.Sub(s.decidedRotatedAt) > tailSamplingDecisionCacheTTL || len(s.decided) >= tailSamplingMaxDecisions {
+		s.decidedPrev, s.decided = s.decided, map[string]float64{}
+		s.decidedRotatedAt = now
+	}
+	s.decided[traceId] = sampleRate
+}
+
+func (s *TailSampler) decision(traceId string) (float64, bool) {
+	if sampleRate, ok := s.decided[traceId]; ok {
+		return sampleRate, true
+	}
+	sampleRate, ok := s.decidedPrev[traceId]
+	return sampleRate, ok
+}
+
+// sampleRate returns the number of traces the trace represents if it is kept or 0 if it is dropped.
+// Traces matching the policies are always kept, so they represent only themselves.
+func (p *samplingPolicy) sampleRate(traceId []byte, spans []bufferedSpan) float64 {
+	if p.matches(spans) {
+		return 1
+	}
+	probability := p.serviceProbability(rootServiceName(spans))
+	if probability <= 0 || traceIdRatio(traceId) >= probability {
+		return 0
+	}
+	return 1 / probability
+}
+
+func (p *samplingPolicy) matches(spans []bufferedSpan) bool {
+	var start, end uint64
+	for i, bs := range spans {
+		s := bs.span
+		if p.keepErrors && s.GetStatus().GetCode() == tracev1.Status_STATUS_CODE_ERROR {
+			return true
+		}
+		if p.matchesAttributes(bs.resource.GetAttributes()) || p.matchesAttributes(s.Attributes) {
+			return true
+		}
+		if i == 0 || s.StartTimeUnixNano < start {
+			start = s.StartTimeUnixNano
+		}
+		if s.EndTimeUnixNano > end {
+			end = s.EndTimeUnixNano
+		}
+	}
+	return p.latencyThreshold > 0 && end > start && time.Duration(end-start) >= p.latencyThreshold
+}
+
+func (p *samplingPolicy) matchesAttributes(kvs []*otlpcommon.KeyValue) bool {
+	for _, a := range p.attributes {
+		for _, kv := range kvs {
+			if !utils.GlobMatch(kv.Key, a.key) {
+				continue
+			}
+			if a.pattern == nil || a.pattern.MatchString(valueToString(kv.Value)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *samplingPolicy) serviceProbability(serviceName string) float64 {
+	for _, s := range p.services {
+		if utils.GlobMatch(serviceName, s.ServiceName) {
+			return s.Probability
+		}
+	}
+	return p.probability
+}
+
+// rootServiceName returns the service of the root span, or of the first span if the root span hasn't been received.
+func rootServiceName(spans []bufferedSpan) string {
+	if len(spans) == 0 {
+		return ""
+	}
+	root := spans[0]
+	for _, bs := range spans {
+		if len(bs.span.ParentSpanId) == 0 {
+			root = bs
+			break
+		}
+	}
+	for _, kv := range root.resource.GetAttributes() {
+		if kv.Key == semconv.AttributeServiceName {
+			return valueToString(kv.Value)
+		}
+	}
+	return ""
+}
+
+// traceIdRatio maps the random part of the trace id to [0, 1), so that all collector instances
+// make the same probabilistic decision for a trace.
+func traceIdRatio(traceId []byte) float64 {
+	if len(traceId) < 16 {
+		return 0
+	}
+	return float64(binary.BigEndian.Uint64(traceId[8:16])>>11) / (1 << 53)
+}
+
+// addTraces derives the span metrics and passes the spans through the project's tail sampler, if sampling is enabled,
+// on their way to the batch. The metrics are derived before sampling, so they account for all the requests.
+func (c *Collector) addTraces(project *db.Project, req *tracesv1.ExportTraceServiceRequest) {
+	c.getSpanMetrics(project).Add(req)
+	if s := c.getTailSampler(project.Id); s != nil {
+		s.Add(req)
+		return
+	}
+	c.getTracesBatch(project).Add(req)
+}
+
+func (c *Collector) getTailSampler(projectId db.ProjectId) *TailSampler {
+	c.tailSamplersLock.Lock()
+	defer c.tailSamplersLock.Unlock()
+	return c.tailSamplers[projectId]
+}
+
+// updateTailSamplers starts, reconfigures, and stops the samplers according to the projects' settings.
+func (c *Collector) updateTailSamplers(projects []*db.Project) {
+	enabled := map[db.ProjectId]*db.Project{}
+	for _, p := range projects {
+		if cfg := p.Settings.TraceSampling; cfg != nil && cfg.Enabled {
+			enabled[p.Id] = p
+		}
+	}
+	c.tailSamplersLock.Lock()
+	defer c.tailSamplersLock.Unlock()
+	for id, s := range c.tailSamplers {
+		if enabled[id] == nil {
+			s.Close()
+			delete(c.tailSamplers, id)
+		}
+	}
+	for id, p := range enabled {
+		cfg := p.Settings.TraceSampling
+		if s := c.tailSamplers[id]; s != nil {
+			if err := s.Update(cfg); err != nil {
+				klog.Errorf("invalid trace sampling settings in project %s: %s", id, err)
+			}
+			continue
+		}
+		s, err := NewTailSampler(cfg, func(req *tracesv1.ExportTraceServiceRequest, sampleRate float64) {
+			c.getTracesBatch(p).AddSampled(req, sampleRate)
+		})
+		if err != nil {
+			klog.Errorf("invalid trace sampling settings in project %s: %s", id, err)
+			continue
+		}
+		c.tailSamplers[id] = s
+	}
+}
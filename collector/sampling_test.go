@@ -0,0 +1,156 @@
+package collector
+
+import (
+	"encoding/binary"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coroot/coroot/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tracesv1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	otlpresource "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// sampledTraceId returns a trace id with the given traceIdRatio.
+func sampledTraceId(ratio float64) []byte {
+	id := make([]byte, 16)
+	id[0] = 1
+	binary.BigEndian.PutUint64(id[8:], uint64(ratio*(1<<53))<<11)
+	return id
+}
+
+func samplingRequest(service string, spans ...*tracev1.Span) *tracesv1.ExportTraceServiceRequest {
+	return &tracesv1.ExportTraceServiceRequest{ResourceSpans: []*tracev1.ResourceSpans{{
+		Resource:   &otlpresource.Resource{Attributes: otlpAttrs("service.name", service)},
+		ScopeSpans: []*tracev1.ScopeSpans{{Spans: spans}},
+	}}}
+}
+
+type samplerOutput struct {
+	lock        sync.Mutex
+	sampleRates map[string]float64
+	spans       map[string]int
+}
+
+func (o *samplerOutput) add(req *tracesv1.ExportTraceServiceRequest, sampleRate float64) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			for _, s := range ss.Spans {
+				o.sampleRates[string(s.TraceId)] = sampleRate
+				o.spans[string(s.TraceId)]++
+			}
+		}
+	}
+}
+
+func TestTailSampler(t *testing.T) {
+	cfg := &db.TraceSampling{
+		Enabled:            true,
+		DecisionWait:       db.MaxTraceSamplingDecisionWait,
+		KeepErrors:         true,
+		LatencyThresholdMs: 1000,
+		Attributes:         []db.TraceSamplingAttribute{{Key: "http.route", Pattern: "^/checkout"}},
+		Probability:        0.1,
+		Services:           []db.TraceSamplingServiceSettings{{ServiceName: "payment*", Probability: 0.5}},
+	}
+	out := &samplerOutput{sampleRates: map[string]float64{}, spans: map[string]int{}}
+	s, err := NewTailSampler(cfg, out.add)
+	require.NoError(t, err)
+
+	span := func(traceId []byte, parent bool, durationMs uint64) *tracev1.Span {
+		s := &tracev1.Span{TraceId: traceId, SpanId: []byte{1, 2, 3, 4, 5, 6, 7, 8}, StartTimeUnixNano: 1e9, EndTimeUnixNano: 1e9 + durationMs*1e6}
+		if parent {
+			s.ParentSpanId = []byte{8, 7, 6, 5, 4, 3, 2, 1}
+		}
+		return s
+	}
+	failed := sampledTraceId(0.9)
+	slow := sampledTraceId(0.91)
+	checkout := sampledTraceId(0.92)
+	lucky := sampledTraceId(0.05)
+	dropped := sampledTraceId(0.3)
+	payment := sampledTraceId(0.35)
+
+	failedSpan := span(failed, true, 10)
+	failedSpan.Status = &tracev1.Status{Code: tracev1.Status_STATUS_CODE_ERROR}
+	checkoutSpan := span(checkout, false, 10)
+	checkoutSpan.Attributes = otlpAttrs("http.route", "/checkout/{id}")
+
+	s.Add(samplingRequest("frontend", span(failed, false, 10), span(slow, false, 10), checkoutSpan, span(lucky, false, 10), span(dropped, false, 10)))
+	s.Add(samplingRequest("backend", failedSpan, span(slow, true, 2000), span(lucky, true, 10), span(dropped, true, 10)))
+	s.Add(samplingRequest("payment-api", span(payment, false, 10)))
+	assert.Empty(t, out.sampleRates, "nothing is decided before the decision window expires")
+
+	s.lock.Lock()
+	kept := s.flush(time.Now().Add(time.Hour), false)
+	s.lock.Unlock()
+	s.emit(kept)
+
+	assert.Equal(t, map[string]float64{
+		string(failed):   1,
+		string(slow):     1,
+		string(checkout): 1,
+		string(lucky):    10,
+		string(payment):  2,
+	}, out.sampleRates)
+	assert.Equal(t, 2, out.spans[string(failed)], "whole traces are kept")
+	assert.Equal(t, 2, out.spans[string(lucky)])
+
+	// late spans follow the decision made for their trace
+	s.Add(samplingRequest("db", span(lucky, true, 1), span(dropped, true, 1)))
+	assert.Equal(t, 3, out.spans[string(lucky)])
+	assert.Equal(t, 0, out.spans[string(dropped)])
+
+	// the buffered traces are decided on close
+	late := sampledTraceId(0.99)
+	s.Add(samplingRequest("frontend", span(late, false, 5000)))
+	s.Close()
+	assert.Equal(t, float64(1), out.sampleRates[string(late)])
+}
+
+func TestTailSamplerBufferLimit(t *testing.T) {
+	out := &samplerOutput{sampleRates: map[string]float64{}, spans: map[string]int{}}
+	s, err := NewTailSampler(&db.TraceSampling{Enabled: true, DecisionWait: db.MaxTraceSamplingDecisionWait, Probability: 1}, out.add)
+	require.NoError(t, err)
+	defer s.Close()
+
+	first := sampledTraceId(0.5)
+	s.Add(samplingRequest("svc", &tracev1.Span{TraceId: first}))
+	spans := make([]*tracev1.Span, tailSamplingMaxBufferedSpans)
+	for i := range spans {
+		spans[i] = &tracev1.Span{TraceId: sampledTraceId(0.6)}
+	}
+	s.Add(samplingRequest("svc", spans...))
+	assert.Equal(t, map[string]float64{string(first): 1}, out.sampleRates, "the oldest trace is decided early when the buffer is full")
+}
+
+func TestTraceIdRatio(t *testing.T) {
+	assert.Equal(t, 0.0, traceIdRatio(make([]byte, 16)))
+	assert.InDelta(t, 0.25, traceIdRatio(sampledTraceId(0.25)), 1e-9)
+	assert.Less(t, traceIdRatio([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}), 1.0)
+}
+
+func TestTailSamplerDecisionCacheLimit(t *testing.T) {
+	out := &samplerOutput{sampleRates: map[string]float64{}, spans: map[string]int{}}
+	s, err := NewTailSampler(&db.TraceSampling{Enabled: true, DecisionWait: db.MaxTraceSamplingDecisionWait, Probability: 1}, out.add)
+	require.NoError(t, err)
+	defer s.Close()
+
+	now := time.Now()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for i := 0; i < 2*tailSamplingMaxDecisions+1; i++ {
+		s.remember(now, strconv.Itoa(i), 1)
+	}
+	assert.LessOrEqual(t, len(s.decided)+len(s.decidedPrev), 2*tailSamplingMaxDecisions)
+	_, ok := s.decision("0")
+	assert.False(t, ok, "the oldest decisions are evicted")
+	_, ok = s.decision(strconv.Itoa(2 * tailSamplingMaxDecisions))
+	assert.True(t, ok)
+}
@@ -0,0 +1,237 @@
+package collector
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/coroot/coroot/db"
+	promModel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
+	tracesv1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"k8s.io/klog"
+)
+
+const (
+	spanMetricsRequests = "coroot_span_requests_total"
+	spanMetricsDuration = "coroot_span_duration_seconds"
+
+	spanMetricsInterval      = 15 * time.Second
+	spanMetricsStaleAfter    = 10 * time.Minute
+	spanMetricsMaxSeries     = 10000
+	spanMetricsOtherSpanName = "other"
+)
+
+// spanMetricsBuckets match the latency objectives available in the SLO settings.
+var spanMetricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// SpanMetrics derives RED metrics from the server and consumer spans: the number of requests and the latency histogram
+// labeled by the service, the span name, and the status (ok or failed). The errors are the requests with the failed status.
+// The series are cumulative and periodically written through the project's metrics path.
+// Once the number of series reaches the limit, the spans of new operations are accounted as the "other" span name.
+type SpanMetrics struct {
+	write func(wr *prompb.WriteRequest)
+
+	lock   sync.Mutex
+	done   chan struct{}
+	series map[spanMetricsKey]*spanMetricsSeries
+}
+
+type spanMetricsKey struct {
+	service  string
+	spanName string
+	status   string
+}
+
+type spanMetricsSeries struct {
+	count     uint64
+	sum       float64
+	buckets   []uint64
+	updatedAt time.Time
+}
+
+func NewSpanMetrics(interval time.Duration, write func(wr *prompb.WriteRequest)) *SpanMetrics {
+	m := &SpanMetrics{
+		write:  write,
+		done:   make(chan struct{}),
+		series: map[spanMetricsKey]*spanMetricsSeries{},
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.done:
+				return
+			case <-ticker.C:
+				m.flush(time.Now())
+			}
+		}
+	}()
+	return m
+}
+
+func (m *SpanMetrics) Close() {
+	close(m.done)
+	m.flush(time.Now())
+}
+
+func (m *SpanMetrics) Add(req *tracesv1.ExportTraceServiceRequest) {
+	now := time.Now()
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, rs := range req.GetResourceSpans() {
+		var service string
+		for _, attr := range rs.GetResource().GetAttributes() {
+			if attr.Key == semconv.AttributeServiceName {
+				service = attr.Value.GetStringValue()
+			}
+		}
+		if service == "" {
+			continue
+		}
+		for _, ss := range rs.GetScopeSpans() {
+			for _, s := range ss.GetSpans() {
+				if s.Kind != tracev1.Span_SPAN_KIND_SERVER && s.Kind != tracev1.Span_SPAN_KIND_CONSUMER {
+					continue
+				}
+				if s.EndTimeUnixNano < s.StartTimeUnixNano {
+					continue
+				}
+				status := "ok"
+				if s.GetStatus().GetCode() == tracev1.Status_STATUS_CODE_ERROR {
+					status = "failed"
+				}
+				m.observe(spanMetricsKey{service: service, spanName: s.Name, status: status}, time.Duration(s.EndTimeUnixNano-s.StartTimeUnixNano), now)
+			}
+		}
+	}
+}
+
+func (m *SpanMetrics) observe(k spanMetricsKey, d time.Duration, now time.Time) {
+	s := m.series[k]
+	if s == nil {
+		if len(m.series) >= spanMetricsMaxSeries {
+			k.spanName = spanMetricsOtherSpanName
+			s = m.series[k]
+		}
+		if s == nil {
+			s = &spanMetricsSeries{buckets: make([]uint64, len(spanMetricsBuckets))}
+			m.series[k] = s
+		}
+	}
+	v := d.Seconds()
+	s.count++
+	s.sum += v
+	for i, le := range spanMetricsBuckets {
+		if v <= le {
+			s.buckets[i]++
+		}
+	}
+	s.updatedAt = now
+}
+
+func (m *SpanMetrics) flush(now time.Time) {
+	wr := m.collect(now)
+	if len(wr.Timeseries) > 0 {
+		m.write(wr)
+	}
+}
+
+// collect returns the current values of the series and forgets the series that haven't been updated for a while.
+func (m *SpanMetrics) collect(now time.Time) *prompb.WriteRequest {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	wr := &prompb.WriteRequest{}
+	if len(m.series) == 0 {
+		return wr
+	}
+	ts := now.UnixMilli()
+	add := func(name string, k spanMetricsKey, le string, v float64) {
+		labels := []prompb.Label{{Name: promModel.MetricNameLabel, Value: name}}
+		if le != "" {
+			labels = append(labels, prompb.Label{Name: promModel.BucketLabel, Value: le})
+		}
+		labels = append(labels,
+			prompb.Label{Name: "service_name", Value: k.service},
+			prompb.Label{Name: "span_name", Value: k.spanName},
+			prompb.Label{Name: "status", Value: k.status},
+		)
+		wr.Timeseries = append(wr.Timeseries, prompb.TimeSeries{Labels: labels, Samples: []prompb.Sample{{Value: v, Timestamp: ts}}})
+	}
+	for k, s := range m.series {
+		if now.Sub(s.updatedAt) > spanMetricsStaleAfter {
+			delete(m.series, k)
+			continue
+		}
+		add(spanMetricsRequests, k, "", float64(s.count))
+		for i, le := range spanMetricsBuckets {
+			add(spanMetricsDuration+"_bucket", k, formatFloat(le), float64(s.buckets[i]))
+		}
+		add(spanMetricsDuration+"_bucket", k, formatFloat(math.Inf(1)), float64(s.count))
+		add(spanMetricsDuration+"_sum", k, "", s.sum)
+		add(spanMetricsDuration+"_count", k, "", float64(s.count))
+	}
+	wr.Metadata = []prompb.MetricMetadata{
+		{MetricFamilyName: spanMetricsRequests, Type: prompb.MetricMetadata_COUNTER, Help: "Number of requests derived from the server and consumer spans"},
+		{MetricFamilyName: spanMetricsDuration, Type: prompb.MetricMetadata_HISTOGRAM, Help: "Histogram of the durations of the server and consumer spans", Unit: "seconds"},
+	}
+	return wr
+}
+
+func (c *Collector) getSpanMetrics(project *db.Project) *SpanMetrics {
+	c.spanMetricsLock.Lock()
+	defer c.spanMetricsLock.Unlock()
+	m := c.spanMetrics[project.Id]
+	if m == nil {
+		projectId := project.Id
+		m = NewSpanMetrics(spanMetricsInterval, func(wr *prompb.WriteRequest) {
+			c.projectsLock.RLock()
+			p := c.projects[projectId]
+			c.projectsLock.RUnlock()
+			if p != nil {
+				c.writeSpanMetrics(p, wr)
+			}
+		})
+		c.spanMetrics[project.Id] = m
+	}
+	return m
+}
+
+// updateSpanMetrics stops the span metrics of the projects that no longer exist.
+func (c *Collector) updateSpanMetrics(projects []*db.Project) {
+	exists := map[db.ProjectId]bool{}
+	for _, p := range projects {
+		exists[p.Id] = true
+	}
+	c.spanMetricsLock.Lock()
+	defer c.spanMetricsLock.Unlock()
+	for id, m := range c.spanMetrics {
+		if !exists[id] {
+			m.Close()
+			delete(c.spanMetrics, id)
+		}
+	}
+}
+
+// writeSpanMetrics writes the derived metrics in the same way as the metrics received from the agents.
+// They aren't subject to the ingestion quota since the spans they are derived from have already been accounted.
+func (c *Collector) writeSpanMetrics(project *db.Project, wr *prompb.WriteRequest) {
+	cfg := project.PrometheusConfig(c.globalPrometheus)
+	addExtraLabels(wr, cfg.ExtraLabels)
+	if cfg.UseClickHouse {
+		c.getMetricsBatch(project).Add(wr)
+		return
+	}
+	if cfg.Url == "" && cfg.RemoteWriteUrl == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), spanMetricsInterval)
+	defer cancel()
+	if err := remoteWrite(ctx, cfg, wr); err != nil {
+		klog.Errorln("failed to write span metrics:", err)
+	}
+}
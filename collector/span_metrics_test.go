@@ -0,0 +1,124 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/coroot/coroot/db"
+	promModel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
+	tracesv1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	otlpcommon "go.opentelemetry.io/proto/otlp/common/v1"
+	otlpresource "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func spanMetricsRequest(service string, spans ...*tracev1.Span) *tracesv1.ExportTraceServiceRequest {
+	return &tracesv1.ExportTraceServiceRequest{ResourceSpans: []*tracev1.ResourceSpans{{
+		Resource:   &otlpresource.Resource{Attributes: []*otlpcommon.KeyValue{otlpStringAttr(semconv.AttributeServiceName, service)}},
+		ScopeSpans: []*tracev1.ScopeSpans{{Spans: spans}},
+	}}}
+}
+
+func metricSpan(name string, kind tracev1.Span_SpanKind, d time.Duration, failed bool) *tracev1.Span {
+	s := &tracev1.Span{Name: name, Kind: kind, StartTimeUnixNano: 1e18, EndTimeUnixNano: 1e18 + uint64(d)}
+	if failed {
+		s.Status = &tracev1.Status{Code: tracev1.Status_STATUS_CODE_ERROR}
+	}
+	return s
+}
+
+func spanMetricsValues(wr *prompb.WriteRequest) map[string]float64 {
+	res := map[string]float64{}
+	for _, ts := range wr.Timeseries {
+		ls := map[string]string{}
+		for _, l := range ts.Labels {
+			ls[l.Name] = l.Value
+		}
+		k := ls[promModel.MetricNameLabel] + "/" + ls["service_name"] + "/" + ls["span_name"] + "/" + ls["status"]
+		if le := ls[promModel.BucketLabel]; le != "" {
+			k += "/" + le
+		}
+		res[k] = ts.Samples[0].Value
+	}
+	return res
+}
+
+func TestSpanMetrics(t *testing.T) {
+	m := &SpanMetrics{series: map[spanMetricsKey]*spanMetricsSeries{}}
+	now := time.Now()
+	m.Add(spanMetricsRequest("checkout",
+		metricSpan("GET /cart", tracev1.Span_SPAN_KIND_SERVER, 3*time.Millisecond, false),
+		metricSpan("GET /cart", tracev1.Span_SPAN_KIND_SERVER, 200*time.Millisecond, false),
+		metricSpan("GET /cart", tracev1.Span_SPAN_KIND_SERVER, 20*time.Second, true),
+		metricSpan("orders", tracev1.Span_SPAN_KIND_CONSUMER, 30*time.Millisecond, false),
+		metricSpan("SELECT", tracev1.Span_SPAN_KIND_CLIENT, time.Millisecond, false),
+		metricSpan("render", tracev1.Span_SPAN_KIND_INTERNAL, time.Millisecond, false),
+	))
+	m.Add(spanMetricsRequest("", metricSpan("GET /", tracev1.Span_SPAN_KIND_SERVER, time.Millisecond, false)))
+
+	wr := m.collect(now)
+	require.Len(t, wr.Metadata, 2)
+	v := spanMetricsValues(wr)
+	assert.Equal(t, 2., v["coroot_span_requests_total/checkout/GET /cart/ok"])
+	assert.Equal(t, 1., v["coroot_span_requests_total/checkout/GET /cart/failed"])
+	assert.Equal(t, 1., v["coroot_span_requests_total/checkout/orders/ok"])
+	assert.Len(t, m.series, 3, "client and internal spans, as well as spans without a service, are ignored")
+
+	assert.Equal(t, 1., v["coroot_span_duration_seconds_bucket/checkout/GET /cart/ok/0.005"])
+	assert.Equal(t, 1., v["coroot_span_duration_seconds_bucket/checkout/GET /cart/ok/0.1"])
+	assert.Equal(t, 2., v["coroot_span_duration_seconds_bucket/checkout/GET /cart/ok/0.25"])
+	assert.Equal(t, 2., v["coroot_span_duration_seconds_bucket/checkout/GET /cart/ok/+Inf"])
+	assert.Equal(t, 0., v["coroot_span_duration_seconds_bucket/checkout/GET /cart/failed/10"])
+	assert.Equal(t, 1., v["coroot_span_duration_seconds_bucket/checkout/GET /cart/failed/+Inf"])
+	assert.InDelta(t, 0.203, v["coroot_span_duration_seconds_sum/checkout/GET /cart/ok"], 1e-9)
+	assert.Equal(t, 2., v["coroot_span_duration_seconds_count/checkout/GET /cart/ok"])
+
+	m.Add(spanMetricsRequest("checkout", metricSpan("GET /cart", tracev1.Span_SPAN_KIND_SERVER, time.Millisecond, false)))
+	v = spanMetricsValues(m.collect(now))
+	assert.Equal(t, 3., v["coroot_span_requests_total/checkout/GET /cart/ok"], "counters are cumulative")
+
+	for k, s := range m.series {
+		if k.spanName == "orders" {
+			s.updatedAt = now.Add(-spanMetricsStaleAfter - time.Second)
+		}
+	}
+	v = spanMetricsValues(m.collect(now))
+	assert.NotContains(t, v, "coroot_span_requests_total/checkout/orders/ok", "stale series are removed")
+	assert.Len(t, m.series, 2)
+}
+
+func TestSpanMetricsLimit(t *testing.T) {
+	m := &SpanMetrics{series: map[spanMetricsKey]*spanMetricsSeries{}}
+	for i := 0; i < spanMetricsMaxSeries; i++ {
+		m.observe(spanMetricsKey{service: "svc", spanName: "op-" + strconv.Itoa(i), status: "ok"}, time.Millisecond, time.Now())
+	}
+	require.Len(t, m.series, spanMetricsMaxSeries)
+	m.Add(spanMetricsRequest("svc",
+		metricSpan("new-1", tracev1.Span_SPAN_KIND_SERVER, time.Millisecond, false),
+		metricSpan("new-2", tracev1.Span_SPAN_KIND_SERVER, time.Millisecond, false),
+	))
+	assert.Len(t, m.series, spanMetricsMaxSeries+1)
+	assert.Equal(t, uint64(2), m.series[spanMetricsKey{service: "svc", spanName: spanMetricsOtherSpanName, status: "ok"}].count)
+}
+
+func TestSpanMetricsDeletedProjects(t *testing.T) {
+	c := &Collector{spanMetrics: map[db.ProjectId]*SpanMetrics{}}
+	p1, p2 := &db.Project{Id: "p1"}, &db.Project{Id: "p2"}
+	m1 := c.getSpanMetrics(p1)
+	m2 := c.getSpanMetrics(p2)
+
+	c.updateSpanMetrics([]*db.Project{p1})
+	assert.Len(t, c.spanMetrics, 1)
+	assert.Same(t, m1, c.getSpanMetrics(p1))
+	select {
+	case <-m2.done:
+	default:
+		t.Error("the span metrics of a deleted project are stopped")
+	}
+	m1.Close()
+}
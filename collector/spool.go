@@ -0,0 +1,429 @@
+package collector
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/ch-go"
+	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/klauspost/compress/zstd"
+	"k8s.io/klog"
+)
+
+const (
+	spoolFileExt          = ".block"
+	spoolReplayInterval   = 5 * time.Second
+	spoolMinRetryInterval = 5 * time.Second
+	spoolMaxRetryInterval = 5 * time.Minute
+)
+
+// Spool is a per-project write-ahead log of the blocks that failed to be written to ClickHouse.
+// Each block is stored in a separate file, the blocks are replayed in the order they were written.
+type Spool struct {
+	dir     string
+	maxSize int64
+	maxAge  time.Duration
+
+	replayLock sync.Mutex
+
+	lock     sync.Mutex
+	entries  []spoolEntry
+	size     int64
+	seq      uint64
+	stats    SpoolStats
+	retryAt  time.Time
+	retryGap time.Duration
+}
+
+type spoolEntry struct {
+	name    string
+	created time.Time
+	rows    int
+	size    int64
+}
+
+type SpoolStats struct {
+	Blocks         int             `json:"blocks"`
+	Rows           int             `json:"rows"`
+	Size           int64           `json:"size"`
+	Oldest         timeseries.Time `json:"oldest"`
+	SpooledBlocks  uint64          `json:"spooled_blocks"`
+	ReplayedBlocks uint64          `json:"replayed_blocks"`
+	DroppedBlocks  uint64          `json:"dropped_blocks"`
+	DroppedRows    uint64          `json:"dropped_rows"`
+	RejectedBlocks uint64          `json:"rejected_blocks"`
+	RejectedRows   uint64          `json:"rejected_rows"`
+	LastError      string          `json:"last_error"`
+	NextRetry      timeseries.Time `json:"next_retry"`
+}
+
+// spoolBlock is a serialized INSERT query. The columns are stored in the ClickHouse native format,
+// so they are sent as is on replay and don't need to be decoded into typed columns.
+type spoolBlock struct {
+	Query   string
+	Columns []spoolColumn
+}
+
+type spoolColumn struct {
+	Name     string
+	DataType chproto.ColumnType
+	NumRows  int
+	Data     []byte
+}
+
+func (c *spoolColumn) Type() chproto.ColumnType {
+	return c.DataType
+}
+
+func (c *spoolColumn) Rows() int {
+	return c.NumRows
+}
+
+func (c *spoolColumn) EncodeColumn(b *chproto.Buffer) {
+	b.PutRaw(c.Data)
+}
+
+func newSpoolBlock(query ch.Query) (*spoolBlock, error) {
+	b := &spoolBlock{Query: query.Body}
+	for _, col := range query.Input {
+		if v, ok := col.Data.(chproto.Preparable); ok {
+			if err := v.Prepare(); err != nil {
+				return nil, fmt.Errorf("failed to prepare column %s: %w", col.Name, err)
+			}
+		}
+		var buf chproto.Buffer
+		if v, ok := col.Data.(chproto.StateEncoder); ok {
+			v.EncodeState(&buf)
+		}
+		col.Data.EncodeColumn(&buf)
+		b.Columns = append(b.Columns, spoolColumn{Name: col.Name, DataType: col.Data.Type(), NumRows: col.Data.Rows(), Data: buf.Buf})
+	}
+	return b, nil
+}
+
+func (b *spoolBlock) rows() int {
+	if len(b.Columns) == 0 {
+		return 0
+	}
+	return b.Columns[0].NumRows
+}
+
+func queryRows(query ch.Query) int {
+	if len(query.Input) == 0 {
+		return 0
+	}
+	return query.Input[0].Data.Rows()
+}
+
+// clickhouseRetriableErrors are the server errors caused by the state of the server rather than by the block itself.
+var clickhouseRetriableErrors = []chproto.Error{
+	chproto.ErrTimeoutExceeded,
+	chproto.ErrTooManySimultaneousQueries,
+	chproto.ErrSocketTimeout,
+	chproto.ErrNetworkError,
+	chproto.ErrMemoryLimitExceeded,
+	chproto.ErrTableIsReadOnly,
+	chproto.ErrTooManyParts,
+	chproto.ErrAllConnectionTriesFailed,
+	chproto.ErrKeeperException,
+}
+
+// isRetriable reports whether writing the block may succeed later.
+// Network errors and timeouts are retriable, while an exception returned by ClickHouse means the block
+// has been rejected (e.g., because of a schema mismatch) and will fail the same way on every attempt,
+// unless the exception is caused by the state of the server.
+func isRetriable(err error) bool {
+	if !ch.IsException(err) {
+		return true
+	}
+	return ch.IsErr(err, clickhouseRetriableErrors...)
+}
+
+func (b *spoolBlock) query() ch.Query {
+	input := make(chproto.Input, 0, len(b.Columns))
+	for i := range b.Columns {
+		input = append(input, chproto.InputColumn{Name: b.Columns[i].Name, Data: &b.Columns[i]})
+	}
+	return ch.Query{Body: b.Query, Input: input}
+}
+
+func NewSpool(dir string, maxSize int64, maxAge time.Duration) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	s := &Spool{dir: dir, maxSize: maxSize, maxAge: maxAge}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		if !strings.HasSuffix(f.Name(), spoolFileExt) {
+			_ = os.Remove(filepath.Join(dir, f.Name()))
+			continue
+		}
+		e, ok := parseSpoolFileName(f.Name())
+		if !ok {
+			klog.Warningln("unexpected file in the spool:", filepath.Join(dir, f.Name()))
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			return nil, err
+		}
+		e.size = info.Size()
+		s.entries = append(s.entries, e)
+		s.size += e.size
+	}
+	sort.Slice(s.entries, func(i, j int) bool {
+		return s.entries[i].name < s.entries[j].name
+	})
+	return s, nil
+}
+
+// The file name is <unix nano>-<seq>-<rows>.block, so that the lexicographical order is the order of writes.
+func spoolFileName(created time.Time, seq uint64, rows int) string {
+	return fmt.Sprintf("%020d-%010d-%d%s", created.UnixNano(), seq, rows, spoolFileExt)
+}
+
+func parseSpoolFileName(name string) (spoolEntry, bool) {
+	parts := strings.Split(strings.TrimSuffix(name, spoolFileExt), "-")
+	if len(parts) != 3 {
+		return spoolEntry{}, false
+	}
+	ts, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return spoolEntry{}, false
+	}
+	rows, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return spoolEntry{}, false
+	}
+	return spoolEntry{name: name, created: time.Unix(0, ts), rows: rows}, true
+}
+
+// Len returns the number of blocks waiting to be replayed.
+func (s *Spool) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.entries)
+}
+
+func (s *Spool) Stats() SpoolStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	res := s.stats
+	res.Blocks = len(s.entries)
+	res.Size = s.size
+	for _, e := range s.entries {
+		res.Rows += e.rows
+	}
+	if len(s.entries) > 0 {
+		res.Oldest = timeseries.Time(s.entries[0].created.Unix())
+		res.NextRetry = timeseries.Time(s.retryAt.Unix())
+	}
+	return res
+}
+
+// Write stores the block in the spool, dropping the oldest blocks if the spool exceeds its size limit.
+func (s *Spool) Write(query ch.Query) error {
+	b, err := newSpoolBlock(query)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.seq++
+	name := spoolFileName(now, s.seq, b.rows())
+	size, err := s.writeFile(name, b)
+	if err != nil {
+		s.dropped(b.rows())
+		return err
+	}
+	if size > s.maxSize {
+		_ = os.Remove(filepath.Join(s.dir, name))
+		s.dropped(b.rows())
+		return fmt.Errorf("the block of %d bytes exceeds the spool size limit", size)
+	}
+	for len(s.entries) > 0 && s.size+size > s.maxSize {
+		s.remove(s.entries[0], true)
+	}
+	s.entries = append(s.entries, spoolEntry{name: name, created: now, rows: b.rows(), size: size})
+	s.size += size
+	s.stats.SpooledBlocks++
+	return nil
+}
+
+func (s *Spool) writeFile(name string, b *spoolBlock) (int64, error) {
+	tmp := filepath.Join(s.dir, name+".tmp")
+	f, err := os.Create(tmp)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = os.Remove(tmp)
+	}()
+	zw, err := zstd.NewWriter(f, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+	if err = gob.NewEncoder(zw).Encode(b); err != nil {
+		_ = zw.Close()
+		_ = f.Close()
+		return 0, err
+	}
+	if err = zw.Close(); err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+	if err = f.Close(); err != nil {
+		return 0, err
+	}
+	if err = os.Rename(tmp, filepath.Join(s.dir, name)); err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (s *Spool) readFile(name string) (*spoolBlock, error) {
+	f, err := os.Open(filepath.Join(s.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := zstd.NewReader(f, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	b := &spoolBlock{}
+	if err = gob.NewDecoder(zr).Decode(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *Spool) remove(e spoolEntry, dropped bool) {
+	for i := range s.entries {
+		if s.entries[i].name != e.name {
+			continue
+		}
+		s.entries = append(s.entries[:i], s.entries[i+1:]...)
+		s.size -= e.size
+		if err := os.Remove(filepath.Join(s.dir, e.name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			klog.Errorln(err)
+		}
+		if dropped {
+			s.dropped(e.rows)
+		}
+		return
+	}
+}
+
+func (s *Spool) dropped(rows int) {
+	s.stats.DroppedBlocks++
+	s.stats.DroppedRows += uint64(rows)
+}
+
+// Reject counts a block that has been rejected by ClickHouse and won't be retried.
+func (s *Spool) Reject(rows int, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.rejected(rows, err)
+}
+
+func (s *Spool) rejected(rows int, err error) {
+	s.stats.RejectedBlocks++
+	s.stats.RejectedRows += uint64(rows)
+	s.stats.LastError = err.Error()
+}
+
+// Expire drops the blocks older than the age limit.
+func (s *Spool) Expire(now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for len(s.entries) > 0 && now.Sub(s.entries[0].created) > s.maxAge {
+		s.remove(s.entries[0], true)
+	}
+}
+
+// Replay writes the spooled blocks in order until the spool is empty or exec fails with a retriable error.
+// After such a failure, the next attempt is made with an exponential backoff.
+// Blocks rejected by ClickHouse are removed, so that they don't block the rest of the spool.
+func (s *Spool) Replay(now time.Time, exec func(query ch.Query) error) {
+	s.replayLock.Lock()
+	defer s.replayLock.Unlock()
+
+	s.lock.Lock()
+	if now.Before(s.retryAt) {
+		s.lock.Unlock()
+		return
+	}
+	s.lock.Unlock()
+
+	for {
+		s.lock.Lock()
+		if len(s.entries) == 0 {
+			s.retryGap = 0
+			s.lock.Unlock()
+			return
+		}
+		e := s.entries[0]
+		s.lock.Unlock()
+
+		b, err := s.readFile(e.name)
+		if err != nil {
+			klog.Errorf("failed to read spooled block %s: %s", e.name, err)
+			s.lock.Lock()
+			s.remove(e, true)
+			s.lock.Unlock()
+			continue
+		}
+		if err = exec(b.query()); err != nil && !isRetriable(err) {
+			klog.Errorf("spooled block %s rejected by clickhouse, dropping it: %s", e.name, err)
+			s.lock.Lock()
+			s.remove(e, false)
+			s.rejected(e.rows, err)
+			s.lock.Unlock()
+			continue
+		}
+		if err != nil {
+			s.lock.Lock()
+			s.stats.LastError = err.Error()
+			s.retryGap = min(max(2*s.retryGap, spoolMinRetryInterval), spoolMaxRetryInterval)
+			s.retryAt = now.Add(s.retryGap)
+			s.lock.Unlock()
+			return
+		}
+		s.lock.Lock()
+		s.remove(e, false)
+		s.stats.ReplayedBlocks++
+		s.stats.LastError = ""
+		s.lock.Unlock()
+	}
+}
@@ -0,0 +1,164 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/ch-go"
+	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSpoolQuery(rows int) ch.Query {
+	timestamp := new(chproto.ColDateTime64).WithPrecision(chproto.PrecisionNano)
+	serviceName := new(chproto.ColStr).LowCardinality()
+	attributes := chproto.NewMap[string, string](new(chproto.ColStr).LowCardinality(), new(chproto.ColStr))
+	events := new(chproto.ColStr).LowCardinality().Array()
+	for i := 0; i < rows; i++ {
+		timestamp.Append(time.Unix(0, int64(i)))
+		serviceName.Append("svc")
+		attributes.Append(map[string]string{"k": "v"})
+		events.Append([]string{"e1", "e2"})
+	}
+	input := chproto.Input{
+		{Name: "Timestamp", Data: timestamp},
+		{Name: "ServiceName", Data: serviceName},
+		{Name: "SpanAttributes", Data: attributes},
+		{Name: "Events.Name", Data: events},
+	}
+	return ch.Query{Body: input.Into("@@table_otel_traces@@"), Input: input}
+}
+
+func encodeSpoolQuery(t *testing.T, q ch.Query) []byte {
+	var buf chproto.Buffer
+	b := chproto.Block{Columns: len(q.Input), Rows: q.Input[0].Data.Rows()}
+	require.NoError(t, b.EncodeRawBlock(&buf, chproto.Version, q.Input))
+	return buf.Buf
+}
+
+func TestSpool(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewSpool(dir, 1<<20, time.Hour)
+	require.NoError(t, err)
+
+	for _, rows := range []int{1, 2, 3} {
+		require.NoError(t, s.Write(testSpoolQuery(rows)))
+	}
+	assert.Equal(t, 3, s.Len())
+	assert.Equal(t, 6, s.Stats().Rows)
+
+	// the spool survives a restart
+	s, err = NewSpool(dir, 1<<20, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 3, s.Len())
+
+	var replayed []int
+	fail := true
+	exec := func(q ch.Query) error {
+		if fail && len(replayed) == 1 {
+			return errors.New("connection refused")
+		}
+		expected := testSpoolQuery(len(replayed) + 1)
+		assert.Equal(t, expected.Body, q.Body)
+		assert.Equal(t, encodeSpoolQuery(t, expected), encodeSpoolQuery(t, q))
+		replayed = append(replayed, q.Input[0].Data.Rows())
+		return nil
+	}
+	now := time.Now()
+	s.Replay(now, exec)
+	assert.Equal(t, []int{1}, replayed)
+	stats := s.Stats()
+	assert.Equal(t, 2, stats.Blocks)
+	assert.Equal(t, "connection refused", stats.LastError)
+
+	fail = false
+	s.Replay(now.Add(time.Second), exec)
+	assert.Equal(t, []int{1}, replayed, "the retry must be postponed")
+	s.Replay(now.Add(spoolMinRetryInterval), exec)
+	assert.Equal(t, []int{1, 2, 3}, replayed)
+	stats = s.Stats()
+	assert.Equal(t, 0, stats.Blocks)
+	assert.Equal(t, int64(0), stats.Size)
+	assert.Equal(t, uint64(3), stats.ReplayedBlocks)
+	assert.Equal(t, uint64(0), stats.DroppedBlocks)
+}
+
+func TestSpoolLimits(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 1<<20, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, s.Write(testSpoolQuery(10)))
+	require.NoError(t, s.Write(testSpoolQuery(20)))
+	s.Expire(time.Now().Add(2 * time.Hour))
+	stats := s.Stats()
+	assert.Equal(t, 0, stats.Blocks)
+	assert.Equal(t, uint64(2), stats.DroppedBlocks)
+	assert.Equal(t, uint64(30), stats.DroppedRows)
+
+	require.NoError(t, s.Write(testSpoolQuery(10)))
+	size := s.Stats().Size
+	s.maxSize = size + size/2
+	require.NoError(t, s.Write(testSpoolQuery(10)))
+	stats = s.Stats()
+	assert.Equal(t, 1, stats.Blocks, "the oldest block must be dropped to fit the new one")
+	assert.Equal(t, uint64(3), stats.DroppedBlocks)
+
+	s.maxSize = 1
+	assert.Error(t, s.Write(testSpoolQuery(10)))
+	assert.Equal(t, uint64(4), s.Stats().DroppedBlocks)
+}
+
+func TestSpoolRejectedBlocks(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 1<<20, time.Hour)
+	require.NoError(t, err)
+	for _, rows := range []int{1, 2, 3} {
+		require.NoError(t, s.Write(testSpoolQuery(rows)))
+	}
+
+	var replayed []int
+	tooManyParts := true
+	exec := func(q ch.Query) error {
+		switch q.Input[0].Data.Rows() {
+		case 1:
+			if tooManyParts {
+				return &ch.Exception{Code: chproto.ErrTooManyParts, Name: "DB::Exception", Message: "Too many parts"}
+			}
+		case 2:
+			return fmt.Errorf("failed: %w", &ch.Exception{Code: chproto.ErrNoSuchColumnInTable, Name: "DB::Exception", Message: "No such column Foo"})
+		}
+		replayed = append(replayed, q.Input[0].Data.Rows())
+		return nil
+	}
+	now := time.Now()
+	s.Replay(now, exec)
+	assert.Empty(t, replayed, "a retriable server error must stop the replay")
+	assert.Equal(t, 3, s.Len())
+
+	tooManyParts = false
+	s.Replay(now.Add(spoolMinRetryInterval), exec)
+	assert.Equal(t, []int{1, 3}, replayed, "the rejected block must not block the rest of the spool")
+	stats := s.Stats()
+	assert.Equal(t, 0, stats.Blocks)
+	assert.Equal(t, uint64(2), stats.ReplayedBlocks)
+	assert.Equal(t, uint64(1), stats.RejectedBlocks)
+	assert.Equal(t, uint64(2), stats.RejectedRows)
+	assert.Equal(t, uint64(0), stats.DroppedBlocks)
+
+	s.Reject(10, errors.New("rejected"))
+	stats = s.Stats()
+	assert.Equal(t, uint64(2), stats.RejectedBlocks)
+	assert.Equal(t, uint64(12), stats.RejectedRows)
+	assert.Equal(t, "rejected", stats.LastError)
+}
+
+func TestIsRetriable(t *testing.T) {
+	assert.True(t, isRetriable(errors.New("dial tcp 127.0.0.1:9000: connect: connection refused")))
+	assert.True(t, isRetriable(context.DeadlineExceeded))
+	assert.True(t, isRetriable(&ch.Exception{Code: chproto.ErrMemoryLimitExceeded}))
+	assert.True(t, isRetriable(fmt.Errorf("insert: %w", &ch.Exception{Code: chproto.ErrTooManyParts})))
+	assert.False(t, isRetriable(&ch.Exception{Code: chproto.ErrNoSuchColumnInTable}))
+	assert.False(t, isRetriable(fmt.Errorf("insert: %w", &ch.Exception{Code: chproto.ErrTypeMismatch})))
+}
@@ -0,0 +1,181 @@
+package collector
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Thrift binary protocol field types.
+const (
+	thriftStop   byte = 0
+	thriftBool   byte = 2
+	thriftByte   byte = 3
+	thriftDouble byte = 4
+	thriftI16    byte = 6
+	thriftI32    byte = 8
+	thriftI64    byte = 10
+	thriftString byte = 11
+	thriftStruct byte = 12
+	thriftMap    byte = 13
+	thriftSet    byte = 14
+	thriftList   byte = 15
+
+	thriftMaxDepth = 64
+)
+
+var errThriftTruncated = errors.New("thrift: unexpected end of data")
+
+// thriftReader decodes messages encoded with the Thrift binary protocol without the generated code.
+// The first error is sticky: once it occurs, all the following reads return zero values.
+type thriftReader struct {
+	data  []byte
+	err   error
+	depth int
+}
+
+func (r *thriftReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = errThriftTruncated
+		return nil
+	}
+	res := r.data[:n]
+	r.data = r.data[n:]
+	return res
+}
+
+func (r *thriftReader) byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *thriftReader) bool() bool {
+	return r.byte() != 0
+}
+
+func (r *thriftReader) i16() int16 {
+	if b := r.next(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *thriftReader) i32() int32 {
+	if b := r.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (r *thriftReader) i64() int64 {
+	if b := r.next(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (r *thriftReader) double() float64 {
+	return math.Float64frombits(uint64(r.i64()))
+}
+
+func (r *thriftReader) binary() []byte {
+	return r.next(int(r.i32()))
+}
+
+func (r *thriftReader) string() string {
+	return string(r.binary())
+}
+
+// readStruct calls f for each field of the struct. f must read the value of the field,
+// or call skip for the fields it isn't interested in.
+func (r *thriftReader) readStruct(f func(id int16, typ byte)) {
+	if !r.enter() {
+		return
+	}
+	defer r.leave()
+	for r.err == nil {
+		typ := r.byte()
+		if typ == thriftStop {
+			return
+		}
+		id := r.i16()
+		if r.err != nil {
+			return
+		}
+		f(id, typ)
+	}
+}
+
+// readList calls f for each element of the list (or set).
+func (r *thriftReader) readList(f func(typ byte)) {
+	typ := r.byte()
+	size := r.i32()
+	if r.err == nil && (size < 0 || int(size) > len(r.data)) {
+		r.err = fmt.Errorf("thrift: invalid list size %d", size)
+	}
+	for i := int32(0); i < size && r.err == nil; i++ {
+		f(typ)
+	}
+}
+
+func (r *thriftReader) skip(typ byte) {
+	switch typ {
+	case thriftBool, thriftByte:
+		r.next(1)
+	case thriftI16:
+		r.next(2)
+	case thriftI32:
+		r.next(4)
+	case thriftDouble, thriftI64:
+		r.next(8)
+	case thriftString:
+		r.binary()
+	case thriftStruct:
+		r.readStruct(func(_ int16, typ byte) { r.skip(typ) })
+	case thriftList, thriftSet:
+		if !r.enter() {
+			return
+		}
+		r.readList(r.skip)
+		r.leave()
+	case thriftMap:
+		if !r.enter() {
+			return
+		}
+		kt, vt := r.byte(), r.byte()
+		size := r.i32()
+		if r.err == nil && (size < 0 || int(size) > len(r.data)) {
+			r.err = fmt.Errorf("thrift: invalid map size %d", size)
+		}
+		for i := int32(0); i < size && r.err == nil; i++ {
+			r.skip(kt)
+			r.skip(vt)
+		}
+		r.leave()
+	default:
+		if r.err == nil {
+			r.err = fmt.Errorf("thrift: unknown field type %d", typ)
+		}
+	}
+}
+
+func (r *thriftReader) enter() bool {
+	if r.depth >= thriftMaxDepth {
+		if r.err == nil {
+			r.err = errors.New("thrift: maximum nesting depth exceeded")
+		}
+		return false
+	}
+	r.depth++
+	return true
+}
+
+func (r *thriftReader) leave() {
+	r.depth--
+}
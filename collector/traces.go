@@ -2,66 +2,75 @@ package collector
 
 import (
 	"encoding/hex"
-	"io"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/ClickHouse/ch-go"
 	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/coroot/coroot/db"
 	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
 	v1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
-	"google.golang.org/protobuf/proto"
+	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
 	"k8s.io/klog"
 )
 
 func (c *Collector) Traces(w http.ResponseWriter, r *http.Request) {
-	project, err := c.getProject(r.Header.Get(ApiKeyHeader))
+	project, err := c.getProject(r.Header.Get(ApiKeyHeader), db.ApiKeyScopeIngestTraces)
 	if err != nil {
 		klog.Errorln(err)
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), projectErrorStatus(err))
 		return
 	}
 
-	contentType := r.Header.Get("Content-Type")
-	switch contentType {
-	case "application/x-protobuf":
-	default:
-		http.Error(w, "unsupported content type: "+contentType, http.StatusBadRequest)
-		return
-	}
-
-	decoder, err := getDecoder(r.Header.Get("Content-Encoding"), r.Body)
+	req := &v1.ExportTraceServiceRequest{}
+	contentType, err := readOTLPRequest(w, r, req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	data, err := io.ReadAll(decoder)
-	if err != nil {
-		klog.Errorln(err)
-		http.Error(w, "", http.StatusInternalServerError)
-		return
-	}
-	req := &v1.ExportTraceServiceRequest{}
-	err = proto.Unmarshal(data, req)
-	if err != nil {
-		klog.Errorln(err)
-		http.Error(w, "", http.StatusBadRequest)
+	if err = c.checkQuota(project.Id, tracesIngestion(req)); err != nil {
+		writeQuotaError(w, contentType, err)
 		return
 	}
 
-	c.getTracesBatch(project).Add(req)
+	resp := rejectInvalidSpans(req)
+	c.getRedactor(project.Id).Traces(req, nil)
+	c.addTraces(project, req)
 
+	writeOTLPResponse(w, contentType, resp)
+}
+
+// rejectInvalidSpans removes the spans with malformed ids, since they can't be linked to their traces.
+func rejectInvalidSpans(req *v1.ExportTraceServiceRequest) *v1.ExportTraceServiceResponse {
+	var rejected int64
+	for _, rs := range req.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			ss.Spans = slices.DeleteFunc(ss.Spans, func(s *tracev1.Span) bool {
+				invalid := !validTraceId(s.TraceId) || !validSpanId(s.SpanId) || (len(s.ParentSpanId) > 0 && !validSpanId(s.ParentSpanId))
+				if invalid {
+					rejected++
+				}
+				return invalid
+			})
+		}
+	}
 	resp := &v1.ExportTraceServiceResponse{}
-	w.Header().Set("Content-Type", contentType)
-	data, err = proto.Marshal(resp)
-	if err != nil {
-		klog.Errorln(err)
-		http.Error(w, "", http.StatusInternalServerError)
-		return
+	if rejected > 0 {
+		resp.PartialSuccess = &v1.ExportTracePartialSuccess{
+			RejectedSpans: rejected,
+			ErrorMessage:  "spans with invalid trace or span ids were rejected",
+		}
 	}
-	_, _ = w.Write(data)
+	return resp
+}
+
+func validTraceId(id []byte) bool {
+	return len(id) == 16 && slices.ContainsFunc(id, func(b byte) bool { return b != 0 })
+}
+
+func validSpanId(id []byte) bool {
+	return len(id) == 8 && slices.ContainsFunc(id, func(b byte) bool { return b != 0 })
 }
 
 type TracesBatch struct {
@@ -91,6 +100,7 @@ type TracesBatch struct {
 	LinksSpanId        *chproto.ColArr[string]
 	LinksTraceState    *chproto.ColArr[string]
 	LinksAttributes    *chproto.ColArr[map[string]string]
+	SampleRate         *chproto.ColFloat64
 }
 
 func NewTracesBatch(limit int, timeout time.Duration, exec func(query ch.Query) error) *TracesBatch {
@@ -119,6 +129,7 @@ func NewTracesBatch(limit int, timeout time.Duration, exec func(query ch.Query)
 		LinksSpanId:        new(chproto.ColStr).Array(),
 		LinksTraceState:    new(chproto.ColStr).Array(),
 		LinksAttributes:    chproto.NewArray[map[string]string](chproto.NewMap[string, string](new(chproto.ColStr).LowCardinality(), new(chproto.ColStr))),
+		SampleRate:         new(chproto.ColFloat64),
 	}
 
 	go func() {
@@ -147,6 +158,12 @@ func (b *TracesBatch) Close() {
 }
 
 func (b *TracesBatch) Add(req *v1.ExportTraceServiceRequest) {
+	b.AddSampled(req, 1)
+}
+
+// AddSampled adds the spans of the traces kept by the tail sampler.
+// The sample rate is the number of traces each of them represents.
+func (b *TracesBatch) AddSampled(req *v1.ExportTraceServiceRequest, sampleRate float64) {
 	b.lock.Lock()
 	defer b.lock.Unlock()
 
@@ -208,6 +225,7 @@ func (b *TracesBatch) Add(req *v1.ExportTraceServiceRequest) {
 				b.LinksSpanId.Append(linkSpanIds)
 				b.LinksTraceState.Append(linkTraceStates)
 				b.LinksAttributes.Append(linkAttributes)
+				b.SampleRate.Append(sampleRate)
 			}
 		}
 	}
@@ -243,6 +261,7 @@ func (b *TracesBatch) save() {
 		{Name: "Links.SpanId", Data: b.LinksSpanId},
 		{Name: "Links.TraceState", Data: b.LinksTraceState},
 		{Name: "Links.Attributes", Data: b.LinksAttributes},
+		{Name: "SampleRate", Data: b.SampleRate},
 	}
 	err := b.exec(ch.Query{Body: input.Into("@@table_otel_traces@@"), Input: input})
 	if err != nil {
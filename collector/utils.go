@@ -5,6 +5,7 @@ import (
 	"compress/gzip"
 	"compress/zlib"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -93,3 +94,9 @@ func getDecoder(encoding string, body io.ReadCloser) (io.ReadCloser, error) {
 	}
 	return nil, fmt.Errorf("unsupported content encoding: %q", encoding)
 }
+
+var errUnsupportedContentType = errors.New("unsupported content type")
+
+func unsupportedContentType(contentType string) error {
+	return fmt.Errorf("%w: %s", errUnsupportedContentType, contentType)
+}
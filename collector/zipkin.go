@@ -0,0 +1,282 @@
+package collector
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
+	v1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	zipkinScopeName = "zipkin"
+)
+
+var zipkinProtoKinds = map[uint64]string{1: "CLIENT", 2: "SERVER", 3: "PRODUCER", 4: "CONSUMER"}
+
+// Zipkin accepts the spans sent using the Zipkin v2 API (the /api/v2/spans endpoint, JSON or protobuf).
+func (c *Collector) Zipkin(w http.ResponseWriter, r *http.Request) {
+	c.addLegacyTraces(w, r, func(contentType string, data []byte) (*v1.ExportTraceServiceRequest, error) {
+		var spans []zipkinSpan
+		var err error
+		switch contentType {
+		case "", "application/json":
+			spans, err = unmarshalZipkinJson(data)
+		case "application/x-protobuf", "application/protobuf":
+			spans, err = unmarshalZipkinProtobuf(data)
+		default:
+			return nil, unsupportedContentType(contentType)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return zipkinToOTLP(spans), nil
+	})
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+	IPv4        string `json:"ipv4"`
+	IPv6        string `json:"ipv6"`
+	Port        int    `json:"port"`
+}
+
+type zipkinAnnotation struct {
+	Timestamp uint64 `json:"timestamp"`
+	Value     string `json:"value"`
+}
+
+type zipkinSpan struct {
+	traceId        []byte
+	id             []byte
+	parentId       []byte
+	name           string
+	kind           string
+	timestamp      uint64
+	duration       uint64
+	localEndpoint  *zipkinEndpoint
+	remoteEndpoint *zipkinEndpoint
+	annotations    []zipkinAnnotation
+	tags           map[string]string
+}
+
+func unmarshalZipkinJson(data []byte) ([]zipkinSpan, error) {
+	var spans []struct {
+		TraceId        string             `json:"traceId"`
+		Id             string             `json:"id"`
+		ParentId       string             `json:"parentId"`
+		Name           string             `json:"name"`
+		Kind           string             `json:"kind"`
+		Timestamp      uint64             `json:"timestamp"`
+		Duration       uint64             `json:"duration"`
+		LocalEndpoint  *zipkinEndpoint    `json:"localEndpoint"`
+		RemoteEndpoint *zipkinEndpoint    `json:"remoteEndpoint"`
+		Annotations    []zipkinAnnotation `json:"annotations"`
+		Tags           map[string]string  `json:"tags"`
+	}
+	if err := json.Unmarshal(data, &spans); err != nil {
+		return nil, err
+	}
+	res := make([]zipkinSpan, 0, len(spans))
+	for _, s := range spans {
+		span := zipkinSpan{
+			name:           s.Name,
+			kind:           s.Kind,
+			timestamp:      s.Timestamp,
+			duration:       s.Duration,
+			localEndpoint:  s.LocalEndpoint,
+			remoteEndpoint: s.RemoteEndpoint,
+			annotations:    s.Annotations,
+			tags:           s.Tags,
+		}
+		var err error
+		if span.traceId, err = zipkinHexId(s.TraceId, 16); err != nil {
+			return nil, fmt.Errorf("invalid trace id %q: %w", s.TraceId, err)
+		}
+		if span.id, err = zipkinHexId(s.Id, 8); err != nil {
+			return nil, fmt.Errorf("invalid span id %q: %w", s.Id, err)
+		}
+		if span.parentId, err = zipkinHexId(s.ParentId, 8); err != nil {
+			return nil, fmt.Errorf("invalid parent span id %q: %w", s.ParentId, err)
+		}
+		res = append(res, span)
+	}
+	return res, nil
+}
+
+func zipkinHexId(id string, size int) ([]byte, error) {
+	if id == "" {
+		return nil, nil
+	}
+	if len(id)%2 == 1 {
+		id = "0" + id
+	}
+	b, err := hex.DecodeString(id)
+	if err != nil {
+		return nil, err
+	}
+	return padId(b, size), nil
+}
+
+// unmarshalZipkinProtobuf decodes zipkin.proto3 ListOfSpans:
+//
+//	ListOfSpans { repeated Span spans = 1; }
+//	Span { bytes trace_id = 1; bytes parent_id = 2; bytes id = 3; Kind kind = 4; string name = 5; fixed64 timestamp = 6;
+//	       uint64 duration = 7; Endpoint local_endpoint = 8; Endpoint remote_endpoint = 9; repeated Annotation annotations = 10;
+//	       map<string, string> tags = 11; }
+//	Endpoint { string service_name = 1; bytes ipv4 = 2; bytes ipv6 = 3; int32 port = 4; }
+//	Annotation { fixed64 timestamp = 1; string value = 2; }
+func unmarshalZipkinProtobuf(data []byte) ([]zipkinSpan, error) {
+	var res []zipkinSpan
+	err := walkProto(data, func(f protoField) error {
+		if f.num != 1 || f.typ != protowire.BytesType {
+			return nil
+		}
+		span := zipkinSpan{}
+		err := walkProto(f.bytes, func(f protoField) error {
+			var err error
+			switch f.num {
+			case 1:
+				span.traceId = padId(f.bytes, 16)
+			case 2:
+				span.parentId = f.bytes
+			case 3:
+				span.id = f.bytes
+			case 4:
+				span.kind = zipkinProtoKinds[f.uint]
+			case 5:
+				span.name = string(f.bytes)
+			case 6:
+				span.timestamp = f.uint
+			case 7:
+				span.duration = f.uint
+			case 8:
+				span.localEndpoint, err = unmarshalZipkinEndpoint(f.bytes)
+			case 9:
+				span.remoteEndpoint, err = unmarshalZipkinEndpoint(f.bytes)
+			case 10:
+				var a zipkinAnnotation
+				err = walkProto(f.bytes, func(f protoField) error {
+					switch f.num {
+					case 1:
+						a.Timestamp = f.uint
+					case 2:
+						a.Value = string(f.bytes)
+					}
+					return nil
+				})
+				span.annotations = append(span.annotations, a)
+			case 11:
+				var k, v string
+				err = walkProto(f.bytes, func(f protoField) error {
+					switch f.num {
+					case 1:
+						k = string(f.bytes)
+					case 2:
+						v = string(f.bytes)
+					}
+					return nil
+				})
+				if span.tags == nil {
+					span.tags = map[string]string{}
+				}
+				span.tags[k] = v
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		res = append(res, span)
+		return nil
+	})
+	return res, err
+}
+
+func unmarshalZipkinEndpoint(data []byte) (*zipkinEndpoint, error) {
+	e := &zipkinEndpoint{}
+	err := walkProto(data, func(f protoField) error {
+		switch f.num {
+		case 1:
+			e.ServiceName = string(f.bytes)
+		case 2:
+			if len(f.bytes) == net.IPv4len {
+				e.IPv4 = net.IP(f.bytes).String()
+			}
+		case 3:
+			if len(f.bytes) == net.IPv6len {
+				e.IPv6 = net.IP(f.bytes).String()
+			}
+		case 4:
+			e.Port = int(int32(f.uint))
+		}
+		return nil
+	})
+	return e, err
+}
+
+// zipkinToOTLP converts Zipkin spans to OTLP: the local endpoint defines the service,
+// and the remote endpoint becomes the peer attributes used to build the service map.
+func zipkinToOTLP(spans []zipkinSpan) *v1.ExportTraceServiceRequest {
+	l := newLegacyServiceSpans()
+	for _, zs := range spans {
+		var serviceName string
+		if zs.localEndpoint != nil {
+			serviceName = zs.localEndpoint.ServiceName
+		}
+		s := &tracev1.Span{
+			TraceId:           zs.traceId,
+			SpanId:            zs.id,
+			ParentSpanId:      zs.parentId,
+			Name:              zs.name,
+			Kind:              spanKindFromString(zs.kind),
+			StartTimeUnixNano: zs.timestamp * 1000,
+			EndTimeUnixNano:   (zs.timestamp + zs.duration) * 1000,
+		}
+		for k, v := range zs.tags {
+			s.Attributes = append(s.Attributes, otlpStringAttr(k, v))
+		}
+		if e := zs.localEndpoint; e != nil {
+			if ip := e.ip(); ip != "" {
+				s.Attributes = append(s.Attributes, otlpStringAttr(semconv.AttributeNetSockHostAddr, ip))
+			}
+			if e.Port > 0 {
+				s.Attributes = append(s.Attributes, otlpStringAttr(semconv.AttributeNetHostPort, strconv.Itoa(e.Port)))
+			}
+		}
+		if e := zs.remoteEndpoint; e != nil {
+			if e.ServiceName != "" && zs.tags[semconv.AttributePeerService] == "" {
+				s.Attributes = append(s.Attributes, otlpStringAttr(semconv.AttributePeerService, e.ServiceName))
+			}
+			if ip := e.ip(); ip != "" {
+				s.Attributes = append(s.Attributes, otlpStringAttr(semconv.AttributeNetSockPeerAddr, ip))
+				if zs.tags[semconv.AttributeNetPeerName] == "" {
+					s.Attributes = append(s.Attributes, otlpStringAttr(semconv.AttributeNetPeerName, ip))
+				}
+			}
+			if e.Port > 0 && zs.tags[semconv.AttributeNetPeerPort] == "" {
+				s.Attributes = append(s.Attributes, otlpStringAttr(semconv.AttributeNetPeerPort, strconv.Itoa(e.Port)))
+			}
+		}
+		for _, a := range zs.annotations {
+			s.Events = append(s.Events, &tracev1.Span_Event{TimeUnixNano: a.Timestamp * 1000, Name: a.Value})
+		}
+		applyLegacySpanTags(s)
+		ss := l.resource(serviceName, nil, zipkinScopeName)
+		ss.Spans = append(ss.Spans, s)
+	}
+	return l.req
+}
+
+func (e *zipkinEndpoint) ip() string {
+	if e.IPv4 != "" {
+		return e.IPv4
+	}
+	return e.IPv6
+}
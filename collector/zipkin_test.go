@@ -0,0 +1,132 @@
+package collector
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
+	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestZipkinJson(t *testing.T) {
+	body := `[
+		{
+			"traceId": "463ac35c9f6413ad", "id": "a2fb4a1d1a96d312", "name": "get /api", "kind": "SERVER",
+			"timestamp": 1700000000000000, "duration": 2000,
+			"localEndpoint": {"serviceName": "frontend", "ipv4": "10.0.0.2", "port": 8080},
+			"tags": {"http.method": "GET", "error": "upstream timeout"},
+			"annotations": [{"timestamp": 1700000000001000, "value": "wr"}]
+		},
+		{
+			"traceId": "463ac35c9f6413ad", "parentId": "a2fb4a1d1a96d312", "id": "b3fb4a1d1a96d312", "name": "query", "kind": "CLIENT",
+			"timestamp": 1700000000000500, "duration": 1000,
+			"localEndpoint": {"serviceName": "frontend"},
+			"remoteEndpoint": {"serviceName": "postgres", "ipv4": "10.0.0.3", "port": 5432}
+		},
+		{"traceId": "5af7183fb1d4cf5f463ac35c9f6413ad", "id": "c3fb4a1d1a96d312", "name": "consume", "localEndpoint": {"serviceName": "worker"}}
+	]`
+	spans, err := unmarshalZipkinJson([]byte(body))
+	require.NoError(t, err)
+	req := zipkinToOTLP(spans)
+	require.Len(t, req.ResourceSpans, 2, "spans are grouped by the local service")
+
+	frontend := req.ResourceSpans[0]
+	assert.Equal(t, "frontend", attributesToMap(frontend.Resource.Attributes)[semconv.AttributeServiceName])
+	assert.Equal(t, zipkinScopeName, frontend.ScopeSpans[0].Scope.Name)
+	require.Len(t, frontend.ScopeSpans[0].Spans, 2)
+
+	server := frontend.ScopeSpans[0].Spans[0]
+	assert.Equal(t, "0000000000000000463ac35c9f6413ad", hex.EncodeToString(server.TraceId))
+	assert.Equal(t, "a2fb4a1d1a96d312", hex.EncodeToString(server.SpanId))
+	assert.Empty(t, server.ParentSpanId)
+	assert.Equal(t, tracev1.Span_SPAN_KIND_SERVER, server.Kind)
+	assert.Equal(t, uint64(1700000000000000000), server.StartTimeUnixNano)
+	assert.Equal(t, uint64(1700000000002000000), server.EndTimeUnixNano)
+	assert.Equal(t, &tracev1.Status{Code: tracev1.Status_STATUS_CODE_ERROR, Message: "upstream timeout"}, server.Status)
+	assert.Equal(t, map[string]string{
+		"http.method":                    "GET",
+		semconv.AttributeNetSockHostAddr: "10.0.0.2",
+		semconv.AttributeNetHostPort:     "8080",
+	}, attributesToMap(server.Attributes))
+	require.Len(t, server.Events, 1)
+	assert.Equal(t, "wr", server.Events[0].Name)
+	assert.Equal(t, uint64(1700000000001000000), server.Events[0].TimeUnixNano)
+
+	client := frontend.ScopeSpans[0].Spans[1]
+	assert.Equal(t, "a2fb4a1d1a96d312", hex.EncodeToString(client.ParentSpanId))
+	assert.Equal(t, tracev1.Span_SPAN_KIND_CLIENT, client.Kind)
+	assert.Nil(t, client.Status)
+	assert.Equal(t, map[string]string{
+		semconv.AttributePeerService:     "postgres",
+		semconv.AttributeNetSockPeerAddr: "10.0.0.3",
+		semconv.AttributeNetPeerName:     "10.0.0.3",
+		semconv.AttributeNetPeerPort:     "5432",
+	}, attributesToMap(client.Attributes))
+
+	worker := req.ResourceSpans[1]
+	assert.Equal(t, "worker", attributesToMap(worker.Resource.Attributes)[semconv.AttributeServiceName])
+	assert.Equal(t, "5af7183fb1d4cf5f463ac35c9f6413ad", hex.EncodeToString(worker.ScopeSpans[0].Spans[0].TraceId))
+	assert.Equal(t, tracev1.Span_SPAN_KIND_INTERNAL, worker.ScopeSpans[0].Spans[0].Kind)
+
+	_, err = unmarshalZipkinJson([]byte(`[{"traceId": "xyz", "id": "a2fb4a1d1a96d312"}]`))
+	assert.Error(t, err)
+}
+
+func TestZipkinProtobuf(t *testing.T) {
+	var endpoint []byte
+	endpoint = protowire.AppendTag(endpoint, 1, protowire.BytesType)
+	endpoint = protowire.AppendString(endpoint, "backend")
+	endpoint = protowire.AppendTag(endpoint, 2, protowire.BytesType)
+	endpoint = protowire.AppendBytes(endpoint, []byte{10, 0, 0, 4})
+	endpoint = protowire.AppendTag(endpoint, 4, protowire.VarintType)
+	endpoint = protowire.AppendVarint(endpoint, 9000)
+
+	var tag []byte
+	tag = protowire.AppendTag(tag, 1, protowire.BytesType)
+	tag = protowire.AppendString(tag, "rpc.method")
+	tag = protowire.AppendTag(tag, 2, protowire.BytesType)
+	tag = protowire.AppendString(tag, "Get")
+
+	var span []byte
+	span = protowire.AppendTag(span, 1, protowire.BytesType)
+	span = protowire.AppendBytes(span, []byte{1, 2, 3, 4, 5, 6, 7, 8})
+	span = protowire.AppendTag(span, 3, protowire.BytesType)
+	span = protowire.AppendBytes(span, []byte{8, 7, 6, 5, 4, 3, 2, 1})
+	span = protowire.AppendTag(span, 4, protowire.VarintType)
+	span = protowire.AppendVarint(span, 2)
+	span = protowire.AppendTag(span, 5, protowire.BytesType)
+	span = protowire.AppendString(span, "Get")
+	span = protowire.AppendTag(span, 6, protowire.Fixed64Type)
+	span = protowire.AppendFixed64(span, 1700000000000000)
+	span = protowire.AppendTag(span, 7, protowire.VarintType)
+	span = protowire.AppendVarint(span, 100)
+	span = protowire.AppendTag(span, 8, protowire.BytesType)
+	span = protowire.AppendBytes(span, endpoint)
+	span = protowire.AppendTag(span, 11, protowire.BytesType)
+	span = protowire.AppendBytes(span, tag)
+
+	var data []byte
+	data = protowire.AppendTag(data, 1, protowire.BytesType)
+	data = protowire.AppendBytes(data, span)
+
+	spans, err := unmarshalZipkinProtobuf(data)
+	require.NoError(t, err)
+	require.Len(t, spans, 1)
+	assert.Equal(t, "backend", spans[0].localEndpoint.ServiceName)
+	assert.Equal(t, "10.0.0.4", spans[0].localEndpoint.IPv4)
+	assert.Equal(t, 9000, spans[0].localEndpoint.Port)
+
+	req := zipkinToOTLP(spans)
+	s := req.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	assert.Equal(t, "00000000000000000102030405060708", hex.EncodeToString(s.TraceId))
+	assert.Equal(t, "0807060504030201", hex.EncodeToString(s.SpanId))
+	assert.Equal(t, tracev1.Span_SPAN_KIND_SERVER, s.Kind)
+	assert.Equal(t, uint64(1700000000000100000), s.EndTimeUnixNano)
+	assert.Equal(t, "Get", attributesToMap(s.Attributes)["rpc.method"])
+
+	_, err = unmarshalZipkinProtobuf(data[:len(data)-3])
+	assert.Error(t, err)
+}
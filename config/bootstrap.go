@@ -17,6 +17,10 @@ func (cfg *Config) Bootstrap(database *db.DB) error {
 		}
 	}
 
+	if err := cfg.bootstrapSSO(database); err != nil {
+		return err
+	}
+
 	if len(cfg.Projects) == 0 {
 		p, err := getOrCreateDefaultProject(database)
 		if err != nil {
@@ -245,3 +249,20 @@ func getOrCreateDefaultProject(database *db.DB) (*db.Project, error) {
 	}
 	return nil, nil
 }
+
+func (cfg *Config) bootstrapSSO(database *db.DB) error {
+	if cfg.Auth.SSO != nil {
+		sso := *cfg.Auth.SSO
+		sso.Readonly = true
+		return database.SaveSSOSettings(sso)
+	}
+	sso, err := database.GetSSOSettings()
+	if err != nil {
+		return err
+	}
+	if !sso.Readonly {
+		return nil
+	}
+	sso.Readonly = false
+	return database.SaveSSOSettings(sso)
+}
@@ -7,4 +7,9 @@ type CollectorConfig struct {
 	LogsTTL     timeseries.Duration
 	ProfilesTTL timeseries.Duration
 	MetricsTTL  timeseries.Duration
+
+	// SpoolDir is empty if the spool is disabled.
+	SpoolDir     string
+	SpoolMaxSize int64
+	SpoolMaxAge  timeseries.Duration
 }
@@ -48,6 +48,8 @@ type Config struct {
 
 	ClickHouseSpaceManager ClickHouseSpaceManager `yaml:"clickhouse_space_manager"`
 
+	CollectorSpool CollectorSpool `yaml:"collector_spool"`
+
 	CorootCloud *cloud.Settings `yaml:"corootCloud"`
 
 	BootstrapClickhouse *Clickhouse `yaml:"-"`
@@ -86,6 +88,14 @@ type ClickHouseSpaceManager struct {
 	MinPartitions         int  `yaml:"min_partitions"`
 }
 
+// CollectorSpool configures the on-disk spool of the telemetry that failed to be written to ClickHouse.
+// The limits are applied to each project separately.
+type CollectorSpool struct {
+	Disabled  bool                `yaml:"disabled"`
+	MaxSizeMB int                 `yaml:"max_size_mb"`
+	MaxAge    timeseries.Duration `yaml:"max_age"`
+}
+
 type Cache struct {
 	TTL        timeseries.Duration `yaml:"ttl"`
 	GCInterval timeseries.Duration `yaml:"gc_interval"`
@@ -189,8 +199,9 @@ func (p *Prometheus) Validate() error {
 }
 
 type Auth struct {
-	AnonymousRole          string `yaml:"anonymous_role"`
-	BootstrapAdminPassword string `yaml:"bootstrap_admin_password"`
+	AnonymousRole          string          `yaml:"anonymous_role"`
+	BootstrapAdminPassword string          `yaml:"bootstrap_admin_password"`
+	SSO                    *db.SSOSettings `yaml:"sso"`
 }
 
 func NewConfig() *Config {
@@ -226,6 +237,11 @@ func NewConfig() *Config {
 			UsageThresholdPercent: 70,
 			MinPartitions:         1,
 		},
+
+		CollectorSpool: CollectorSpool{
+			MaxSizeMB: 1024,
+			MaxAge:    24 * timeseries.Hour,
+		},
 	}
 	if !cfg.GRPC.Disabled && cfg.GRPC.ListenAddress == "" {
 		cfg.GRPC.ListenAddress = ":4317"
@@ -292,12 +308,22 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if !cfg.CollectorSpool.Disabled && (cfg.CollectorSpool.MaxSizeMB <= 0 || cfg.CollectorSpool.MaxAge <= 0) {
+		return fmt.Errorf("invalid collector_spool settings: max_size_mb and max_age must be positive")
+	}
+
 	if cfg.CorootCloud != nil {
 		if err = cfg.CorootCloud.Validate(); err != nil {
 			return fmt.Errorf("invalid corootCloud settings: %w", err)
 		}
 	}
 
+	if cfg.Auth.SSO != nil {
+		if err = cfg.Auth.SSO.Validate(); err != nil {
+			return fmt.Errorf("invalid auth.sso settings: %w", err)
+		}
+	}
+
 	for i, p := range cfg.Projects {
 		if err = p.Validate(); err != nil {
 			return fmt.Errorf("invalid project #%d: %w", i, err)
@@ -34,6 +34,9 @@ var (
 	clickHouseSpaceManagerDisabled              = kingpin.Flag("disable-clickhouse-space-manager", "If enabled, Coroot will manage ClickHouse disk space by removing old partitions").Envar("CLICKHOUSE_SPACE_MANAGER_DISABLED").Bool()
 	clickHouseSpaceManagerUsageThresholdPercent = kingpin.Flag("clickhouse-space-manager-usage-threshold", "Disk usage percentage threshold for triggering partition cleanup in ClickHouse").Envar("CLICKHOUSE_SPACE_MANAGER_USAGE_THRESHOLD").Int()
 	clickHouseSpaceManagerMinPartitions         = kingpin.Flag("clickhouse-space-manager-min-partitions", "Minimum number of partitions to keep when cleaning up ClickHouse disk space").Envar("CLICKHOUSE_SPACE_MANAGER_MIN_PARTITIONS").Int()
+	collectorSpoolDisabled                      = kingpin.Flag("disable-collector-spool", "Drop the telemetry that failed to be written to ClickHouse instead of spooling it to disk").Envar("COLLECTOR_SPOOL_DISABLED").Bool()
+	collectorSpoolMaxSizeMB                     = kingpin.Flag("collector-spool-max-size-mb", "Maximum size of the on-disk spool per project, in megabytes (default 1024)").Envar("COLLECTOR_SPOOL_MAX_SIZE_MB").Int()
+	collectorSpoolMaxAge                        = timeseries.DurationFlag(kingpin.Flag("collector-spool-max-age", "Maximum age of the spooled telemetry (e.g. 1h, 2d; default 24h)").Envar("COLLECTOR_SPOOL_MAX_AGE"))
 
 	globalClickhouseAddress         = kingpin.Flag("global-clickhouse-address", "").Envar("GLOBAL_CLICKHOUSE_ADDRESS").String()
 	globalClickhouseUser            = kingpin.Flag("global-clickhouse-user", "").Envar("GLOBAL_CLICKHOUSE_USER").String()
@@ -142,6 +145,15 @@ func (cfg *Config) ApplyFlags() {
 	if *clickHouseSpaceManagerMinPartitions > 0 {
 		cfg.ClickHouseSpaceManager.MinPartitions = *clickHouseSpaceManagerMinPartitions
 	}
+	if *collectorSpoolDisabled {
+		cfg.CollectorSpool.Disabled = true
+	}
+	if *collectorSpoolMaxSizeMB > 0 {
+		cfg.CollectorSpool.MaxSizeMB = *collectorSpoolMaxSizeMB
+	}
+	if *collectorSpoolMaxAge > 0 {
+		cfg.CollectorSpool.MaxAge = *collectorSpoolMaxAge
+	}
 
 	keep := cfg.GlobalClickhouse != nil || *globalClickhouseAddress != ""
 	if cfg.GlobalClickhouse == nil {
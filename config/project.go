@@ -241,6 +241,11 @@ func (c *ApplicationCategory) Validate() error {
 	if c.Name == "" {
 		return fmt.Errorf("name is required")
 	}
+	for i, w := range c.MaintenanceWindows {
+		if err := w.Validate(); err != nil {
+			return fmt.Errorf("invalid maintenance window #%d: %w", i, err)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,40 @@
+package constructor
+
+import (
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func cassandra(instance *model.Instance, queryName string, m *model.MetricValues) {
+	if instance == nil {
+		return
+	}
+	if instance.Cassandra == nil {
+		instance.Cassandra = model.NewCassandra()
+	}
+	c := instance.Cassandra
+	switch queryName {
+	case "cassandra_up":
+		c.Up = merge(c.Up, m.Values, timeseries.Any)
+	case "cassandra_scrape_error":
+		c.Error.Update(m.Values, m.Labels["error"])
+		c.Warning.Update(m.Values, m.Labels["warning"])
+	case "cassandra_info":
+		c.Version.Update(m.Values, m.Labels["version"])
+	case "cassandra_client_requests_total":
+		op := m.Labels["operation"]
+		c.Requests[op] = merge(c.Requests[op], m.Values, timeseries.Any)
+	case "cassandra_client_request_latency_seconds_total":
+		op := m.Labels["operation"]
+		c.RequestsTime[op] = merge(c.RequestsTime[op], m.Values, timeseries.Any)
+	case "cassandra_pending_compactions":
+		c.PendingCompactions = merge(c.PendingCompactions, m.Values, timeseries.Any)
+	case "cassandra_dropped_messages_total":
+		t := strings.ToUpper(m.Labels["message_type"])
+		c.DroppedMessages[t] = merge(c.DroppedMessages[t], m.Values, timeseries.Any)
+	case "cassandra_hints_total":
+		c.Hints = merge(c.Hints, m.Values, timeseries.Any)
+	}
+}
@@ -0,0 +1,38 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func clickhouse(instance *model.Instance, queryName string, m *model.MetricValues) {
+	if instance == nil {
+		return
+	}
+	if instance.ClickHouse == nil {
+		instance.ClickHouse = model.NewClickHouse()
+	}
+	ch := instance.ClickHouse
+	switch queryName {
+	case "clickhouse_replicas_queue_size":
+		ch.ReplicationQueueSize = merge(ch.ReplicationQueueSize, m.Values, timeseries.Any)
+	case "clickhouse_replicas_max_absolute_delay":
+		ch.ReplicationDelay = merge(ch.ReplicationDelay, m.Values, timeseries.Any)
+	case "clickhouse_readonly_replicas":
+		ch.ReadonlyReplicas = merge(ch.ReadonlyReplicas, m.Values, timeseries.Any)
+	case "clickhouse_max_part_count_for_partition":
+		ch.MaxPartsPerPartition = merge(ch.MaxPartsPerPartition, m.Values, timeseries.Any)
+	case "clickhouse_merges_running":
+		ch.MergesRunning = merge(ch.MergesRunning, m.Values, timeseries.Any)
+	case "clickhouse_merges_total":
+		ch.Merges = merge(ch.Merges, m.Values, timeseries.Any)
+	case "clickhouse_rejected_inserts_total":
+		ch.RejectedInserts = merge(ch.RejectedInserts, m.Values, timeseries.Any)
+	case "clickhouse_delayed_inserts_total":
+		ch.DelayedInserts = merge(ch.DelayedInserts, m.Values, timeseries.Any)
+	case "clickhouse_zookeeper_sessions":
+		ch.ZooKeeperSessions = merge(ch.ZooKeeperSessions, m.Values, timeseries.Any)
+	case "clickhouse_zookeeper_hardware_exceptions_total":
+		ch.ZooKeeperHardwareExceptions = merge(ch.ZooKeeperHardwareExceptions, m.Values, timeseries.Any)
+	}
+}
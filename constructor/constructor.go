@@ -243,6 +243,9 @@ func (c *Constructor) queryCache(ctx context.Context, cache Cache, project *db.P
 		addQuery(qRecordingRuleApplicationL7InboundRequests, qRecordingRuleApplicationL7InboundRequests, qRecordingRuleApplicationL7InboundRequests, true, nil)
 		addQuery(qRecordingRuleApplicationL7InboundHistogram, qRecordingRuleApplicationL7InboundHistogram, qRecordingRuleApplicationL7InboundHistogram, true, nil)
 	}
+	for _, q := range SpanMetricsQueries {
+		addQuery(q.Name, q.Name, q.Query, true, nil)
+	}
 	for appId := range checkConfigs {
 		qName := fmt.Sprintf("%s/%s/", qApplicationCustomSLI, appId)
 		availabilityCfg, _ := checkConfigs.GetAvailability(appId)
@@ -448,6 +451,30 @@ func enrichInstances(w *model.World, metrics map[string][]*model.MetricValues, r
 			case strings.HasPrefix(queryName, "mysql_"):
 				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeMysql)
 				mysql(instance, queryName, m)
+			case strings.HasPrefix(queryName, "kafka_"):
+				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeKafka)
+				kafka(instance, queryName, m)
+			case strings.HasPrefix(queryName, "rabbitmq_"):
+				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeRabbitmq)
+				rabbitmq(instance, queryName, m)
+			case strings.HasPrefix(queryName, "nats_"):
+				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeNats)
+				nats(instance, queryName, m)
+			case strings.HasPrefix(queryName, "elasticsearch_"):
+				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeElasticsearch, model.ApplicationTypeOpensearch)
+				elasticsearch(instance, queryName, m)
+			case strings.HasPrefix(queryName, "cassandra_"):
+				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeCassandra)
+				cassandra(instance, queryName, m)
+			case strings.HasPrefix(queryName, "clickhouse_"):
+				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeClickHouse)
+				clickhouse(instance, queryName, m)
+			case strings.HasPrefix(queryName, "nginx_"):
+				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeNginx)
+				proxy(instance, model.ApplicationTypeNginx, queryName, m)
+			case strings.HasPrefix(queryName, "envoy_"):
+				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeEnvoy)
+				proxy(instance, model.ApplicationTypeEnvoy, queryName, m)
 			}
 		}
 	}
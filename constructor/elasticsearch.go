@@ -0,0 +1,44 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func elasticsearch(instance *model.Instance, queryName string, m *model.MetricValues) {
+	if instance == nil {
+		return
+	}
+	if instance.Elasticsearch == nil {
+		instance.Elasticsearch = model.NewElasticsearch()
+	}
+	es := instance.Elasticsearch
+	switch queryName {
+	case "elasticsearch_cluster_health_status":
+		es.ClusterName.Update(m.Values, m.Labels["cluster"])
+		es.ClusterStatus.Update(m.Values, m.Labels["color"])
+	case "elasticsearch_cluster_health_number_of_nodes":
+		es.ClusterNodes = merge(es.ClusterNodes, m.Values, timeseries.Any)
+	case "elasticsearch_cluster_health_unassigned_shards":
+		es.UnassignedShards = merge(es.UnassignedShards, m.Values, timeseries.Any)
+	default:
+		n := es.GetOrCreateNode(m.Labels["name"])
+		switch queryName {
+		case "elasticsearch_jvm_heap_used_bytes":
+			n.HeapUsed = merge(n.HeapUsed, m.Values, timeseries.Any)
+		case "elasticsearch_jvm_heap_max_bytes":
+			n.HeapMax = merge(n.HeapMax, m.Values, timeseries.Any)
+		case "elasticsearch_thread_pool_rejected":
+			pool := m.Labels["type"]
+			n.RejectedTasks[pool] = merge(n.RejectedTasks[pool], m.Values, timeseries.Any)
+		case "elasticsearch_indexing_time":
+			n.IndexingTime = merge(n.IndexingTime, m.Values, timeseries.Any)
+		case "elasticsearch_indexing_total":
+			n.IndexingOps = merge(n.IndexingOps, m.Values, timeseries.Any)
+		case "elasticsearch_search_time":
+			n.SearchTime = merge(n.SearchTime, m.Values, timeseries.Any)
+		case "elasticsearch_search_total":
+			n.SearchOps = merge(n.SearchOps, m.Values, timeseries.Any)
+		}
+	}
+}
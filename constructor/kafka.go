@@ -0,0 +1,40 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func kafka(instance *model.Instance, queryName string, m *model.MetricValues) {
+	if instance == nil {
+		return
+	}
+	if instance.Kafka == nil {
+		instance.Kafka = model.NewKafka()
+	}
+	switch queryName {
+	case "kafka_brokers":
+		instance.Kafka.Brokers = merge(instance.Kafka.Brokers, m.Values, timeseries.Any)
+	case "kafka_controller_active_count":
+		instance.Kafka.ActiveControllers = merge(instance.Kafka.ActiveControllers, m.Values, timeseries.Any)
+	case "kafka_offline_partitions":
+		instance.Kafka.OfflinePartitions = merge(instance.Kafka.OfflinePartitions, m.Values, timeseries.Any)
+	case "kafka_under_replicated_partitions":
+		instance.Kafka.UnderReplicatedPartitions = merge(instance.Kafka.UnderReplicatedPartitions, m.Values, timeseries.Any)
+	case "kafka_topic_under_replicated_partitions":
+		topic := m.Labels["topic"]
+		instance.Kafka.TopicUnderReplicatedPartitions[topic] = merge(instance.Kafka.TopicUnderReplicatedPartitions[topic], m.Values, timeseries.Any)
+	case "kafka_topic_offline_partitions":
+		topic := m.Labels["topic"]
+		instance.Kafka.TopicOfflinePartitions[topic] = merge(instance.Kafka.TopicOfflinePartitions[topic], m.Values, timeseries.Any)
+	case "kafka_topic_produce_rate":
+		topic := m.Labels["topic"]
+		instance.Kafka.TopicProduceRate[topic] = merge(instance.Kafka.TopicProduceRate[topic], m.Values, timeseries.Any)
+	case "kafka_consumergroup_lag":
+		k := model.KafkaConsumerGroupKey{Group: m.Labels["consumergroup"], Topic: m.Labels["topic"]}
+		instance.Kafka.ConsumerGroupLag[k] = merge(instance.Kafka.ConsumerGroupLag[k], m.Values, timeseries.Any)
+	case "kafka_consumergroup_lag_seconds":
+		k := model.KafkaConsumerGroupKey{Group: m.Labels["group"], Topic: m.Labels["topic"]}
+		instance.Kafka.ConsumerGroupLagSeconds[k] = merge(instance.Kafka.ConsumerGroupLagSeconds[k], m.Values, timeseries.Any)
+	}
+}
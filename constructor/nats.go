@@ -0,0 +1,22 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func nats(instance *model.Instance, queryName string, m *model.MetricValues) {
+	if instance == nil {
+		return
+	}
+	if instance.Nats == nil {
+		instance.Nats = model.NewNats()
+	}
+	switch queryName {
+	case "nats_slow_consumers":
+		instance.Nats.SlowConsumers = merge(instance.Nats.SlowConsumers, m.Values, timeseries.Any)
+	case "nats_jetstream_consumer_pending":
+		k := model.NatsConsumerKey{Stream: m.Labels["stream_name"], Consumer: m.Labels["consumer_name"]}
+		instance.Nats.JetstreamPending[k] = merge(instance.Nats.JetstreamPending[k], m.Values, timeseries.Any)
+	}
+}
@@ -0,0 +1,47 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func proxy(instance *model.Instance, t model.ApplicationType, queryName string, m *model.MetricValues) {
+	if instance == nil {
+		return
+	}
+	if instance.Proxy == nil {
+		instance.Proxy = model.NewProxy(t)
+	}
+	p := instance.Proxy
+	switch queryName {
+	case "nginx_connections_active", "envoy_connections_active":
+		p.ActiveConnections = merge(p.ActiveConnections, m.Values, timeseries.Any)
+	case "nginx_connections_dropped", "envoy_connections_dropped":
+		p.DroppedConnections = merge(p.DroppedConnections, m.Values, timeseries.NanSum)
+	case "nginx_connections_limit":
+		p.ConnectionsLimit = merge(p.ConnectionsLimit, m.Values, timeseries.Any)
+	case "envoy_connections_usage":
+		p.ConnectionsUsage = merge(p.ConnectionsUsage, m.Values, timeseries.Any)
+	case "nginx_upstream_responses":
+		u := p.GetOrCreateUpstream(m.Labels["upstream"])
+		u.Requests = merge(u.Requests, m.Values, timeseries.NanSum)
+		if m.Labels["code"] == "5xx" {
+			u.Errors = merge(u.Errors, m.Values, timeseries.NanSum)
+		}
+	case "nginx_upstream_fails":
+		u := p.GetOrCreateUpstream(m.Labels["upstream"])
+		u.ConnectFailures = merge(u.ConnectFailures, m.Values, timeseries.NanSum)
+	case "envoy_upstream_requests":
+		u := p.GetOrCreateUpstream(m.Labels["envoy_cluster_name"])
+		u.Requests = merge(u.Requests, m.Values, timeseries.Any)
+	case "envoy_upstream_errors":
+		u := p.GetOrCreateUpstream(m.Labels["envoy_cluster_name"])
+		u.Errors = merge(u.Errors, m.Values, timeseries.Any)
+	case "envoy_upstream_connect_failures":
+		u := p.GetOrCreateUpstream(m.Labels["envoy_cluster_name"])
+		u.ConnectFailures = merge(u.ConnectFailures, m.Values, timeseries.Any)
+	case "envoy_upstream_overflows":
+		u := p.GetOrCreateUpstream(m.Labels["envoy_cluster_name"])
+		u.Overflows = merge(u.Overflows, m.Values, timeseries.Any)
+	}
+}
@@ -19,6 +19,8 @@ import (
 
 const (
 	qApplicationCustomSLI = "application_custom_sli"
+	qSpanMetricsRequests  = "span_metrics_requests"
+	qSpanMetricsHistogram = "span_metrics_histogram"
 
 	qRecordingRuleApplicationLogMessages        = "rr_application_log_messages"
 	qRecordingRuleApplicationTCPSuccessful      = "rr_connection_tcp_successful"
@@ -141,6 +143,13 @@ func l7InboundHistogram(metric string) string {
 	return fmt.Sprintf(`sum by(app_id, le) (rate(%s{app_id!=""}[$RANGE])) or rate(%s{app_id=""}[$RANGE])`, metric, metric)
 }
 
+// SpanMetricsQueries aggregate the RED metrics the collector derives from the server and consumer spans.
+// They are used as SLIs of the applications not covered by the agent, e.g., serverless functions.
+var SpanMetricsQueries = []Query{
+	Q(qSpanMetricsRequests, `sum by(service_name, status) (rate(coroot_span_requests_total[$RANGE]))`, "service_name", "status"),
+	Q(qSpanMetricsHistogram, `sum by(service_name, le) (rate(coroot_span_duration_seconds_bucket[$RANGE]))`, "service_name", "le"),
+}
+
 var QUERIES = []Query{
 	Q("node_agent_info", `node_agent_info`, "version"),
 
@@ -443,6 +452,75 @@ var QUERIES = []Query{
 	qDB("memcached_items_evicted_total", `rate(memcached_items_evicted_total[$RANGE])`),
 	qDB("memcached_commands_total", `rate(memcached_commands_total[$RANGE])`, "command", "status"),
 
+	qDB("kafka_brokers", `kafka_brokers`),
+	qDB("kafka_controller_active_count", `kafka_controller_kafkacontroller_activecontrollercount`),
+	qDB("kafka_offline_partitions", `kafka_controller_kafkacontroller_offlinepartitionscount`),
+	qDB("kafka_under_replicated_partitions", `kafka_server_replicamanager_underreplicatedpartitions`),
+	qDB("kafka_topic_under_replicated_partitions", `sum without(partition) (kafka_topic_partition_under_replicated_partition)`, "topic"),
+	qDB("kafka_topic_offline_partitions", `count without(partition) (kafka_topic_partition_leader == -1)`, "topic"),
+	qDB("kafka_topic_produce_rate", `sum without(partition) (rate(kafka_topic_partition_current_offset[$RANGE]))`, "topic"),
+	qDB("kafka_consumergroup_lag", `sum without(partition) (kafka_consumergroup_lag)`, "consumergroup", "topic"),
+	qDB("kafka_consumergroup_lag_seconds", `max without(partition) (kafka_consumergroup_group_lag_seconds)`, "group", "topic"),
+
+	qDB("rabbitmq_queue_messages_ready", `rabbitmq_queue_messages_ready`, "vhost", "queue"),
+	qDB("rabbitmq_queue_messages_unacked", `rabbitmq_queue_messages_unacked or rabbitmq_queue_messages_unacknowledged`, "vhost", "queue"),
+	qDB("rabbitmq_queue_consumers", `rabbitmq_queue_consumers`, "vhost", "queue"),
+	qDB("rabbitmq_memory_alarm", `rabbitmq_alarms_memory_used_watermark or rabbitmq_node_mem_alarm`, "node"),
+	qDB("rabbitmq_disk_alarm", `rabbitmq_alarms_free_disk_space_watermark or rabbitmq_node_disk_free_alarm`, "node"),
+	qDB("rabbitmq_partitions", `rabbitmq_partitions`, "node"),
+
+	qDB("nats_slow_consumers", `rate(gnatsd_varz_slow_consumers[$RANGE]) or rate(nats_varz_slow_consumers[$RANGE])`),
+	qDB("nats_jetstream_consumer_pending", `jetstream_consumer_num_pending`, "stream_name", "consumer_name"),
+
+	qDB("elasticsearch_cluster_health_status", `elasticsearch_cluster_health_status == 1`, "cluster", "color"),
+	qDB("elasticsearch_cluster_health_number_of_nodes", `elasticsearch_cluster_health_number_of_nodes`, "cluster"),
+	qDB("elasticsearch_cluster_health_unassigned_shards", `elasticsearch_cluster_health_unassigned_shards`, "cluster"),
+	qDB("elasticsearch_jvm_heap_used_bytes", `elasticsearch_jvm_memory_used_bytes{area="heap"}`, "name"),
+	qDB("elasticsearch_jvm_heap_max_bytes", `elasticsearch_jvm_memory_max_bytes{area="heap"}`, "name"),
+	qDB("elasticsearch_thread_pool_rejected", `rate(elasticsearch_thread_pool_rejected_count[$RANGE])`, "name", "type"),
+	qDB("elasticsearch_indexing_time", `rate(elasticsearch_indices_indexing_index_time_seconds_total[$RANGE])`, "name"),
+	qDB("elasticsearch_indexing_total", `rate(elasticsearch_indices_indexing_index_total[$RANGE])`, "name"),
+	qDB("elasticsearch_search_time", `rate(elasticsearch_indices_search_query_time_seconds[$RANGE])`, "name"),
+	qDB("elasticsearch_search_total", `rate(elasticsearch_indices_search_query_total[$RANGE])`, "name"),
+
+	qDB("cassandra_up", `cassandra_up`),
+	qDB("cassandra_scrape_error", `cassandra_scrape_error`, "error", "warning"),
+	qDB("cassandra_info", `cassandra_info`, "version"),
+	qDB("cassandra_client_requests_total", `rate(cassandra_client_requests_total[$RANGE])`, "operation"),
+	qDB("cassandra_client_request_latency_seconds_total", `rate(cassandra_client_request_latency_seconds_total[$RANGE])`, "operation"),
+	qDB("cassandra_pending_compactions", `cassandra_pending_compactions`),
+	qDB("cassandra_dropped_messages_total", `rate(cassandra_dropped_messages_total[$RANGE])`, "message_type"),
+	qDB("cassandra_hints_total", `rate(cassandra_hints_total[$RANGE])`),
+
+	// scraped by the agent from the built-in Prometheus endpoint of ClickHouse (port 9363 by default)
+	qDB("clickhouse_replicas_queue_size", `ClickHouseAsyncMetrics_ReplicasSumQueueSize`),
+	qDB("clickhouse_replicas_max_absolute_delay", `ClickHouseAsyncMetrics_ReplicasMaxAbsoluteDelay`),
+	qDB("clickhouse_readonly_replicas", `ClickHouseMetrics_ReadonlyReplica`),
+	qDB("clickhouse_max_part_count_for_partition", `ClickHouseAsyncMetrics_MaxPartCountForPartition`),
+	qDB("clickhouse_merges_running", `ClickHouseMetrics_Merge`),
+	qDB("clickhouse_merges_total", `rate(ClickHouseProfileEvents_Merge[$RANGE])`),
+	qDB("clickhouse_rejected_inserts_total", `rate(ClickHouseProfileEvents_RejectedInserts[$RANGE])`),
+	qDB("clickhouse_delayed_inserts_total", `rate(ClickHouseProfileEvents_DelayedInserts[$RANGE])`),
+	qDB("clickhouse_zookeeper_sessions", `ClickHouseMetrics_ZooKeeperSession`),
+	qDB("clickhouse_zookeeper_hardware_exceptions_total", `rate(ClickHouseProfileEvents_ZooKeeperHardwareExceptions[$RANGE])`),
+
+	qDB("nginx_connections_active", `nginx_connections_active or nginxplus_connections_active or nginx_vts_main_connections{status="active"}`),
+	qDB("nginx_connections_dropped", `(rate(nginx_connections_accepted[$RANGE]) - rate(nginx_connections_handled[$RANGE])) or rate(nginxplus_connections_dropped[$RANGE])`),
+	// neither Nginx nor its exporters report worker_connections, so the limit is expected to be exported
+	// as nginx_worker_connections_limit (worker_processes * worker_connections), e.g., using a recording rule
+	qDB("nginx_connections_limit", `nginx_worker_connections_limit`),
+	qDB("nginx_upstream_responses", `rate(nginxplus_upstream_server_responses[$RANGE]) or rate(nginx_vts_upstream_requests_total{code!="total"}[$RANGE])`, "upstream", "server", "backend", "code"),
+	qDB("nginx_upstream_fails", `rate(nginxplus_upstream_server_fails[$RANGE])`, "upstream", "server"),
+
+	qDB("envoy_connections_active", `envoy_server_total_connections`),
+	qDB("envoy_connections_dropped", `rate(envoy_listener_downstream_cx_overflow[$RANGE])`, "envoy_listener_address"),
+	// the pressure of the global downstream connection limit configured in the overload manager, in percent
+	qDB("envoy_connections_usage", `envoy_overload_envoy_resource_monitors_global_downstream_max_connections_pressure`),
+	qDB("envoy_upstream_requests", `rate(envoy_cluster_upstream_rq_total[$RANGE])`, "envoy_cluster_name"),
+	qDB("envoy_upstream_errors", `rate(envoy_cluster_upstream_rq_xx{envoy_response_code_class="5"}[$RANGE])`, "envoy_cluster_name"),
+	qDB("envoy_upstream_connect_failures", `rate(envoy_cluster_upstream_cx_connect_fail[$RANGE])`, "envoy_cluster_name"),
+	qDB("envoy_upstream_overflows", `rate(envoy_cluster_upstream_cx_overflow[$RANGE]) + rate(envoy_cluster_upstream_rq_pending_overflow[$RANGE]) + rate(envoy_cluster_upstream_rq_retry_overflow[$RANGE])`, "envoy_cluster_name"),
+
 	qJVM("container_jvm_info", `container_jvm_info`, "java_version"),
 	qJVM("container_jvm_heap_used_bytes", `container_jvm_heap_used_bytes`),
 	qJVM("container_jvm_gc_time_seconds", `rate(container_jvm_gc_time_seconds[$RANGE])`, "gc"),
@@ -0,0 +1,39 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func rabbitmq(instance *model.Instance, queryName string, m *model.MetricValues) {
+	if instance == nil {
+		return
+	}
+	if instance.Rabbitmq == nil {
+		instance.Rabbitmq = model.NewRabbitmq()
+	}
+	switch queryName {
+	case "rabbitmq_queue_messages_ready", "rabbitmq_queue_messages_unacked", "rabbitmq_queue_consumers":
+		if m.Labels["queue"] == "" { // aggregated metrics, per-object metrics are disabled
+			return
+		}
+		q := instance.Rabbitmq.GetOrCreateQueue(model.RabbitmqQueueKey{Vhost: m.Labels["vhost"], Queue: m.Labels["queue"]})
+		switch queryName {
+		case "rabbitmq_queue_messages_ready":
+			q.Ready = merge(q.Ready, m.Values, timeseries.Any)
+		case "rabbitmq_queue_messages_unacked":
+			q.Unacked = merge(q.Unacked, m.Values, timeseries.Any)
+		case "rabbitmq_queue_consumers":
+			q.Consumers = merge(q.Consumers, m.Values, timeseries.Any)
+		}
+	case "rabbitmq_memory_alarm":
+		node := m.Labels["node"]
+		instance.Rabbitmq.MemoryAlarm[node] = merge(instance.Rabbitmq.MemoryAlarm[node], m.Values, timeseries.Any)
+	case "rabbitmq_disk_alarm":
+		node := m.Labels["node"]
+		instance.Rabbitmq.DiskAlarm[node] = merge(instance.Rabbitmq.DiskAlarm[node], m.Values, timeseries.Any)
+	case "rabbitmq_partitions":
+		node := m.Labels["node"]
+		instance.Rabbitmq.Partitions[node] = merge(instance.Rabbitmq.Partitions[node], m.Values, timeseries.Any)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
 	"k8s.io/klog"
 )
 
@@ -16,6 +17,7 @@ func (c *Constructor) loadSLIs(w *model.World, metrics map[string][]*model.Metri
 	inboundLatencyRaw := inboundLatency(w, metrics[qRecordingRuleApplicationL7InboundHistogram+"_raw"], project)
 	builtinAvailabilityRaw := builtinAvailability(w, metrics[qRecordingRuleApplicationL7Requests+"_raw"], project)
 	builtinLatencyRaw := builtinLatency(w, metrics[qRecordingRuleApplicationL7Histogram+"_raw"], project)
+	spans := newSpanMetricsSLIs(w, metrics[qSpanMetricsRequests+"_raw"], metrics[qSpanMetricsHistogram+"_raw"])
 
 	customAvailabilityRaw := map[model.ApplicationId]availabilitySlis{}
 	customLatencyRaw := map[model.ApplicationId][]model.HistogramBucket{}
@@ -35,6 +37,9 @@ func (c *Constructor) loadSLIs(w *model.World, metrics map[string][]*model.Metri
 			if !ok || raw.total.IsEmpty() {
 				raw = builtinAvailabilityRaw[app.Id]
 			}
+			if raw.total.IsEmpty() {
+				raw = spans.availability(app)
+			}
 			if !raw.total.IsEmpty() {
 				app.AvailabilitySLIs = append(app.AvailabilitySLIs, &model.AvailabilitySLI{
 					Config:        availabilityCfg,
@@ -56,6 +61,9 @@ func (c *Constructor) loadSLIs(w *model.World, metrics map[string][]*model.Metri
 			if len(raw) == 0 {
 				raw = builtinLatencyRaw[app.Id]
 			}
+			if len(raw) == 0 {
+				raw = spans.latency(app)
+			}
 			if len(raw) > 0 {
 				app.LatencySLIs = append(app.LatencySLIs, &model.LatencySLI{
 					Config:    latencyCfg,
@@ -164,6 +172,84 @@ func loadCustomSLIs(metrics map[string][]*model.MetricValues,
 	}
 }
 
+// spanMetricsSLIs are the SLIs derived from the server and consumer spans by the collector.
+// They are used for the applications that have neither inbound nor outbound request metrics collected by the agent.
+type spanMetricsSLIs struct {
+	w         *model.World
+	services  []string
+	requests  map[string]map[string]*timeseries.TimeSeries
+	histogram map[string]map[string]*timeseries.TimeSeries
+}
+
+func newSpanMetricsSLIs(w *model.World, requests, histogram []*model.MetricValues) *spanMetricsSLIs {
+	s := &spanMetricsSLIs{
+		w:         w,
+		requests:  map[string]map[string]*timeseries.TimeSeries{},
+		histogram: map[string]map[string]*timeseries.TimeSeries{},
+	}
+	add := func(dst map[string]map[string]*timeseries.TimeSeries, mv *model.MetricValues, label string) {
+		service := mv.Labels["service_name"]
+		if service == "" || strings.HasPrefix(service, "/") { // eBPF-based spans
+			return
+		}
+		if dst[service] == nil {
+			dst[service] = map[string]*timeseries.TimeSeries{}
+		}
+		v := mv.Labels[label]
+		dst[service][v] = merge(dst[service][v], mv.Values, timeseries.NanSum)
+	}
+	for _, mv := range requests {
+		add(s.requests, mv, "status")
+	}
+	for _, mv := range histogram {
+		add(s.histogram, mv, "le")
+	}
+	services := utils.NewStringSet()
+	for service := range s.requests {
+		services.Add(service)
+	}
+	for service := range s.histogram {
+		services.Add(service)
+	}
+	s.services = services.Items()
+	return s
+}
+
+// service returns the service of the application in the same way as the tracing view does.
+func (s *spanMetricsSLIs) service(app *model.Application) string {
+	if len(s.services) == 0 {
+		return ""
+	}
+	if app.Settings != nil && app.Settings.Tracing != nil {
+		return app.Settings.Tracing.Service
+	}
+	return model.GuessService(s.services, s.w, app)
+}
+
+func (s *spanMetricsSLIs) availability(app *model.Application) availabilitySlis {
+	byStatus := s.requests[s.service(app)]
+	if len(byStatus) == 0 {
+		return availabilitySlis{}
+	}
+	total := timeseries.NewAggregate(timeseries.NanSum)
+	failed := timeseries.NewAggregate(timeseries.NanSum)
+	for status, ts := range byStatus {
+		total.Add(ts)
+		if model.IsRequestStatusFailed(status) {
+			failed.Add(ts)
+		}
+	}
+	return availabilitySlis{total: total.Get(), failed: failed.Get()}
+}
+
+func (s *spanMetricsSLIs) latency(app *model.Application) []model.HistogramBucket {
+	buckets := s.histogram[s.service(app)]
+	if len(buckets) == 0 {
+		return nil
+	}
+	return histogramBuckets(buckets)
+}
+
 type availabilitySlis struct {
 	total  *timeseries.TimeSeries
 	failed *timeseries.TimeSeries
@@ -75,11 +75,23 @@ func (a *Alert) Migrate(m *Migrator) error {
 		resolved_by TEXT NOT NULL DEFAULT '',
 		report TEXT NOT NULL DEFAULT '',
 		pattern_words TEXT NOT NULL DEFAULT '',
-		manually_resolved_at INT NOT NULL DEFAULT 0
+		manually_resolved_at INT NOT NULL DEFAULT 0,
+		acknowledged_at INT NOT NULL DEFAULT 0,
+		acknowledged_by TEXT NOT NULL DEFAULT '',
+		silence_id TEXT NOT NULL DEFAULT ''
 	)`)
 	if err != nil {
 		return err
 	}
+	if err = m.AddColumnIfNotExists("alert", "acknowledged_at", "INT NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err = m.AddColumnIfNotExists("alert", "acknowledged_by", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err = m.AddColumnIfNotExists("alert", "silence_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
 	if err = m.Exec(`CREATE INDEX IF NOT EXISTS alert_project_resolved ON alert (project_id, resolved_at)`); err != nil {
 		return err
 	}
@@ -355,7 +367,7 @@ func (db *DB) QueryAlerts(projectId ProjectId, q AlertsQuery) (*AlertsResult, er
 	}
 
 	query := fmt.Sprintf(`
-		SELECT alert.id, alert.fingerprint, alert.rule_id, alert.application_id, alert.application_category, alert.severity, alert.summary, alert.details, alert.opened_at, alert.resolved_at, alert.updated_at, alert.suppressed, alert.resolved_by, alert.report, alert.pattern_words, alert.manually_resolved_at
+		SELECT alert.id, alert.fingerprint, alert.rule_id, alert.application_id, alert.application_category, alert.severity, alert.summary, alert.details, alert.opened_at, alert.resolved_at, alert.updated_at, alert.suppressed, alert.resolved_by, alert.report, alert.pattern_words, alert.manually_resolved_at, alert.acknowledged_at, alert.acknowledged_by, alert.silence_id
 		FROM alert%s
 		WHERE %s
 		ORDER BY %s
@@ -383,7 +395,7 @@ func (db *DB) QueryAlerts(projectId ProjectId, q AlertsQuery) (*AlertsResult, er
 
 func (db *DB) GetAlert(projectId ProjectId, id string) (*model.Alert, error) {
 	row := db.db.QueryRow(
-		"SELECT id, fingerprint, rule_id, application_id, application_category, severity, summary, details, opened_at, resolved_at, updated_at, suppressed, resolved_by, report, pattern_words, manually_resolved_at FROM alert WHERE project_id = $1 AND id = $2",
+		"SELECT id, fingerprint, rule_id, application_id, application_category, severity, summary, details, opened_at, resolved_at, updated_at, suppressed, resolved_by, report, pattern_words, manually_resolved_at, acknowledged_at, acknowledged_by, silence_id FROM alert WHERE project_id = $1 AND id = $2",
 		projectId, id)
 
 	a, err := scanAlertRow(row, projectId)
@@ -395,7 +407,7 @@ func (db *DB) GetAlert(projectId ProjectId, id string) (*model.Alert, error) {
 
 func (db *DB) GetActiveOrSuppressedAlertByFingerprint(projectId ProjectId, fingerprint string) (*model.Alert, error) {
 	row := db.db.QueryRow(
-		"SELECT id, fingerprint, rule_id, application_id, application_category, severity, summary, details, opened_at, resolved_at, updated_at, suppressed, resolved_by, report, pattern_words, manually_resolved_at FROM alert WHERE project_id = $1 AND fingerprint = $2 AND (resolved_at = 0 OR suppressed = 1) ORDER BY opened_at DESC LIMIT 1",
+		"SELECT id, fingerprint, rule_id, application_id, application_category, severity, summary, details, opened_at, resolved_at, updated_at, suppressed, resolved_by, report, pattern_words, manually_resolved_at, acknowledged_at, acknowledged_by, silence_id FROM alert WHERE project_id = $1 AND fingerprint = $2 AND (resolved_at = 0 OR suppressed = 1) ORDER BY opened_at DESC LIMIT 1",
 		projectId, fingerprint)
 
 	a, err := scanAlertRow(row, projectId)
@@ -411,8 +423,8 @@ func (db *DB) CreateAlert(projectId ProjectId, a *model.Alert) error {
 	a.UpdatedAt = now
 	detailsJSON, _ := json.Marshal(a.Details)
 	_, err := db.db.Exec(
-		"INSERT INTO alert (id, fingerprint, rule_id, project_id, application_id, application_category, severity, summary, details, opened_at, resolved_at, updated_at, suppressed, resolved_by, report, pattern_words, manually_resolved_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)",
-		a.Id, a.Fingerprint, a.RuleId, projectId, a.ApplicationId.String(), a.ApplicationCategory, a.Severity.String(), a.Summary, string(detailsJSON), a.OpenedAt, a.ResolvedAt, a.UpdatedAt, boolToInt(a.Suppressed), a.ResolvedBy, a.Report, a.PatternWords, a.ManuallyResolvedAt)
+		"INSERT INTO alert (id, fingerprint, rule_id, project_id, application_id, application_category, severity, summary, details, opened_at, resolved_at, updated_at, suppressed, resolved_by, report, pattern_words, manually_resolved_at, silence_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)",
+		a.Id, a.Fingerprint, a.RuleId, projectId, a.ApplicationId.String(), a.ApplicationCategory, a.Severity.String(), a.Summary, string(detailsJSON), a.OpenedAt, a.ResolvedAt, a.UpdatedAt, boolToInt(a.Suppressed), a.ResolvedBy, a.Report, a.PatternWords, a.ManuallyResolvedAt, a.SilenceId)
 	return err
 }
 
@@ -420,8 +432,8 @@ func (db *DB) UpdateAlert(projectId ProjectId, a *model.Alert) error {
 	a.UpdatedAt = timeseries.Now()
 	detailsJSON, _ := json.Marshal(a.Details)
 	_, err := db.db.Exec(
-		"UPDATE alert SET severity = $1, summary = $2, details = $3, resolved_at = $4, updated_at = $5 WHERE project_id = $6 AND id = $7",
-		a.Severity.String(), a.Summary, string(detailsJSON), a.ResolvedAt, a.UpdatedAt, projectId, a.Id)
+		"UPDATE alert SET severity = $1, summary = $2, details = $3, resolved_at = $4, updated_at = $5, silence_id = $6 WHERE project_id = $7 AND id = $8",
+		a.Severity.String(), a.Summary, string(detailsJSON), a.ResolvedAt, a.UpdatedAt, a.SilenceId, projectId, a.Id)
 	return err
 }
 
@@ -435,7 +447,7 @@ func (db *DB) ResolveAlert(projectId ProjectId, id string, resolvedAt timeseries
 func (db *DB) ResolveAlertsByRule(projectId ProjectId, ruleId string) ([]*model.Alert, error) {
 	now := timeseries.Now()
 	rows, err := db.db.Query(`
-		SELECT id, fingerprint, rule_id, application_id, application_category, severity, summary, details, opened_at, resolved_at, updated_at, suppressed, resolved_by, report, pattern_words, manually_resolved_at
+		SELECT id, fingerprint, rule_id, application_id, application_category, severity, summary, details, opened_at, resolved_at, updated_at, suppressed, resolved_by, report, pattern_words, manually_resolved_at, acknowledged_at, acknowledged_by, silence_id
 		FROM alert
 		WHERE project_id = $1 AND rule_id = $2 AND resolved_at = 0
 	`, projectId, ruleId)
@@ -497,6 +509,49 @@ func (db *DB) SuppressAlerts(projectId ProjectId, ids []string, suppressedBy str
 	return err
 }
 
+// AcknowledgeAlerts marks the firing alerts as acknowledged, which stops their escalation.
+// Alerts that are already acknowledged keep the original acknowledgement.
+func (db *DB) AcknowledgeAlerts(projectId ProjectId, ids []string, acknowledgedBy string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	now := timeseries.Now()
+	placeholders := make([]string, len(ids))
+	args := []any{now, acknowledgedBy, projectId}
+	for i, id := range ids {
+		args = append(args, id)
+		placeholders[i] = fmt.Sprintf("$%d", len(args))
+	}
+	_, err := db.db.Exec(
+		"UPDATE alert SET acknowledged_at = $1, acknowledged_by = $2, updated_at = $1 WHERE project_id = $3 AND resolved_at = 0 AND manually_resolved_at = 0 AND suppressed = 0 AND acknowledged_at = 0 AND id IN ("+strings.Join(placeholders, ", ")+")",
+		args...)
+	return err
+}
+
+// GetUnacknowledgedAlerts returns the firing alerts that were opened before the given time and haven't been acknowledged.
+// Silenced alerts are never escalated.
+func (db *DB) GetUnacknowledgedAlerts(projectId ProjectId, openedBefore timeseries.Time) ([]*model.Alert, error) {
+	rows, err := db.db.Query(`
+		SELECT id, fingerprint, rule_id, application_id, application_category, severity, summary, details, opened_at, resolved_at, updated_at, suppressed, resolved_by, report, pattern_words, manually_resolved_at, acknowledged_at, acknowledged_by, silence_id
+		FROM alert
+		WHERE project_id = $1 AND resolved_at = 0 AND manually_resolved_at = 0 AND suppressed = 0 AND acknowledged_at = 0 AND silence_id = '' AND opened_at <= $2
+		ORDER BY opened_at
+	`, projectId, openedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var alerts []*model.Alert
+	for rows.Next() {
+		alert, err := scanAlert(rows, projectId)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, rows.Err()
+}
+
 func (db *DB) ClearSuppression(projectId ProjectId, fingerprint string) error {
 	_, err := db.db.Exec(
 		"UPDATE alert SET suppressed = 0, resolved_by = '' WHERE project_id = $1 AND fingerprint = $2 AND suppressed = 1",
@@ -516,7 +571,7 @@ func (db *DB) ReopenAlerts(projectId ProjectId, ids []string) (int, error) {
 		placeholders[i] = fmt.Sprintf("$%d", len(args))
 	}
 	result, err := db.db.Exec(
-		"UPDATE alert SET manually_resolved_at = 0, resolved_by = '', suppressed = 0, updated_at = $1 WHERE project_id = $2 AND (manually_resolved_at > 0 OR suppressed = 1) AND id IN ("+strings.Join(placeholders, ", ")+")",
+		"UPDATE alert SET manually_resolved_at = 0, resolved_by = '', suppressed = 0, acknowledged_at = 0, acknowledged_by = '', updated_at = $1 WHERE project_id = $2 AND (manually_resolved_at > 0 OR suppressed = 1) AND id IN ("+strings.Join(placeholders, ", ")+")",
 		args...)
 	if err != nil {
 		return 0, err
@@ -532,7 +587,7 @@ func scanAlert(rows *sql.Rows, projectId ProjectId) (*model.Alert, error) {
 	var a model.Alert
 	var severityStr string
 	var detailsJSON sql.NullString
-	err := rows.Scan(&a.Id, &a.Fingerprint, &a.RuleId, &a.ApplicationId, &a.ApplicationCategory, &severityStr, &a.Summary, &detailsJSON, &a.OpenedAt, &a.ResolvedAt, &a.UpdatedAt, &a.Suppressed, &a.ResolvedBy, &a.Report, &a.PatternWords, &a.ManuallyResolvedAt)
+	err := rows.Scan(&a.Id, &a.Fingerprint, &a.RuleId, &a.ApplicationId, &a.ApplicationCategory, &severityStr, &a.Summary, &detailsJSON, &a.OpenedAt, &a.ResolvedAt, &a.UpdatedAt, &a.Suppressed, &a.ResolvedBy, &a.Report, &a.PatternWords, &a.ManuallyResolvedAt, &a.AcknowledgedAt, &a.AcknowledgedBy, &a.SilenceId)
 	if err != nil {
 		return nil, err
 	}
@@ -548,7 +603,7 @@ func scanAlertRow(row *sql.Row, projectId ProjectId) (*model.Alert, error) {
 	var a model.Alert
 	var severityStr string
 	var detailsJSON sql.NullString
-	err := row.Scan(&a.Id, &a.Fingerprint, &a.RuleId, &a.ApplicationId, &a.ApplicationCategory, &severityStr, &a.Summary, &detailsJSON, &a.OpenedAt, &a.ResolvedAt, &a.UpdatedAt, &a.Suppressed, &a.ResolvedBy, &a.Report, &a.PatternWords, &a.ManuallyResolvedAt)
+	err := row.Scan(&a.Id, &a.Fingerprint, &a.RuleId, &a.ApplicationId, &a.ApplicationCategory, &severityStr, &a.Summary, &detailsJSON, &a.OpenedAt, &a.ResolvedAt, &a.UpdatedAt, &a.Suppressed, &a.ResolvedBy, &a.Report, &a.PatternWords, &a.ManuallyResolvedAt, &a.AcknowledgedAt, &a.AcknowledgedBy, &a.SilenceId)
 	if err != nil {
 		return nil, err
 	}
@@ -611,7 +666,7 @@ func (db *DB) ClearAlertingRulesReadonly(projectId ProjectId) error {
 
 func (db *DB) GetLatestAlertsByRule(projectId ProjectId, ruleId string) ([]*model.Alert, error) {
 	rows, err := db.db.Query(`
-		SELECT id, fingerprint, rule_id, application_id, application_category, severity, summary, details, opened_at, resolved_at, updated_at, suppressed, resolved_by, report, pattern_words, manually_resolved_at
+		SELECT id, fingerprint, rule_id, application_id, application_category, severity, summary, details, opened_at, resolved_at, updated_at, suppressed, resolved_by, report, pattern_words, manually_resolved_at, acknowledged_at, acknowledged_by, silence_id
 		FROM alert
 		WHERE project_id = $1 AND rule_id = $2 AND resolved_at = 0
 		ORDER BY opened_at DESC
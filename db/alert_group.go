@@ -0,0 +1,272 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+const (
+	AlertGroupByRule      = "rule"
+	AlertGroupByNode      = "node"
+	AlertGroupByNamespace = "namespace"
+	AlertGroupByCategory  = "category"
+
+	defaultAlertGroupWait      = 30 * timeseries.Second
+	defaultAlertGroupInterval  = 5 * timeseries.Minute
+	defaultAlertRepeatInterval = 4 * timeseries.Hour
+)
+
+var AlertGroupByLabels = []string{AlertGroupByRule, AlertGroupByNode, AlertGroupByNamespace, AlertGroupByCategory}
+
+// ApplicationCategoryAlertGrouping configures Alertmanager-style grouping of alert notifications.
+// Alerts with the same values of the By labels are sent as a single digest message:
+// the first one after GroupWait, then no more often than GroupInterval while the group changes,
+// and again every RepeatInterval while any of its alerts is firing. Zero durations mean the defaults.
+type ApplicationCategoryAlertGrouping struct {
+	Enabled        bool                `json:"enabled" yaml:"enabled"`
+	By             []string            `json:"by" yaml:"by"`
+	GroupWait      timeseries.Duration `json:"group_wait" yaml:"groupWait,omitempty"`
+	GroupInterval  timeseries.Duration `json:"group_interval" yaml:"groupInterval,omitempty"`
+	RepeatInterval timeseries.Duration `json:"repeat_interval" yaml:"repeatInterval,omitempty"`
+}
+
+func (g *ApplicationCategoryAlertGrouping) Validate() error {
+	for _, l := range g.By {
+		if !slices.Contains(AlertGroupByLabels, l) {
+			return fmt.Errorf("unknown grouping label: %s", l)
+		}
+	}
+	if g.GroupWait < 0 || g.GroupInterval < 0 || g.RepeatInterval < 0 {
+		return fmt.Errorf("durations must not be negative")
+	}
+	return nil
+}
+
+func (g *ApplicationCategoryAlertGrouping) intervals() (wait, interval, repeat timeseries.Duration) {
+	wait, interval, repeat = defaultAlertGroupWait, defaultAlertGroupInterval, defaultAlertRepeatInterval
+	if g == nil {
+		return
+	}
+	if g.GroupWait > 0 {
+		wait = g.GroupWait
+	}
+	if g.GroupInterval > 0 {
+		interval = g.GroupInterval
+	}
+	if g.RepeatInterval > 0 {
+		repeat = g.RepeatInterval
+	}
+	return
+}
+
+// AlertGroup is the notification state of a group of alerts for a single destination.
+// Version is incremented on every membership change, and SentVersion is the version that was last sent.
+type AlertGroup struct {
+	ProjectId   ProjectId
+	Key         string
+	Destination IncidentNotificationDestination
+	Category    model.ApplicationCategory
+	Labels      map[string]string
+	Members     map[string]*AlertGroupMember
+	CreatedAt   timeseries.Time
+	SentAt      timeseries.Time
+	Version     int
+	SentVersion int
+	ExternalKey string
+}
+
+type AlertGroupMember struct {
+	AlertId       string                    `json:"alert_id"`
+	RuleId        string                    `json:"rule_id"`
+	ApplicationId model.ApplicationId       `json:"application_id"`
+	Status        model.Status              `json:"status"`
+	OpenedAt      timeseries.Time           `json:"opened_at"`
+	ResolvedAt    timeseries.Time           `json:"resolved_at,omitempty"`
+	Details       *AlertNotificationDetails `json:"details,omitempty"`
+}
+
+func (m *AlertGroupMember) Resolved() bool {
+	return m.ResolvedAt > 0
+}
+
+func AlertGroupKey(labels map[string]string) string {
+	parts := make([]string, 0, len(labels))
+	for k, v := range labels {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// Id returns an identifier of this particular occurrence of the group,
+// suitable for deduplicating it in incident management systems.
+func (g *AlertGroup) Id() string {
+	return fmt.Sprintf("%s:group:%s:%d", g.ProjectId, g.Key, g.CreatedAt)
+}
+
+// Status returns the highest severity among the firing members, or OK if all of them are resolved.
+func (g *AlertGroup) Status() model.Status {
+	status := model.OK
+	for _, m := range g.Members {
+		if !m.Resolved() && m.Status > status {
+			status = m.Status
+		}
+	}
+	return status
+}
+
+func (g *AlertGroup) Firing() int {
+	n := 0
+	for _, m := range g.Members {
+		if !m.Resolved() {
+			n++
+		}
+	}
+	return n
+}
+
+// SortedMembers returns the firing members first, ordered by severity, then the resolved ones.
+func (g *AlertGroup) SortedMembers() []*AlertGroupMember {
+	res := make([]*AlertGroupMember, 0, len(g.Members))
+	for _, m := range g.Members {
+		res = append(res, m)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		mi, mj := res[i], res[j]
+		if mi.Resolved() != mj.Resolved() {
+			return !mi.Resolved()
+		}
+		if mi.Status != mj.Status {
+			return mi.Status > mj.Status
+		}
+		if mi.OpenedAt != mj.OpenedAt {
+			return mi.OpenedAt < mj.OpenedAt
+		}
+		return mi.AlertId < mj.AlertId
+	})
+	return res
+}
+
+// Due reports whether the group needs to be sent at now, and whether it is a repeat of an unchanged group.
+func (g *AlertGroup) Due(settings *ApplicationCategoryAlertGrouping, now timeseries.Time) (send bool, repeat bool) {
+	wait, interval, repeatInterval := settings.intervals()
+	switch {
+	case g.SentAt.IsZero():
+		return !now.Before(g.CreatedAt.Add(wait)), false
+	case g.Version != g.SentVersion:
+		return !now.Before(g.SentAt.Add(interval)), false
+	case g.Firing() > 0 && !now.Before(g.SentAt.Add(repeatInterval)):
+		return true, true
+	}
+	return false, false
+}
+
+func (g *AlertGroup) Migrate(m *Migrator) error {
+	return m.Exec(`
+	CREATE TABLE IF NOT EXISTS alert_group (
+		project_id TEXT NOT NULL REFERENCES project(id),
+		key TEXT NOT NULL,
+		destination TEXT NOT NULL,
+		category TEXT NOT NULL,
+		labels TEXT NOT NULL DEFAULT '{}',
+		members TEXT NOT NULL DEFAULT '{}',
+		created_at INT NOT NULL,
+		sent_at INT NOT NULL DEFAULT 0,
+		version INT NOT NULL DEFAULT 0,
+		sent_version INT NOT NULL DEFAULT 0,
+		external_key TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (project_id, key, destination)
+	)`)
+}
+
+func (db *DB) GetAlertGroups(projectId ProjectId) ([]*AlertGroup, error) {
+	query := "SELECT project_id, key, destination, category, labels, members, created_at, sent_at, version, sent_version, external_key FROM alert_group"
+	var args []any
+	if projectId != "" {
+		query += " WHERE project_id = $1"
+		args = append(args, projectId)
+	}
+	rows, err := db.db.Query(query+" ORDER BY project_id, created_at", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []*AlertGroup
+	for rows.Next() {
+		g, err := scanAlertGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, g)
+	}
+	return res, rows.Err()
+}
+
+func (db *DB) GetAlertGroup(projectId ProjectId, key string, destination IncidentNotificationDestination) (*AlertGroup, error) {
+	row := db.db.QueryRow(
+		"SELECT project_id, key, destination, category, labels, members, created_at, sent_at, version, sent_version, external_key FROM alert_group WHERE project_id = $1 AND key = $2 AND destination = $3",
+		projectId, key, destination)
+	g, err := scanAlertGroup(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return g, err
+}
+
+func (db *DB) PutAlertGroup(g *AlertGroup) error {
+	labels, err := json.Marshal(g.Labels)
+	if err != nil {
+		return err
+	}
+	members, err := json.Marshal(g.Members)
+	if err != nil {
+		return err
+	}
+	res, err := db.db.Exec(
+		"UPDATE alert_group SET category = $1, labels = $2, members = $3, sent_at = $4, version = $5, sent_version = $6, external_key = $7 WHERE project_id = $8 AND key = $9 AND destination = $10",
+		g.Category, string(labels), string(members), g.SentAt, g.Version, g.SentVersion, g.ExternalKey, g.ProjectId, g.Key, g.Destination)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if rowsAffected > 0 {
+		return nil
+	}
+	_, err = db.db.Exec(
+		"INSERT INTO alert_group (project_id, key, destination, category, labels, members, created_at, sent_at, version, sent_version, external_key) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)",
+		g.ProjectId, g.Key, g.Destination, g.Category, string(labels), string(members), g.CreatedAt, g.SentAt, g.Version, g.SentVersion, g.ExternalKey)
+	return err
+}
+
+func (db *DB) DeleteAlertGroup(g *AlertGroup) error {
+	_, err := db.db.Exec("DELETE FROM alert_group WHERE project_id = $1 AND key = $2 AND destination = $3", g.ProjectId, g.Key, g.Destination)
+	return err
+}
+
+func scanAlertGroup(row interface{ Scan(...any) error }) (*AlertGroup, error) {
+	var g AlertGroup
+	var labels, members string
+	err := row.Scan(&g.ProjectId, &g.Key, &g.Destination, &g.Category, &labels, &members, &g.CreatedAt, &g.SentAt, &g.Version, &g.SentVersion, &g.ExternalKey)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(labels), &g.Labels); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(members), &g.Members); err != nil {
+		return nil, err
+	}
+	if g.Members == nil {
+		g.Members = map[string]*AlertGroupMember{}
+	}
+	return &g, nil
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertGroupDue(t *testing.T) {
+	settings := &ApplicationCategoryAlertGrouping{Enabled: true, GroupWait: 30, GroupInterval: 300, RepeatInterval: 3600}
+	g := &AlertGroup{
+		CreatedAt: 1000,
+		Version:   1,
+		Members:   map[string]*AlertGroupMember{"a1": {AlertId: "a1", Status: model.CRITICAL}},
+	}
+
+	check := func(now timeseries.Time, send, repeat bool) {
+		t.Helper()
+		s, r := g.Due(settings, now)
+		assert.Equal(t, send, s)
+		assert.Equal(t, repeat, r)
+	}
+
+	check(1029, false, false)
+	check(1030, true, false)
+
+	g.SentAt, g.SentVersion = 1030, 1
+	check(1100, false, false)
+
+	g.Members["a2"] = &AlertGroupMember{AlertId: "a2", Status: model.WARNING}
+	g.Version++
+	check(1100, false, false)
+	check(1330, true, false)
+
+	g.SentAt, g.SentVersion = 1330, 2
+	check(4929, false, false)
+	check(4930, true, true)
+
+	g.Members["a1"].ResolvedAt = 2000
+	g.Members["a2"].ResolvedAt = 2000
+	check(4930, false, false)
+
+	g.Members["a1"].ResolvedAt = 0
+	s, _ := g.Due(nil, g.SentAt.Add(defaultAlertRepeatInterval))
+	assert.True(t, s)
+}
+
+func TestAlertGroupStatus(t *testing.T) {
+	g := &AlertGroup{Members: map[string]*AlertGroupMember{
+		"a1": {AlertId: "a1", Status: model.WARNING, OpenedAt: 10},
+		"a2": {AlertId: "a2", Status: model.CRITICAL, OpenedAt: 20, ResolvedAt: 30},
+		"a3": {AlertId: "a3", Status: model.WARNING, OpenedAt: 5},
+	}}
+	assert.Equal(t, model.WARNING, g.Status())
+	assert.Equal(t, 2, g.Firing())
+	var ids []string
+	for _, m := range g.SortedMembers() {
+		ids = append(ids, m.AlertId)
+	}
+	assert.Equal(t, []string{"a3", "a1", "a2"}, ids)
+
+	g.Members["a1"].ResolvedAt = 40
+	g.Members["a3"].ResolvedAt = 40
+	assert.Equal(t, model.OK, g.Status())
+
+	assert.Equal(t, "node=n1,rule=r1", AlertGroupKey(map[string]string{"rule": "r1", "node": "n1"}))
+	assert.Error(t, (&ApplicationCategoryAlertGrouping{By: []string{"pod"}}).Validate())
+	assert.NoError(t, (&ApplicationCategoryAlertGrouping{By: AlertGroupByLabels}).Validate())
+}
+
+func TestAlertGroupStorage(t *testing.T) {
+	db, err := NewSqlite(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate())
+	p := &Project{Name: "p"}
+	require.NoError(t, db.SaveProject(p))
+	id := p.Id
+
+	slack := IncidentNotificationDestination{IntegrationType: IntegrationTypeSlack, SlackChannel: "ops"}
+	g := &AlertGroup{
+		ProjectId:   id,
+		Key:         "node=n1",
+		Destination: slack,
+		Category:    model.ApplicationCategoryApplication,
+		Labels:      map[string]string{"node": "n1"},
+		Members: map[string]*AlertGroupMember{
+			"a1": {AlertId: "a1", ApplicationId: model.NewApplicationId(string(id), "default", model.ApplicationKindDeployment, "app"), Status: model.CRITICAL,
+				Details: &AlertNotificationDetails{Summary: "down"}},
+		},
+		CreatedAt: 100,
+		Version:   1,
+	}
+	require.NoError(t, db.PutAlertGroup(g))
+
+	_, err = db.GetAlertGroup(id, "node=n1", IncidentNotificationDestination{IntegrationType: IntegrationTypePagerduty})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	res, err := db.GetAlertGroup(id, "node=n1", slack)
+	require.NoError(t, err)
+	assert.Equal(t, g, res)
+
+	res.SentAt, res.SentVersion, res.ExternalKey = 200, 1, "C1:123.456"
+	require.NoError(t, db.PutAlertGroup(res))
+	groups, err := db.GetAlertGroups("")
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "C1:123.456", groups[0].ExternalKey)
+	assert.Equal(t, timeseries.Time(100), groups[0].CreatedAt)
+
+	require.NoError(t, db.DeleteAlertGroup(res))
+	groups, err = db.GetAlertGroups(id)
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcknowledgeAlerts(t *testing.T) {
+	db, err := NewSqlite(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate())
+	p := &Project{Name: "p"}
+	require.NoError(t, db.SaveProject(p))
+	id := p.Id
+
+	for _, a := range []string{"a1", "a2", "a3"} {
+		require.NoError(t, db.CreateAlert(id, &model.Alert{Id: a, Fingerprint: a, RuleId: "r", Severity: model.WARNING}))
+	}
+	require.NoError(t, db.ResolveAlerts(id, []string{"a3"}, "user"))
+
+	now := timeseries.Now()
+	alerts, err := db.GetUnacknowledgedAlerts(id, now)
+	require.NoError(t, err)
+	assert.Len(t, alerts, 2)
+	alerts, err = db.GetUnacknowledgedAlerts(id, now.Add(-timeseries.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	require.NoError(t, db.AcknowledgeAlerts(id, []string{"a1", "a3"}, "alice"))
+	require.NoError(t, db.AcknowledgeAlerts(id, []string{"a1"}, "bob"))
+
+	a, err := db.GetAlert(id, "a1")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", a.AcknowledgedBy)
+	assert.False(t, a.AcknowledgedAt.IsZero())
+	a, err = db.GetAlert(id, "a3")
+	require.NoError(t, err)
+	assert.True(t, a.AcknowledgedAt.IsZero(), "resolved alerts can't be acknowledged")
+
+	alerts, err = db.GetUnacknowledgedAlerts(id, now)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "a2", alerts[0].Id)
+}
+
+func TestAlertEscalationValidate(t *testing.T) {
+	pagerduty := ApplicationCategoryNotificationDestinations{Pagerduty: &ApplicationCategoryNotificationSettingsPagerduty{Enabled: true}}
+	e := &ApplicationCategoryAlertEscalation{Enabled: true, Steps: []ApplicationCategoryAlertEscalationStep{
+		{Delay: 15 * timeseries.Minute, ApplicationCategoryNotificationDestinations: pagerduty},
+	}}
+	assert.NoError(t, e.Validate())
+	assert.Len(t, e.ActiveSteps(), 1)
+
+	e.Steps = append(e.Steps, ApplicationCategoryAlertEscalationStep{ApplicationCategoryNotificationDestinations: pagerduty})
+	assert.Error(t, e.Validate())
+	e.Steps[1] = ApplicationCategoryAlertEscalationStep{Delay: timeseries.Hour}
+	assert.Error(t, e.Validate())
+
+	e.Enabled = false
+	assert.Empty(t, e.ActiveSteps())
+	e = nil
+	assert.Empty(t, e.ActiveSteps())
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+const (
+	apiKeyUsageUpdateInterval = timeseries.Minute
+)
+
+var (
+	emptyApiKey = strings.Repeat("0", 32)
+
+	ErrApiKeyExpired   = errors.New("the API key has expired")
+	ErrApiKeyForbidden = errors.New("the API key is not allowed to access this endpoint")
+)
+
+type ApiKeyScope string
+
+const (
+	ApiKeyScopeAll            ApiKeyScope = "*"
+	ApiKeyScopeIngestMetrics  ApiKeyScope = "ingest:metrics"
+	ApiKeyScopeIngestTraces   ApiKeyScope = "ingest:traces"
+	ApiKeyScopeIngestLogs     ApiKeyScope = "ingest:logs"
+	ApiKeyScopeIngestProfiles ApiKeyScope = "ingest:profiles"
+	ApiKeyScopeQuery          ApiKeyScope = "query"
+	ApiKeyScopeAlertingRules  ApiKeyScope = "alerting_rules"
+
+	// ApiKeyScopeIngestAny can't be granted, it's required by endpoints used by agents sending any kind of telemetry,
+	// e.g., the agent config, and is allowed by any ingest scope.
+	ApiKeyScopeIngestAny ApiKeyScope = "ingest:*"
+)
+
+var ApiKeyScopes = []ApiKeyScope{
+	ApiKeyScopeAll,
+	ApiKeyScopeIngestMetrics,
+	ApiKeyScopeIngestTraces,
+	ApiKeyScopeIngestLogs,
+	ApiKeyScopeIngestProfiles,
+	ApiKeyScopeQuery,
+	ApiKeyScopeAlertingRules,
+}
+
+type ApiKey struct {
+	Key         string `json:"key" yaml:"key"`
+	Description string `json:"description" yaml:"description"`
+	// Scopes limit what the key can be used for. A key without scopes has full access for backward compatibility.
+	Scopes    []ApiKeyScope   `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	ExpiresAt timeseries.Time `json:"expires_at,omitempty" yaml:"expiresAt,omitempty"`
+}
+
+func (k *ApiKey) Validate() error {
+	if k.Key == "" {
+		return fmt.Errorf("key is required")
+	}
+	for _, s := range k.Scopes {
+		if !slices.Contains(ApiKeyScopes, s) {
+			return fmt.Errorf("unknown scope: %s", s)
+		}
+	}
+	return nil
+}
+
+func (k *ApiKey) IsEmpty() bool {
+	return k.Key == emptyApiKey
+}
+
+func (k *ApiKey) IsExpired(now timeseries.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
+
+// Allows reports whether the key can be used for the scope.
+// ApiKeyScopeAll requires a key with full access, ApiKeyScopeIngestAny requires any ingest scope.
+func (k *ApiKey) Allows(scope ApiKeyScope) bool {
+	if len(k.Scopes) == 0 || slices.Contains(k.Scopes, ApiKeyScopeAll) {
+		return true
+	}
+	if scope == ApiKeyScopeIngestAny {
+		return slices.ContainsFunc(k.Scopes, func(s ApiKeyScope) bool { return strings.HasPrefix(string(s), "ingest:") })
+	}
+	return scope != ApiKeyScopeAll && slices.Contains(k.Scopes, scope)
+}
+
+// Check returns an error if the key has expired or is not allowed to be used for the scope.
+func (k *ApiKey) Check(scope ApiKeyScope, now timeseries.Time) error {
+	if k.IsExpired(now) {
+		return fmt.Errorf("%w (expired at %s)", ErrApiKeyExpired, k.ExpiresAt.ToStandard().UTC().Format("2006-01-02 15:04:05 UTC"))
+	}
+	if !k.Allows(scope) {
+		return fmt.Errorf("%w (required scope: %s)", ErrApiKeyForbidden, scope)
+	}
+	return nil
+}
+
+// FindApiKey returns the project and the key matching the given value.
+func FindApiKey(projects []*Project, key string) (*Project, *ApiKey) {
+	for _, p := range projects {
+		for i := range p.Settings.ApiKeys {
+			if p.Settings.ApiKeys[i].Key == key {
+				return p, &p.Settings.ApiKeys[i]
+			}
+		}
+	}
+	return nil, nil
+}
+
+type ApiKeyUsage struct {
+	ProjectId  ProjectId
+	Key        string
+	LastUsedAt timeseries.Time
+}
+
+func (u *ApiKeyUsage) Migrate(m *Migrator) error {
+	return m.Exec(`
+	CREATE TABLE IF NOT EXISTS api_key_usage (
+		project_id TEXT NOT NULL REFERENCES project(id),
+		key TEXT NOT NULL,
+		last_used_at INT NOT NULL,
+		PRIMARY KEY (project_id, key)
+	)`)
+}
+
+func (db *DB) GetApiKeysLastUsed(projectId ProjectId) (map[string]timeseries.Time, error) {
+	rows, err := db.db.Query("SELECT key, last_used_at FROM api_key_usage WHERE project_id = $1", projectId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[string]timeseries.Time{}
+	for rows.Next() {
+		var key string
+		var t timeseries.Time
+		if err = rows.Scan(&key, &t); err != nil {
+			return nil, err
+		}
+		res[key] = t
+	}
+	return res, rows.Err()
+}
+
+func (db *DB) SetApiKeyLastUsed(projectId ProjectId, key string, t timeseries.Time) error {
+	res, err := db.db.Exec("UPDATE api_key_usage SET last_used_at = $1 WHERE project_id = $2 AND key = $3", t, projectId, key)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	_, err = db.db.Exec("INSERT INTO api_key_usage (project_id, key, last_used_at) VALUES ($1, $2, $3)", projectId, key, t)
+	return err
+}
+
+// ApiKeyUsageTracker records when API keys were last used.
+// Usage is kept in memory and written to the database by a background goroutine once per minute,
+// so that request handlers never wait for the database.
+type ApiKeyUsageTracker struct {
+	db      *DB
+	lock    sync.Mutex
+	pending map[apiKeyUsage]timeseries.Time
+}
+
+type apiKeyUsage struct {
+	projectId ProjectId
+	key       string
+}
+
+func NewApiKeyUsageTracker(db *DB) *ApiKeyUsageTracker {
+	t := &ApiKeyUsageTracker{db: db, pending: map[apiKeyUsage]timeseries.Time{}}
+	go func() {
+		ticker := time.NewTicker(apiKeyUsageUpdateInterval.ToStandard())
+		defer ticker.Stop()
+		for range ticker.C {
+			t.Flush()
+		}
+	}()
+	return t
+}
+
+func (t *ApiKeyUsageTracker) Touch(projectId ProjectId, key string, now timeseries.Time) {
+	u := apiKeyUsage{projectId: projectId, key: key}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if now.After(t.pending[u]) {
+		t.pending[u] = now
+	}
+}
+
+// Flush writes the recorded usage to the database.
+// Entries that fail to be written are kept and retried on the next flush.
+func (t *ApiKeyUsageTracker) Flush() {
+	t.lock.Lock()
+	pending := t.pending
+	t.pending = map[apiKeyUsage]timeseries.Time{}
+	t.lock.Unlock()
+	for u, ts := range pending {
+		if err := t.db.SetApiKeyLastUsed(u.projectId, u.key, ts); err != nil {
+			klog.Errorln("failed to update the API key usage:", err)
+			t.Touch(u.projectId, u.key, ts)
+		}
+	}
+}
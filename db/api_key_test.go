@@ -0,0 +1,83 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApiKeyCheck(t *testing.T) {
+	now := timeseries.Time(1000)
+
+	k := ApiKey{Key: "k"}
+	assert.NoError(t, k.Check(ApiKeyScopeIngestMetrics, now))
+	assert.NoError(t, k.Check(ApiKeyScopeAll, now))
+
+	k = ApiKey{Key: "k", Scopes: []ApiKeyScope{ApiKeyScopeIngestMetrics, ApiKeyScopeIngestLogs}}
+	assert.NoError(t, k.Check(ApiKeyScopeIngestMetrics, now))
+	assert.NoError(t, k.Check(ApiKeyScopeIngestLogs, now))
+	assert.True(t, errors.Is(k.Check(ApiKeyScopeIngestTraces, now), ErrApiKeyForbidden))
+	assert.True(t, errors.Is(k.Check(ApiKeyScopeAll, now), ErrApiKeyForbidden))
+
+	k = ApiKey{Key: "k", Scopes: []ApiKeyScope{ApiKeyScopeIngestTraces}}
+	assert.NoError(t, k.Check(ApiKeyScopeIngestAny, now))
+	k = ApiKey{Key: "k", Scopes: []ApiKeyScope{ApiKeyScopeQuery}}
+	assert.True(t, errors.Is(k.Check(ApiKeyScopeIngestAny, now), ErrApiKeyForbidden))
+
+	k = ApiKey{Key: "k", Scopes: []ApiKeyScope{ApiKeyScopeIngestAny}}
+	assert.Error(t, k.Validate(), "the scope can't be granted")
+
+	k = ApiKey{Key: "k", Scopes: []ApiKeyScope{ApiKeyScopeAll}}
+	assert.NoError(t, k.Check(ApiKeyScopeAll, now))
+
+	k = ApiKey{Key: "k", ExpiresAt: now.Add(timeseries.Minute)}
+	assert.NoError(t, k.Check(ApiKeyScopeQuery, now))
+	assert.True(t, errors.Is(k.Check(ApiKeyScopeQuery, now.Add(timeseries.Minute)), ErrApiKeyExpired))
+
+	k = ApiKey{Key: "k", Scopes: []ApiKeyScope{"unknown"}}
+	assert.Error(t, k.Validate())
+}
+
+func TestFindApiKey(t *testing.T) {
+	p1 := &Project{Id: "p1", Settings: ProjectSettings{ApiKeys: []ApiKey{{Key: "a"}, {Key: "b"}}}}
+	p2 := &Project{Id: "p2", Settings: ProjectSettings{ApiKeys: []ApiKey{{Key: "c"}}}}
+
+	p, k := FindApiKey([]*Project{p1, p2}, "c")
+	assert.Equal(t, p2, p)
+	assert.Equal(t, "c", k.Key)
+
+	p, k = FindApiKey([]*Project{p1, p2}, "d")
+	assert.Nil(t, p)
+	assert.Nil(t, k)
+}
+
+func TestApiKeyUsageTracker(t *testing.T) {
+	db, err := NewSqlite(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate())
+	p := &Project{Name: "p"}
+	require.NoError(t, db.SaveProject(p))
+
+	tracker := NewApiKeyUsageTracker(db)
+	tracker.Touch(p.Id, "key", 1000)
+	tracker.Touch(p.Id, "key", 1030)
+	tracker.Touch(p.Id, "key", 1010)
+
+	used, err := db.GetApiKeysLastUsed(p.Id)
+	require.NoError(t, err)
+	assert.Empty(t, used, "usage must not be written until flushed")
+
+	tracker.Flush()
+	used, err = db.GetApiKeysLastUsed(p.Id)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]timeseries.Time{"key": 1030}, used)
+
+	tracker.Touch(p.Id, "key", 1100)
+	tracker.Flush()
+	used, err = db.GetApiKeysLastUsed(p.Id)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]timeseries.Time{"key": 1100}, used)
+}
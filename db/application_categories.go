@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
 	"github.com/coroot/coroot/utils"
 	"golang.org/x/exp/maps"
 )
@@ -17,12 +18,14 @@ type ApplicationCategory struct {
 	BuiltinPatterns      string                                  `json:"builtin_patterns"`
 	CustomPatterns       string                                  `json:"custom_patterns"`
 	NotificationSettings ApplicationCategoryNotificationSettings `json:"notification_settings"`
+	MaintenanceWindows   model.MaintenanceWindows                `json:"maintenance_windows"`
 }
 
 type ApplicationCategorySettings struct {
 	CustomPatterns       []string                                `json:"custom_patterns,omitempty" yaml:"customPatterns,omitempty"`
 	NotifyOfDeployments  bool                                    `json:"notify_of_deployments,omitempty"` // deprecated: use NotificationSettings
 	NotificationSettings ApplicationCategoryNotificationSettings `json:"notification_settings,omitempty" yaml:"notificationSettings,omitempty"`
+	MaintenanceWindows   model.MaintenanceWindows                `json:"maintenance_windows,omitempty" yaml:"maintenanceWindows,omitempty"`
 }
 
 type ApplicationCategoryNotificationSettings struct {
@@ -42,16 +45,75 @@ type ApplicationCategoryDeploymentNotificationSettings struct {
 }
 
 type ApplicationCategoryAlertNotificationSettings struct {
-	Enabled                                     bool `json:"enabled" yaml:"enabled"`
+	Enabled                                     bool                                `json:"enabled" yaml:"enabled"`
+	Grouping                                    *ApplicationCategoryAlertGrouping   `json:"grouping,omitempty" yaml:"grouping,omitempty"`
+	Escalation                                  *ApplicationCategoryAlertEscalation `json:"escalation,omitempty" yaml:"escalation,omitempty"`
+	ApplicationCategoryNotificationDestinations `yaml:",inline"`
+}
+
+// ApplicationCategoryAlertEscalation notifies additional destinations about the alerts
+// that are still firing and haven't been acknowledged after the step's delay since they were opened.
+type ApplicationCategoryAlertEscalation struct {
+	Enabled bool                                     `json:"enabled" yaml:"enabled"`
+	Steps   []ApplicationCategoryAlertEscalationStep `json:"steps" yaml:"steps"`
+}
+
+type ApplicationCategoryAlertEscalationStep struct {
+	Delay                                       timeseries.Duration `json:"delay" yaml:"delay"`
 	ApplicationCategoryNotificationDestinations `yaml:",inline"`
 }
 
+func (e *ApplicationCategoryAlertEscalation) Validate() error {
+	for i, s := range e.Steps {
+		if s.Delay <= 0 {
+			return fmt.Errorf("escalation step #%d: delay must be positive", i+1)
+		}
+		if !s.hasEnabled() {
+			return fmt.Errorf("escalation step #%d: no destinations", i+1)
+		}
+		if err := s.ApplicationCategoryNotificationDestinations.Validate(); err != nil {
+			return fmt.Errorf("escalation step #%d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// ActiveSteps returns the steps of an enabled escalation policy, or nil.
+func (e *ApplicationCategoryAlertEscalation) ActiveSteps() []ApplicationCategoryAlertEscalationStep {
+	if e == nil || !e.Enabled {
+		return nil
+	}
+	return e.Steps
+}
+
 type ApplicationCategoryNotificationDestinations struct {
-	Slack     *ApplicationCategoryNotificationSettingsSlack     `json:"slack,omitempty" yaml:"slack,omitempty"`
-	Teams     *ApplicationCategoryNotificationSettingsTeams     `json:"teams,omitempty" yaml:"teams,omitempty"`
-	Pagerduty *ApplicationCategoryNotificationSettingsPagerduty `json:"pagerduty,omitempty" yaml:"pagerduty,omitempty"`
-	Opsgenie  *ApplicationCategoryNotificationSettingsOpsgenie  `json:"opsgenie,omitempty" yaml:"opsgenie,omitempty"`
-	Webhook   *ApplicationCategoryNotificationSettingsWebhook   `json:"webhook,omitempty" yaml:"webhook,omitempty"`
+	Slack      *ApplicationCategoryNotificationSettingsSlack      `json:"slack,omitempty" yaml:"slack,omitempty"`
+	Teams      *ApplicationCategoryNotificationSettingsTeams      `json:"teams,omitempty" yaml:"teams,omitempty"`
+	Pagerduty  *ApplicationCategoryNotificationSettingsPagerduty  `json:"pagerduty,omitempty" yaml:"pagerduty,omitempty"`
+	Opsgenie   *ApplicationCategoryNotificationSettingsOpsgenie   `json:"opsgenie,omitempty" yaml:"opsgenie,omitempty"`
+	Webhook    *ApplicationCategoryNotificationSettingsWebhook    `json:"webhook,omitempty" yaml:"webhook,omitempty"`
+	Email      *ApplicationCategoryNotificationSettingsEmail      `json:"email,omitempty" yaml:"email,omitempty"`
+	Telegram   *ApplicationCategoryNotificationSettingsTelegram   `json:"telegram,omitempty" yaml:"telegram,omitempty"`
+	Discord    *ApplicationCategoryNotificationSettingsDiscord    `json:"discord,omitempty" yaml:"discord,omitempty"`
+	GoogleChat *ApplicationCategoryNotificationSettingsGoogleChat `json:"googlechat,omitempty" yaml:"googleChat,omitempty"`
+	Mattermost *ApplicationCategoryNotificationSettingsMattermost `json:"mattermost,omitempty" yaml:"mattermost,omitempty"`
+}
+
+func (s ApplicationCategoryNotificationDestinations) Validate() error {
+	if s.Email != nil {
+		if err := ValidateEmailRecipients(s.Email.To); err != nil {
+			return err
+		}
+	}
+	if s.Telegram != nil {
+		if err := ValidateTelegramChatId(s.Telegram.ChatId); err != nil {
+			return err
+		}
+	}
+	if s.Mattermost != nil && strings.Contains(s.Mattermost.Channel, ":") {
+		return fmt.Errorf("channel name must not contain ':'")
+	}
+	return nil
 }
 
 func (s ApplicationCategoryNotificationDestinations) hasEnabled() bool {
@@ -59,7 +121,12 @@ func (s ApplicationCategoryNotificationDestinations) hasEnabled() bool {
 		(s.Teams != nil && s.Teams.Enabled) ||
 		(s.Pagerduty != nil && s.Pagerduty.Enabled) ||
 		(s.Opsgenie != nil && s.Opsgenie.Enabled) ||
-		(s.Webhook != nil && s.Webhook.Enabled)
+		(s.Webhook != nil && s.Webhook.Enabled) ||
+		(s.Email != nil && s.Email.Enabled) ||
+		(s.Telegram != nil && s.Telegram.Enabled) ||
+		(s.Discord != nil && s.Discord.Enabled) ||
+		(s.GoogleChat != nil && s.GoogleChat.Enabled) ||
+		(s.Mattermost != nil && s.Mattermost.Enabled)
 }
 
 type ApplicationCategoryNotificationSettingsSlack struct {
@@ -84,6 +151,35 @@ type ApplicationCategoryNotificationSettingsWebhook struct {
 	Enabled bool `json:"enabled" yaml:"enabled"`
 }
 
+// ApplicationCategoryNotificationSettingsEmail overrides the default recipients of the email integration.
+type ApplicationCategoryNotificationSettingsEmail struct {
+	Enabled bool     `json:"enabled" yaml:"enabled"`
+	To      []string `json:"to" yaml:"to,omitempty"`
+}
+
+// Destination returns the notification destination; no recipients mean the integration's default ones.
+func (s *ApplicationCategoryNotificationSettingsEmail) Destination() IncidentNotificationDestination {
+	return IncidentNotificationDestination{IntegrationType: IntegrationTypeEmail, EmailTo: strings.Join(s.To, ",")}
+}
+
+type ApplicationCategoryNotificationSettingsTelegram struct {
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	ChatId  string `json:"chat_id" yaml:"chatId,omitempty"`
+}
+
+type ApplicationCategoryNotificationSettingsDiscord struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+}
+
+type ApplicationCategoryNotificationSettingsGoogleChat struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+}
+
+type ApplicationCategoryNotificationSettingsMattermost struct {
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	Channel string `json:"channel" yaml:"channel,omitempty"`
+}
+
 func (p *Project) CalcApplicationCategory(appId model.ApplicationId) model.ApplicationCategory {
 	id := fmt.Sprintf("%s/%s", appId.Namespace, appId.Name)
 
@@ -142,6 +238,7 @@ func (p *Project) GetApplicationCategories() map[model.ApplicationCategory]*Appl
 			categorySettings = &ApplicationCategorySettings{}
 		}
 		category.NotificationSettings = categorySettings.NotificationSettings
+		category.MaintenanceWindows = categorySettings.MaintenanceWindows
 		notifyOfDeployments := category.Default || categorySettings.NotifyOfDeployments
 
 		{
@@ -304,6 +401,166 @@ func (p *Project) GetApplicationCategories() map[model.ApplicationCategory]*Appl
 				category.NotificationSettings.Alerts.Opsgenie = nil
 			}
 		}
+		{
+			integrationEmail := p.Settings.Integrations.Email
+			if integrationEmail != nil {
+				if integrationEmail.Incidents {
+					if category.NotificationSettings.Incidents.Email == nil {
+						category.NotificationSettings.Incidents.Enabled = true
+						category.NotificationSettings.Incidents.Email = &ApplicationCategoryNotificationSettingsEmail{Enabled: true}
+					}
+				}
+				if integrationEmail.Deployments {
+					if category.NotificationSettings.Deployments.Email == nil {
+						category.NotificationSettings.Deployments.Enabled = notifyOfDeployments
+						category.NotificationSettings.Deployments.Email = &ApplicationCategoryNotificationSettingsEmail{Enabled: notifyOfDeployments}
+					}
+				}
+				if boolValue(integrationEmail.Alerts) {
+					if category.NotificationSettings.Alerts.Email == nil {
+						category.NotificationSettings.Alerts.Enabled = true
+						category.NotificationSettings.Alerts.Email = &ApplicationCategoryNotificationSettingsEmail{Enabled: true}
+					}
+				}
+			}
+			if integrationEmail == nil || !integrationEmail.Incidents {
+				category.NotificationSettings.Incidents.Email = nil
+			}
+			if integrationEmail == nil || !integrationEmail.Deployments {
+				category.NotificationSettings.Deployments.Email = nil
+			}
+			if integrationEmail == nil || !boolValue(integrationEmail.Alerts) {
+				category.NotificationSettings.Alerts.Email = nil
+			}
+		}
+		{
+			integrationTelegram := p.Settings.Integrations.Telegram
+			if integrationTelegram != nil {
+				if integrationTelegram.Incidents {
+					if category.NotificationSettings.Incidents.Telegram == nil {
+						category.NotificationSettings.Incidents.Enabled = true
+						category.NotificationSettings.Incidents.Telegram = &ApplicationCategoryNotificationSettingsTelegram{Enabled: true}
+					}
+				}
+				if integrationTelegram.Deployments {
+					if category.NotificationSettings.Deployments.Telegram == nil {
+						category.NotificationSettings.Deployments.Enabled = notifyOfDeployments
+						category.NotificationSettings.Deployments.Telegram = &ApplicationCategoryNotificationSettingsTelegram{Enabled: notifyOfDeployments}
+					}
+				}
+				if boolValue(integrationTelegram.Alerts) {
+					if category.NotificationSettings.Alerts.Telegram == nil {
+						category.NotificationSettings.Alerts.Enabled = true
+						category.NotificationSettings.Alerts.Telegram = &ApplicationCategoryNotificationSettingsTelegram{Enabled: true}
+					}
+				}
+			}
+			if integrationTelegram == nil || !integrationTelegram.Incidents {
+				category.NotificationSettings.Incidents.Telegram = nil
+			}
+			if integrationTelegram == nil || !integrationTelegram.Deployments {
+				category.NotificationSettings.Deployments.Telegram = nil
+			}
+			if integrationTelegram == nil || !boolValue(integrationTelegram.Alerts) {
+				category.NotificationSettings.Alerts.Telegram = nil
+			}
+		}
+		{
+			integrationDiscord := p.Settings.Integrations.Discord
+			if integrationDiscord != nil {
+				if integrationDiscord.Incidents {
+					if category.NotificationSettings.Incidents.Discord == nil {
+						category.NotificationSettings.Incidents.Enabled = true
+						category.NotificationSettings.Incidents.Discord = &ApplicationCategoryNotificationSettingsDiscord{Enabled: true}
+					}
+				}
+				if integrationDiscord.Deployments {
+					if category.NotificationSettings.Deployments.Discord == nil {
+						category.NotificationSettings.Deployments.Enabled = notifyOfDeployments
+						category.NotificationSettings.Deployments.Discord = &ApplicationCategoryNotificationSettingsDiscord{Enabled: notifyOfDeployments}
+					}
+				}
+				if boolValue(integrationDiscord.Alerts) {
+					if category.NotificationSettings.Alerts.Discord == nil {
+						category.NotificationSettings.Alerts.Enabled = true
+						category.NotificationSettings.Alerts.Discord = &ApplicationCategoryNotificationSettingsDiscord{Enabled: true}
+					}
+				}
+			}
+			if integrationDiscord == nil || !integrationDiscord.Incidents {
+				category.NotificationSettings.Incidents.Discord = nil
+			}
+			if integrationDiscord == nil || !integrationDiscord.Deployments {
+				category.NotificationSettings.Deployments.Discord = nil
+			}
+			if integrationDiscord == nil || !boolValue(integrationDiscord.Alerts) {
+				category.NotificationSettings.Alerts.Discord = nil
+			}
+		}
+		{
+			integrationGoogleChat := p.Settings.Integrations.GoogleChat
+			if integrationGoogleChat != nil {
+				if integrationGoogleChat.Incidents {
+					if category.NotificationSettings.Incidents.GoogleChat == nil {
+						category.NotificationSettings.Incidents.Enabled = true
+						category.NotificationSettings.Incidents.GoogleChat = &ApplicationCategoryNotificationSettingsGoogleChat{Enabled: true}
+					}
+				}
+				if integrationGoogleChat.Deployments {
+					if category.NotificationSettings.Deployments.GoogleChat == nil {
+						category.NotificationSettings.Deployments.Enabled = notifyOfDeployments
+						category.NotificationSettings.Deployments.GoogleChat = &ApplicationCategoryNotificationSettingsGoogleChat{Enabled: notifyOfDeployments}
+					}
+				}
+				if boolValue(integrationGoogleChat.Alerts) {
+					if category.NotificationSettings.Alerts.GoogleChat == nil {
+						category.NotificationSettings.Alerts.Enabled = true
+						category.NotificationSettings.Alerts.GoogleChat = &ApplicationCategoryNotificationSettingsGoogleChat{Enabled: true}
+					}
+				}
+			}
+			if integrationGoogleChat == nil || !integrationGoogleChat.Incidents {
+				category.NotificationSettings.Incidents.GoogleChat = nil
+			}
+			if integrationGoogleChat == nil || !integrationGoogleChat.Deployments {
+				category.NotificationSettings.Deployments.GoogleChat = nil
+			}
+			if integrationGoogleChat == nil || !boolValue(integrationGoogleChat.Alerts) {
+				category.NotificationSettings.Alerts.GoogleChat = nil
+			}
+		}
+		{
+			integrationMattermost := p.Settings.Integrations.Mattermost
+			if integrationMattermost != nil {
+				if integrationMattermost.Incidents {
+					if category.NotificationSettings.Incidents.Mattermost == nil {
+						category.NotificationSettings.Incidents.Enabled = true
+						category.NotificationSettings.Incidents.Mattermost = &ApplicationCategoryNotificationSettingsMattermost{Enabled: true}
+					}
+				}
+				if integrationMattermost.Deployments {
+					if category.NotificationSettings.Deployments.Mattermost == nil {
+						category.NotificationSettings.Deployments.Enabled = notifyOfDeployments
+						category.NotificationSettings.Deployments.Mattermost = &ApplicationCategoryNotificationSettingsMattermost{Enabled: notifyOfDeployments}
+					}
+				}
+				if boolValue(integrationMattermost.Alerts) {
+					if category.NotificationSettings.Alerts.Mattermost == nil {
+						category.NotificationSettings.Alerts.Enabled = true
+						category.NotificationSettings.Alerts.Mattermost = &ApplicationCategoryNotificationSettingsMattermost{Enabled: true}
+					}
+				}
+			}
+			if integrationMattermost == nil || !integrationMattermost.Incidents {
+				category.NotificationSettings.Incidents.Mattermost = nil
+			}
+			if integrationMattermost == nil || !integrationMattermost.Deployments {
+				category.NotificationSettings.Deployments.Mattermost = nil
+			}
+			if integrationMattermost == nil || !boolValue(integrationMattermost.Alerts) {
+				category.NotificationSettings.Alerts.Mattermost = nil
+			}
+		}
 
 		if !category.NotificationSettings.Incidents.hasEnabled() {
 			category.NotificationSettings.Incidents.Enabled = false
@@ -319,6 +576,29 @@ func (p *Project) GetApplicationCategories() map[model.ApplicationCategory]*Appl
 	return res
 }
 
+// InMaintenanceWindow reports whether the given application category is within one of its maintenance windows at t.
+func (p *Project) InMaintenanceWindow(category model.ApplicationCategory, t timeseries.Time) bool {
+	settings := p.Settings.ApplicationCategorySettings[category]
+	if settings == nil {
+		return false
+	}
+	return settings.MaintenanceWindows.IsActive(t)
+}
+
+// MaxMaintenanceWindowDuration returns the longest maintenance window duration across all application categories.
+func (p *Project) MaxMaintenanceWindowDuration() timeseries.Duration {
+	var res timeseries.Duration
+	for _, settings := range p.Settings.ApplicationCategorySettings {
+		if settings == nil {
+			continue
+		}
+		if d := settings.MaintenanceWindows.MaxDuration(); d > res {
+			res = d
+		}
+	}
+	return res
+}
+
 func (p *Project) NewApplicationCategory() *ApplicationCategory {
 	category := &ApplicationCategory{}
 	if slack := p.Settings.Integrations.Slack; slack != nil {
@@ -370,6 +650,61 @@ func (p *Project) NewApplicationCategory() *ApplicationCategory {
 			category.NotificationSettings.Alerts.Opsgenie = &ApplicationCategoryNotificationSettingsOpsgenie{}
 		}
 	}
+	if email := p.Settings.Integrations.Email; email != nil {
+		if email.Incidents {
+			category.NotificationSettings.Incidents.Email = &ApplicationCategoryNotificationSettingsEmail{}
+		}
+		if email.Deployments {
+			category.NotificationSettings.Deployments.Email = &ApplicationCategoryNotificationSettingsEmail{}
+		}
+		if boolValue(email.Alerts) {
+			category.NotificationSettings.Alerts.Email = &ApplicationCategoryNotificationSettingsEmail{}
+		}
+	}
+	if telegram := p.Settings.Integrations.Telegram; telegram != nil {
+		if telegram.Incidents {
+			category.NotificationSettings.Incidents.Telegram = &ApplicationCategoryNotificationSettingsTelegram{ChatId: telegram.DefaultChatId}
+		}
+		if telegram.Deployments {
+			category.NotificationSettings.Deployments.Telegram = &ApplicationCategoryNotificationSettingsTelegram{ChatId: telegram.DefaultChatId}
+		}
+		if boolValue(telegram.Alerts) {
+			category.NotificationSettings.Alerts.Telegram = &ApplicationCategoryNotificationSettingsTelegram{ChatId: telegram.DefaultChatId}
+		}
+	}
+	if discord := p.Settings.Integrations.Discord; discord != nil {
+		if discord.Incidents {
+			category.NotificationSettings.Incidents.Discord = &ApplicationCategoryNotificationSettingsDiscord{}
+		}
+		if discord.Deployments {
+			category.NotificationSettings.Deployments.Discord = &ApplicationCategoryNotificationSettingsDiscord{}
+		}
+		if boolValue(discord.Alerts) {
+			category.NotificationSettings.Alerts.Discord = &ApplicationCategoryNotificationSettingsDiscord{}
+		}
+	}
+	if googleChat := p.Settings.Integrations.GoogleChat; googleChat != nil {
+		if googleChat.Incidents {
+			category.NotificationSettings.Incidents.GoogleChat = &ApplicationCategoryNotificationSettingsGoogleChat{}
+		}
+		if googleChat.Deployments {
+			category.NotificationSettings.Deployments.GoogleChat = &ApplicationCategoryNotificationSettingsGoogleChat{}
+		}
+		if boolValue(googleChat.Alerts) {
+			category.NotificationSettings.Alerts.GoogleChat = &ApplicationCategoryNotificationSettingsGoogleChat{}
+		}
+	}
+	if mattermost := p.Settings.Integrations.Mattermost; mattermost != nil {
+		if mattermost.Incidents {
+			category.NotificationSettings.Incidents.Mattermost = &ApplicationCategoryNotificationSettingsMattermost{Channel: mattermost.DefaultChannel}
+		}
+		if mattermost.Deployments {
+			category.NotificationSettings.Deployments.Mattermost = &ApplicationCategoryNotificationSettingsMattermost{Channel: mattermost.DefaultChannel}
+		}
+		if boolValue(mattermost.Alerts) {
+			category.NotificationSettings.Alerts.Mattermost = &ApplicationCategoryNotificationSettingsMattermost{Channel: mattermost.DefaultChannel}
+		}
+	}
 	return category
 }
 
@@ -404,6 +739,7 @@ func (db *DB) SaveApplicationCategory(project *Project, name model.ApplicationCa
 		categorySettings.CustomPatterns = strings.Fields(category.CustomPatterns)
 	}
 	categorySettings.NotificationSettings = category.NotificationSettings
+	categorySettings.MaintenanceWindows = category.MaintenanceWindows
 	if slack := categorySettings.NotificationSettings.Incidents.Slack; slack != nil {
 		if s := project.Settings.Integrations.Slack; s != nil && slack.Channel == s.DefaultChannel {
 			slack.Channel = ""
@@ -0,0 +1,239 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/coroot/coroot/timeseries"
+)
+
+type AuditObjectType string
+
+const (
+	AuditObjectUser                AuditObjectType = "user"
+	AuditObjectRole                AuditObjectType = "role"
+	AuditObjectSSO                 AuditObjectType = "sso"
+	AuditObjectCloud               AuditObjectType = "cloud"
+	AuditObjectProject             AuditObjectType = "project"
+	AuditObjectApiKey              AuditObjectType = "api_key"
+	AuditObjectDashboard           AuditObjectType = "dashboard"
+	AuditObjectApplicationCategory AuditObjectType = "application_category"
+	AuditObjectCustomApplication   AuditObjectType = "custom_application"
+	AuditObjectCustomCloudPricing  AuditObjectType = "custom_cloud_pricing"
+	AuditObjectIntegration         AuditObjectType = "integration"
+	AuditObjectAlert               AuditObjectType = "alert"
+	AuditObjectAlertingRule        AuditObjectType = "alerting_rule"
+	AuditObjectSilence             AuditObjectType = "silence"
+	AuditObjectInspection          AuditObjectType = "inspection"
+	AuditObjectApplicationSettings AuditObjectType = "application_settings"
+	AuditObjectRedactionRules      AuditObjectType = "redaction_rules"
+	AuditObjectTraceSampling       AuditObjectType = "trace_sampling"
+	AuditObjectIngestionLimits     AuditObjectType = "ingestion_limits"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate      AuditAction = "create"
+	AuditActionUpdate      AuditAction = "update"
+	AuditActionDelete      AuditAction = "delete"
+	AuditActionResolve     AuditAction = "resolve"
+	AuditActionSuppress    AuditAction = "suppress"
+	AuditActionReopen      AuditAction = "reopen"
+	AuditActionAcknowledge AuditAction = "acknowledge"
+)
+
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditEntry is a record of a change made by a user. Entries are never updated or deleted,
+// including when the project they refer to is deleted.
+type AuditEntry struct {
+	Id         int                    `json:"id"`
+	Time       timeseries.Time        `json:"time"`
+	Actor      string                 `json:"actor"`
+	ProjectId  ProjectId              `json:"project_id"`
+	ObjectType AuditObjectType        `json:"object_type"`
+	ObjectId   string                 `json:"object_id"`
+	Action     AuditAction            `json:"action"`
+	Changes    map[string]AuditChange `json:"changes"`
+}
+
+type AuditLog struct{}
+
+func (l *AuditLog) Migrate(m *Migrator) error {
+	err := m.Exec(`
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		time INT NOT NULL,
+		actor TEXT NOT NULL,
+		project_id TEXT NOT NULL DEFAULT '',
+		object_type TEXT NOT NULL,
+		object_id TEXT NOT NULL DEFAULT '',
+		action TEXT NOT NULL,
+		changes TEXT NOT NULL DEFAULT '{}'
+	)`)
+	if err != nil {
+		return err
+	}
+	return m.Exec(`CREATE INDEX IF NOT EXISTS audit_log_project_time ON audit_log (project_id, time)`)
+}
+
+type AuditQuery struct {
+	ProjectId  ProjectId
+	Actor      string
+	ObjectType AuditObjectType
+	ObjectId   string
+	Action     AuditAction
+	From       timeseries.Time
+	To         timeseries.Time
+	Offset     int
+	Limit      int
+}
+
+type AuditResult struct {
+	Entries []*AuditEntry `json:"entries"`
+	Total   int           `json:"total"`
+}
+
+func (db *DB) AddAuditEntry(e *AuditEntry) error {
+	if e.Time.IsZero() {
+		e.Time = timeseries.Now()
+	}
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return err
+	}
+	_, err = db.db.Exec(
+		"INSERT INTO audit_log (time, actor, project_id, object_type, object_id, action, changes) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		e.Time, e.Actor, e.ProjectId, e.ObjectType, e.ObjectId, e.Action, string(changes))
+	return err
+}
+
+func (db *DB) QueryAuditLog(q AuditQuery) (*AuditResult, error) {
+	where := "1 = 1"
+	var args []any
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where += fmt.Sprintf(" AND "+cond, len(args))
+	}
+	if q.ProjectId != "" {
+		add("project_id = $%d", q.ProjectId)
+	}
+	if q.Actor != "" {
+		add("actor = $%d", q.Actor)
+	}
+	if q.ObjectType != "" {
+		add("object_type = $%d", q.ObjectType)
+	}
+	if q.ObjectId != "" {
+		add("object_id = $%d", q.ObjectId)
+	}
+	if q.Action != "" {
+		add("action = $%d", q.Action)
+	}
+	if !q.From.IsZero() {
+		add("time >= $%d", q.From)
+	}
+	if !q.To.IsZero() {
+		add("time <= $%d", q.To)
+	}
+
+	var res AuditResult
+	if err := db.db.QueryRow("SELECT count(*) FROM audit_log WHERE "+where, args...).Scan(&res.Total); err != nil {
+		return nil, err
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	query := fmt.Sprintf(
+		"SELECT id, time, actor, project_id, object_type, object_id, action, changes FROM audit_log WHERE %s ORDER BY id DESC LIMIT $%d OFFSET $%d",
+		where, len(args)+1, len(args)+2)
+	rows, err := db.db.Query(query, append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e AuditEntry
+		var changes string
+		if err = rows.Scan(&e.Id, &e.Time, &e.Actor, &e.ProjectId, &e.ObjectType, &e.ObjectId, &e.Action, &changes); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(changes), &e.Changes); err != nil {
+			return nil, err
+		}
+		res.Entries = append(res.Entries, &e)
+	}
+	return &res, rows.Err()
+}
+
+// AuditDiff returns the changes between the JSON representations of two versions of an object.
+// Nested objects are compared field by field, and the paths of changed fields are joined with dots.
+// A nil before or after means the object was created or deleted, so all its fields are reported.
+func AuditDiff(before, after any) (map[string]AuditChange, error) {
+	b, err := auditNormalize(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := auditNormalize(after)
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]AuditChange{}
+	auditDiff("", b, a, res)
+	return res, nil
+}
+
+func auditNormalize(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var res any
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+func auditDiff(path string, before, after any, res map[string]AuditChange) {
+	bm, bok := before.(map[string]any)
+	am, aok := after.(map[string]any)
+	if (bok || before == nil) && (aok || after == nil) && (bok || aok) {
+		for k, v := range bm {
+			auditDiff(auditPath(path, k), v, am[k], res)
+		}
+		for k, v := range am {
+			if _, ok := bm[k]; !ok {
+				auditDiff(auditPath(path, k), nil, v, res)
+			}
+		}
+		return
+	}
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+	if path == "" {
+		path = "."
+	}
+	res[path] = AuditChange{Before: before, After: after}
+}
+
+func auditPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditDiff(t *testing.T) {
+	type cfg struct {
+		Enabled bool   `json:"enabled"`
+		Channel string `json:"channel"`
+	}
+	type obj struct {
+		Name  string   `json:"name"`
+		Tags  []string `json:"tags"`
+		Slack *cfg     `json:"slack"`
+	}
+
+	changes, err := AuditDiff(
+		&obj{Name: "a", Tags: []string{"x"}, Slack: &cfg{Enabled: true, Channel: "ops"}},
+		&obj{Name: "a", Tags: []string{"x", "y"}, Slack: &cfg{Enabled: true, Channel: "dev"}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]AuditChange{
+		"tags":          {Before: []any{"x"}, After: []any{"x", "y"}},
+		"slack.channel": {Before: "ops", After: "dev"},
+	}, changes)
+
+	changes, err = AuditDiff(nil, &obj{Name: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]AuditChange{
+		"name": {Before: nil, After: "a"},
+	}, changes)
+
+	var deleted *obj
+	changes, err = AuditDiff(&obj{Name: "a"}, deleted)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]AuditChange{
+		"name": {Before: "a", After: nil},
+	}, changes)
+
+	changes, err = AuditDiff(&obj{Name: "a"}, &obj{Name: "a"})
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestAuditLog(t *testing.T) {
+	db, err := NewSqlite(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate())
+
+	for i, e := range []*AuditEntry{
+		{Time: 100, Actor: "alice", ProjectId: "p1", ObjectType: AuditObjectAlertingRule, ObjectId: "r1", Action: AuditActionCreate},
+		{Time: 200, Actor: "bob", ProjectId: "p1", ObjectType: AuditObjectAlertingRule, ObjectId: "r1", Action: AuditActionUpdate,
+			Changes: map[string]AuditChange{"enabled": {Before: true, After: false}}},
+		{Time: 300, Actor: "alice", ProjectId: "p2", ObjectType: AuditObjectIntegration, ObjectId: "slack", Action: AuditActionDelete},
+		{Time: 400, Actor: "alice", ObjectType: AuditObjectUser, ObjectId: "bob@example.com", Action: AuditActionUpdate},
+	} {
+		require.NoError(t, db.AddAuditEntry(e), i)
+	}
+
+	res, err := db.QueryAuditLog(AuditQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, res.Total)
+	require.Len(t, res.Entries, 4)
+	assert.Equal(t, "bob@example.com", res.Entries[0].ObjectId)
+
+	res, err = db.QueryAuditLog(AuditQuery{ProjectId: "p1", Actor: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Total)
+	require.Len(t, res.Entries, 1)
+	assert.Equal(t, map[string]AuditChange{"enabled": {Before: true, After: false}}, res.Entries[0].Changes)
+
+	res, err = db.QueryAuditLog(AuditQuery{Actor: "alice", From: 150, To: 350})
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Total)
+
+	res, err = db.QueryAuditLog(AuditQuery{Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 4, res.Total)
+	require.Len(t, res.Entries, 2)
+	assert.Equal(t, "r1", res.Entries[0].ObjectId)
+	assert.Equal(t, AuditActionUpdate, res.Entries[0].Action)
+}
@@ -114,6 +114,7 @@ func (db *DB) Migrate(extraTables ...Table) error {
 		&Incident{},
 		&IncidentNotification{},
 		&AlertNotification{},
+		&AlertGroup{},
 		&ApplicationDeployment{},
 		&ApplicationSettings{},
 		&Dashboards{},
@@ -121,6 +122,10 @@ func (db *DB) Migrate(extraTables ...Table) error {
 		&User{},
 		&AlertingRule{},
 		&Alert{},
+		&Silence{},
+		&Role{},
+		&ApiKeyUsage{},
+		&AuditLog{},
 	}
 	return db.Migrator().Migrate(append(defaultTables, extraTables...)...)
 }
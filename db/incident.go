@@ -83,9 +83,12 @@ func (n *IncidentNotification) Migrate(m *Migrator) error {
 }
 
 type IncidentNotificationDestination struct {
-	IntegrationType IntegrationType
-	SlackChannel    string
-	TeamsChannel    string
+	IntegrationType   IntegrationType
+	SlackChannel      string
+	TeamsChannel      string
+	EmailTo           string // comma-separated list of recipients
+	TelegramChatId    string
+	MattermostChannel string
 }
 
 func (d IncidentNotificationDestination) Value() (driver.Value, error) {
@@ -96,6 +99,18 @@ func (d IncidentNotificationDestination) Value() (driver.Value, error) {
 		if d.TeamsChannel != "" {
 			return fmt.Sprintf("%s:%s", d.IntegrationType, d.TeamsChannel), nil
 		}
+	case IntegrationTypeEmail:
+		if d.EmailTo != "" {
+			return fmt.Sprintf("%s:%s", d.IntegrationType, d.EmailTo), nil
+		}
+	case IntegrationTypeTelegram:
+		if d.TelegramChatId != "" {
+			return fmt.Sprintf("%s:%s", d.IntegrationType, d.TelegramChatId), nil
+		}
+	case IntegrationTypeMattermost:
+		if d.MattermostChannel != "" {
+			return fmt.Sprintf("%s:%s", d.IntegrationType, d.MattermostChannel), nil
+		}
 	}
 	return fmt.Sprintf("%s", d.IntegrationType), nil
 }
@@ -113,6 +128,12 @@ func (d *IncidentNotificationDestination) Scan(src any) error {
 			d.SlackChannel = parts[1]
 		case IntegrationTypeTeams:
 			d.TeamsChannel = parts[1]
+		case IntegrationTypeEmail:
+			d.EmailTo = parts[1]
+		case IntegrationTypeTelegram:
+			d.TelegramChatId = parts[1]
+		case IntegrationTypeMattermost:
+			d.MattermostChannel = parts[1]
 		}
 	}
 	return nil
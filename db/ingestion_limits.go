@@ -0,0 +1,23 @@
+package db
+
+import "fmt"
+
+// IngestionLimits protects the shared storage from a single project flooding it.
+// The limits are enforced by the collector on all the ingestion endpoints. Zero means no limit.
+type IngestionLimits struct {
+	BytesPerSecond   int64 `json:"bytes_per_second"`
+	RecordsPerSecond int64 `json:"records_per_second"`
+	MaxSeries        int   `json:"max_series"`
+	MaxServices      int   `json:"max_services"`
+}
+
+func (l *IngestionLimits) Validate() error {
+	if l.BytesPerSecond < 0 || l.RecordsPerSecond < 0 || l.MaxSeries < 0 || l.MaxServices < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	return nil
+}
+
+func (l *IngestionLimits) IsEmpty() bool {
+	return l == nil || *l == IngestionLimits{}
+}
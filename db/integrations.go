@@ -2,7 +2,9 @@ package db
 
 import (
 	"fmt"
+	"net/mail"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/coroot/coroot/timeseries"
@@ -24,6 +26,11 @@ const (
 	IntegrationTypeTeams      IntegrationType = "teams"
 	IntegrationTypeOpsgenie   IntegrationType = "opsgenie"
 	IntegrationTypeWebhook    IntegrationType = "webhook"
+	IntegrationTypeEmail      IntegrationType = "email"
+	IntegrationTypeTelegram   IntegrationType = "telegram"
+	IntegrationTypeDiscord    IntegrationType = "discord"
+	IntegrationTypeGoogleChat IntegrationType = "googlechat"
+	IntegrationTypeMattermost IntegrationType = "mattermost"
 )
 
 type Integrations struct {
@@ -38,11 +45,16 @@ type NotificationIntegrations struct {
 	Readonly bool   `json:"readonly" yaml:"-"`
 	BaseUrl  string `json:"base_url" yaml:"baseURL"`
 
-	Slack     *IntegrationSlack     `json:"slack,omitempty" yaml:"slack,omitempty"`
-	Teams     *IntegrationTeams     `json:"teams,omitempty" yaml:"teams,omitempty"`
-	Pagerduty *IntegrationPagerduty `json:"pagerduty,omitempty" yaml:"pagerduty,omitempty"`
-	Opsgenie  *IntegrationOpsgenie  `json:"opsgenie,omitempty" yaml:"opsgenie,omitempty"`
-	Webhook   *IntegrationWebhook   `json:"webhook,omitempty" yaml:"webhook,omitempty"`
+	Slack      *IntegrationSlack      `json:"slack,omitempty" yaml:"slack,omitempty"`
+	Teams      *IntegrationTeams      `json:"teams,omitempty" yaml:"teams,omitempty"`
+	Pagerduty  *IntegrationPagerduty  `json:"pagerduty,omitempty" yaml:"pagerduty,omitempty"`
+	Opsgenie   *IntegrationOpsgenie   `json:"opsgenie,omitempty" yaml:"opsgenie,omitempty"`
+	Webhook    *IntegrationWebhook    `json:"webhook,omitempty" yaml:"webhook,omitempty"`
+	Email      *IntegrationEmail      `json:"email,omitempty" yaml:"email,omitempty"`
+	Telegram   *IntegrationTelegram   `json:"telegram,omitempty" yaml:"telegram,omitempty"`
+	Discord    *IntegrationDiscord    `json:"discord,omitempty" yaml:"discord,omitempty"`
+	GoogleChat *IntegrationGoogleChat `json:"googlechat,omitempty" yaml:"googleChat,omitempty"`
+	Mattermost *IntegrationMattermost `json:"mattermost,omitempty" yaml:"mattermost,omitempty"`
 }
 
 func (i *NotificationIntegrations) Validate() error {
@@ -79,6 +91,31 @@ func (i *NotificationIntegrations) Validate() error {
 			return fmt.Errorf("invalid webhook configuration: %w", err)
 		}
 	}
+	if i.Email != nil {
+		if err := i.Email.Validate(); err != nil {
+			return fmt.Errorf("invalid email configuration: %w", err)
+		}
+	}
+	if i.Telegram != nil {
+		if err := i.Telegram.Validate(); err != nil {
+			return fmt.Errorf("invalid telegram configuration: %w", err)
+		}
+	}
+	if i.Discord != nil {
+		if err := i.Discord.Validate(); err != nil {
+			return fmt.Errorf("invalid discord configuration: %w", err)
+		}
+	}
+	if i.GoogleChat != nil {
+		if err := i.GoogleChat.Validate(); err != nil {
+			return fmt.Errorf("invalid google chat configuration: %w", err)
+		}
+	}
+	if i.Mattermost != nil {
+		if err := i.Mattermost.Validate(); err != nil {
+			return fmt.Errorf("invalid mattermost configuration: %w", err)
+		}
+	}
 
 	return nil
 
@@ -147,6 +184,56 @@ func (integrations Integrations) GetInfo() []IntegrationInfo {
 	}
 	res = append(res, i)
 
+	i = IntegrationInfo{Type: IntegrationTypeEmail, Title: "Email"}
+	if cfg := integrations.Email; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Deployments = cfg.Deployments
+		i.Alerts = boolValue(cfg.Alerts)
+		i.Details = fmt.Sprintf("default recipients: %s", strings.Join(cfg.To, ", "))
+	}
+	res = append(res, i)
+
+	i = IntegrationInfo{Type: IntegrationTypeTelegram, Title: "Telegram"}
+	if cfg := integrations.Telegram; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Deployments = cfg.Deployments
+		i.Alerts = boolValue(cfg.Alerts)
+		i.Details = fmt.Sprintf("default chat: %s", cfg.DefaultChatId)
+	}
+	res = append(res, i)
+
+	i = IntegrationInfo{Type: IntegrationTypeDiscord, Title: "Discord"}
+	if cfg := integrations.Discord; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Deployments = cfg.Deployments
+		i.Alerts = boolValue(cfg.Alerts)
+	}
+	res = append(res, i)
+
+	i = IntegrationInfo{Type: IntegrationTypeGoogleChat, Title: "Google Chat"}
+	if cfg := integrations.GoogleChat; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Deployments = cfg.Deployments
+		i.Alerts = boolValue(cfg.Alerts)
+	}
+	res = append(res, i)
+
+	i = IntegrationInfo{Type: IntegrationTypeMattermost, Title: "Mattermost"}
+	if cfg := integrations.Mattermost; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Deployments = cfg.Deployments
+		i.Alerts = boolValue(cfg.Alerts)
+		if cfg.DefaultChannel != "" {
+			i.Details = fmt.Sprintf("default channel: %s", cfg.DefaultChannel)
+		}
+	}
+	res = append(res, i)
+
 	return res
 }
 
@@ -329,6 +416,146 @@ func (i *IntegrationWebhook) Validate() error {
 	return nil
 }
 
+const (
+	EmailTLSNone     = "none"
+	EmailTLSStartTLS = "starttls"
+	EmailTLS         = "tls"
+)
+
+type IntegrationEmail struct {
+	Host          string   `json:"host" yaml:"host"`
+	Port          int      `json:"port" yaml:"port"`
+	TLS           string   `json:"tls" yaml:"tls"`
+	TlsSkipVerify bool     `json:"tls_skip_verify" yaml:"tlsSkipVerify"`
+	Username      string   `json:"username" yaml:"username,omitempty"`
+	Password      string   `json:"password" yaml:"password,omitempty"`
+	From          string   `json:"from" yaml:"from"`
+	To            []string `json:"to" yaml:"to"`
+	Incidents     bool     `json:"incidents" yaml:"incidents"`
+	Deployments   bool     `json:"deployments" yaml:"deployments"`
+	Alerts        *bool    `json:"alerts,omitempty" yaml:"alerts,omitempty"`
+}
+
+func (i *IntegrationEmail) Validate() error {
+	if i.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if i.Port <= 0 || i.Port > 65535 {
+		return fmt.Errorf("invalid port")
+	}
+	switch i.TLS {
+	case "", EmailTLSNone, EmailTLSStartTLS, EmailTLS:
+	default:
+		return fmt.Errorf("unknown tls mode: %s", i.TLS)
+	}
+	if _, err := mail.ParseAddress(i.From); err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+	if len(i.To) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+	if err := ValidateEmailRecipients(i.To); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidateEmailRecipients checks that every recipient is a valid address.
+// Commas are rejected, since recipients are stored as a comma-separated list in notification destinations.
+func ValidateEmailRecipients(recipients []string) error {
+	for _, r := range recipients {
+		if strings.ContainsAny(r, ",:") {
+			return fmt.Errorf("invalid recipient: %s", r)
+		}
+		if _, err := mail.ParseAddress(r); err != nil {
+			return fmt.Errorf("invalid recipient %s: %w", r, err)
+		}
+	}
+	return nil
+}
+
+type IntegrationTelegram struct {
+	BotToken      string `json:"bot_token" yaml:"botToken"`
+	DefaultChatId string `json:"default_chat_id" yaml:"defaultChatId"`
+	Incidents     bool   `json:"incidents" yaml:"incidents"`
+	Deployments   bool   `json:"deployments" yaml:"deployments"`
+	Alerts        *bool  `json:"alerts,omitempty" yaml:"alerts,omitempty"`
+}
+
+func (i *IntegrationTelegram) Validate() error {
+	if i.BotToken == "" {
+		return fmt.Errorf("bot token is required")
+	}
+	if i.DefaultChatId == "" {
+		return fmt.Errorf("default chat id is required")
+	}
+	return ValidateTelegramChatId(i.DefaultChatId)
+}
+
+// ValidateTelegramChatId checks that the chat id is either numeric or a public channel username (@channel).
+func ValidateTelegramChatId(chatId string) error {
+	if chatId == "" {
+		return nil
+	}
+	if strings.HasPrefix(chatId, "@") {
+		if len(chatId) < 2 || strings.ContainsAny(chatId, ": ") {
+			return fmt.Errorf("invalid chat id: %s", chatId)
+		}
+		return nil
+	}
+	if _, err := strconv.ParseInt(chatId, 10, 64); err != nil {
+		return fmt.Errorf("invalid chat id: %s", chatId)
+	}
+	return nil
+}
+
+type IntegrationDiscord struct {
+	WebhookUrl  string `json:"webhook_url" yaml:"webhookURL"`
+	Incidents   bool   `json:"incidents" yaml:"incidents"`
+	Deployments bool   `json:"deployments" yaml:"deployments"`
+	Alerts      *bool  `json:"alerts,omitempty" yaml:"alerts,omitempty"`
+}
+
+func (i *IntegrationDiscord) Validate() error {
+	return validateWebhookUrl(i.WebhookUrl)
+}
+
+type IntegrationGoogleChat struct {
+	WebhookUrl  string `json:"webhook_url" yaml:"webhookURL"`
+	Incidents   bool   `json:"incidents" yaml:"incidents"`
+	Deployments bool   `json:"deployments" yaml:"deployments"`
+	Alerts      *bool  `json:"alerts,omitempty" yaml:"alerts,omitempty"`
+}
+
+func (i *IntegrationGoogleChat) Validate() error {
+	return validateWebhookUrl(i.WebhookUrl)
+}
+
+type IntegrationMattermost struct {
+	WebhookUrl     string `json:"webhook_url" yaml:"webhookURL"`
+	DefaultChannel string `json:"default_channel" yaml:"defaultChannel,omitempty"`
+	Incidents      bool   `json:"incidents" yaml:"incidents"`
+	Deployments    bool   `json:"deployments" yaml:"deployments"`
+	Alerts         *bool  `json:"alerts,omitempty" yaml:"alerts,omitempty"`
+}
+
+func (i *IntegrationMattermost) Validate() error {
+	if strings.Contains(i.DefaultChannel, ":") {
+		return fmt.Errorf("channel name must not contain ':'")
+	}
+	return validateWebhookUrl(i.WebhookUrl)
+}
+
+func validateWebhookUrl(webhookUrl string) error {
+	if webhookUrl == "" {
+		return fmt.Errorf("webhook url is required")
+	}
+	if u, err := url.Parse(webhookUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook url")
+	}
+	return nil
+}
+
 type IntegrationAWS struct {
 	Region          string `json:"region"`
 	AccessKeyID     string `json:"access_key_id"`
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegrationEmailValidate(t *testing.T) {
+	cfg := IntegrationEmail{Host: "localhost", Port: 1025, From: "Coroot <coroot@example.com>", To: []string{"ops@example.com"}}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.TLS = "ssl"
+	assert.Error(t, cfg.Validate())
+	cfg.TLS = EmailTLSStartTLS
+	assert.NoError(t, cfg.Validate())
+
+	cfg.To = nil
+	assert.Error(t, cfg.Validate())
+	cfg.To = []string{"ops@example.com,dev@example.com"}
+	assert.Error(t, cfg.Validate())
+	cfg.To = []string{"not an address"}
+	assert.Error(t, cfg.Validate())
+}
+
+func TestEmailNotificationDestination(t *testing.T) {
+	for _, d := range []IncidentNotificationDestination{
+		(&ApplicationCategoryNotificationSettingsEmail{}).Destination(),
+		(&ApplicationCategoryNotificationSettingsEmail{To: []string{"ops@example.com", "Dev <dev@example.com>"}}).Destination(),
+	} {
+		v, err := d.Value()
+		require.NoError(t, err)
+		var scanned IncidentNotificationDestination
+		require.NoError(t, scanned.Scan(v))
+		assert.Equal(t, d, scanned)
+	}
+}
+
+func TestChatIntegrationsValidate(t *testing.T) {
+	telegram := IntegrationTelegram{BotToken: "123:abc", DefaultChatId: "-1001234567890"}
+	assert.NoError(t, telegram.Validate())
+	telegram.DefaultChatId = "@alerts"
+	assert.NoError(t, telegram.Validate())
+	telegram.DefaultChatId = "alerts"
+	assert.Error(t, telegram.Validate())
+	telegram.DefaultChatId = ""
+	assert.Error(t, telegram.Validate())
+
+	discord := IntegrationDiscord{WebhookUrl: "https://discord.com/api/webhooks/1/abc"}
+	assert.NoError(t, discord.Validate())
+	discord.WebhookUrl = "discord.com/api/webhooks/1/abc"
+	assert.Error(t, discord.Validate())
+
+	mattermost := IntegrationMattermost{WebhookUrl: "https://mattermost.example.com/hooks/abc", DefaultChannel: "town-square"}
+	assert.NoError(t, mattermost.Validate())
+	mattermost.DefaultChannel = "a:b"
+	assert.Error(t, mattermost.Validate())
+
+	d := ApplicationCategoryNotificationDestinations{Telegram: &ApplicationCategoryNotificationSettingsTelegram{Enabled: true, ChatId: "chat"}}
+	assert.Error(t, d.Validate())
+	d.Telegram.ChatId = "@chat"
+	assert.NoError(t, d.Validate())
+}
+
+func TestChatNotificationDestination(t *testing.T) {
+	for _, d := range []IncidentNotificationDestination{
+		{IntegrationType: IntegrationTypeTelegram, TelegramChatId: "-1001234567890"},
+		{IntegrationType: IntegrationTypeTelegram},
+		{IntegrationType: IntegrationTypeDiscord},
+		{IntegrationType: IntegrationTypeGoogleChat},
+		{IntegrationType: IntegrationTypeMattermost, MattermostChannel: "town-square"},
+	} {
+		v, err := d.Value()
+		require.NoError(t, err)
+		var scanned IncidentNotificationDestination
+		require.NoError(t, scanned.Scan(v))
+		assert.Equal(t, d, scanned)
+	}
+}
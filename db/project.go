@@ -15,10 +15,6 @@ const (
 	DefaultRefreshInterval = 30
 )
 
-var (
-	emptyApiKey = strings.Repeat("0", 32)
-)
-
 type ProjectId string
 
 type Project struct {
@@ -38,22 +34,9 @@ type ProjectSettings struct {
 	ApiKeys                     []ApiKey                                                   `json:"api_keys"`
 	CustomCloudPricing          *CustomCloudPricing                                        `json:"custom_cloud_pricing"`
 	MemberProjects              []string                                                   `json:"member_projects"`
-}
-
-type ApiKey struct {
-	Key         string `json:"key" yaml:"key"`
-	Description string `json:"description" yaml:"description"`
-}
-
-func (k *ApiKey) Validate() error {
-	if k.Key == "" {
-		return fmt.Errorf("key is required")
-	}
-	return nil
-}
-
-func (k *ApiKey) IsEmpty() bool {
-	return k.Key == emptyApiKey
+	RedactionRules              []RedactionRule                                            `json:"redaction_rules,omitempty"`
+	TraceSampling               *TraceSampling                                             `json:"trace_sampling,omitempty"`
+	IngestionLimits             *IngestionLimits                                           `json:"ingestion_limits,omitempty"`
 }
 
 func (p *Project) Migrate(m *Migrator) error {
@@ -113,6 +96,21 @@ func (p *Project) applyDefaults() {
 		alerts := webhook.Incidents && webhook.AlertTemplate != ""
 		webhook.Alerts = &alerts
 	}
+	if email := p.Settings.Integrations.Email; email != nil && email.Alerts == nil {
+		email.Alerts = &email.Incidents
+	}
+	if telegram := p.Settings.Integrations.Telegram; telegram != nil && telegram.Alerts == nil {
+		telegram.Alerts = &telegram.Incidents
+	}
+	if discord := p.Settings.Integrations.Discord; discord != nil && discord.Alerts == nil {
+		discord.Alerts = &discord.Incidents
+	}
+	if googleChat := p.Settings.Integrations.GoogleChat; googleChat != nil && googleChat.Alerts == nil {
+		googleChat.Alerts = &googleChat.Incidents
+	}
+	if mattermost := p.Settings.Integrations.Mattermost; mattermost != nil && mattermost.Alerts == nil {
+		mattermost.Alerts = &mattermost.Incidents
+	}
 }
 
 func (p *Project) GetCustomApplicationName(instance string) string {
@@ -259,6 +257,9 @@ func (db *DB) DeleteProject(id ProjectId) error {
 	defer func() {
 		_ = tx.Rollback()
 	}()
+	if _, err = tx.Exec("DELETE FROM api_key_usage WHERE project_id = $1", id); err != nil {
+		return err
+	}
 	if _, err = tx.Exec("DELETE FROM check_configs WHERE project_id = $1", id); err != nil {
 		return err
 	}
@@ -277,12 +278,18 @@ func (db *DB) DeleteProject(id ProjectId) error {
 	if _, err = tx.Exec("DELETE FROM dashboards WHERE project_id = $1", id); err != nil {
 		return err
 	}
+	if _, err = tx.Exec("DELETE FROM alert_group WHERE project_id = $1", id); err != nil {
+		return err
+	}
 	if _, err = tx.Exec("DELETE FROM alert WHERE project_id = $1", id); err != nil {
 		return err
 	}
 	if _, err = tx.Exec("DELETE FROM alerting_rule WHERE project_id = $1", id); err != nil {
 		return err
 	}
+	if _, err = tx.Exec("DELETE FROM silence WHERE project_id = $1", id); err != nil {
+		return err
+	}
 	if _, err = tx.Exec("DELETE FROM project WHERE id = $1", id); err != nil {
 		return err
 	}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+)
+
+type RedactionAction string
+
+const (
+	RedactionActionDrop RedactionAction = "drop"
+	RedactionActionHash RedactionAction = "hash"
+	RedactionActionMask RedactionAction = "mask"
+)
+
+const DefaultRedactionReplacement = "***"
+
+// RedactionRule describes how sensitive data is removed from spans and log records before they are stored.
+// A rule matches attributes by the key (a glob pattern) and/or values by a regular expression.
+// Rules without an attribute key are also applied to span status messages and log bodies.
+type RedactionRule struct {
+	Name         string          `json:"name"`
+	Action       RedactionAction `json:"action"`
+	AttributeKey string          `json:"attribute_key,omitempty"`
+	Pattern      string          `json:"pattern,omitempty"`
+	Replacement  string          `json:"replacement,omitempty"`
+}
+
+func (r *RedactionRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch r.Action {
+	case RedactionActionDrop:
+		if r.AttributeKey == "" {
+			return fmt.Errorf("%s: attribute key is required to drop attributes", r.Name)
+		}
+	case RedactionActionHash, RedactionActionMask:
+	default:
+		return fmt.Errorf("%s: unknown action: %s", r.Name, r.Action)
+	}
+	if r.AttributeKey == "" && r.Pattern == "" {
+		return fmt.Errorf("%s: attribute key or pattern is required", r.Name)
+	}
+	if r.Pattern != "" {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("%s: invalid pattern: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+func ValidateRedactionRules(rules []RedactionRule) error {
+	names := map[string]bool{}
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		if names[r.Name] {
+			return fmt.Errorf("duplicate rule name: %s", r.Name)
+		}
+		names[r.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRedactionRules(t *testing.T) {
+	assert.NoError(t, ValidateRedactionRules(nil))
+	assert.NoError(t, ValidateRedactionRules([]RedactionRule{
+		{Name: "tokens", Action: RedactionActionDrop, AttributeKey: "*.token"},
+		{Name: "emails", Action: RedactionActionMask, Pattern: `\S+@\S+`},
+		{Name: "user ids", Action: RedactionActionHash, AttributeKey: "user.id"},
+	}))
+
+	assert.Error(t, ValidateRedactionRules([]RedactionRule{{Action: RedactionActionMask, Pattern: "x"}}), "name is required")
+	assert.Error(t, ValidateRedactionRules([]RedactionRule{{Name: "r", Action: "encrypt", Pattern: "x"}}))
+	assert.Error(t, ValidateRedactionRules([]RedactionRule{{Name: "r", Action: RedactionActionMask}}))
+	assert.Error(t, ValidateRedactionRules([]RedactionRule{{Name: "r", Action: RedactionActionDrop, Pattern: "x"}}))
+	assert.Error(t, ValidateRedactionRules([]RedactionRule{{Name: "r", Action: RedactionActionMask, Pattern: "("}}))
+	assert.Error(t, ValidateRedactionRules([]RedactionRule{
+		{Name: "r", Action: RedactionActionMask, Pattern: "x"},
+		{Name: "r", Action: RedactionActionMask, Pattern: "y"},
+	}))
+}
@@ -0,0 +1,192 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"slices"
+
+	"github.com/coroot/coroot/rbac"
+)
+
+type Role struct {
+	Name        rbac.RoleName
+	Permissions rbac.PermissionSet
+}
+
+func (r *Role) Migrate(m *Migrator) error {
+	return m.Exec(`
+	CREATE TABLE IF NOT EXISTS role (
+		name TEXT NOT NULL PRIMARY KEY,
+		permissions TEXT NOT NULL
+	)`)
+}
+
+// RoleManager provides the built-in roles along with the custom roles stored in the database.
+type RoleManager struct {
+	db *DB
+}
+
+func NewRoleManager(db *DB) *RoleManager {
+	return &RoleManager{db: db}
+}
+
+func (mgr *RoleManager) GetRoles() ([]rbac.Role, error) {
+	rows, err := mgr.db.db.Query("SELECT name, permissions FROM role ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := slices.Clone(rbac.Roles)
+	for rows.Next() {
+		var r rbac.Role
+		var permissions string
+		if err = rows.Scan(&r.Name, &permissions); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(permissions), &r.Permissions); err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, rows.Err()
+}
+
+func (mgr *RoleManager) AddRole(role rbac.Role) error {
+	if role.Name.Builtin() {
+		return ErrConflict
+	}
+	permissions, err := json.Marshal(role.Permissions)
+	if err != nil {
+		return err
+	}
+	_, err = mgr.db.db.Exec("INSERT INTO role (name, permissions) VALUES ($1, $2)", role.Name, string(permissions))
+	if mgr.db.IsUniqueViolationError(err) {
+		return ErrConflict
+	}
+	return err
+}
+
+// UpdateRole updates the role with the given name. If the role is renamed, it is also renamed for all the users it is assigned to.
+func (mgr *RoleManager) UpdateRole(name rbac.RoleName, role rbac.Role) error {
+	if name.Builtin() || role.Name.Builtin() {
+		return ErrConflict
+	}
+	permissions, err := json.Marshal(role.Permissions)
+	if err != nil {
+		return err
+	}
+	tx, err := mgr.db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec("UPDATE role SET name = $1, permissions = $2 WHERE name = $3", role.Name, string(permissions), name)
+	if err != nil {
+		if mgr.db.IsUniqueViolationError(err) {
+			return ErrConflict
+		}
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	if role.Name != name {
+		if err = renameUserRole(tx, name, role.Name); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteRole deletes the role with the given name. A role that is assigned to any user cannot be deleted.
+func (mgr *RoleManager) DeleteRole(name rbac.RoleName) error {
+	if name.Builtin() {
+		return ErrConflict
+	}
+	tx, err := mgr.db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec("DELETE FROM role WHERE name = $1", name)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	assigned, err := isRoleAssigned(tx, name)
+	if err != nil {
+		return err
+	}
+	if assigned {
+		return ErrConflict
+	}
+	return tx.Commit()
+}
+
+func isRoleAssigned(tx *sql.Tx, name rbac.RoleName) (bool, error) {
+	rows, err := tx.Query("SELECT roles FROM users")
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rs string
+		if err = rows.Scan(&rs); err != nil {
+			return false, err
+		}
+		var roles []rbac.RoleName
+		if err = json.Unmarshal([]byte(rs), &roles); err != nil {
+			return false, err
+		}
+		if slices.Contains(roles, name) {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+func renameUserRole(tx *sql.Tx, from, to rbac.RoleName) error {
+	rows, err := tx.Query("SELECT id, roles FROM users")
+	if err != nil {
+		return err
+	}
+	updates := map[int]string{}
+	for rows.Next() {
+		var id int
+		var rs string
+		if err = rows.Scan(&id, &rs); err != nil {
+			rows.Close()
+			return err
+		}
+		var roles []rbac.RoleName
+		if err = json.Unmarshal([]byte(rs), &roles); err != nil {
+			rows.Close()
+			return err
+		}
+		if i := slices.Index(roles, from); i >= 0 {
+			roles[i] = to
+			data, err := json.Marshal(roles)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			updates[id] = string(data)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for id, roles := range updates {
+		if _, err = tx.Exec("UPDATE users SET roles = $1 WHERE id = $2", roles, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
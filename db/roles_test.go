@@ -0,0 +1,34 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteRole(t *testing.T) {
+	db, err := NewSqlite(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate())
+	mgr := NewRoleManager(db)
+
+	require.NoError(t, mgr.AddRole(rbac.Role{Name: "oncall"}))
+	require.NoError(t, mgr.AddRole(rbac.Role{Name: "unused"}))
+	require.NoError(t, db.AddUser("alice@example.com", "secret", "Alice", "oncall"))
+
+	assert.ErrorIs(t, mgr.DeleteRole("oncall"), ErrConflict, "the role is assigned to a user")
+	assert.ErrorIs(t, mgr.DeleteRole(rbac.RoleAdmin), ErrConflict)
+	assert.ErrorIs(t, mgr.DeleteRole("unknown"), ErrNotFound)
+	require.NoError(t, mgr.DeleteRole("unused"))
+
+	roles, err := mgr.GetRoles()
+	require.NoError(t, err)
+	var names []rbac.RoleName
+	for _, r := range roles {
+		names = append(names, r.Name)
+	}
+	assert.Contains(t, names, rbac.RoleName("oncall"), "the assigned role is kept")
+	assert.NotContains(t, names, rbac.RoleName("unused"))
+}
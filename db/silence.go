@@ -0,0 +1,115 @@
+package db
+
+import (
+	"encoding/json"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+type Silence model.Silence
+
+func (s *Silence) Migrate(m *Migrator) error {
+	err := m.Exec(`
+	CREATE TABLE IF NOT EXISTS silence (
+		id TEXT NOT NULL,
+		project_id TEXT NOT NULL REFERENCES project(id),
+		starts_at INT NOT NULL,
+		ends_at INT NOT NULL,
+		created_by TEXT NOT NULL DEFAULT '',
+		created_at INT NOT NULL,
+		comment TEXT NOT NULL DEFAULT '',
+		matchers TEXT NOT NULL,
+		PRIMARY KEY (project_id, id)
+	)`)
+	if err != nil {
+		return err
+	}
+	return m.Exec(`CREATE INDEX IF NOT EXISTS silence_project_ends_at ON silence (project_id, ends_at)`)
+}
+
+const silenceColumns = "id, starts_at, ends_at, created_by, created_at, comment, matchers"
+
+func (db *DB) GetSilences(projectId ProjectId) ([]*model.Silence, error) {
+	return db.querySilences(projectId, "SELECT "+silenceColumns+" FROM silence WHERE project_id = $1 ORDER BY starts_at DESC")
+}
+
+// GetActiveSilences returns silences whose time window contains the given moment.
+func (db *DB) GetActiveSilences(projectId ProjectId, now timeseries.Time) (model.Silences, error) {
+	return db.querySilences(projectId, "SELECT "+silenceColumns+" FROM silence WHERE project_id = $1 AND starts_at <= $2 AND ends_at > $2", now)
+}
+
+func (db *DB) GetSilence(projectId ProjectId, id string) (*model.Silence, error) {
+	ss, err := db.querySilences(projectId, "SELECT "+silenceColumns+" FROM silence WHERE project_id = $1 AND id = $2", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(ss) == 0 {
+		return nil, ErrNotFound
+	}
+	return ss[0], nil
+}
+
+func (db *DB) CreateSilence(projectId ProjectId, s *model.Silence) error {
+	s.Id = utils.NanoId(8)
+	s.ProjectId = string(projectId)
+	s.CreatedAt = timeseries.Now()
+	matchers, err := json.Marshal(s.Matchers)
+	if err != nil {
+		return err
+	}
+	_, err = db.db.Exec(
+		"INSERT INTO silence (id, project_id, starts_at, ends_at, created_by, created_at, comment, matchers) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		s.Id, projectId, s.StartsAt, s.EndsAt, s.CreatedBy, s.CreatedAt, s.Comment, string(matchers))
+	return err
+}
+
+func (db *DB) UpdateSilence(projectId ProjectId, s *model.Silence) error {
+	matchers, err := json.Marshal(s.Matchers)
+	if err != nil {
+		return err
+	}
+	res, err := db.db.Exec(
+		"UPDATE silence SET starts_at = $1, ends_at = $2, comment = $3, matchers = $4 WHERE project_id = $5 AND id = $6",
+		s.StartsAt, s.EndsAt, s.Comment, string(matchers), projectId, s.Id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ExpireSilence ends the silence immediately, keeping it in the history.
+func (db *DB) ExpireSilence(projectId ProjectId, id string, now timeseries.Time) error {
+	_, err := db.db.Exec("UPDATE silence SET ends_at = $1 WHERE project_id = $2 AND id = $3 AND ends_at > $1", now, projectId, id)
+	return err
+}
+
+func (db *DB) DeleteSilence(projectId ProjectId, id string) error {
+	_, err := db.db.Exec("DELETE FROM silence WHERE project_id = $1 AND id = $2", projectId, id)
+	return err
+}
+
+func (db *DB) querySilences(projectId ProjectId, query string, args ...any) ([]*model.Silence, error) {
+	rows, err := db.db.Query(query, append([]any{projectId}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []*model.Silence
+	for rows.Next() {
+		s := model.Silence{ProjectId: string(projectId)}
+		var matchers string
+		if err = rows.Scan(&s.Id, &s.StartsAt, &s.EndsAt, &s.CreatedBy, &s.CreatedAt, &s.Comment, &matchers); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(matchers), &s.Matchers); err != nil {
+			return nil, err
+		}
+		res = append(res, &s)
+	}
+	return res, rows.Err()
+}
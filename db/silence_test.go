@@ -0,0 +1,110 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSilences(t *testing.T) {
+	db, err := NewSqlite(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate())
+	p := &Project{Name: "p"}
+	require.NoError(t, db.SaveProject(p))
+	id := p.Id
+
+	now := timeseries.Now()
+	s := &model.Silence{
+		StartsAt:  now.Add(-timeseries.Minute),
+		EndsAt:    now.Add(timeseries.Hour),
+		CreatedBy: "alice",
+		Comment:   "maintenance",
+		Matchers:  model.SilenceMatchers{ApplicationIdPatterns: []string{"default:*:api"}, Severities: []model.Status{model.CRITICAL}},
+	}
+	require.NoError(t, db.CreateSilence(id, s))
+	require.NotEmpty(t, s.Id)
+	future := &model.Silence{
+		StartsAt: now.Add(timeseries.Hour),
+		EndsAt:   now.Add(2 * timeseries.Hour),
+		Matchers: model.SilenceMatchers{RuleIds: []model.AlertingRuleId{"cpu"}},
+	}
+	require.NoError(t, db.CreateSilence(id, future))
+
+	got, err := db.GetSilence(id, s.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "maintenance", got.Comment)
+	assert.Equal(t, s.Matchers, got.Matchers)
+	_, err = db.GetSilence(id, "unknown")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	all, err := db.GetSilences(id)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	active, err := db.GetActiveSilences(id, now)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, s.Id, active[0].Id)
+
+	appId := model.NewApplicationId("c1", "default", model.ApplicationKindDeployment, "api")
+	critical := &model.Alert{RuleId: "storage-space", ApplicationId: appId, Severity: model.CRITICAL}
+	warning := &model.Alert{RuleId: "storage-space", ApplicationId: appId, Severity: model.WARNING}
+	assert.Equal(t, s.Id, active.FindForAlert(critical, now).Id)
+	assert.Nil(t, active.FindForAlert(warning, now))
+
+	got.Matchers.Severities = nil
+	got.Comment = "maintenance (extended)"
+	require.NoError(t, db.UpdateSilence(id, got))
+	active, err = db.GetActiveSilences(id, now)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, "maintenance (extended)", active[0].Comment)
+	assert.NotNil(t, active.FindForAlert(warning, now))
+	assert.ErrorIs(t, db.UpdateSilence(id, &model.Silence{Id: "unknown"}), ErrNotFound)
+
+	require.NoError(t, db.ExpireSilence(id, s.Id, now))
+	active, err = db.GetActiveSilences(id, now)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+	all, err = db.GetSilences(id)
+	require.NoError(t, err)
+	assert.Len(t, all, 2, "expired silences are kept in the history")
+
+	require.NoError(t, db.DeleteSilence(id, s.Id))
+	all, err = db.GetSilences(id)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, future.Id, all[0].Id)
+}
+
+func TestSilencedAlert(t *testing.T) {
+	db, err := NewSqlite(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate())
+	p := &Project{Name: "p"}
+	require.NoError(t, db.SaveProject(p))
+	id := p.Id
+
+	require.NoError(t, db.CreateAlert(id, &model.Alert{Id: "a1", Fingerprint: "a1", RuleId: "r", Severity: model.WARNING, SilenceId: "s1"}))
+	require.NoError(t, db.CreateAlert(id, &model.Alert{Id: "a2", Fingerprint: "a2", RuleId: "r", Severity: model.WARNING}))
+
+	a, err := db.GetAlert(id, "a1")
+	require.NoError(t, err)
+	assert.Equal(t, "s1", a.SilenceId)
+	assert.True(t, a.IsFiring(), "silenced alerts are stored as usual")
+
+	alerts, err := db.GetUnacknowledgedAlerts(id, timeseries.Now())
+	require.NoError(t, err)
+	require.Len(t, alerts, 1, "silenced alerts are not escalated")
+	assert.Equal(t, "a2", alerts[0].Id)
+
+	a.SilenceId = ""
+	require.NoError(t, db.UpdateAlert(id, a))
+	a, err = db.GetAlert(id, "a1")
+	require.NoError(t, err)
+	assert.Empty(t, a.SilenceId, "the marker is cleared once the silence ends")
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/coroot/coroot/rbac"
+)
+
+const (
+	SSOSettingName = "sso"
+
+	SSOProviderOIDC = "oidc"
+
+	SSODefaultRolesClaim = "groups"
+)
+
+type SSOSettings struct {
+	Enabled  bool   `json:"enabled" yaml:"enabled"`
+	Provider string `json:"provider" yaml:"provider"`
+	ForceSSO bool   `json:"force_sso" yaml:"forceSSO"`
+
+	IssuerURL    string   `json:"issuer_url" yaml:"issuerURL"`
+	ClientId     string   `json:"client_id" yaml:"clientId"`
+	ClientSecret string   `json:"client_secret" yaml:"clientSecret"`
+	Scopes       []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+
+	// RolesClaim is the ID token claim (e.g., groups) whose values are mapped to Coroot roles using RoleMapping.
+	RolesClaim  string                   `json:"roles_claim" yaml:"rolesClaim"`
+	RoleMapping map[string]rbac.RoleName `json:"role_mapping,omitempty" yaml:"roleMapping,omitempty"`
+	DefaultRole rbac.RoleName            `json:"default_role" yaml:"defaultRole"`
+
+	Readonly bool `json:"readonly" yaml:"-"`
+}
+
+func (s *SSOSettings) Validate() error {
+	if s.Provider == "" {
+		s.Provider = SSOProviderOIDC
+	}
+	if s.Provider != SSOProviderOIDC {
+		return fmt.Errorf("unsupported provider: %s", s.Provider)
+	}
+	if s.RolesClaim == "" {
+		s.RolesClaim = SSODefaultRolesClaim
+	}
+	if s.DefaultRole == "" {
+		s.DefaultRole = rbac.RoleViewer
+	}
+	if !s.Enabled {
+		return nil
+	}
+	if u, err := url.Parse(s.IssuerURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid issuer URL: %s", s.IssuerURL)
+	}
+	if s.ClientId == "" {
+		return fmt.Errorf("client ID is required")
+	}
+	for group, role := range s.RoleMapping {
+		if group == "" || role == "" {
+			return fmt.Errorf("invalid role mapping: '%s' -> '%s'", group, role)
+		}
+	}
+	return nil
+}
+
+// MapRoles returns the roles mapped from the given groups and whether any of the groups matched.
+// If none matched, it falls back to the default role.
+func (s *SSOSettings) MapRoles(groups []string) ([]rbac.RoleName, bool) {
+	uniq := map[rbac.RoleName]bool{}
+	for _, g := range groups {
+		if role, ok := s.RoleMapping[g]; ok {
+			uniq[role] = true
+		}
+	}
+	if len(uniq) == 0 {
+		return []rbac.RoleName{s.DefaultRole}, false
+	}
+	res := make([]rbac.RoleName, 0, len(uniq))
+	for role := range uniq {
+		res = append(res, role)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res, true
+}
+
+func (db *DB) GetSSOSettings() (SSOSettings, error) {
+	var s SSOSettings
+	err := db.GetSetting(SSOSettingName, &s)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return s, err
+	}
+	if s.Provider == "" {
+		s.Provider = SSOProviderOIDC
+	}
+	if s.RolesClaim == "" {
+		s.RolesClaim = SSODefaultRolesClaim
+	}
+	if s.DefaultRole == "" {
+		s.DefaultRole = rbac.RoleViewer
+	}
+	return s, nil
+}
+
+func (db *DB) SaveSSOSettings(s SSOSettings) error {
+	return db.SetSetting(SSOSettingName, s)
+}
+
+var ErrSSOLocalUserExists = errors.New("a user with this email already exists and logs in with a password")
+
+// UpsertSSOUser creates a user authenticated by the identity provider or updates the existing one.
+// SSO users have no password, so they can't log in with the login form.
+// The roles of an existing user are only updated if updateRoles is set, so that users whose groups
+// are not mapped keep the roles assigned to them in Coroot.
+// Users created in Coroot with a password are never linked to an SSO identity automatically:
+// otherwise, anyone able to register the same email at the identity provider could take over the account.
+// To switch such a user to SSO, an admin has to delete it first.
+func (db *DB) UpsertSSOUser(email, name string, roles []rbac.RoleName, updateRoles bool) (int, error) {
+	if name == "" {
+		name = email
+	}
+	rs, err := json.Marshal(roles)
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	var password string
+	err = db.db.QueryRow("SELECT id, password FROM users WHERE email = $1", email).Scan(&id, &password)
+	switch {
+	case err == nil && password != "":
+		return 0, fmt.Errorf("%w: %s", ErrSSOLocalUserExists, email)
+	case err == nil:
+		if updateRoles {
+			_, err = db.db.Exec("UPDATE users SET name = $1, roles = $2 WHERE id = $3", name, string(rs), id)
+		} else {
+			_, err = db.db.Exec("UPDATE users SET name = $1 WHERE id = $2", name, id)
+		}
+		return id, err
+	case errors.Is(err, sql.ErrNoRows):
+	default:
+		return 0, err
+	}
+	_, err = db.db.Exec("INSERT INTO users(email, name, password, roles) VALUES($1, $2, '', $3)", email, name, string(rs))
+	if err != nil {
+		if db.IsUniqueViolationError(err) {
+			return 0, ErrConflict
+		}
+		return 0, err
+	}
+	err = db.db.QueryRow("SELECT id FROM users WHERE email = $1", email).Scan(&id)
+	return id, err
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertSSOUser(t *testing.T) {
+	db, err := NewSqlite(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate())
+
+	id, err := db.UpsertSSOUser("alice@example.com", "Alice", []rbac.RoleName{rbac.RoleViewer}, true)
+	require.NoError(t, err)
+	again, err := db.UpsertSSOUser("alice@example.com", "Alice Smith", []rbac.RoleName{rbac.RoleEditor}, true)
+	require.NoError(t, err)
+	assert.Equal(t, id, again)
+	u, err := db.GetUser(id)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice Smith", u.Name)
+	assert.Equal(t, []rbac.RoleName{rbac.RoleEditor}, u.Roles)
+
+	_, err = db.UpsertSSOUser("alice@example.com", "Alice", []rbac.RoleName{rbac.RoleViewer}, false)
+	require.NoError(t, err)
+	u, err = db.GetUser(id)
+	require.NoError(t, err)
+	assert.Equal(t, []rbac.RoleName{rbac.RoleEditor}, u.Roles, "unmapped roles are kept")
+
+	require.NoError(t, db.AddUser("bob@example.com", "secret", "Bob", rbac.RoleAdmin))
+	_, err = db.UpsertSSOUser("bob@example.com", "Bob", []rbac.RoleName{rbac.RoleViewer}, true)
+	assert.ErrorIs(t, err, ErrSSOLocalUserExists, "password users must not be linked to an SSO identity")
+	users, err := db.GetUsers()
+	require.NoError(t, err)
+	for _, u := range users {
+		if u.Email == "bob@example.com" {
+			assert.Equal(t, []rbac.RoleName{rbac.RoleAdmin}, u.Roles)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/coroot/coroot/timeseries"
+)
+
+const (
+	DefaultTraceSamplingDecisionWait = 10 * timeseries.Second
+	MaxTraceSamplingDecisionWait     = 2 * timeseries.Minute
+)
+
+// TraceSampling configures tail-based sampling of traces in the collector.
+// Spans are buffered per trace for the decision window, then the whole trace is either kept or dropped.
+// Traces matching any of the policies (errors, latency, attributes) are always kept, the rest are kept
+// with the probability configured for the service of the root span.
+type TraceSampling struct {
+	Enabled            bool                           `json:"enabled"`
+	DecisionWait       timeseries.Duration            `json:"decision_wait"`
+	KeepErrors         bool                           `json:"keep_errors"`
+	LatencyThresholdMs int                            `json:"latency_threshold_ms"`
+	Attributes         []TraceSamplingAttribute       `json:"attributes,omitempty"`
+	Probability        float64                        `json:"probability"`
+	Services           []TraceSamplingServiceSettings `json:"services,omitempty"`
+}
+
+// TraceSamplingAttribute keeps the traces containing a span or resource attribute whose key matches
+// the glob pattern and whose value matches the regular expression (any value if the pattern is empty).
+type TraceSamplingAttribute struct {
+	Key     string `json:"key"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// TraceSamplingServiceSettings overrides the sampling probability for the services matching the glob pattern.
+type TraceSamplingServiceSettings struct {
+	ServiceName string  `json:"service_name"`
+	Probability float64 `json:"probability"`
+}
+
+func (s *TraceSampling) Validate() error {
+	if s.DecisionWait < 0 || s.DecisionWait > MaxTraceSamplingDecisionWait {
+		return fmt.Errorf("decision wait must be between 0 and %s", MaxTraceSamplingDecisionWait)
+	}
+	if s.LatencyThresholdMs < 0 {
+		return fmt.Errorf("latency threshold must not be negative")
+	}
+	for _, a := range s.Attributes {
+		if a.Key == "" {
+			return fmt.Errorf("attribute key is required")
+		}
+		if a.Pattern != "" {
+			if _, err := regexp.Compile(a.Pattern); err != nil {
+				return fmt.Errorf("%s: invalid pattern: %w", a.Key, err)
+			}
+		}
+	}
+	if !validProbability(s.Probability) {
+		return fmt.Errorf("probability must be between 0 and 1")
+	}
+	for _, svc := range s.Services {
+		if svc.ServiceName == "" {
+			return fmt.Errorf("service name is required")
+		}
+		if !validProbability(svc.Probability) {
+			return fmt.Errorf("%s: probability must be between 0 and 1", svc.ServiceName)
+		}
+	}
+	return nil
+}
+
+func (s *TraceSampling) GetDecisionWait() timeseries.Duration {
+	if s.DecisionWait == 0 {
+		return DefaultTraceSamplingDecisionWait
+	}
+	return s.DecisionWait
+}
+
+func validProbability(p float64) bool {
+	return p >= 0 && p <= 1
+}
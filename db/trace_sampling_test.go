@@ -0,0 +1,38 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceSamplingValidate(t *testing.T) {
+	s := TraceSampling{
+		Enabled:            true,
+		KeepErrors:         true,
+		LatencyThresholdMs: 500,
+		Attributes:         []TraceSamplingAttribute{{Key: "http.route", Pattern: "^/checkout"}, {Key: "debug"}},
+		Probability:        0.1,
+		Services:           []TraceSamplingServiceSettings{{ServiceName: "payment*", Probability: 1}},
+	}
+	assert.NoError(t, s.Validate())
+	assert.Equal(t, DefaultTraceSamplingDecisionWait, s.GetDecisionWait())
+
+	invalid := []func(s *TraceSampling){
+		func(s *TraceSampling) { s.DecisionWait = 10 * timeseries.Minute },
+		func(s *TraceSampling) { s.LatencyThresholdMs = -1 },
+		func(s *TraceSampling) { s.Attributes = []TraceSamplingAttribute{{Pattern: "x"}} },
+		func(s *TraceSampling) { s.Attributes = []TraceSamplingAttribute{{Key: "x", Pattern: "("}} },
+		func(s *TraceSampling) { s.Probability = 1.5 },
+		func(s *TraceSampling) { s.Services = []TraceSamplingServiceSettings{{Probability: 0.5}} },
+		func(s *TraceSampling) {
+			s.Services = []TraceSamplingServiceSettings{{ServiceName: "x", Probability: -0.1}}
+		},
+	}
+	for _, f := range invalid {
+		ss := s
+		f(&ss)
+		assert.Error(t, ss.Validate())
+	}
+}
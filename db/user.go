@@ -22,6 +22,9 @@ type User struct {
 	Name      string
 	Roles     []rbac.RoleName
 	Anonymous bool
+
+	// Permissions, if set, are used instead of the roles' permissions (e.g., for requests authenticated with an API key).
+	Permissions rbac.PermissionSet
 }
 
 func (u *User) Migrate(m *Migrator) error {
@@ -47,6 +50,15 @@ func AnonymousUser(role rbac.RoleName) *User {
 	return &User{Name: AnonymousUserName, Roles: []rbac.RoleName{role}, Anonymous: true}
 }
 
+// ApiKeyUser returns a pseudo-user representing a request authenticated with a project API key.
+func ApiKeyUser(key *ApiKey, permissions rbac.PermissionSet) *User {
+	name := "API key"
+	if key.Description != "" {
+		name += ": " + key.Description
+	}
+	return &User{Name: name, Permissions: permissions}
+}
+
 func (db *DB) CreateAdminIfNotExists(password string) error {
 	var i int
 	err := db.db.QueryRow("SELECT 1 FROM users WHERE email = $1", AdminUserLogin).Scan(&i)
@@ -150,6 +150,10 @@ export default class Api {
         }
     }
 
+    audit(query, cb) {
+        this.get(`audit`, query, cb);
+    }
+
     ssoStatus(cb) {
         this.get(`sso-status`, {}, cb);
     }
@@ -192,6 +196,37 @@ export default class Api {
         }
     }
 
+    redactionRules(form, cb) {
+        const url = this.projectPath('redaction_rules');
+        if (form) {
+            this.post(url, form, cb);
+        } else {
+            this.get(url, {}, cb);
+        }
+    }
+
+    redactionDryRun(form, cb) {
+        this.post(this.projectPath('redaction_rules/dry_run'), form, cb);
+    }
+
+    traceSampling(form, cb) {
+        const url = this.projectPath('trace_sampling');
+        if (form) {
+            this.post(url, form, cb);
+        } else {
+            this.get(url, {}, cb);
+        }
+    }
+
+    ingestionLimits(form, cb) {
+        const url = this.projectPath('ingestion_limits');
+        if (form) {
+            this.post(url, form, cb);
+        } else {
+            this.get(url, {}, cb);
+        }
+    }
+
     getOverview(view, query, cb) {
         this.get(this.projectPath(`overview/${view}`), { query }, cb);
     }
@@ -303,6 +338,10 @@ export default class Api {
         this.post(this.projectPath(`alerts/suppress`), { ids }, cb);
     }
 
+    acknowledgeAlerts(ids, cb) {
+        this.post(this.projectPath(`alerts/acknowledge`), { ids }, cb);
+    }
+
     reopenAlerts(ids, cb) {
         this.post(this.projectPath(`alerts/reopen`), { ids }, cb);
     }
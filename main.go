@@ -19,7 +19,6 @@ import (
 	"github.com/coroot/coroot/config"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/grpc"
-	"github.com/coroot/coroot/rbac"
 	"github.com/coroot/coroot/stats"
 	"github.com/coroot/coroot/utils"
 	"github.com/coroot/coroot/watchers"
@@ -117,6 +116,11 @@ func main() {
 		ProfilesTTL: cfg.Profiles.TTL,
 		MetricsTTL:  cfg.Metrics.TTL,
 	}
+	if !cfg.CollectorSpool.Disabled {
+		collConfig.SpoolDir = path.Join(cfg.DataDir, "spool")
+		collConfig.SpoolMaxSize = int64(cfg.CollectorSpool.MaxSizeMB) << 20
+		collConfig.SpoolMaxAge = cfg.CollectorSpool.MaxAge
+	}
 	coll := collector.New(collConfig, database, promCache, globalClickhouse, globalPrometheus, grpcServer)
 
 	go func() {
@@ -135,7 +139,7 @@ func main() {
 
 	statsCollector := stats.NewCollector(cfg.DisableUsageStatistics, instanceUuid, version, Edition, database, promCache, pricing, globalClickhouse)
 
-	a := api.NewApi(cfg, promCache, database, coll, statsCollector, pricing, rbac.NewStaticRoleManager(), nil, globalClickhouse, globalPrometheus, deploymentUuid, instanceUuid, nil)
+	a := api.NewApi(cfg, promCache, database, coll, statsCollector, pricing, db.NewRoleManager(database), nil, globalClickhouse, globalPrometheus, deploymentUuid, instanceUuid, nil)
 	err = a.AuthInit(cfg.Auth.AnonymousRole, cfg.Auth.BootstrapAdminPassword)
 	if err != nil {
 		klog.Exitln(err)
@@ -154,6 +158,11 @@ func main() {
 	router.HandleFunc("/v1/logs", coll.Logs)
 	router.HandleFunc("/v1/profiles", coll.Profiles)
 	router.HandleFunc("/v1/config", coll.Config)
+	router.HandleFunc("/loki/api/v1/push", coll.LokiPush)
+	router.HandleFunc("/api/v2/spans", coll.Zipkin)
+	router.HandleFunc("/api/traces", coll.Jaeger)
+	router.HandleFunc("/v1experimental/profiles", coll.OTLPProfiles)
+	router.HandleFunc("/ingest", coll.PyroscopeIngest)
 
 	r := router
 	if cfg.UrlBasePath != "/" {
@@ -163,6 +172,11 @@ func main() {
 		r.HandleFunc("/v1/logs", coll.Logs)
 		r.HandleFunc("/v1/profiles", coll.Profiles)
 		r.HandleFunc("/v1/config", coll.Config)
+		r.HandleFunc("/loki/api/v1/push", coll.LokiPush)
+		r.HandleFunc("/api/v2/spans", coll.Zipkin)
+		r.HandleFunc("/api/traces", coll.Jaeger)
+		r.HandleFunc("/v1experimental/profiles", coll.OTLPProfiles)
+		r.HandleFunc("/ingest", coll.PyroscopeIngest)
 	}
 	r.UseEncodedPath()
 	r.HandleFunc("/api/login", a.Login).Methods(http.MethodPost)
@@ -172,12 +186,21 @@ func main() {
 	r.HandleFunc("/api/users", a.Auth(a.Users)).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/roles", a.Auth(a.Roles)).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/sso", a.Auth(a.SSO)).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/audit", a.Auth(a.Audit)).Methods(http.MethodGet)
+	r.HandleFunc("/api/sso-status", a.SSOStatus).Methods(http.MethodGet)
+	r.HandleFunc("/api/sso-login", a.SSOLogin).Methods(http.MethodGet)
+	r.HandleFunc("/sso/oidc", a.SSOCallback).Methods(http.MethodGet)
 	r.HandleFunc("/api/ai", a.Auth(a.AI)).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/cloud", a.Auth(a.Cloud)).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/", a.Auth(a.Project)).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/{project}", a.Auth(a.Project)).Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
 	r.HandleFunc("/api/project/{project}/status", a.Auth(a.Status)).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/collector/spool", a.AuthOrApiKey(db.ApiKeyScopeQuery, a.CollectorSpool)).Methods(http.MethodGet)
 	r.HandleFunc("/api/project/{project}/api_keys", a.Auth(a.ApiKeys)).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/redaction_rules", a.Auth(a.RedactionRules)).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/redaction_rules/dry_run", a.Auth(a.RedactionDryRun)).Methods(http.MethodPost)
+	r.HandleFunc("/api/project/{project}/trace_sampling", a.Auth(a.TraceSampling)).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/ingestion_limits", a.Auth(a.IngestionLimits)).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/{project}/overview/{view}", a.Auth(a.Overview)).Methods(http.MethodGet)
 	r.HandleFunc("/api/project/{project}/incidents", a.Auth(a.Incidents)).Methods(http.MethodGet)
 	r.HandleFunc("/api/project/{project}/incident/{incident}", a.Auth(a.Incident)).Methods(http.MethodGet)
@@ -186,9 +209,12 @@ func main() {
 	r.HandleFunc("/api/project/{project}/alerts/suppress", a.Auth(a.SuppressAlerts)).Methods(http.MethodPost)
 	r.HandleFunc("/api/project/{project}/alerts/{alert}", a.Auth(a.Alert)).Methods(http.MethodGet)
 	r.HandleFunc("/api/project/{project}/alerts/reopen", a.Auth(a.ReopenAlerts)).Methods(http.MethodPost)
-	r.HandleFunc("/api/project/{project}/alerting-rules", a.Auth(a.AlertingRules)).Methods(http.MethodGet, http.MethodPost)
-	r.HandleFunc("/api/project/{project}/alerting-rules/export", a.Auth(a.AlertingRulesExport)).Methods(http.MethodGet)
-	r.HandleFunc("/api/project/{project}/alerting-rules/{rule}", a.Auth(a.AlertingRule)).Methods(http.MethodGet, http.MethodPut, http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/alerts/acknowledge", a.Auth(a.AcknowledgeAlerts)).Methods(http.MethodPost)
+	r.HandleFunc("/api/project/{project}/alerting-rules", a.AuthOrApiKey(db.ApiKeyScopeAlertingRules, a.AlertingRules)).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/alerting-rules/export", a.AuthOrApiKey(db.ApiKeyScopeAlertingRules, a.AlertingRulesExport)).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/alerting-rules/{rule}", a.AuthOrApiKey(db.ApiKeyScopeAlertingRules, a.AlertingRule)).Methods(http.MethodGet, http.MethodPut, http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/silences", a.Auth(a.Silences)).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/silences/{silence}", a.Auth(a.Silence)).Methods(http.MethodGet, http.MethodPut, http.MethodDelete)
 	r.HandleFunc("/api/project/{project}/dashboards", a.Auth(a.Dashboards)).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/{project}/dashboards/{dashboard}", a.Auth(a.Dashboards)).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/{project}/panel/data", a.Auth(a.PanelData)).Methods(http.MethodGet)
@@ -217,12 +243,12 @@ func main() {
 	r.HandleFunc("/oauth/revoke", a.MCPOAuthRevoke).Methods(http.MethodPost)
 	r.PathPrefix("/mcp").Handler(a.SetupMCP(api.MCPInstructions).HTTPHandler())
 
-	r.HandleFunc("/api/v1/query_range", a.ApiKeyAuth(a.PrometheusQueryRange))
-	r.HandleFunc("/api/v1/series", a.ApiKeyAuth(a.PrometheusSeries))
-	r.HandleFunc("/api/v1/metadata", a.ApiKeyAuth(a.PrometheusMetricMetadata))
-	r.HandleFunc("/api/v1/label/{labelName}/values", a.ApiKeyAuth(a.PrometheusLabelValues))
+	r.HandleFunc("/api/v1/query_range", a.ApiKeyAuth(db.ApiKeyScopeQuery, a.PrometheusQueryRange))
+	r.HandleFunc("/api/v1/series", a.ApiKeyAuth(db.ApiKeyScopeQuery, a.PrometheusSeries))
+	r.HandleFunc("/api/v1/metadata", a.ApiKeyAuth(db.ApiKeyScopeQuery, a.PrometheusMetricMetadata))
+	r.HandleFunc("/api/v1/label/{labelName}/values", a.ApiKeyAuth(db.ApiKeyScopeQuery, a.PrometheusLabelValues))
 
-	r.HandleFunc("/api/clickhouse-config", a.ApiKeyAuth(a.ClickhouseConfig)).Methods(http.MethodGet)
+	r.HandleFunc("/api/clickhouse-config", a.ApiKeyAuth(db.ApiKeyScopeAll, a.ClickhouseConfig)).Methods(http.MethodGet)
 	r.HandleFunc("/api/clickhouse-connect", a.ClickhouseConnect).Methods(http.MethodConnect)
 
 	r.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
@@ -84,6 +84,9 @@ type Alert struct {
 	UpdatedAt           timeseries.Time     `json:"updated_at"`
 	Suppressed          bool                `json:"suppressed"`
 	ResolvedBy          string              `json:"resolved_by,omitempty"`
+	AcknowledgedAt      timeseries.Time     `json:"acknowledged_at"`
+	AcknowledgedBy      string              `json:"acknowledged_by,omitempty"`
+	SilenceId           string              `json:"silence_id,omitempty"` // the silence that was active when the alert was opened
 	Report              AuditReportName     `json:"report,omitempty"`
 	PatternWords        string              `json:"-"`
 }
@@ -556,6 +556,380 @@ func BuiltinAlertingRules() []AlertingRule {
 			Enabled: true,
 			Builtin: true,
 		},
+		{
+			Id:   "kafka-consumer-lag",
+			Name: "Kafka consumer lag",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.KafkaConsumerLagTime.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           5 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "Some Kafka consumer groups are falling behind the producers. Messages are processed with a delay.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "kafka-under-replicated-partitions",
+			Name: "Kafka under-replicated partitions",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.KafkaUnderReplicated.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           5 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "Some Kafka partitions have fewer in-sync replicas than configured. A broker failure may cause data loss or unavailability.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "kafka-offline-partitions",
+			Name: "Kafka offline partitions",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.KafkaOfflinePartitions.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           2 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "Some Kafka partitions have no active leader. Producers and consumers of these partitions are failing.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "kafka-controller",
+			Name: "Kafka controller",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.KafkaActiveControllers.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           2 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "The Kafka cluster has no single active controller. Partition leadership and cluster metadata may not be updated.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "rabbitmq-queue-growth",
+			Name: "RabbitMQ queue growth",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.RabbitmqQueueGrowth.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           5 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "Messages are accumulating in RabbitMQ queues faster than they are consumed. Processing is delayed.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "rabbitmq-unacked-messages",
+			Name: "RabbitMQ unacknowledged messages",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.RabbitmqUnackedMessages.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           5 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "RabbitMQ consumers hold many messages without acknowledging them. Consumers may be stuck or overloaded.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "rabbitmq-no-consumers",
+			Name: "RabbitMQ consumers",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.RabbitmqNoConsumers.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           2 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "Some RabbitMQ queues lost all their consumers. Messages are not being processed.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "rabbitmq-alarms",
+			Name: "RabbitMQ resource alarms",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.RabbitmqAlarms.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           2 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "RabbitMQ memory or disk alarms are active. Publishers are blocked until the alarms clear.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "rabbitmq-partitions",
+			Name: "RabbitMQ network partitions",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.RabbitmqPartitions.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           2 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "A RabbitMQ cluster network partition has been detected. Queues may be unavailable or diverge between nodes.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "nats-slow-consumers",
+			Name: "NATS slow consumers",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.NatsSlowConsumers.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           5 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "NATS is disconnecting clients that can't keep up with the message rate. Messages to these clients are dropped.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "nats-jetstream-pending",
+			Name: "NATS JetStream pending messages",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.NatsJetstreamPending.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           5 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "JetStream consumers are falling behind their streams. Messages are processed with a delay.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "elasticsearch-cluster-red",
+			Name: "Elasticsearch cluster status (red)",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.ElasticsearchClusterRed.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      CRITICAL,
+			For:           2 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "Some primary shards of the search cluster are unassigned. Searches may return partial results and indexing into the affected indices fails.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "elasticsearch-cluster-yellow",
+			Name: "Elasticsearch cluster status (yellow)",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.ElasticsearchClusterYellow.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           10 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "Some replica shards of the search cluster are unassigned. Losing another node may cause data unavailability.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "elasticsearch-heap-usage",
+			Name: "Elasticsearch JVM heap usage",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.ElasticsearchHeapUsage.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           5 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "Search nodes are running low on JVM heap. This may cause long GC pauses, circuit breaker errors, or out-of-memory crashes.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "elasticsearch-rejected-tasks",
+			Name: "Elasticsearch rejected tasks",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.ElasticsearchRejectedTasks.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           5 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "Search nodes are rejecting indexing or search requests because their thread pool queues are full.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "cassandra-availability",
+			Name: "Cassandra availability",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.CassandraAvailability.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      CRITICAL,
+			For:           2 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "Some Cassandra nodes are down or not responding. Requests may fail if the remaining replicas can't satisfy the consistency level.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "cassandra-dropped-mutations",
+			Name: "Cassandra dropped mutations",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.CassandraDroppedMutations.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           5 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "Cassandra nodes are dropping writes because they can't process them in time. Replicas may become inconsistent until repaired.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "clickhouse-replication-queue",
+			Name: "ClickHouse replication queue",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.ClickHouseReplicationQueue.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           10 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "The ClickHouse replication queue keeps growing. Replicas are falling behind and may return stale data.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "clickhouse-rejected-inserts",
+			Name: "ClickHouse rejected inserts",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.ClickHouseRejectedInserts.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      CRITICAL,
+			For:           2 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "ClickHouse is rejecting inserts due to too many parts. Background merges can't keep up with the insert rate.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "clickhouse-keeper-session",
+			Name: "ClickHouse ZooKeeper/Keeper session",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.ClickHouseKeeperSession.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      CRITICAL,
+			For:           2 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "ClickHouse has lost its ZooKeeper/Keeper session or some replicas are read-only. Inserts into replicated tables fail.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "proxy-upstream-errors",
+			Name: "Proxy upstream errors",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.ProxyUpstreamErrors.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           5 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "Some upstreams behind the proxy are returning 5xx errors. Check the backend applications linked to the failing upstreams.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
+		{
+			Id:   "proxy-connection-limits",
+			Name: "Proxy connection limits",
+			Source: AlertSource{
+				Type:  AlertSourceTypeCheck,
+				Check: &CheckSource{CheckId: Checks.ProxyConnectionLimits.Id},
+			},
+			Selector:      AppSelector{Type: AppSelectorTypeAll},
+			Severity:      WARNING,
+			For:           5 * timeseries.Minute,
+			KeepFiringFor: 5 * timeseries.Minute,
+			Templates: AlertTemplates{
+				Description: "The proxy is dropping client connections because the worker or listener connection limit has been reached.",
+			},
+			Enabled: true,
+			Builtin: true,
+		},
 		{
 			Id:   "memcached-availability",
 			Name: "Memcached availability",
@@ -257,6 +257,69 @@ func (app *Application) IsMemcached() bool {
 	return false
 }
 
+func (app *Application) IsKafka() bool {
+	for _, i := range app.Instances {
+		if i.Kafka != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *Application) IsRabbitmq() bool {
+	for _, i := range app.Instances {
+		if i.Rabbitmq != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *Application) IsNats() bool {
+	for _, i := range app.Instances {
+		if i.Nats != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *Application) IsElasticsearch() bool {
+	for _, i := range app.Instances {
+		if i.Elasticsearch != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *Application) IsCassandra() bool {
+	for _, i := range app.Instances {
+		if i.Cassandra != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *Application) IsProxy() bool {
+	for _, i := range app.Instances {
+		if i.Proxy != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *Application) IsClickHouse() bool {
+	for _, i := range app.Instances {
+		if i.ClickHouse != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (app *Application) IsPostgres() bool {
 	for _, i := range app.Instances {
 		if i.Postgres != nil {
@@ -99,6 +99,21 @@ type ApplicationDeploymentNotifications struct {
 	Webhook struct {
 		State ApplicationDeploymentState `json:"state"`
 	} `json:"webhook"`
+	Email struct {
+		State ApplicationDeploymentState `json:"state"`
+	} `json:"email"`
+	Telegram struct {
+		State ApplicationDeploymentState `json:"state"`
+	} `json:"telegram"`
+	Discord struct {
+		State ApplicationDeploymentState `json:"state"`
+	} `json:"discord"`
+	GoogleChat struct {
+		State ApplicationDeploymentState `json:"state"`
+	} `json:"googlechat"`
+	Mattermost struct {
+		State ApplicationDeploymentState `json:"state"`
+	} `json:"mattermost"`
 }
 
 type ApplicationDeploymentSummary struct {
@@ -47,6 +47,10 @@ func GetDefaultInstrumentation(t ApplicationType) *ApplicationInstrumentation {
 		return &ApplicationInstrumentation{Type: ApplicationTypeMemcached, Port: "11211"}
 	case ApplicationTypeMysql:
 		return &ApplicationInstrumentation{Type: ApplicationTypeMysql, Port: "3306"}
+	case ApplicationTypeCassandra:
+		return &ApplicationInstrumentation{Type: ApplicationTypeCassandra, Port: "9042"}
+	case ApplicationTypeClickHouse:
+		return &ApplicationInstrumentation{Type: ApplicationTypeClickHouse, Port: "9363"} // the built-in Prometheus endpoint
 	}
 	return nil
 }
@@ -115,6 +115,20 @@ func (at ApplicationType) AuditReport() AuditReportName {
 		return AuditReportMongodb
 	case ApplicationTypeMemcached:
 		return AuditReportMemcached
+	case ApplicationTypeKafka:
+		return AuditReportKafka
+	case ApplicationTypeRabbitmq:
+		return AuditReportRabbitmq
+	case ApplicationTypeNats:
+		return AuditReportNats
+	case ApplicationTypeElasticsearch, ApplicationTypeOpensearch:
+		return AuditReportElasticsearch
+	case ApplicationTypeCassandra:
+		return AuditReportCassandra
+	case ApplicationTypeClickHouse:
+		return AuditReportClickHouse
+	case ApplicationTypeNginx, ApplicationTypeEnvoy:
+		return AuditReportProxy
 	case ApplicationTypeJava:
 		return AuditReportJvm
 	case ApplicationTypeDotNet:
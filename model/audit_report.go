@@ -11,28 +11,35 @@ import (
 type AuditReportName string
 
 const (
-	AuditReportSLO         AuditReportName = "SLO"
-	AuditReportInstances   AuditReportName = "Instances"
-	AuditReportCPU         AuditReportName = "CPU"
-	AuditReportGPU         AuditReportName = "GPU"
-	AuditReportMemory      AuditReportName = "Memory"
-	AuditReportStorage     AuditReportName = "Storage"
-	AuditReportNetwork     AuditReportName = "Net"
-	AuditReportDNS         AuditReportName = "DNS"
-	AuditReportLogs        AuditReportName = "Logs"
-	AuditReportPostgres    AuditReportName = "Postgres"
-	AuditReportRedis       AuditReportName = "Redis"
-	AuditReportMongodb     AuditReportName = "Mongodb"
-	AuditReportMemcached   AuditReportName = "Memcached"
-	AuditReportMysql       AuditReportName = "Mysql"
-	AuditReportJvm         AuditReportName = "JVM"
-	AuditReportDotNet      AuditReportName = ".NET"
-	AuditReportPython      AuditReportName = "Python"
-	AuditReportNodejs      AuditReportName = "Node.js"
-	AuditReportNode        AuditReportName = "Node"
-	AuditReportDeployments AuditReportName = "Deployments"
-	AuditReportProfiling   AuditReportName = "Profiling"
-	AuditReportTracing     AuditReportName = "Tracing"
+	AuditReportSLO           AuditReportName = "SLO"
+	AuditReportInstances     AuditReportName = "Instances"
+	AuditReportCPU           AuditReportName = "CPU"
+	AuditReportGPU           AuditReportName = "GPU"
+	AuditReportMemory        AuditReportName = "Memory"
+	AuditReportStorage       AuditReportName = "Storage"
+	AuditReportNetwork       AuditReportName = "Net"
+	AuditReportDNS           AuditReportName = "DNS"
+	AuditReportLogs          AuditReportName = "Logs"
+	AuditReportPostgres      AuditReportName = "Postgres"
+	AuditReportRedis         AuditReportName = "Redis"
+	AuditReportMongodb       AuditReportName = "Mongodb"
+	AuditReportMemcached     AuditReportName = "Memcached"
+	AuditReportMysql         AuditReportName = "Mysql"
+	AuditReportKafka         AuditReportName = "Kafka"
+	AuditReportRabbitmq      AuditReportName = "RabbitMQ"
+	AuditReportNats          AuditReportName = "NATS"
+	AuditReportElasticsearch AuditReportName = "Elasticsearch"
+	AuditReportCassandra     AuditReportName = "Cassandra"
+	AuditReportClickHouse    AuditReportName = "ClickHouse"
+	AuditReportProxy         AuditReportName = "Proxy"
+	AuditReportJvm           AuditReportName = "JVM"
+	AuditReportDotNet        AuditReportName = ".NET"
+	AuditReportPython        AuditReportName = "Python"
+	AuditReportNodejs        AuditReportName = "Node.js"
+	AuditReportNode          AuditReportName = "Node"
+	AuditReportDeployments   AuditReportName = "Deployments"
+	AuditReportProfiling     AuditReportName = "Profiling"
+	AuditReportTracing       AuditReportName = "Tracing"
 )
 
 type ConfigurationHint struct {
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+type Cassandra struct {
+	Up      *timeseries.TimeSeries
+	Error   LabelLastValue
+	Warning LabelLastValue
+	Version LabelLastValue
+
+	Requests     map[string]*timeseries.TimeSeries // by operation (read, write)
+	RequestsTime map[string]*timeseries.TimeSeries // by operation (read, write)
+
+	PendingCompactions *timeseries.TimeSeries
+	DroppedMessages    map[string]*timeseries.TimeSeries // by message type
+	Hints              *timeseries.TimeSeries
+}
+
+func NewCassandra() *Cassandra {
+	return &Cassandra{
+		Requests:        map[string]*timeseries.TimeSeries{},
+		RequestsTime:    map[string]*timeseries.TimeSeries{},
+		DroppedMessages: map[string]*timeseries.TimeSeries{},
+	}
+}
+
+func (c *Cassandra) IsUp() bool {
+	return c.Up.Last() > 0
+}
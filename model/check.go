@@ -109,6 +109,40 @@ var Checks = struct {
 	MysqlReplicationStatus     CheckConfig
 	MysqlReplicationLag        CheckConfig
 	MysqlConnections           CheckConfig
+	KafkaConsumerLagTime       CheckConfig
+	KafkaConsumerLagMessages   CheckConfig
+	KafkaUnderReplicated       CheckConfig
+	KafkaOfflinePartitions     CheckConfig
+	KafkaActiveControllers     CheckConfig
+	RabbitmqQueueGrowth        CheckConfig
+	RabbitmqUnackedMessages    CheckConfig
+	RabbitmqNoConsumers        CheckConfig
+	RabbitmqAlarms             CheckConfig
+	RabbitmqPartitions         CheckConfig
+	NatsSlowConsumers          CheckConfig
+	NatsJetstreamPending       CheckConfig
+	ElasticsearchClusterRed    CheckConfig
+	ElasticsearchClusterYellow CheckConfig
+	ElasticsearchUnassigned    CheckConfig
+	ElasticsearchHeapUsage     CheckConfig
+	ElasticsearchRejectedTasks CheckConfig
+	ElasticsearchIndexingTime  CheckConfig
+	ElasticsearchSearchTime    CheckConfig
+	CassandraAvailability      CheckConfig
+	CassandraReadLatency       CheckConfig
+	CassandraWriteLatency      CheckConfig
+	CassandraCompactions       CheckConfig
+	CassandraDroppedMutations  CheckConfig
+	CassandraHintedHandoffs    CheckConfig
+	ClickHouseReplicationQueue CheckConfig
+	ClickHouseParts            CheckConfig
+	ClickHouseRejectedInserts  CheckConfig
+	ClickHouseKeeperSession    CheckConfig
+	ProxyUpstreamErrors        CheckConfig
+	ProxyConnectFailures       CheckConfig
+	ProxyConnectionLimits      CheckConfig
+	ProxyConnectionSaturation  CheckConfig
+	ProxyCircuitBreakers       CheckConfig
 }{
 	index: map[CheckId]*CheckConfig{},
 
@@ -507,6 +541,286 @@ var Checks = struct {
 		ConditionFormatTemplate: "the number of connections > <threshold> of `max_connections`",
 		Unit:                    CheckUnitPercent,
 	},
+	KafkaConsumerLagTime: CheckConfig{
+		Category:                AuditReportKafka,
+		Type:                    CheckTypeItemBased,
+		Title:                   "Kafka consumer lag (time)",
+		DefaultThreshold:        60,
+		MessageTemplate:         `{{.ItemsWithToBe "consumer group"}} far behind the producers`,
+		ConditionFormatTemplate: "the consumer group lag > <threshold>",
+		Unit:                    CheckUnitSecond,
+	},
+	KafkaConsumerLagMessages: CheckConfig{
+		Category:                AuditReportKafka,
+		Type:                    CheckTypeItemBased,
+		Title:                   "Kafka consumer lag (messages)",
+		DefaultThreshold:        10000,
+		MessageTemplate:         `{{.ItemsWithHave "consumer group"}} too many unconsumed messages`,
+		ConditionFormatTemplate: "the number of unconsumed messages in a consumer group > <threshold>",
+	},
+	KafkaUnderReplicated: CheckConfig{
+		Category:                AuditReportKafka,
+		Type:                    CheckTypeEventBased,
+		Title:                   "Kafka under-replicated partitions",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "partition"}} under-replicated`,
+		ConditionFormatTemplate: "the number of under-replicated partitions > <threshold>",
+	},
+	KafkaOfflinePartitions: CheckConfig{
+		Category:                AuditReportKafka,
+		Type:                    CheckTypeEventBased,
+		Title:                   "Kafka offline partitions",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "partition"}} without an active leader`,
+		ConditionFormatTemplate: "the number of offline partitions > <threshold>",
+	},
+	KafkaActiveControllers: CheckConfig{
+		Category:                AuditReportKafka,
+		Type:                    CheckTypeManual,
+		Title:                   "Kafka controller",
+		DefaultThreshold:        1,
+		MessageTemplate:         `the cluster has {{.Value}} active controllers`,
+		ConditionFormatTemplate: "the number of active controllers != <threshold>",
+	},
+	RabbitmqQueueGrowth: CheckConfig{
+		Category:                AuditReportRabbitmq,
+		Type:                    CheckTypeItemBased,
+		Title:                   "RabbitMQ queue growth",
+		DefaultThreshold:        1000,
+		MessageTemplate:         `{{.ItemsWithToBe "queue"}} growing`,
+		ConditionFormatTemplate: "the number of ready messages in a queue has increased by > <threshold>",
+	},
+	RabbitmqUnackedMessages: CheckConfig{
+		Category:                AuditReportRabbitmq,
+		Type:                    CheckTypeItemBased,
+		Title:                   "RabbitMQ unacknowledged messages",
+		DefaultThreshold:        1000,
+		MessageTemplate:         `{{.ItemsWithHave "queue"}} too many unacknowledged messages`,
+		ConditionFormatTemplate: "the number of unacknowledged messages in a queue > <threshold>",
+	},
+	RabbitmqNoConsumers: CheckConfig{
+		Category:                AuditReportRabbitmq,
+		Type:                    CheckTypeItemBased,
+		Title:                   "RabbitMQ consumers",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.ItemsWithHave "queue"}} lost all consumers`,
+		ConditionFormatTemplate: "the number of consumers of a previously consumed queue <= <threshold>",
+	},
+	RabbitmqAlarms: CheckConfig{
+		Category:                AuditReportRabbitmq,
+		Type:                    CheckTypeItemBased,
+		Title:                   "RabbitMQ resource alarms",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.ItemsWithHave "node"}} memory or disk alarms, publishers are blocked`,
+		ConditionFormatTemplate: "the number of nodes with active memory or disk alarms > <threshold>",
+	},
+	RabbitmqPartitions: CheckConfig{
+		Category:                AuditReportRabbitmq,
+		Type:                    CheckTypeEventBased,
+		Title:                   "RabbitMQ network partitions",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "network partition"}} detected`,
+		ConditionFormatTemplate: "the number of network partitions > <threshold>",
+	},
+	NatsSlowConsumers: CheckConfig{
+		Category:                AuditReportNats,
+		Type:                    CheckTypeEventBased,
+		Title:                   "NATS slow consumers",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "slow consumer"}} detected`,
+		ConditionFormatTemplate: "the number of slow consumers > <threshold>",
+	},
+	NatsJetstreamPending: CheckConfig{
+		Category:                AuditReportNats,
+		Type:                    CheckTypeItemBased,
+		Title:                   "NATS JetStream pending messages",
+		DefaultThreshold:        10000,
+		MessageTemplate:         `{{.ItemsWithHave "JetStream consumer"}} too many pending messages`,
+		ConditionFormatTemplate: "the number of pending messages of a JetStream consumer > <threshold>",
+	},
+	ElasticsearchClusterRed: CheckConfig{
+		Category:                AuditReportElasticsearch,
+		Type:                    CheckTypeItemBased,
+		Title:                   "Cluster status (red)",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.ItemsWithToBe "cluster"}} red, some primary shards are unassigned`,
+		ConditionFormatTemplate: "the cluster status is red",
+	},
+	ElasticsearchClusterYellow: CheckConfig{
+		Category:                AuditReportElasticsearch,
+		Type:                    CheckTypeItemBased,
+		Title:                   "Cluster status (yellow)",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.ItemsWithToBe "cluster"}} yellow, some replica shards are unassigned`,
+		ConditionFormatTemplate: "the cluster status is yellow",
+	},
+	ElasticsearchUnassigned: CheckConfig{
+		Category:                AuditReportElasticsearch,
+		Type:                    CheckTypeEventBased,
+		Title:                   "Unassigned shards",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "shard"}} unassigned`,
+		ConditionFormatTemplate: "the number of unassigned shards > <threshold>",
+	},
+	ElasticsearchHeapUsage: CheckConfig{
+		Category:                AuditReportElasticsearch,
+		Type:                    CheckTypeItemBased,
+		Title:                   "JVM heap usage",
+		DefaultThreshold:        85,
+		MessageTemplate:         `{{.ItemsWithHave "node"}} high JVM heap usage`,
+		ConditionFormatTemplate: "the heap usage of a node > <threshold>",
+		Unit:                    CheckUnitPercent,
+	},
+	ElasticsearchRejectedTasks: CheckConfig{
+		Category:                AuditReportElasticsearch,
+		Type:                    CheckTypeEventBased,
+		Title:                   "Rejected thread pool tasks",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "thread pool task"}} rejected`,
+		ConditionFormatTemplate: "the number of rejected thread pool tasks > <threshold>",
+	},
+	ElasticsearchIndexingTime: CheckConfig{
+		Category:                AuditReportElasticsearch,
+		Type:                    CheckTypeItemBased,
+		Title:                   "Indexing latency",
+		DefaultThreshold:        0.1,
+		MessageTemplate:         `{{.ItemsWithToBe "node"}} indexing documents slowly`,
+		ConditionFormatTemplate: "the average indexing time on a node > <threshold>",
+		Unit:                    CheckUnitSecond,
+	},
+	ElasticsearchSearchTime: CheckConfig{
+		Category:                AuditReportElasticsearch,
+		Type:                    CheckTypeItemBased,
+		Title:                   "Search latency",
+		DefaultThreshold:        0.5,
+		MessageTemplate:         `{{.ItemsWithToBe "node"}} serving search queries slowly`,
+		ConditionFormatTemplate: "the average search query time on a node > <threshold>",
+		Unit:                    CheckUnitSecond,
+	},
+	CassandraAvailability: CheckConfig{
+		Category:                AuditReportCassandra,
+		Type:                    CheckTypeItemBased,
+		Title:                   "Cassandra availability",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.ItemsWithToBe "cassandra instance"}} unavailable`,
+		ConditionFormatTemplate: "the number of unavailable cassandra instances > <threshold>",
+	},
+	CassandraReadLatency: CheckConfig{
+		Category:                AuditReportCassandra,
+		Type:                    CheckTypeItemBased,
+		Title:                   "Cassandra read latency",
+		DefaultThreshold:        0.1,
+		MessageTemplate:         `{{.ItemsWithToBe "cassandra instance"}} serving reads slowly`,
+		ConditionFormatTemplate: "the average read latency > <threshold>",
+		Unit:                    CheckUnitSecond,
+	},
+	CassandraWriteLatency: CheckConfig{
+		Category:                AuditReportCassandra,
+		Type:                    CheckTypeItemBased,
+		Title:                   "Cassandra write latency",
+		DefaultThreshold:        0.05,
+		MessageTemplate:         `{{.ItemsWithToBe "cassandra instance"}} serving writes slowly`,
+		ConditionFormatTemplate: "the average write latency > <threshold>",
+		Unit:                    CheckUnitSecond,
+	},
+	CassandraCompactions: CheckConfig{
+		Category:                AuditReportCassandra,
+		Type:                    CheckTypeItemBased,
+		Title:                   "Cassandra pending compactions",
+		DefaultThreshold:        100,
+		MessageTemplate:         `{{.ItemsWithHave "cassandra instance"}} too many pending compactions`,
+		ConditionFormatTemplate: "the number of pending compactions > <threshold>",
+	},
+	CassandraDroppedMutations: CheckConfig{
+		Category:                AuditReportCassandra,
+		Type:                    CheckTypeEventBased,
+		Title:                   "Cassandra dropped mutations",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "mutation"}} dropped`,
+		ConditionFormatTemplate: "the number of dropped mutations > <threshold>",
+	},
+	CassandraHintedHandoffs: CheckConfig{
+		Category:                AuditReportCassandra,
+		Type:                    CheckTypeEventBased,
+		Title:                   "Cassandra hinted handoffs",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "hint"}} stored for unavailable replicas`,
+		ConditionFormatTemplate: "the number of stored hints > <threshold>",
+	},
+	ClickHouseReplicationQueue: CheckConfig{
+		Category:                AuditReportClickHouse,
+		Type:                    CheckTypeItemBased,
+		Title:                   "ClickHouse replication queue",
+		DefaultThreshold:        100,
+		MessageTemplate:         `{{.ItemsWithHave "clickhouse replica"}} large replication queues`,
+		ConditionFormatTemplate: "the number of tasks in the replication queue > <threshold>",
+	},
+	ClickHouseParts: CheckConfig{
+		Category:                AuditReportClickHouse,
+		Type:                    CheckTypeItemBased,
+		Title:                   "ClickHouse parts",
+		DefaultThreshold:        300,
+		MessageTemplate:         `{{.ItemsWithHave "clickhouse instance"}} too many parts in a partition`,
+		ConditionFormatTemplate: "the max number of parts in a partition > <threshold>",
+	},
+	ClickHouseRejectedInserts: CheckConfig{
+		Category:                AuditReportClickHouse,
+		Type:                    CheckTypeEventBased,
+		Title:                   "ClickHouse rejected inserts",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "insert"}} rejected due to too many parts`,
+		ConditionFormatTemplate: "the number of rejected inserts > <threshold>",
+	},
+	ClickHouseKeeperSession: CheckConfig{
+		Category:                AuditReportClickHouse,
+		Type:                    CheckTypeItemBased,
+		Title:                   "ClickHouse ZooKeeper/Keeper session",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.ItemsWithHave "clickhouse instance"}} lost the ZooKeeper/Keeper session`,
+		ConditionFormatTemplate: "the ZooKeeper/Keeper session has been lost or some replicas are read-only",
+	},
+	ProxyUpstreamErrors: CheckConfig{
+		Category:                AuditReportProxy,
+		Type:                    CheckTypeItemBased,
+		Title:                   "Upstream errors",
+		DefaultThreshold:        5,
+		MessageTemplate:         `{{.ItemsWithToBe "upstream"}} returning 5xx errors`,
+		ConditionFormatTemplate: "the percentage of 5xx responses from an upstream > <threshold>",
+		Unit:                    CheckUnitPercent,
+	},
+	ProxyConnectFailures: CheckConfig{
+		Category:                AuditReportProxy,
+		Type:                    CheckTypeEventBased,
+		Title:                   "Upstream connection failures",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "upstream connection attempt"}} failed`,
+		ConditionFormatTemplate: "the number of failed upstream connection attempts > <threshold>",
+	},
+	ProxyConnectionLimits: CheckConfig{
+		Category:                AuditReportProxy,
+		Type:                    CheckTypeEventBased,
+		Title:                   "Connection limits",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "client connection"}} dropped due to connection limits`,
+		ConditionFormatTemplate: "the number of connections dropped due to worker or listener limits > <threshold>",
+	},
+	ProxyConnectionSaturation: CheckConfig{
+		Category:                AuditReportProxy,
+		Type:                    CheckTypeItemBased,
+		Title:                   "Connection saturation",
+		DefaultThreshold:        80,
+		Unit:                    CheckUnitPercent,
+		MessageTemplate:         `{{.ItemsWithToBe "instance"}} using over {{.ThresholdPercent}} of the connection limit, max usage: {{.ValuePercent}}`,
+		ConditionFormatTemplate: "the percentage of the worker or listener connection limit in use > <threshold>",
+	},
+	ProxyCircuitBreakers: CheckConfig{
+		Category:                AuditReportProxy,
+		Type:                    CheckTypeEventBased,
+		Title:                   "Circuit breakers",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "request"}} rejected by circuit breakers`,
+		ConditionFormatTemplate: "the number of requests rejected by circuit breakers > <threshold>",
+	},
 }
 
 func init() {
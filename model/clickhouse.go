@@ -0,0 +1,25 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+type ClickHouse struct {
+	ReplicationQueueSize *timeseries.TimeSeries
+	ReplicationDelay     *timeseries.TimeSeries
+	ReadonlyReplicas     *timeseries.TimeSeries
+
+	MaxPartsPerPartition *timeseries.TimeSeries
+	MergesRunning        *timeseries.TimeSeries
+	Merges               *timeseries.TimeSeries
+
+	RejectedInserts *timeseries.TimeSeries
+	DelayedInserts  *timeseries.TimeSeries
+
+	ZooKeeperSessions           *timeseries.TimeSeries
+	ZooKeeperHardwareExceptions *timeseries.TimeSeries
+}
+
+func NewClickHouse() *ClickHouse {
+	return &ClickHouse{}
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+type ElasticsearchNode struct {
+	HeapUsed      *timeseries.TimeSeries
+	HeapMax       *timeseries.TimeSeries
+	RejectedTasks map[string]*timeseries.TimeSeries // by thread pool
+
+	IndexingTime *timeseries.TimeSeries
+	IndexingOps  *timeseries.TimeSeries
+	SearchTime   *timeseries.TimeSeries
+	SearchOps    *timeseries.TimeSeries
+}
+
+type Elasticsearch struct {
+	ClusterName      LabelLastValue
+	ClusterStatus    LabelLastValue
+	ClusterNodes     *timeseries.TimeSeries
+	UnassignedShards *timeseries.TimeSeries
+
+	// by node name, the exporter can collect the stats of all nodes of the cluster
+	Nodes map[string]*ElasticsearchNode
+}
+
+func NewElasticsearch() *Elasticsearch {
+	return &Elasticsearch{
+		Nodes: map[string]*ElasticsearchNode{},
+	}
+}
+
+func (es *Elasticsearch) GetOrCreateNode(name string) *ElasticsearchNode {
+	n := es.Nodes[name]
+	if n == nil {
+		n = &ElasticsearchNode{RejectedTasks: map[string]*timeseries.TimeSeries{}}
+		es.Nodes[name] = n
+	}
+	return n
+}
@@ -65,6 +65,15 @@ type Instance struct {
 	Mongodb   *Mongodb
 	Memcached *Memcached
 	Mysql     *Mysql
+	Kafka     *Kafka
+	Rabbitmq  *Rabbitmq
+	Nats      *Nats
+
+	Elasticsearch *Elasticsearch
+	Cassandra     *Cassandra
+	ClickHouse    *ClickHouse
+
+	Proxy *Proxy
 }
 
 func NewInstance(name string, owner *Application) *Instance {
@@ -107,6 +116,20 @@ func (instance *Instance) InstrumentedType() ApplicationType {
 		return ApplicationTypeMongodb
 	case instance.Memcached != nil:
 		return ApplicationTypeMemcached
+	case instance.Kafka != nil:
+		return ApplicationTypeKafka
+	case instance.Rabbitmq != nil:
+		return ApplicationTypeRabbitmq
+	case instance.Nats != nil:
+		return ApplicationTypeNats
+	case instance.Elasticsearch != nil:
+		return ApplicationTypeElasticsearch
+	case instance.Cassandra != nil:
+		return ApplicationTypeCassandra
+	case instance.ClickHouse != nil:
+		return ApplicationTypeClickHouse
+	case instance.Proxy != nil:
+		return instance.Proxy.Type
 	}
 	return ApplicationTypeUnknown
 }
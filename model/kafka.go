@@ -0,0 +1,41 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/coroot/coroot/timeseries"
+)
+
+type KafkaConsumerGroupKey struct {
+	Group string
+	Topic string
+}
+
+func (k KafkaConsumerGroupKey) String() string {
+	return fmt.Sprintf("%s: %s", k.Group, k.Topic)
+}
+
+type Kafka struct {
+	// broker metrics (JMX)
+	ActiveControllers         *timeseries.TimeSeries
+	OfflinePartitions         *timeseries.TimeSeries
+	UnderReplicatedPartitions *timeseries.TimeSeries
+
+	// cluster metrics (kafka-exporter, kafka-lag-exporter)
+	Brokers                        *timeseries.TimeSeries
+	TopicUnderReplicatedPartitions map[string]*timeseries.TimeSeries
+	TopicOfflinePartitions         map[string]*timeseries.TimeSeries
+	TopicProduceRate               map[string]*timeseries.TimeSeries
+	ConsumerGroupLag               map[KafkaConsumerGroupKey]*timeseries.TimeSeries
+	ConsumerGroupLagSeconds        map[KafkaConsumerGroupKey]*timeseries.TimeSeries
+}
+
+func NewKafka() *Kafka {
+	return &Kafka{
+		TopicUnderReplicatedPartitions: map[string]*timeseries.TimeSeries{},
+		TopicOfflinePartitions:         map[string]*timeseries.TimeSeries{},
+		TopicProduceRate:               map[string]*timeseries.TimeSeries{},
+		ConsumerGroupLag:               map[KafkaConsumerGroupKey]*timeseries.TimeSeries{},
+		ConsumerGroupLagSeconds:        map[KafkaConsumerGroupKey]*timeseries.TimeSeries{},
+	}
+}
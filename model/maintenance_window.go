@@ -0,0 +1,67 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/coroot/coroot/timeseries"
+	"github.com/robfig/cron/v3"
+)
+
+// MaintenanceWindow is a recurring period defined by a cron schedule (its start) and a duration.
+// For example, {Schedule: "0 1 * * *", Duration: 2h, Timezone: "Europe/Berlin"} means every night from 01:00 to 03:00 Berlin time.
+type MaintenanceWindow struct {
+	Schedule string              `json:"schedule" yaml:"schedule"`
+	Duration timeseries.Duration `json:"duration" yaml:"duration"`
+	Timezone string              `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	Comment  string              `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+func (w MaintenanceWindow) Validate() error {
+	if _, err := cron.ParseStandard(w.Schedule); err != nil {
+		return fmt.Errorf("invalid schedule '%s': %w", w.Schedule, err)
+	}
+	if w.Duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone '%s': %w", w.Timezone, err)
+	}
+	return nil
+}
+
+// IsActive reports whether t falls within one of the occurrences of the window.
+func (w MaintenanceWindow) IsActive(t timeseries.Time) bool {
+	schedule, err := cron.ParseStandard(w.Schedule)
+	if err != nil || w.Duration <= 0 {
+		return false
+	}
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return false
+	}
+	now := t.ToStandard().In(loc)
+	start := schedule.Next(now.Add(-w.Duration.ToStandard()))
+	return !start.After(now)
+}
+
+type MaintenanceWindows []MaintenanceWindow
+
+func (ws MaintenanceWindows) IsActive(t timeseries.Time) bool {
+	for _, w := range ws {
+		if w.IsActive(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ws MaintenanceWindows) MaxDuration() timeseries.Duration {
+	var res timeseries.Duration
+	for _, w := range ws {
+		if w.Duration > res {
+			res = w.Duration
+		}
+	}
+	return res
+}
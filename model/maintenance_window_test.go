@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceWindow(t *testing.T) {
+	w := MaintenanceWindow{Schedule: "0 1 * * *", Duration: 2 * timeseries.Hour, Timezone: "Europe/Berlin"}
+	assert.NoError(t, w.Validate())
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	at := func(hour, min int) timeseries.Time {
+		return timeseries.Time(time.Date(2024, 7, 10, hour, min, 0, 0, berlin).Unix())
+	}
+	assert.False(t, w.IsActive(at(0, 59)))
+	assert.True(t, w.IsActive(at(1, 0)))
+	assert.True(t, w.IsActive(at(2, 30)))
+	assert.False(t, w.IsActive(at(3, 0)))
+	assert.False(t, w.IsActive(at(13, 0)))
+
+	assert.Error(t, MaintenanceWindow{Schedule: "0 1 * *", Duration: timeseries.Hour}.Validate())
+	assert.Error(t, MaintenanceWindow{Schedule: "0 1 * * *"}.Validate())
+	assert.Error(t, MaintenanceWindow{Schedule: "0 1 * * *", Duration: timeseries.Hour, Timezone: "Mars/Olympus"}.Validate())
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/coroot/coroot/timeseries"
+)
+
+type NatsConsumerKey struct {
+	Stream   string
+	Consumer string
+}
+
+func (k NatsConsumerKey) String() string {
+	return fmt.Sprintf("%s: %s", k.Stream, k.Consumer)
+}
+
+type Nats struct {
+	SlowConsumers    *timeseries.TimeSeries
+	JetstreamPending map[NatsConsumerKey]*timeseries.TimeSeries
+}
+
+func NewNats() *Nats {
+	return &Nats{
+		JetstreamPending: map[NatsConsumerKey]*timeseries.TimeSeries{},
+	}
+}
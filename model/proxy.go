@@ -0,0 +1,60 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+type ProxyUpstream struct {
+	Requests        *timeseries.TimeSeries
+	Errors          *timeseries.TimeSeries // 5xx responses
+	ConnectFailures *timeseries.TimeSeries
+	Overflows       *timeseries.TimeSeries // requests and connections rejected by circuit breakers (Envoy only)
+}
+
+// Proxy holds the metrics of a reverse proxy (Nginx or Envoy).
+type Proxy struct {
+	Type ApplicationType
+
+	ActiveConnections *timeseries.TimeSeries
+	// DroppedConnections is the rate of client connections dropped because the worker (Nginx)
+	// or listener (Envoy) connection limit has been reached.
+	DroppedConnections *timeseries.TimeSeries
+	// ConnectionsLimit is the total number of connections the workers can handle (Nginx): worker_processes * worker_connections.
+	ConnectionsLimit *timeseries.TimeSeries
+	// ConnectionsUsage is the percentage of the global downstream connection limit in use (Envoy).
+	ConnectionsUsage *timeseries.TimeSeries
+
+	Upstreams map[string]*ProxyUpstream // by upstream (Nginx) or cluster (Envoy) name
+}
+
+func NewProxy(t ApplicationType) *Proxy {
+	return &Proxy{
+		Type:      t,
+		Upstreams: map[string]*ProxyUpstream{},
+	}
+}
+
+func (p *Proxy) GetOrCreateUpstream(name string) *ProxyUpstream {
+	u := p.Upstreams[name]
+	if u == nil {
+		u = &ProxyUpstream{}
+		p.Upstreams[name] = u
+	}
+	return u
+}
+
+// GetConnectionsUsage returns the percentage of the connection limit in use, or nil if the limit is unknown.
+func (p *Proxy) GetConnectionsUsage() *timeseries.TimeSeries {
+	if !p.ConnectionsUsage.IsEmpty() {
+		return p.ConnectionsUsage
+	}
+	if p.ConnectionsLimit.IsEmpty() || p.ActiveConnections.IsEmpty() {
+		return nil
+	}
+	return timeseries.Aggregate2(p.ActiveConnections, p.ConnectionsLimit, func(active, limit float32) float32 {
+		if limit <= 0 {
+			return timeseries.NaN
+		}
+		return active / limit * 100
+	})
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyConnectionsUsage(t *testing.T) {
+	p := NewProxy(ApplicationTypeNginx)
+	assert.Nil(t, p.GetConnectionsUsage())
+
+	p.ActiveConnections = timeseries.NewWithData(0, 15, []float32{100, 900, 50})
+	assert.Nil(t, p.GetConnectionsUsage(), "no limit")
+
+	p.ConnectionsLimit = timeseries.NewWithData(0, 15, []float32{1000, 1000, 0})
+	usage := p.GetConnectionsUsage()
+	assert.Equal(t, float32(10), usage.Reduce(timeseries.Min))
+	assert.Equal(t, float32(90), usage.Reduce(timeseries.Max))
+	assert.True(t, timeseries.IsNaN(usage.Last()), "a zero limit means the limit is unknown")
+
+	p = NewProxy(ApplicationTypeEnvoy)
+	p.ConnectionsUsage = timeseries.NewWithData(0, 15, []float32{42})
+	assert.Equal(t, float32(42), p.GetConnectionsUsage().Last())
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+type RabbitmqQueueKey struct {
+	Vhost string
+	Queue string
+}
+
+func (k RabbitmqQueueKey) String() string {
+	if k.Vhost == "" || k.Vhost == "/" {
+		return k.Queue
+	}
+	return k.Vhost + "/" + k.Queue
+}
+
+type RabbitmqQueue struct {
+	Ready     *timeseries.TimeSeries
+	Unacked   *timeseries.TimeSeries
+	Consumers *timeseries.TimeSeries
+}
+
+type Rabbitmq struct {
+	Queues map[RabbitmqQueueKey]*RabbitmqQueue
+
+	// by the RabbitMQ node name (empty if the metrics are exposed by the node itself)
+	MemoryAlarm map[string]*timeseries.TimeSeries
+	DiskAlarm   map[string]*timeseries.TimeSeries
+	Partitions  map[string]*timeseries.TimeSeries
+}
+
+func NewRabbitmq() *Rabbitmq {
+	return &Rabbitmq{
+		Queues:      map[RabbitmqQueueKey]*RabbitmqQueue{},
+		MemoryAlarm: map[string]*timeseries.TimeSeries{},
+		DiskAlarm:   map[string]*timeseries.TimeSeries{},
+		Partitions:  map[string]*timeseries.TimeSeries{},
+	}
+}
+
+func (r *Rabbitmq) GetOrCreateQueue(k RabbitmqQueueKey) *RabbitmqQueue {
+	q := r.Queues[k]
+	if q == nil {
+		q = &RabbitmqQueue{}
+		r.Queues[k] = q
+	}
+	return q
+}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+type SilenceLabelMatcher struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type SilenceMatchers struct {
+	RuleIds               []AlertingRuleId      `json:"rule_ids,omitempty"`
+	ApplicationIdPatterns []string              `json:"application_id_patterns,omitempty"`
+	Categories            []ApplicationCategory `json:"categories,omitempty"`
+	Severities            []Status              `json:"severities,omitempty"`
+	Labels                []SilenceLabelMatcher `json:"labels,omitempty"`
+}
+
+func (m SilenceMatchers) IsEmpty() bool {
+	return len(m.RuleIds) == 0 && len(m.ApplicationIdPatterns) == 0 && len(m.Categories) == 0 && len(m.Severities) == 0 && len(m.Labels) == 0
+}
+
+type Silence struct {
+	Id        string          `json:"id"`
+	ProjectId string          `json:"project_id"`
+	StartsAt  timeseries.Time `json:"starts_at"`
+	EndsAt    timeseries.Time `json:"ends_at"`
+	CreatedBy string          `json:"created_by"`
+	CreatedAt timeseries.Time `json:"created_at"`
+	Comment   string          `json:"comment"`
+	Matchers  SilenceMatchers `json:"matchers"`
+}
+
+func (s *Silence) IsActive(now timeseries.Time) bool {
+	return !s.StartsAt.After(now) && s.EndsAt.After(now)
+}
+
+// Matches reports whether the silence applies to an alert or incident with the given attributes.
+// All non-empty matchers must match; values within a single matcher are ORed.
+func (s *Silence) Matches(ruleId string, appId ApplicationId, category ApplicationCategory, severity Status, labels map[string]string) bool {
+	m := s.Matchers
+	if m.IsEmpty() {
+		return false
+	}
+	if len(m.RuleIds) > 0 {
+		found := false
+		for _, id := range m.RuleIds {
+			if string(id) == ruleId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(m.ApplicationIdPatterns) > 0 && !utils.GlobMatch(appId.StringWithoutClusterId(), m.ApplicationIdPatterns...) {
+		return false
+	}
+	if len(m.Categories) > 0 {
+		found := false
+		for _, c := range m.Categories {
+			if c == category {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(m.Severities) > 0 {
+		found := false
+		for _, sev := range m.Severities {
+			if sev == severity {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, l := range m.Labels {
+		v, ok := labels[l.Name]
+		if !ok || !utils.GlobMatch(v, l.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Silence) MatchesAlert(a *Alert) bool {
+	return s.Matches(a.RuleId, a.ApplicationId, a.ApplicationCategory, a.Severity, a.Labels())
+}
+
+type Silences []*Silence
+
+func (ss Silences) FindForAlert(a *Alert, now timeseries.Time) *Silence {
+	for _, s := range ss {
+		if s.IsActive(now) && s.MatchesAlert(a) {
+			return s
+		}
+	}
+	return nil
+}
+
+// Labels returns the label values of the alert parsed from its "Labels" detail (lines of name="value").
+func (a *Alert) Labels() map[string]string {
+	res := map[string]string{}
+	for _, d := range a.Details {
+		if d.Name != "Labels" {
+			continue
+		}
+		for _, line := range strings.Split(d.Value, "\n") {
+			name, value, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			res[strings.TrimSpace(name)] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return res
+}
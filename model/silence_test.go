@@ -0,0 +1,46 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSilenceMatchesAlert(t *testing.T) {
+	now := timeseries.Time(1000)
+	alert := &Alert{
+		RuleId:              "storage-space",
+		ApplicationId:       NewApplicationId("c1", "default", ApplicationKindDeployment, "api"),
+		ApplicationCategory: "application",
+		Severity:            CRITICAL,
+		Details:             []AlertDetail{{Name: "Labels", Value: "instance=\"node-1\"\njob=\"node\""}},
+	}
+
+	s := &Silence{StartsAt: now.Add(-timeseries.Minute), EndsAt: now.Add(timeseries.Hour)}
+	assert.False(t, s.MatchesAlert(alert), "a silence without matchers must not match anything")
+
+	s.Matchers = SilenceMatchers{ApplicationIdPatterns: []string{"default:*:api"}}
+	assert.True(t, s.MatchesAlert(alert))
+
+	s.Matchers.Severities = []Status{WARNING}
+	assert.False(t, s.MatchesAlert(alert))
+	s.Matchers.Severities = []Status{WARNING, CRITICAL}
+	assert.True(t, s.MatchesAlert(alert))
+
+	s.Matchers.RuleIds = []AlertingRuleId{"cpu"}
+	assert.False(t, s.MatchesAlert(alert))
+	s.Matchers.RuleIds = []AlertingRuleId{"storage-space"}
+	assert.True(t, s.MatchesAlert(alert))
+
+	s.Matchers.Labels = []SilenceLabelMatcher{{Name: "instance", Value: "node-*"}}
+	assert.True(t, s.MatchesAlert(alert))
+	s.Matchers.Labels = []SilenceLabelMatcher{{Name: "instance", Value: "node-2"}}
+	assert.False(t, s.MatchesAlert(alert))
+
+	s.Matchers.Labels = nil
+	ss := Silences{s}
+	assert.Equal(t, s, ss.FindForAlert(alert, now))
+	assert.Nil(t, ss.FindForAlert(alert, now.Add(2*timeseries.Hour)))
+	assert.Nil(t, ss.FindForAlert(alert, now.Add(-2*timeseries.Minute)))
+}
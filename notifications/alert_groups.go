@@ -0,0 +1,313 @@
+package notifications
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+const (
+	alertGroupFlushInterval = 10 * time.Second
+	maxAlertGroupLines      = 20
+)
+
+// alertGroupsLock serializes read-modify-write updates of alert groups,
+// which happen from the watchers, the API, and the notifier.
+var alertGroupsLock sync.Mutex
+
+// groupAlert adds the alert to its group for the destination or marks it resolved there.
+// It returns false if the alert must be notified individually: a resolved alert that isn't
+// a member of any group was opened before grouping was enabled or while it was silenced.
+func groupAlert(database *db.DB, project *db.Project, category model.ApplicationCategory, key string, labels map[string]string, alert *model.Alert, details *db.AlertNotificationDetails, destination db.IncidentNotificationDestination) bool {
+	alertGroupsLock.Lock()
+	defer alertGroupsLock.Unlock()
+
+	if alert.ResolvedAt > 0 {
+		groups, err := database.GetAlertGroups(project.Id)
+		if err != nil {
+			klog.Errorln(err)
+			return false
+		}
+		for _, g := range groups {
+			if g.Destination != destination {
+				continue
+			}
+			m := g.Members[alert.Id]
+			if m == nil || m.Resolved() {
+				continue
+			}
+			m.ResolvedAt = alert.ResolvedAt
+			m.Details = details
+			g.Version++
+			if err = database.PutAlertGroup(g); err != nil {
+				klog.Errorln(err)
+			}
+			return true
+		}
+		return false
+	}
+
+	g, err := database.GetAlertGroup(project.Id, key, destination)
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		g = &db.AlertGroup{
+			ProjectId:   project.Id,
+			Key:         key,
+			Destination: destination,
+			Category:    category,
+			Labels:      labels,
+			Members:     map[string]*db.AlertGroupMember{},
+			CreatedAt:   timeseries.Now(),
+		}
+	case err != nil:
+		klog.Errorln(err)
+		return false
+	}
+	g.Members[alert.Id] = &db.AlertGroupMember{
+		AlertId:       alert.Id,
+		RuleId:        alert.RuleId,
+		ApplicationId: alert.ApplicationId,
+		Status:        alert.Severity,
+		OpenedAt:      alert.OpenedAt,
+		Details:       details,
+	}
+	g.Version++
+	if err = database.PutAlertGroup(g); err != nil {
+		klog.Errorln(err)
+	}
+	return true
+}
+
+func alertGroupLabels(by []string, category model.ApplicationCategory, app *model.Application, alert *model.Alert, rule *model.AlertingRule) (string, map[string]string) {
+	labels := map[string]string{}
+	for _, l := range by {
+		switch l {
+		case db.AlertGroupByRule:
+			labels[l] = alert.RuleId
+		case db.AlertGroupByCategory:
+			labels[l] = string(category)
+		case db.AlertGroupByNamespace:
+			if alert.ApplicationId.IsZero() {
+				labels[l] = alert.Labels()["namespace"]
+			} else {
+				labels[l] = alert.ApplicationId.Namespace
+			}
+		case db.AlertGroupByNode:
+			labels[l] = alertNode(app, alert)
+		}
+	}
+	key := db.AlertGroupKey(labels)
+	if _, ok := labels[db.AlertGroupByRule]; ok && rule.Name != "" {
+		labels[db.AlertGroupByRule] = rule.Name
+	}
+	return key, labels
+}
+
+// alertNode returns the node the alert relates to. For application alerts, it is the node(s) of the application's
+// instances, narrowed down to the nodes that are down if there are any: that's usually the reason of the alert.
+func alertNode(app *model.Application, alert *model.Alert) string {
+	if node := alert.Labels()["node"]; node != "" {
+		return node
+	}
+	if app == nil {
+		return ""
+	}
+	var all, down []string
+	for _, i := range app.Instances {
+		if i.Node == nil {
+			continue
+		}
+		name := i.Node.GetName()
+		all = append(all, name)
+		if i.Node.IsDown() {
+			down = append(down, name)
+		}
+	}
+	nodes := all
+	if len(down) > 0 {
+		nodes = down
+	}
+	sort.Strings(nodes)
+	return strings.Join(slices.Compact(nodes), ",")
+}
+
+func (n *AlertNotifier) sendAlertGroups() {
+	if !n.groupsLock.TryLock() {
+		return
+	}
+	defer n.groupsLock.Unlock()
+
+	groups, err := n.db.GetAlertGroups("")
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	if len(groups) == 0 {
+		return
+	}
+	ps, err := n.db.GetProjects()
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	projects := map[db.ProjectId]*db.Project{}
+	for _, p := range ps {
+		projects[p.Id] = p
+	}
+
+	now := timeseries.Now()
+	for _, g := range groups {
+		project := projects[g.ProjectId]
+		if project == nil {
+			continue
+		}
+		if g.SentAt.IsZero() && g.Firing() == 0 {
+			dropAlertGroup(n.db, g)
+			continue
+		}
+		var settings *db.ApplicationCategoryAlertGrouping
+		if category := project.GetApplicationCategories()[g.Category]; category != nil {
+			settings = category.NotificationSettings.Alerts.Grouping
+		}
+		send, repeat := g.Due(settings, now)
+		if !send || project.InMaintenanceWindow(g.Category, now) {
+			continue
+		}
+		integrations := project.Settings.Integrations
+		// if the destination is no longer configured, the group is marked as sent anyway, so that it gets closed
+		if client := getClient(g.Destination, integrations, NotificationTypeAlert); client != nil {
+			if repeat && g.Destination.IntegrationType == db.IntegrationTypeSlack {
+				g.ExternalKey = "" // a reminder is posted as a new message
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			err = client.SendAlertGroup(ctx, integrations.BaseUrl, g)
+			cancel()
+			if err != nil {
+				klog.Errorf("failed to send alert group to %s: %s", g.Destination.IntegrationType, err)
+				continue
+			}
+		}
+		markAlertGroupSent(n.db, g, now)
+	}
+}
+
+// markAlertGroupSent records the sent state of the group. The members resolved by the time of sending
+// are removed from the group, and the group is deleted once it has no members left.
+func markAlertGroupSent(database *db.DB, sent *db.AlertGroup, now timeseries.Time) {
+	alertGroupsLock.Lock()
+	defer alertGroupsLock.Unlock()
+
+	g, err := database.GetAlertGroup(sent.ProjectId, sent.Key, sent.Destination)
+	if err != nil {
+		if !errors.Is(err, db.ErrNotFound) {
+			klog.Errorln(err)
+		}
+		return
+	}
+	g.SentAt = now
+	g.SentVersion = sent.Version
+	g.ExternalKey = sent.ExternalKey
+	for id, m := range sent.Members {
+		if current := g.Members[id]; m.Resolved() && current != nil && current.Resolved() {
+			delete(g.Members, id)
+		}
+	}
+	if len(g.Members) == 0 {
+		err = database.DeleteAlertGroup(g)
+	} else {
+		err = database.PutAlertGroup(g)
+	}
+	if err != nil {
+		klog.Errorln(err)
+	}
+}
+
+// dropAlertGroup deletes a group whose alerts were all resolved before it was sent.
+func dropAlertGroup(database *db.DB, dropped *db.AlertGroup) {
+	alertGroupsLock.Lock()
+	defer alertGroupsLock.Unlock()
+
+	g, err := database.GetAlertGroup(dropped.ProjectId, dropped.Key, dropped.Destination)
+	if err != nil {
+		if !errors.Is(err, db.ErrNotFound) {
+			klog.Errorln(err)
+		}
+		return
+	}
+	if !g.SentAt.IsZero() || g.Firing() > 0 {
+		return
+	}
+	if err = database.DeleteAlertGroup(g); err != nil {
+		klog.Errorln(err)
+	}
+}
+
+func alertGroupTitle(g *db.AlertGroup) string {
+	var title string
+	firing, total := g.Firing(), len(g.Members)
+	switch {
+	case firing == 0:
+		title = fmt.Sprintf("%d alerts resolved", total)
+	case firing < total:
+		title = fmt.Sprintf("%d of %d alerts firing", firing, total)
+	default:
+		title = fmt.Sprintf("%d alerts firing", firing)
+	}
+	if len(g.Labels) > 0 {
+		var labels []string
+		for _, k := range db.AlertGroupByLabels {
+			if v, ok := g.Labels[k]; ok && v != "" {
+				labels = append(labels, fmt.Sprintf("%s=%s", k, v))
+			}
+		}
+		if len(labels) > 0 {
+			title += " (" + strings.Join(labels, ", ") + ")"
+		}
+	}
+	return title
+}
+
+// alertGroupLines returns a line per member of the group, limited to maxAlertGroupLines.
+func alertGroupLines(g *db.AlertGroup) []string {
+	members := g.SortedMembers()
+	var lines []string
+	for i, m := range members {
+		if i == maxAlertGroupLines {
+			lines = append(lines, fmt.Sprintf("and %d more", len(members)-i))
+			break
+		}
+		name := m.ApplicationId.Name
+		var summary, ruleName, duration string
+		if m.Details != nil {
+			summary, ruleName, duration = m.Details.Summary, m.Details.RuleName, m.Details.Duration
+		}
+		if name == "" {
+			name = cmp.Or(ruleName, "Alert")
+		}
+		if m.Resolved() {
+			line := fmt.Sprintf("[RESOLVED] %s: %s", name, summary)
+			if duration != "" {
+				line += fmt.Sprintf(" (duration: %s)", duration)
+			}
+			lines = append(lines, line)
+		} else {
+			lines = append(lines, fmt.Sprintf("[%s] %s: %s", strings.ToUpper(m.Status.String()), name, summary))
+		}
+	}
+	return lines
+}
+
+func alertGroupUrl(baseUrl string, g *db.AlertGroup) string {
+	return fmt.Sprintf("%s/p/%s/alerts", baseUrl, g.ProjectId)
+}
@@ -3,6 +3,8 @@ package notifications
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/coroot/coroot/db"
@@ -13,20 +15,38 @@ import (
 )
 
 type AlertNotifier struct {
-	db *db.DB
+	db         *db.DB
+	groupsLock sync.Mutex
 }
 
 func NewAlertNotifier(database *db.DB) *AlertNotifier {
 	n := &AlertNotifier{db: database}
 	go func() {
 		for range time.Tick(retryInterval) {
+			n.escalateAlerts()
 			n.sendAlerts()
 		}
 	}()
+	go func() {
+		for range time.Tick(alertGroupFlushInterval) {
+			n.sendAlertGroups()
+		}
+	}()
 	return n
 }
 
 func (n *AlertNotifier) Enqueue(project *db.Project, app *model.Application, alert *model.Alert, rule *model.AlertingRule, now timeseries.Time) {
+	if alert.SilenceId != "" { // the alert hasn't been announced yet, so neither has its resolution
+		return
+	}
+	if alert.ResolvedAt == 0 { // silences suppress only firing notifications
+		silences, err := n.db.GetActiveSilences(project.Id, now)
+		if err != nil {
+			klog.Errorln(err)
+		} else if silences.FindForAlert(alert, now) != nil {
+			return
+		}
+	}
 	category := model.ApplicationCategoryApplication
 	if app != nil {
 		category = app.Category
@@ -41,22 +61,74 @@ func (n *AlertNotifier) Enqueue(project *db.Project, app *model.Application, ale
 	if !notificationSettings.Enabled {
 		return
 	}
-	if slack := notificationSettings.Slack; slack != nil && slack.Enabled {
-		n.enqueue(now, project, alert, rule, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeSlack, SlackChannel: slack.Channel})
+	grouping := notificationSettings.Grouping
+	var groupKey string
+	var groupLabels map[string]string
+	var groupDetails *db.AlertNotificationDetails
+	if grouping != nil && grouping.Enabled {
+		groupKey, groupLabels = alertGroupLabels(grouping.By, category, app, alert, rule)
+		groupDetails = alertDetails(project, alert, rule)
 	}
-	if teams := notificationSettings.Teams; teams != nil && teams.Enabled {
-		n.enqueue(now, project, alert, rule, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeTeams, TeamsChannel: teams.Channel})
+	for _, destination := range alertDestinations(notificationSettings.ApplicationCategoryNotificationDestinations) {
+		if groupLabels != nil && groupAlert(n.db, project, category, groupKey, groupLabels, alert, groupDetails, destination) {
+			continue
+		}
+		n.enqueue(now, project, alert, rule, destination)
 	}
-	if pagerduty := notificationSettings.Pagerduty; pagerduty != nil && pagerduty.Enabled {
-		n.enqueue(now, project, alert, rule, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypePagerduty})
+	for _, destination := range escalatedDestinations(n.db, project, alert, notificationSettings) {
+		n.enqueue(now, project, alert, rule, destination)
 	}
-	if opsgenie := notificationSettings.Opsgenie; opsgenie != nil && opsgenie.Enabled {
-		n.enqueue(now, project, alert, rule, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeOpsgenie})
+	n.sendAlerts()
+}
+
+func alertDestinations(settings db.ApplicationCategoryNotificationDestinations) []db.IncidentNotificationDestination {
+	var res []db.IncidentNotificationDestination
+	if slack := settings.Slack; slack != nil && slack.Enabled {
+		res = append(res, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeSlack, SlackChannel: slack.Channel})
 	}
-	if webhook := notificationSettings.Webhook; webhook != nil && webhook.Enabled {
-		n.enqueue(now, project, alert, rule, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeWebhook})
+	if teams := settings.Teams; teams != nil && teams.Enabled {
+		res = append(res, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeTeams, TeamsChannel: teams.Channel})
 	}
-	n.sendAlerts()
+	if pagerduty := settings.Pagerduty; pagerduty != nil && pagerduty.Enabled {
+		res = append(res, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypePagerduty})
+	}
+	if opsgenie := settings.Opsgenie; opsgenie != nil && opsgenie.Enabled {
+		res = append(res, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeOpsgenie})
+	}
+	if webhook := settings.Webhook; webhook != nil && webhook.Enabled {
+		res = append(res, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeWebhook})
+	}
+	if email := settings.Email; email != nil && email.Enabled {
+		res = append(res, email.Destination())
+	}
+	if telegram := settings.Telegram; telegram != nil && telegram.Enabled {
+		res = append(res, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeTelegram, TelegramChatId: telegram.ChatId})
+	}
+	if discord := settings.Discord; discord != nil && discord.Enabled {
+		res = append(res, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeDiscord})
+	}
+	if googleChat := settings.GoogleChat; googleChat != nil && googleChat.Enabled {
+		res = append(res, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeGoogleChat})
+	}
+	if mattermost := settings.Mattermost; mattermost != nil && mattermost.Enabled {
+		res = append(res, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeMattermost, MattermostChannel: mattermost.Channel})
+	}
+	return res
+}
+
+func alertDetails(project *db.Project, alert *model.Alert, rule *model.AlertingRule) *db.AlertNotificationDetails {
+	details := &db.AlertNotificationDetails{
+		ProjectName: project.Name,
+		RuleName:    rule.Name,
+		Severity:    alert.Severity.String(),
+		Summary:     alert.Summary,
+		Details:     filterAlertDetails(alert.Details),
+		ResolvedBy:  alert.ResolvedBy,
+	}
+	if alert.ResolvedAt > 0 {
+		details.Duration = utils.FormatDurationShort(alert.ResolvedAt.Sub(alert.OpenedAt), 2)
+	}
+	return details
 }
 
 func (n *AlertNotifier) sendAlerts() {
@@ -78,7 +150,8 @@ func (n *AlertNotifier) sendAlerts() {
 		destination db.IncidentNotificationDestination
 	}
 	failedDestinations := map[destinationKey]bool{}
-	notifications, err := n.db.GetNotSentAlertNotifications(timeseries.Now().Add(-retryWindow))
+	now := timeseries.Now()
+	notifications, err := n.db.GetNotSentAlertNotifications(sendWindowStart(projects, now))
 	if err != nil {
 		klog.Errorln(err)
 		return
@@ -92,6 +165,9 @@ func (n *AlertNotifier) sendAlerts() {
 		if project == nil {
 			continue
 		}
+		if skip(project, notification.ApplicationId, notification.Timestamp, now) {
+			continue
+		}
 		integrations := project.Settings.Integrations
 		var sendErr error
 		client := getClient(notification.Destination, integrations, NotificationTypeAlert)
@@ -133,18 +209,10 @@ func (n *AlertNotifier) enqueue(now timeseries.Time, project *db.Project, alert
 		Timestamp:     now,
 		Status:        alert.Severity,
 	}
-	details := &db.AlertNotificationDetails{
-		ProjectName: project.Name,
-		RuleName:    rule.Name,
-		Severity:    alert.Severity.String(),
-		Summary:     alert.Summary,
-		Details:     filterAlertDetails(alert.Details),
-	}
-	if alert.ResolvedAt > 0 {
-		details.Duration = utils.FormatDurationShort(alert.ResolvedAt.Sub(alert.OpenedAt), 2)
-	}
+	details := alertDetails(project, alert, rule)
 	switch destination.IntegrationType {
-	case db.IntegrationTypeSlack, db.IntegrationTypeTeams, db.IntegrationTypeWebhook:
+	case db.IntegrationTypeSlack, db.IntegrationTypeTeams, db.IntegrationTypeWebhook, db.IntegrationTypeEmail,
+		db.IntegrationTypeTelegram, db.IntegrationTypeDiscord, db.IntegrationTypeGoogleChat, db.IntegrationTypeMattermost:
 		if alert.ResolvedAt > 0 {
 			n.onResolve("", notification, details)
 		} else {
@@ -219,14 +287,10 @@ func EnqueueResolvedAlerts(database *db.DB, project *db.Project, alerts []*model
 	now := timeseries.Now()
 	for _, alert := range alerts {
 		alert.ResolvedAt = now
-		category := alert.ApplicationCategory
-		if category == "" {
-			if rule.NotificationCategory != "" {
-				category = rule.NotificationCategory
-			} else {
-				category = model.ApplicationCategoryApplication
-			}
+		if alert.SilenceId != "" {
+			continue
 		}
+		category := alertCategory(alert, rule)
 		categorySettings := project.GetApplicationCategories()[category]
 		if categorySettings == nil {
 			continue
@@ -235,87 +299,32 @@ func EnqueueResolvedAlerts(database *db.DB, project *db.Project, alerts []*model
 		if !notificationSettings.Enabled {
 			continue
 		}
-		enqueueResolvedAlert(database, now, project, alert, rule, notificationSettings)
+		enqueueResolvedAlert(database, now, project, category, alert, rule, notificationSettings)
 	}
 }
 
-func enqueueResolvedAlert(database *db.DB, now timeseries.Time, project *db.Project, alert *model.Alert, rule *model.AlertingRule, settings db.ApplicationCategoryAlertNotificationSettings) {
-	details := &db.AlertNotificationDetails{
-		ProjectName: project.Name,
-		RuleName:    rule.Name,
-		Severity:    alert.Severity.String(),
-		Summary:     alert.Summary,
-		Details:     filterAlertDetails(alert.Details),
-		Duration:    utils.FormatDurationShort(now.Sub(alert.OpenedAt), 2),
-		ResolvedBy:  alert.ResolvedBy,
-	}
-
-	if slack := settings.Slack; slack != nil && slack.Enabled {
-		notification := db.AlertNotification{
-			ProjectId:     project.Id,
-			AlertId:       alert.Id,
-			RuleId:        alert.RuleId,
-			ApplicationId: alert.ApplicationId,
-			Destination:   db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeSlack, SlackChannel: slack.Channel},
-			Timestamp:     now,
-			Status:        model.OK,
-			Details:       details,
-		}
-		database.PutAlertNotification(notification)
-	}
-	if teams := settings.Teams; teams != nil && teams.Enabled {
-		notification := db.AlertNotification{
-			ProjectId:     project.Id,
-			AlertId:       alert.Id,
-			RuleId:        alert.RuleId,
-			ApplicationId: alert.ApplicationId,
-			Destination:   db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeTeams, TeamsChannel: teams.Channel},
-			Timestamp:     now,
-			Status:        model.OK,
-			Details:       details,
+func enqueueResolvedAlert(database *db.DB, now timeseries.Time, project *db.Project, category model.ApplicationCategory, alert *model.Alert, rule *model.AlertingRule, settings db.ApplicationCategoryAlertNotificationSettings) {
+	details := alertDetails(project, alert, rule)
+	grouping := settings.Grouping
+	escalated := escalatedDestinations(database, project, alert, settings)
+	for _, destination := range append(alertDestinations(settings.ApplicationCategoryNotificationDestinations), escalated...) {
+		if grouping != nil && grouping.Enabled && !slices.Contains(escalated, destination) &&
+			groupAlert(database, project, category, "", nil, alert, details, destination) {
+			continue
 		}
-		database.PutAlertNotification(notification)
-	}
-	if pagerduty := settings.Pagerduty; pagerduty != nil && pagerduty.Enabled {
-		externalKey := fmt.Sprintf("%s:%s:%s", project.Id, alert.Id, alert.Severity.String())
 		notification := db.AlertNotification{
 			ProjectId:     project.Id,
 			AlertId:       alert.Id,
 			RuleId:        alert.RuleId,
 			ApplicationId: alert.ApplicationId,
-			Destination:   db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypePagerduty},
+			Destination:   destination,
 			Timestamp:     now,
 			Status:        model.OK,
-			ExternalKey:   externalKey,
 			Details:       details,
 		}
-		database.PutAlertNotification(notification)
-	}
-	if opsgenie := settings.Opsgenie; opsgenie != nil && opsgenie.Enabled {
-		externalKey := fmt.Sprintf("%s:%s:%s", project.Id, alert.Id, alert.Severity.String())
-		notification := db.AlertNotification{
-			ProjectId:     project.Id,
-			AlertId:       alert.Id,
-			RuleId:        alert.RuleId,
-			ApplicationId: alert.ApplicationId,
-			Destination:   db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeOpsgenie},
-			Timestamp:     now,
-			Status:        model.OK,
-			ExternalKey:   externalKey,
-			Details:       details,
-		}
-		database.PutAlertNotification(notification)
-	}
-	if webhook := settings.Webhook; webhook != nil && webhook.Enabled {
-		notification := db.AlertNotification{
-			ProjectId:     project.Id,
-			AlertId:       alert.Id,
-			RuleId:        alert.RuleId,
-			ApplicationId: alert.ApplicationId,
-			Destination:   db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeWebhook},
-			Timestamp:     now,
-			Status:        model.OK,
-			Details:       details,
+		switch destination.IntegrationType {
+		case db.IntegrationTypePagerduty, db.IntegrationTypeOpsgenie:
+			notification.ExternalKey = fmt.Sprintf("%s:%s:%s", project.Id, alert.Id, alert.Severity.String())
 		}
 		database.PutAlertNotification(notification)
 	}
@@ -0,0 +1,101 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/utils"
+)
+
+// Discord embed limits: https://discord.com/developers/docs/resources/message#embed-object-embed-limits
+const (
+	discordMaxTitleLength       = 256
+	discordMaxDescriptionLength = 4096
+	discordMaxFields            = 25
+	discordMaxFieldNameLength   = 256
+	discordMaxFieldValueLength  = 1024
+)
+
+type Discord struct {
+	webhookUrl string
+}
+
+func NewDiscord(webhookUrl string) *Discord {
+	return &Discord{webhookUrl: webhookUrl}
+}
+
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	Url         string              `json:"url,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+func (d *Discord) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	return d.send(ctx, incidentMessage(baseUrl, n))
+}
+
+func (d *Discord) SendAlert(ctx context.Context, baseUrl string, n *db.AlertNotification) error {
+	return d.send(ctx, alertMessage(baseUrl, n))
+}
+
+func (d *Discord) SendAlertGroup(ctx context.Context, baseUrl string, g *db.AlertGroup) error {
+	return d.send(ctx, alertGroupMessage(baseUrl, g))
+}
+
+func (d *Discord) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
+	m, ok := deploymentMessage(project, ds)
+	if !ok {
+		return nil
+	}
+	return d.send(ctx, m)
+}
+
+func (d *Discord) send(ctx context.Context, m message) error {
+	title := m.Title
+	if m.Status != "" {
+		title = fmt.Sprintf("[%s] %s", m.Status, title)
+	}
+	e := discordEmbed{
+		Title: utils.Truncate(title, discordMaxTitleLength-1),
+		Url:   m.Link,
+		Color: m.ColorInt(),
+	}
+	if len(m.Items) > 0 {
+		e.Description = utils.Truncate("• "+strings.Join(m.Items, "\n• "), discordMaxDescriptionLength-1)
+	}
+	for i, f := range m.Fields {
+		if i == discordMaxFields {
+			break
+		}
+		value := f.Value
+		if f.Code {
+			value = fmt.Sprintf("```\n%s\n```", utils.Truncate(value, discordMaxFieldValueLength-10))
+		} else {
+			value = utils.Truncate(value, discordMaxFieldValueLength-1)
+		}
+		e.Fields = append(e.Fields, discordEmbedField{
+			Name:   utils.Truncate(f.Name, discordMaxFieldNameLength-1),
+			Value:  value,
+			Inline: !f.Code && len(f.Value) <= 40,
+		})
+	}
+	payload := map[string]any{
+		"embeds":           []discordEmbed{e},
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	}
+	if err := postJson(ctx, d.webhookUrl, payload); err != nil {
+		return fmt.Errorf("discord error: %w", err)
+	}
+	return nil
+}
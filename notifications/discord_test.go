@@ -0,0 +1,57 @@
+package notifications
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscordSend(t *testing.T) {
+	srv, requests := webhookServer(t, http.StatusNoContent)
+	d := NewDiscord(srv.URL + "/api/webhooks/1/token")
+	require.NoError(t, d.SendAlert(context.Background(), "http://coroot", testAlertNotification()))
+
+	r := <-requests
+	assert.Equal(t, "/api/webhooks/1/token", r.path)
+	assert.Equal(t, "application/json", r.contentType)
+	assert.Equal(t, map[string]any{"parse": []any{}}, r.payload["allowed_mentions"])
+	embeds := r.payload["embeds"].([]any)
+	require.Len(t, embeds, 1)
+	e := embeds[0].(map[string]any)
+	assert.Equal(t, "[CRITICAL] api: p99 > 1s", e["title"])
+	assert.Equal(t, "http://coroot/p/p1/alerts?alert=a1", e["url"])
+	assert.Equal(t, float64(0xf44034), e["color"])
+	assert.Equal(t, []any{
+		map[string]any{"name": "Alerting rule", "value": "High latency", "inline": true},
+		map[string]any{"name": "Latency", "value": "1.5s", "inline": true},
+		map[string]any{"name": "Query", "value": "```\nSELECT 1\n```", "inline": false},
+	}, e["fields"])
+}
+
+func TestDiscordLimits(t *testing.T) {
+	srv, requests := webhookServer(t, http.StatusNoContent)
+	d := NewDiscord(srv.URL)
+	m := message{Title: strings.Repeat("t", 1000), Items: []string{strings.Repeat("i", 5000)}}
+	for i := 0; i < 30; i++ {
+		m.Fields = append(m.Fields, messageField{Name: "f", Value: strings.Repeat("v", 2000)})
+	}
+	require.NoError(t, d.send(context.Background(), m))
+
+	e := (<-requests).payload["embeds"].([]any)[0].(map[string]any)
+	assert.Equal(t, discordMaxTitleLength, len([]rune(e["title"].(string))))
+	assert.Equal(t, discordMaxDescriptionLength, len([]rune(e["description"].(string))))
+	fields := e["fields"].([]any)
+	assert.Len(t, fields, discordMaxFields)
+	assert.Equal(t, discordMaxFieldValueLength, len([]rune(fields[0].(map[string]any)["value"].(string))))
+}
+
+func TestDiscordError(t *testing.T) {
+	srv, _ := webhookServer(t, http.StatusBadRequest)
+	err := NewDiscord(srv.URL).SendAlert(context.Background(), "http://coroot", testAlertNotification())
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "discord error: 400 Bad Request"))
+}
@@ -0,0 +1,250 @@
+package notifications
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+)
+
+type Email struct {
+	cfg *db.IntegrationEmail
+	to  []string
+}
+
+// NewEmail returns a client sending to the given recipients, or to the default ones of the integration if there are none.
+func NewEmail(cfg *db.IntegrationEmail, to []string) *Email {
+	if len(to) == 0 {
+		to = cfg.To
+	}
+	return &Email{cfg: cfg, to: to}
+}
+
+// emailRecipients parses the recipients of a notification destination.
+func emailRecipients(destination db.IncidentNotificationDestination) []string {
+	if destination.EmailTo == "" {
+		return nil
+	}
+	return strings.Split(destination.EmailTo, ",")
+}
+
+var emailTextTemplate = template.Must(template.New("email").Parse(`{{ if .Status }}[{{ .Status }}] {{ end }}{{ .Title }}
+{{ if .Fields }}
+{{ range .Fields }}{{ .Name }}:{{ if .Code }}
+{{ .Value }}
+{{ else }} {{ .Value }}
+{{ end }}{{ end }}{{ end }}{{ if .Items }}
+{{ range .Items }}  * {{ . }}
+{{ end }}{{ end }}
+{{ .LinkTitle }}: {{ .Link }}
+`))
+
+var emailHtmlTemplate = htmltemplate.Must(htmltemplate.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Helvetica, Arial, sans-serif; font-size: 14px; color: #212121;">
+<h3 style="margin: 0 0 12px 0;">{{ if .Status }}<span style="color: {{ .Color }};">[{{ .Status }}]</span> {{ end }}{{ .Title }}</h3>
+{{ if .Fields }}<table style="border-collapse: collapse; margin-bottom: 12px;">
+{{ range .Fields }}<tr>
+<td style="padding: 2px 12px 2px 0; vertical-align: top; font-weight: bold; white-space: nowrap;">{{ .Name }}</td>
+<td style="padding: 2px 0;">{{ if .Code }}<pre style="margin: 0; padding: 4px; background: #f5f5f5;">{{ .Value }}</pre>{{ else }}{{ .Value }}{{ end }}</td>
+</tr>
+{{ end }}</table>{{ end }}
+{{ if .Items }}<ul style="margin: 0 0 12px 0; padding-left: 20px;">
+{{ range .Items }}<li>{{ . }}</li>
+{{ end }}</ul>{{ end }}
+<a href="{{ .Link }}" style="display: inline-block; padding: 6px 12px; background: #1976d2; color: #ffffff; text-decoration: none; border-radius: 4px;">{{ .LinkTitle }}</a>
+</body>
+</html>
+`))
+
+func (e *Email) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	return e.send(ctx, incidentMessage(baseUrl, n))
+}
+
+func (e *Email) SendAlert(ctx context.Context, baseUrl string, n *db.AlertNotification) error {
+	return e.send(ctx, alertMessage(baseUrl, n))
+}
+
+func (e *Email) SendAlertGroup(ctx context.Context, baseUrl string, g *db.AlertGroup) error {
+	return e.send(ctx, alertGroupMessage(baseUrl, g))
+}
+
+func (e *Email) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
+	m, ok := deploymentMessage(project, ds)
+	if !ok {
+		return nil
+	}
+	return e.send(ctx, m)
+}
+
+func (e *Email) send(ctx context.Context, m message) error {
+	if len(e.to) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+	msg, err := e.compose(m)
+	if err != nil {
+		return err
+	}
+	from, err := mail.ParseAddress(e.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	c, err := e.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if e.cfg.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("the server doesn't support authentication")
+		}
+		if err = c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err = c.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, r := range e.to {
+		addr, err := mail.ParseAddress(r)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %s: %w", r, err)
+		}
+		if err = c.Rcpt(addr.Address); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func (e *Email) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: e.cfg.Host, InsecureSkipVerify: e.cfg.TlsSkipVerify}
+	var conn net.Conn
+	var err error
+	if e.cfg.TLS == db.EmailTLS {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if e.cfg.TLS == db.EmailTLSStartTLS {
+		if err = c.StartTLS(tlsConfig); err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// compose builds a multipart/alternative message with the plain-text and the HTML versions of the notification.
+func (e *Email) compose(m message) ([]byte, error) {
+	var text, html bytes.Buffer
+	if err := emailTextTemplate.Execute(&text, m); err != nil {
+		return nil, err
+	}
+	if err := emailHtmlTemplate.Execute(&html, m); err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{contentType: "text/plain; charset=UTF-8", content: text.Bytes()},
+		{contentType: "text/html; charset=UTF-8", content: html.Bytes()},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err = qw.Write(part.content); err != nil {
+			return nil, err
+		}
+		if err = qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	from, err := mail.ParseAddress(e.cfg.From)
+	if err != nil {
+		return nil, err
+	}
+	var msg bytes.Buffer
+	headers := [][2]string{
+		{"From", from.String()},
+		{"To", strings.Join(e.to, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", m.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<%s@%s>", emailMessageId(), cmp.Or(emailDomain(from.Address), "coroot"))},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", mw.Boundary())},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&msg, "%s: %s\r\n", h[0], h[1])
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+func emailMessageId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func emailDomain(address string) string {
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return address[i+1:]
+	}
+	return ""
+}
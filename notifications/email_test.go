@@ -0,0 +1,158 @@
+package notifications
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type smtpSession struct {
+	from string
+	rcpt []string
+	data string
+}
+
+// smtpServer accepts a single SMTP session and returns what the client sent.
+func smtpServer(t *testing.T) (int, <-chan smtpSession) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+	res := make(chan smtpSession, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		c := textproto.NewConn(conn)
+		var s smtpSession
+		_ = c.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := c.ReadLine()
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				_ = c.PrintfLine("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				s.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				_ = c.PrintfLine("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				s.rcpt = append(s.rcpt, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				_ = c.PrintfLine("250 OK")
+			case cmd == "DATA":
+				_ = c.PrintfLine("354 go ahead")
+				data, err := io.ReadAll(c.DotReader())
+				if err != nil {
+					return
+				}
+				s.data = string(data)
+				_ = c.PrintfLine("250 OK")
+			case cmd == "QUIT":
+				_ = c.PrintfLine("221 bye")
+				res <- s
+				return
+			default:
+				_ = c.PrintfLine("502 not implemented")
+			}
+		}
+	}()
+	return l.Addr().(*net.TCPAddr).Port, res
+}
+
+func TestEmailSend(t *testing.T) {
+	port, sessions := smtpServer(t)
+	alerts := true
+	var integrations db.Integrations
+	integrations.Email = &db.IntegrationEmail{
+		Host:   "127.0.0.1",
+		Port:   port,
+		From:   "Coroot <coroot@example.com>",
+		To:     []string{"default@example.com"},
+		Alerts: &alerts,
+	}
+	category := db.ApplicationCategoryNotificationSettingsEmail{To: []string{"oncall@example.com", "Payments Team <payments@example.com>"}}
+	client := getClient(category.Destination(), integrations, NotificationTypeAlert)
+	require.NotNil(t, client)
+
+	n := &db.AlertNotification{
+		ProjectId:     "p1",
+		AlertId:       "a1",
+		ApplicationId: model.NewApplicationId("", "default", model.ApplicationKindDeployment, "api"),
+		Status:        model.CRITICAL,
+		Details:       &db.AlertNotificationDetails{RuleName: "High latency", Summary: "p99 > 1s & growing"},
+	}
+	require.NoError(t, client.SendAlert(context.Background(), "http://coroot", n))
+	s := <-sessions
+
+	assert.Equal(t, "coroot@example.com", s.from)
+	assert.Equal(t, []string{"oncall@example.com", "payments@example.com"}, s.rcpt)
+
+	msg, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(s.data)))
+	require.NoError(t, err)
+	assert.Equal(t, `"Coroot" <coroot@example.com>`, msg.Header.Get("From"))
+	assert.Equal(t, "oncall@example.com, Payments Team <payments@example.com>", msg.Header.Get("To"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "[CRITICAL] api: p99 > 1s & growing", subject)
+	assert.Equal(t, "1.0", msg.Header.Get("MIME-Version"))
+	assert.Regexp(t, `^<[0-9a-f]{32}@example\.com>$`, msg.Header.Get("Message-ID"))
+	_, err = msg.Header.Date()
+	assert.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+	parts := map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		assert.Equal(t, "quoted-printable", p.Header.Get("Content-Transfer-Encoding"))
+		body, err := io.ReadAll(quotedprintable.NewReader(p))
+		require.NoError(t, err)
+		parts[p.Header.Get("Content-Type")] = string(body)
+	}
+	require.Len(t, parts, 2)
+	assert.Contains(t, parts["text/plain; charset=UTF-8"], "[CRITICAL] api: p99 > 1s & growing")
+	assert.Contains(t, parts["text/plain; charset=UTF-8"], "View alert: http://coroot/p/p1/alerts?alert=a1")
+	assert.Contains(t, parts["text/html; charset=UTF-8"], "api: p99 &gt; 1s &amp; growing")
+	assert.Contains(t, parts["text/html; charset=UTF-8"], `<a href="http://coroot/p/p1/alerts?alert=a1"`)
+}
+
+func TestEmailDefaultRecipients(t *testing.T) {
+	port, sessions := smtpServer(t)
+	alerts := true
+	var integrations db.Integrations
+	integrations.Email = &db.IntegrationEmail{
+		Host:   "127.0.0.1",
+		Port:   port,
+		From:   "coroot@example.com",
+		To:     []string{"default@example.com"},
+		Alerts: &alerts,
+	}
+	client := getClient(db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeEmail}, integrations, NotificationTypeAlert)
+	require.NotNil(t, client)
+	n := &db.AlertNotification{ProjectId: "p1", AlertId: "a1", Status: model.OK}
+	require.NoError(t, client.SendAlert(context.Background(), "http://coroot", n))
+	s := <-sessions
+	assert.Equal(t, []string{"default@example.com"}, s.rcpt)
+}
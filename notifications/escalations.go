@@ -0,0 +1,145 @@
+package notifications
+
+import (
+	"slices"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+// escalateAlerts notifies the escalation destinations about the alerts that nobody has acknowledged
+// within the step's delay. An alert is escalated to a destination at most once: the existing alert
+// notifications serve as the escalation state, so that the resolution is delivered there as well.
+func (n *AlertNotifier) escalateAlerts() {
+	projects, err := n.db.GetProjects()
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	now := timeseries.Now()
+	for _, project := range projects {
+		n.escalateProjectAlerts(project, now)
+	}
+}
+
+func (n *AlertNotifier) escalateProjectAlerts(project *db.Project, now timeseries.Time) {
+	categories := project.GetApplicationCategories()
+	var minDelay timeseries.Duration
+	for _, c := range categories {
+		if !c.NotificationSettings.Alerts.Enabled {
+			continue
+		}
+		for _, step := range c.NotificationSettings.Alerts.Escalation.ActiveSteps() {
+			if minDelay == 0 || step.Delay < minDelay {
+				minDelay = step.Delay
+			}
+		}
+	}
+	if minDelay == 0 {
+		return
+	}
+
+	alerts, err := n.db.GetUnacknowledgedAlerts(project.Id, now.Add(-minDelay))
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	if len(alerts) == 0 {
+		return
+	}
+	rules, err := n.db.GetAlertingRules(project.Id)
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	rulesById := map[model.AlertingRuleId]*model.AlertingRule{}
+	for _, r := range rules {
+		rulesById[r.Id] = r
+	}
+	silences, err := n.db.GetActiveSilences(project.Id, now)
+	if err != nil {
+		klog.Errorln(err)
+	}
+	ids := make([]string, 0, len(alerts))
+	for _, a := range alerts {
+		ids = append(ids, a.Id)
+	}
+	notifications, err := n.db.GetAlertNotificationsByAlertIds(project.Id, ids)
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+
+	for _, alert := range alerts {
+		rule := rulesById[model.AlertingRuleId(alert.RuleId)]
+		if rule == nil || silences.FindForAlert(alert, now) != nil {
+			continue
+		}
+		categorySettings := categories[alertCategory(alert, rule)]
+		if categorySettings == nil {
+			continue
+		}
+		settings := categorySettings.NotificationSettings.Alerts
+		if !settings.Enabled {
+			continue
+		}
+		notified := alertDestinations(settings.ApplicationCategoryNotificationDestinations)
+		for _, an := range notifications[alert.Id] {
+			notified = append(notified, an.Destination)
+		}
+		for _, step := range settings.Escalation.ActiveSteps() {
+			if now.Before(alert.OpenedAt.Add(step.Delay)) {
+				continue
+			}
+			for _, destination := range alertDestinations(step.ApplicationCategoryNotificationDestinations) {
+				if slices.Contains(notified, destination) {
+					continue
+				}
+				n.enqueue(now, project, alert, rule, destination)
+				notified = append(notified, destination)
+			}
+		}
+	}
+}
+
+// escalatedDestinations returns the escalation destinations the alert has been sent to,
+// except for the regular ones, which are notified anyway.
+func escalatedDestinations(database *db.DB, project *db.Project, alert *model.Alert, settings db.ApplicationCategoryAlertNotificationSettings) []db.IncidentNotificationDestination {
+	steps := settings.Escalation.ActiveSteps()
+	if len(steps) == 0 {
+		return nil
+	}
+	notifications, err := database.GetAlertNotificationsByAlertIds(project.Id, []string{alert.Id})
+	if err != nil {
+		klog.Errorln(err)
+		return nil
+	}
+	regular := alertDestinations(settings.ApplicationCategoryNotificationDestinations)
+	var res []db.IncidentNotificationDestination
+	for _, step := range steps {
+		for _, destination := range alertDestinations(step.ApplicationCategoryNotificationDestinations) {
+			if slices.Contains(regular, destination) || slices.Contains(res, destination) {
+				continue
+			}
+			for _, an := range notifications[alert.Id] {
+				if an.Destination == destination {
+					res = append(res, destination)
+					break
+				}
+			}
+		}
+	}
+	return res
+}
+
+func alertCategory(alert *model.Alert, rule *model.AlertingRule) model.ApplicationCategory {
+	switch {
+	case alert.ApplicationCategory != "":
+		return alert.ApplicationCategory
+	case rule.NotificationCategory != "":
+		return rule.NotificationCategory
+	}
+	return model.ApplicationCategoryApplication
+}
@@ -0,0 +1,92 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/utils"
+)
+
+const googleChatMaxFieldLength = 2000
+
+type GoogleChat struct {
+	webhookUrl string
+}
+
+func NewGoogleChat(webhookUrl string) *GoogleChat {
+	return &GoogleChat{webhookUrl: webhookUrl}
+}
+
+func (gc *GoogleChat) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	return gc.send(ctx, incidentMessage(baseUrl, n))
+}
+
+func (gc *GoogleChat) SendAlert(ctx context.Context, baseUrl string, n *db.AlertNotification) error {
+	return gc.send(ctx, alertMessage(baseUrl, n))
+}
+
+func (gc *GoogleChat) SendAlertGroup(ctx context.Context, baseUrl string, g *db.AlertGroup) error {
+	return gc.send(ctx, alertGroupMessage(baseUrl, g))
+}
+
+func (gc *GoogleChat) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
+	m, ok := deploymentMessage(project, ds)
+	if !ok {
+		return nil
+	}
+	return gc.send(ctx, m)
+}
+
+// send posts the message as a card: https://developers.google.com/workspace/chat/format-messages#card-messages.
+// Card headers can't be colored, so the status is shown as colored text in the first widget instead.
+func (gc *GoogleChat) send(ctx context.Context, m message) error {
+	status := m.Status
+	if status == "" {
+		status = "OK"
+	}
+	widgets := []map[string]any{
+		{"textParagraph": map[string]any{
+			"text": fmt.Sprintf(`<font color="%s"><b>%s</b></font>`, m.Color, status),
+		}},
+	}
+	if len(m.Items) > 0 {
+		items := make([]string, 0, len(m.Items))
+		for _, i := range m.Items {
+			items = append(items, "• "+html.EscapeString(i))
+		}
+		widgets = append(widgets, map[string]any{"textParagraph": map[string]any{"text": strings.Join(items, "<br>")}})
+	}
+	for _, f := range m.Fields {
+		value := html.EscapeString(utils.Truncate(f.Value, googleChatMaxFieldLength))
+		if f.Code {
+			value = "<code>" + value + "</code>"
+		}
+		widgets = append(widgets, map[string]any{"decoratedText": map[string]any{
+			"topLabel": f.Name,
+			"text":     value,
+			"wrapText": true,
+		}})
+	}
+	widgets = append(widgets, map[string]any{"buttonList": map[string]any{
+		"buttons": []map[string]any{
+			{"text": m.LinkTitle, "onClick": map[string]any{"openLink": map[string]any{"url": m.Link}}},
+		},
+	}})
+	payload := map[string]any{
+		"cardsV2": []map[string]any{{
+			"cardId": "coroot",
+			"card": map[string]any{
+				"header":   map[string]any{"title": utils.Truncate(m.Title, 200), "subtitle": "Coroot"},
+				"sections": []map[string]any{{"widgets": widgets}},
+			},
+		}},
+	}
+	if err := postJson(ctx, gc.webhookUrl, payload); err != nil {
+		return fmt.Errorf("google chat error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package notifications
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogleChatSend(t *testing.T) {
+	srv, requests := webhookServer(t, http.StatusOK)
+	gc := NewGoogleChat(srv.URL + "/v1/spaces/AAA/messages?key=k&token=t")
+	require.NoError(t, gc.SendAlert(context.Background(), "http://coroot", testAlertNotification()))
+
+	r := <-requests
+	assert.Equal(t, "/v1/spaces/AAA/messages", r.path)
+	assert.Equal(t, "application/json", r.contentType)
+	cards := r.payload["cardsV2"].([]any)
+	require.Len(t, cards, 1)
+	card := cards[0].(map[string]any)["card"].(map[string]any)
+	assert.Equal(t, map[string]any{"title": "api: p99 > 1s", "subtitle": "Coroot"}, card["header"])
+	widgets := card["sections"].([]any)[0].(map[string]any)["widgets"].([]any)
+	assert.Equal(t, []any{
+		map[string]any{"textParagraph": map[string]any{"text": `<font color="#f44034"><b>CRITICAL</b></font>`}},
+		map[string]any{"decoratedText": map[string]any{"topLabel": "Alerting rule", "text": "High latency", "wrapText": true}},
+		map[string]any{"decoratedText": map[string]any{"topLabel": "Latency", "text": "1.5s", "wrapText": true}},
+		map[string]any{"decoratedText": map[string]any{"topLabel": "Query", "text": "<code>SELECT 1</code>", "wrapText": true}},
+		map[string]any{"buttonList": map[string]any{"buttons": []any{
+			map[string]any{"text": "View alert", "onClick": map[string]any{"openLink": map[string]any{"url": "http://coroot/p/p1/alerts?alert=a1"}}},
+		}}},
+	}, widgets)
+}
+
+func TestGoogleChatError(t *testing.T) {
+	srv, _ := webhookServer(t, http.StatusForbidden)
+	err := NewGoogleChat(srv.URL).SendAlert(context.Background(), "http://coroot", testAlertNotification())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "google chat error: 403 Forbidden")
+}
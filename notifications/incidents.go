@@ -26,6 +26,17 @@ func NewIncidentNotifier(db *db.DB) *IncidentNotifier {
 }
 
 func (n *IncidentNotifier) Enqueue(project *db.Project, app *model.Application, incident *model.ApplicationIncident, now timeseries.Time) {
+	if !incident.Resolved() { // silences suppress only opening notifications
+		silences, err := n.db.GetActiveSilences(project.Id, now)
+		if err != nil {
+			klog.Errorln(err)
+		}
+		for _, s := range silences {
+			if s.Matches("", app.Id, app.Category, incident.Severity, nil) {
+				return
+			}
+		}
+	}
 	categorySettings := project.GetApplicationCategories()[app.Category]
 	if categorySettings == nil {
 		return
@@ -49,6 +60,21 @@ func (n *IncidentNotifier) Enqueue(project *db.Project, app *model.Application,
 	if webhook := notificationSettings.Webhook; webhook != nil && webhook.Enabled {
 		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeWebhook})
 	}
+	if email := notificationSettings.Email; email != nil && email.Enabled {
+		n.enqueue(now, project, app, incident, email.Destination())
+	}
+	if telegram := notificationSettings.Telegram; telegram != nil && telegram.Enabled {
+		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeTelegram, TelegramChatId: telegram.ChatId})
+	}
+	if discord := notificationSettings.Discord; discord != nil && discord.Enabled {
+		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeDiscord})
+	}
+	if googleChat := notificationSettings.GoogleChat; googleChat != nil && googleChat.Enabled {
+		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeGoogleChat})
+	}
+	if mattermost := notificationSettings.Mattermost; mattermost != nil && mattermost.Enabled {
+		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeMattermost, MattermostChannel: mattermost.Channel})
+	}
 	n.sendIncidents()
 }
 
@@ -71,7 +97,8 @@ func (n *IncidentNotifier) sendIncidents() {
 		destination db.IncidentNotificationDestination
 	}
 	failedDestinations := map[destinationKey]bool{}
-	notifications, err := n.db.GetNotSentIncidentNotifications(timeseries.Now().Add(-retryWindow))
+	now := timeseries.Now()
+	notifications, err := n.db.GetNotSentIncidentNotifications(sendWindowStart(projects, now))
 	if err != nil {
 		klog.Errorln(err)
 		return
@@ -85,6 +112,9 @@ func (n *IncidentNotifier) sendIncidents() {
 		if project == nil {
 			continue
 		}
+		if skip(project, notification.ApplicationId, notification.Timestamp, now) {
+			continue
+		}
 		integrations := project.Settings.Integrations
 		var sendErr error
 		client := getClient(notification.Destination, integrations, NotificationTypeIncident)
@@ -126,7 +156,8 @@ func (n *IncidentNotifier) enqueue(now timeseries.Time, project *db.Project, app
 		Status:        incident.Severity,
 	}
 	switch destination.IntegrationType {
-	case db.IntegrationTypeSlack, db.IntegrationTypeTeams, db.IntegrationTypeWebhook:
+	case db.IntegrationTypeSlack, db.IntegrationTypeTeams, db.IntegrationTypeWebhook, db.IntegrationTypeEmail,
+		db.IntegrationTypeTelegram, db.IntegrationTypeDiscord, db.IntegrationTypeGoogleChat, db.IntegrationTypeMattermost:
 		if incident.Resolved() {
 			n.onResolve("", notification, incidentDetails(app, incident))
 		} else {
@@ -0,0 +1,85 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+)
+
+type Mattermost struct {
+	webhookUrl string
+	channel    string
+}
+
+// NewMattermost returns a client posting to the incoming webhook's default channel, unless another channel is specified.
+func NewMattermost(webhookUrl, channel string) *Mattermost {
+	return &Mattermost{webhookUrl: webhookUrl, channel: channel}
+}
+
+type mattermostAttachment struct {
+	Fallback  string                      `json:"fallback"`
+	Color     string                      `json:"color"`
+	Title     string                      `json:"title"`
+	TitleLink string                      `json:"title_link,omitempty"`
+	Text      string                      `json:"text,omitempty"`
+	Fields    []mattermostAttachmentField `json:"fields,omitempty"`
+}
+
+type mattermostAttachmentField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+func (mm *Mattermost) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	return mm.send(ctx, incidentMessage(baseUrl, n))
+}
+
+func (mm *Mattermost) SendAlert(ctx context.Context, baseUrl string, n *db.AlertNotification) error {
+	return mm.send(ctx, alertMessage(baseUrl, n))
+}
+
+func (mm *Mattermost) SendAlertGroup(ctx context.Context, baseUrl string, g *db.AlertGroup) error {
+	return mm.send(ctx, alertGroupMessage(baseUrl, g))
+}
+
+func (mm *Mattermost) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
+	m, ok := deploymentMessage(project, ds)
+	if !ok {
+		return nil
+	}
+	return mm.send(ctx, m)
+}
+
+// send posts the message as a Slack-compatible attachment: https://developers.mattermost.com/integrate/reference/message-attachments/
+func (mm *Mattermost) send(ctx context.Context, m message) error {
+	a := mattermostAttachment{
+		Fallback:  m.Subject,
+		Color:     m.Color,
+		Title:     m.Subject,
+		TitleLink: m.Link,
+	}
+	if len(m.Items) > 0 {
+		a.Text = "* " + strings.Join(m.Items, "\n* ")
+	}
+	for _, f := range m.Fields {
+		value := f.Value
+		if f.Code {
+			value = fmt.Sprintf("```\n%s\n```", value)
+		}
+		a.Fields = append(a.Fields, mattermostAttachmentField{Title: f.Name, Value: value, Short: !f.Code && len(f.Value) <= 40})
+	}
+	payload := map[string]any{
+		"attachments": []mattermostAttachment{a},
+	}
+	if mm.channel != "" {
+		payload["channel"] = mm.channel
+	}
+	if err := postJson(ctx, mm.webhookUrl, payload); err != nil {
+		return fmt.Errorf("mattermost error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package notifications
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMattermostSend(t *testing.T) {
+	srv, requests := webhookServer(t, http.StatusOK)
+
+	require.NoError(t, NewMattermost(srv.URL+"/hooks/xyz", "").SendAlert(context.Background(), "http://coroot", testAlertNotification()))
+	r := <-requests
+	assert.Equal(t, "/hooks/xyz", r.path)
+	assert.Equal(t, "application/json", r.contentType)
+	assert.NotContains(t, r.payload, "channel")
+	assert.Equal(t, []any{map[string]any{
+		"fallback":   "[CRITICAL] api: p99 > 1s",
+		"color":      "#f44034",
+		"title":      "[CRITICAL] api: p99 > 1s",
+		"title_link": "http://coroot/p/p1/alerts?alert=a1",
+		"fields": []any{
+			map[string]any{"title": "Alerting rule", "value": "High latency", "short": true},
+			map[string]any{"title": "Latency", "value": "1.5s", "short": true},
+			map[string]any{"title": "Query", "value": "```\nSELECT 1\n```", "short": false},
+		},
+	}}, r.payload["attachments"])
+
+	require.NoError(t, NewMattermost(srv.URL+"/hooks/xyz", "alerts").SendAlert(context.Background(), "http://coroot", testAlertNotification()))
+	assert.Equal(t, "alerts", (<-requests).payload["channel"])
+}
+
+func TestMattermostError(t *testing.T) {
+	srv, _ := webhookServer(t, http.StatusNotFound)
+	err := NewMattermost(srv.URL, "").SendAlert(context.Background(), "http://coroot", testAlertNotification())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mattermost error: 404 Not Found")
+}
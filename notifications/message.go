@@ -0,0 +1,164 @@
+package notifications
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/utils"
+)
+
+// message is the content of a notification, independent of the formatting of a particular client.
+// It is used by the clients that render notifications from plain structures rather than via an SDK.
+type message struct {
+	Subject   string // a one-line summary including the status, e.g. for an email subject or a preview
+	Title     string
+	Status    string // empty for resolved incidents and alerts
+	Level     model.Status
+	Color     string
+	Fields    []messageField
+	Items     []string
+	Link      string
+	LinkTitle string
+}
+
+type messageField struct {
+	Name  string
+	Value string
+	Code  bool
+}
+
+func (m *message) setStatus(status model.Status) {
+	m.Level = status
+	m.Color = status.Color()
+	m.Subject = m.Title
+	if status != model.OK {
+		m.Status = strings.ToUpper(status.String())
+		m.Subject = fmt.Sprintf("[%s] %s", m.Status, m.Title)
+	}
+}
+
+// ColorInt returns the color as a number, as some APIs expect.
+func (m *message) ColorInt() int {
+	c, _ := strconv.ParseInt(strings.TrimPrefix(m.Color, "#"), 16, 32)
+	return int(c)
+}
+
+// Emoji returns a colored circle matching the status, for the clients that don't support colors.
+func (m *message) Emoji() string {
+	switch m.Level {
+	case model.OK:
+		return "🟢"
+	case model.WARNING:
+		return "🟡"
+	case model.CRITICAL:
+		return "🔴"
+	}
+	return "⚪"
+}
+
+func incidentMessage(baseUrl string, n *db.IncidentNotification) message {
+	m := message{Link: incidentUrl(baseUrl, n), LinkTitle: "View incident"}
+	if n.Status == model.OK {
+		m.Title = fmt.Sprintf("%s incident resolved", n.ApplicationId.Name)
+	} else {
+		m.Title = fmt.Sprintf("%s is not meeting its SLOs", n.ApplicationId.Name)
+	}
+	m.setStatus(n.Status)
+	if n.Details != nil {
+		for _, r := range n.Details.Reports {
+			m.Items = append(m.Items, fmt.Sprintf("%s / %s: %s", r.Name, r.Check, r.Message))
+		}
+		if n.Details.RCASummary != "" {
+			m.Fields = append(m.Fields, messageField{Name: "Root Cause", Value: n.Details.RCASummary})
+			if n.Details.RCARemediations != "" {
+				m.Fields = append(m.Fields, messageField{Name: "Remediations", Value: utils.Truncate(n.Details.RCARemediations, 2000)})
+			}
+		}
+	}
+	return m
+}
+
+func alertMessage(baseUrl string, n *db.AlertNotification) message {
+	displayName := alertDisplayName(n)
+	m := message{Link: alertUrl(baseUrl, n), LinkTitle: "View alert"}
+	if n.Status == model.OK {
+		resolvedText := "resolved"
+		if n.Details != nil && n.Details.ResolvedBy != "" {
+			resolvedText = fmt.Sprintf("manually resolved by %s", n.Details.ResolvedBy)
+		}
+		m.Title = fmt.Sprintf("%s alert %s", displayName, resolvedText)
+		if n.Details != nil && n.Details.Duration != "" {
+			m.Title += fmt.Sprintf(" (duration: %s)", n.Details.Duration)
+		}
+	} else {
+		m.Title = displayName
+		if n.Details != nil && n.Details.Summary != "" {
+			m.Title += ": " + n.Details.Summary
+		}
+	}
+	m.setStatus(n.Status)
+	if n.Details != nil {
+		if n.Details.ProjectName != "" {
+			m.Fields = append(m.Fields, messageField{Name: "Project", Value: n.Details.ProjectName})
+		}
+		if n.Details.RuleName != "" {
+			m.Fields = append(m.Fields, messageField{Name: "Alerting rule", Value: n.Details.RuleName})
+		}
+		for _, d := range n.Details.Details {
+			m.Fields = append(m.Fields, messageField{Name: d.Name, Value: d.Value, Code: d.Code})
+		}
+	}
+	return m
+}
+
+func alertGroupMessage(baseUrl string, g *db.AlertGroup) message {
+	m := message{
+		Title:     alertGroupTitle(g),
+		Items:     alertGroupLines(g),
+		Link:      alertGroupUrl(baseUrl, g),
+		LinkTitle: "View alerts",
+	}
+	m.setStatus(g.Status())
+	return m
+}
+
+// deploymentMessage returns false for in-progress deployments: clients that can't update
+// a previously sent message notify only of the final state of a deployment.
+func deploymentMessage(project *db.Project, ds model.ApplicationDeploymentStatus) (message, bool) {
+	d := ds.Deployment
+
+	status := "Deployed"
+	switch ds.State {
+	case model.ApplicationDeploymentStateInProgress:
+		return message{}, false
+	case model.ApplicationDeploymentStateStuck:
+		status = "Stuck"
+	case model.ApplicationDeploymentStateCancelled:
+		status = "Cancelled"
+	}
+
+	m := message{
+		Title:     fmt.Sprintf("Deployment of %s to %s", d.ApplicationId.Name, project.Name),
+		Level:     ds.Status,
+		Color:     ds.Status.Color(),
+		Link:      deploymentUrl(project.Settings.Integrations.BaseUrl, project.Id, d),
+		LinkTitle: "View deployment",
+		Fields: []messageField{
+			{Name: "Status", Value: status},
+			{Name: "Version", Value: d.Version()},
+		},
+	}
+	m.Subject = fmt.Sprintf("%s: %s", m.Title, status)
+	if ds.State == model.ApplicationDeploymentStateSummary {
+		for _, s := range ds.Summary {
+			m.Items = append(m.Items, fmt.Sprintf("%s %s", s.Emoji(), s.Message))
+		}
+		if len(m.Items) == 0 {
+			m.Items = append(m.Items, "No notable changes")
+		}
+	}
+	return m, true
+}
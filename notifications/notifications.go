@@ -1,9 +1,13 @@
 package notifications
 
 import (
+	"bytes"
 	"cmp"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"strings"
 	"time"
 
@@ -22,6 +26,7 @@ type NotificationClient interface {
 	SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error
 	SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error
 	SendAlert(ctx context.Context, baseUrl string, n *db.AlertNotification) error
+	SendAlertGroup(ctx context.Context, baseUrl string, g *db.AlertGroup) error
 }
 
 type NotificationType int
@@ -53,6 +58,74 @@ func getClient(destination db.IncidentNotificationDestination, integrations db.I
 		if cfg := integrations.Webhook; cfg != nil && isEnabled(cfg.Incidents, cfg.Alerts, notificationType) {
 			return NewWebhook(cfg)
 		}
+	case db.IntegrationTypeEmail:
+		if cfg := integrations.Email; cfg != nil && isEnabled(cfg.Incidents, cfg.Alerts, notificationType) {
+			return NewEmail(cfg, emailRecipients(destination))
+		}
+	case db.IntegrationTypeTelegram:
+		if cfg := integrations.Telegram; cfg != nil && isEnabled(cfg.Incidents, cfg.Alerts, notificationType) {
+			return NewTelegram(cfg.BotToken, cmp.Or(destination.TelegramChatId, cfg.DefaultChatId))
+		}
+	case db.IntegrationTypeDiscord:
+		if cfg := integrations.Discord; cfg != nil && isEnabled(cfg.Incidents, cfg.Alerts, notificationType) {
+			return NewDiscord(cfg.WebhookUrl)
+		}
+	case db.IntegrationTypeGoogleChat:
+		if cfg := integrations.GoogleChat; cfg != nil && isEnabled(cfg.Incidents, cfg.Alerts, notificationType) {
+			return NewGoogleChat(cfg.WebhookUrl)
+		}
+	case db.IntegrationTypeMattermost:
+		if cfg := integrations.Mattermost; cfg != nil && isEnabled(cfg.Incidents, cfg.Alerts, notificationType) {
+			return NewMattermost(cfg.WebhookUrl, cmp.Or(destination.MattermostChannel, cfg.DefaultChannel))
+		}
+	}
+	return nil
+}
+
+// skip reports whether a notification for the application must not be sent now:
+// either it is older than the retry window, or the application's category is in a maintenance window.
+// Notifications held by a maintenance window are sent once it ends, even if that happens after the retry window.
+func skip(project *db.Project, appId model.ApplicationId, timestamp, now timeseries.Time) bool {
+	category := project.CalcApplicationCategory(appId)
+	if project.InMaintenanceWindow(category, now) {
+		return true
+	}
+	return timestamp.Before(now.Add(-retryWindow)) && !project.InMaintenanceWindow(category, timestamp)
+}
+
+// sendWindowStart returns the timestamp of the oldest notification that may still be sent.
+func sendWindowStart(projects map[db.ProjectId]*db.Project, now timeseries.Time) timeseries.Time {
+	var maxHold timeseries.Duration
+	for _, p := range projects {
+		if d := p.MaxMaintenanceWindowDuration(); d > maxHold {
+			maxHold = d
+		}
+	}
+	return now.Add(-retryWindow - maxHold)
+}
+
+// postJson sends the payload to a webhook-style API.
+func postJson(ctx context.Context, url string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if err != nil {
+			return fmt.Errorf("response status: %s", resp.Status)
+		}
+		return fmt.Errorf("%s: %s", resp.Status, string(body))
 	}
 	return nil
 }
@@ -0,0 +1,52 @@
+package notifications
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/stretchr/testify/require"
+)
+
+type webhookRequest struct {
+	path        string
+	contentType string
+	payload     map[string]any
+}
+
+// webhookServer records the requests sent to it and responds with the given status.
+func webhookServer(t *testing.T, status int) (*httptest.Server, <-chan webhookRequest) {
+	requests := make(chan webhookRequest, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		req := webhookRequest{path: r.URL.Path, contentType: r.Header.Get("Content-Type")}
+		require.NoError(t, json.Unmarshal(body, &req.payload))
+		requests <- req
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"ok":false,"description":"Bad Request: chat not found"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests
+}
+
+func testAlertNotification() *db.AlertNotification {
+	return &db.AlertNotification{
+		ProjectId:     "p1",
+		AlertId:       "a1",
+		ApplicationId: model.NewApplicationId("", "default", model.ApplicationKindDeployment, "api"),
+		Status:        model.CRITICAL,
+		Details: &db.AlertNotificationDetails{
+			RuleName: "High latency",
+			Summary:  "p99 > 1s",
+			Details: []model.AlertDetail{
+				{Name: "Latency", Value: "1.5s"},
+				{Name: "Query", Value: "SELECT 1", Code: true},
+			},
+		},
+	}
+}
@@ -113,6 +113,37 @@ func (og *Opsgenie) SendAlert(ctx context.Context, baseUrl string, n *db.AlertNo
 	return err
 }
 
+// SendAlertGroup creates an alert for the group. Opsgenie deduplicates alerts by alias,
+// so subsequent updates of the group only increase the count of the existing alert.
+func (og *Opsgenie) SendAlertGroup(ctx context.Context, baseUrl string, g *db.AlertGroup) error {
+	status := g.Status()
+	if status == model.OK {
+		req := &alert.CloseAlertRequest{
+			IdentifierType:  alert.ALIAS,
+			IdentifierValue: g.Id(),
+			Source:          "Coroot",
+		}
+		_, err := og.client.Close(ctx, req)
+		return err
+	}
+	req := &alert.CreateAlertRequest{
+		Message:     fmt.Sprintf("[%s] %s", strings.ToUpper(status.String()), alertGroupTitle(g)),
+		Alias:       g.Id(),
+		Source:      "Coroot",
+		Description: strings.Join(alertGroupLines(g), "\n") + fmt.Sprintf("\n\n%s", alertGroupUrl(baseUrl, g)),
+	}
+	switch status {
+	case model.CRITICAL:
+		req.Priority = alert.P2
+	case model.WARNING:
+		req.Priority = alert.P3
+	case model.INFO:
+		req.Priority = alert.P4
+	}
+	_, err := og.client.Create(ctx, req)
+	return err
+}
+
 func (og *Opsgenie) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
 	return fmt.Errorf("not supported")
 }
@@ -94,6 +94,30 @@ func (pd *Pagerduty) SendAlert(ctx context.Context, baseUrl string, n *db.AlertN
 	return err
 }
 
+func (pd *Pagerduty) SendAlertGroup(ctx context.Context, baseUrl string, g *db.AlertGroup) error {
+	e := pagerduty.V2Event{
+		RoutingKey: pd.integrationKey,
+		DedupKey:   g.Id(),
+	}
+	status := g.Status()
+	if status == model.OK {
+		e.Action = "resolve"
+	} else {
+		e.Action = "trigger"
+		e.Client = "Coroot"
+		e.ClientURL = alertGroupUrl(baseUrl, g)
+		e.Payload = &pagerduty.V2Payload{
+			Summary:   fmt.Sprintf("[%s] %s", strings.ToUpper(status.String()), alertGroupTitle(g)),
+			Source:    "Coroot",
+			Severity:  status.String(),
+			Timestamp: g.CreatedAt.ToStandard().String(),
+			Details:   map[string]string{"Alerts": strings.Join(alertGroupLines(g), "\n")},
+		}
+	}
+	_, err := pagerduty.ManageEventWithContext(ctx, e)
+	return err
+}
+
 func (pd *Pagerduty) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
 	return fmt.Errorf("not supported")
 }
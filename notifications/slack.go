@@ -204,6 +204,31 @@ func (s *Slack) SendAlert(ctx context.Context, baseUrl string, n *db.AlertNotifi
 	return nil
 }
 
+func (s *Slack) SendAlertGroup(ctx context.Context, baseUrl string, g *db.AlertGroup) error {
+	title := alertGroupTitle(g)
+	status := g.Status()
+	header := fmt.Sprintf("<%s|*%s*>", alertGroupUrl(baseUrl, g), title)
+	if status != model.OK {
+		header = fmt.Sprintf("[%s] %s", strings.ToUpper(status.String()), header)
+	}
+	blocks := []slack.Block{
+		s.section(s.text("%s", header)),
+		s.section(s.text("%s", strings.Join(alertGroupLines(g), "\n"))),
+	}
+	ch := s.channel
+	opts := []slack.MsgOption{s.body(status.Color(), title, blocks...), slack.MsgOptionDisableLinkUnfurl()}
+	if parts := strings.Split(g.ExternalKey, ":"); len(parts) == 2 {
+		ch = parts[0]
+		opts = append(opts, slack.MsgOptionUpdate(parts[1]))
+	}
+	ch, ts, _, err := s.client.SendMessageContext(ctx, ch, opts...)
+	if err != nil {
+		return fmt.Errorf("slack error: %w", err)
+	}
+	g.ExternalKey = fmt.Sprintf("%s:%s", ch, ts)
+	return nil
+}
+
 func (s *Slack) body(color string, fallback string, blocks ...slack.Block) slack.MsgOption {
 	return slack.MsgOptionAttachments(slack.Attachment{
 		Color:    color,
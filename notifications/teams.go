@@ -127,6 +127,29 @@ func (t *Teams) SendAlert(ctx context.Context, baseUrl string, n *db.AlertNotifi
 	return nil
 }
 
+func (t *Teams) SendAlertGroup(ctx context.Context, baseUrl string, g *db.AlertGroup) error {
+	title := fmt.Sprintf("**%s**", alertGroupTitle(g))
+	if status := g.Status(); status != model.OK {
+		title = fmt.Sprintf("[%s] %s", strings.ToUpper(status.String()), title)
+	}
+	card, err := adaptivecard.NewTextBlockCard(strings.Join(alertGroupLines(g), "\n\n"), title, true)
+	if err != nil {
+		return err
+	}
+	action, err := adaptivecard.NewActionOpenURL(alertGroupUrl(baseUrl, g), "View alerts")
+	if err != nil {
+		return err
+	}
+	if err = card.AddAction(true, action); err != nil {
+		return err
+	}
+	msg, err := adaptivecard.NewMessageFromCard(card)
+	if err != nil {
+		return err
+	}
+	return t.client.SendWithContext(ctx, t.webhookUrl, msg)
+}
+
 func (t *Teams) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
 	d := ds.Deployment
 
@@ -0,0 +1,119 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/utils"
+)
+
+const (
+	telegramApiUrl           = "https://api.telegram.org"
+	telegramMaxFieldLength   = 1000
+	telegramMaxMessageLength = 4096 // in UTF-16 code units: https://core.telegram.org/bots/api#sendmessage
+)
+
+type Telegram struct {
+	apiUrl string
+	token  string
+	chatId string
+}
+
+func NewTelegram(token, chatId string) *Telegram {
+	return &Telegram{apiUrl: telegramApiUrl, token: token, chatId: chatId}
+}
+
+func (t *Telegram) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	return t.send(ctx, incidentMessage(baseUrl, n))
+}
+
+func (t *Telegram) SendAlert(ctx context.Context, baseUrl string, n *db.AlertNotification) error {
+	return t.send(ctx, alertMessage(baseUrl, n))
+}
+
+func (t *Telegram) SendAlertGroup(ctx context.Context, baseUrl string, g *db.AlertGroup) error {
+	return t.send(ctx, alertGroupMessage(baseUrl, g))
+}
+
+func (t *Telegram) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
+	m, ok := deploymentMessage(project, ds)
+	if !ok {
+		return nil
+	}
+	return t.send(ctx, m)
+}
+
+func (t *Telegram) send(ctx context.Context, m message) error {
+	// Telegram doesn't support colors, so the status is shown with an emoji
+	header := m.Emoji() + " "
+	if m.Status != "" {
+		header += fmt.Sprintf("[%s] ", m.Status)
+	}
+	header += fmt.Sprintf(`<a href="%s"><b>%s</b></a>`, html.EscapeString(m.Link), html.EscapeString(utils.Truncate(m.Title, telegramMaxFieldLength)))
+	lines := []string{header}
+	if len(m.Items) > 0 {
+		lines = append(lines, "")
+		for _, i := range m.Items {
+			lines = append(lines, "• "+html.EscapeString(i))
+		}
+	}
+	if len(m.Fields) > 0 {
+		lines = append(lines, "")
+		for _, f := range m.Fields {
+			value := html.EscapeString(utils.Truncate(f.Value, telegramMaxFieldLength))
+			if f.Code {
+				lines = append(lines, fmt.Sprintf("<b>%s</b>:\n<pre>%s</pre>", html.EscapeString(f.Name), value))
+			} else {
+				lines = append(lines, fmt.Sprintf("<b>%s</b>: %s", html.EscapeString(f.Name), value))
+			}
+		}
+	}
+	payload := map[string]any{
+		"chat_id":                  t.chatId,
+		"text":                     telegramText(lines),
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}
+	if err := postJson(ctx, fmt.Sprintf("%s/bot%s/sendMessage", t.apiUrl, t.token), payload); err != nil {
+		// the request URL contains the bot token, so it must not end up in the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = fmt.Errorf("%s %s/bot<hidden>/sendMessage: %w", urlErr.Op, t.apiUrl, urlErr.Err)
+		}
+		return fmt.Errorf("telegram error: %w", err)
+	}
+	return nil
+}
+
+// telegramText joins the lines, dropping the ones that don't fit the message length limit.
+// Lines are never cut, so that the HTML markup stays valid.
+func telegramText(lines []string) string {
+	const ellipsis = "\n…"
+	limit := telegramMaxMessageLength - telegramTextLength(ellipsis)
+	var b strings.Builder
+	length := 0
+	for i, l := range lines {
+		if i > 0 {
+			l = "\n" + l
+		}
+		n := telegramTextLength(l)
+		if length+n > limit {
+			b.WriteString(ellipsis)
+			break
+		}
+		b.WriteString(l)
+		length += n
+	}
+	return b.String()
+}
+
+func telegramTextLength(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
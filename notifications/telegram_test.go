@@ -0,0 +1,72 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTelegramToken = "123456:SECRET-TOKEN"
+
+func TestTelegramSend(t *testing.T) {
+	srv, requests := webhookServer(t, http.StatusOK)
+	tg := NewTelegram(testTelegramToken, "-100")
+	tg.apiUrl = srv.URL
+	require.NoError(t, tg.SendAlert(context.Background(), "http://coroot", testAlertNotification()))
+
+	r := <-requests
+	assert.Equal(t, "/bot"+testTelegramToken+"/sendMessage", r.path)
+	assert.Equal(t, "application/json", r.contentType)
+	assert.Equal(t, "-100", r.payload["chat_id"])
+	assert.Equal(t, "HTML", r.payload["parse_mode"])
+	assert.Equal(t,
+		"🔴 [CRITICAL] <a href=\"http://coroot/p/p1/alerts?alert=a1\"><b>api: p99 &gt; 1s</b></a>\n"+
+			"\n"+
+			"<b>Alerting rule</b>: High latency\n"+
+			"<b>Latency</b>: 1.5s\n"+
+			"<b>Query</b>:\n<pre>SELECT 1</pre>",
+		r.payload["text"])
+}
+
+func TestTelegramErrors(t *testing.T) {
+	srv, _ := webhookServer(t, http.StatusBadRequest)
+	tg := NewTelegram(testTelegramToken, "-100")
+	tg.apiUrl = srv.URL
+	err := tg.SendAlert(context.Background(), "http://coroot", testAlertNotification())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400 Bad Request")
+	assert.Contains(t, err.Error(), "chat not found")
+	assert.NotContains(t, err.Error(), "SECRET")
+
+	down := httptest.NewServer(nil)
+	down.Close()
+	tg.apiUrl = down.URL
+	err = tg.SendAlert(context.Background(), "http://coroot", testAlertNotification())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "/bot<hidden>/sendMessage")
+	assert.NotContains(t, err.Error(), "SECRET")
+}
+
+func TestTelegramMessageLength(t *testing.T) {
+	srv, requests := webhookServer(t, http.StatusOK)
+	tg := NewTelegram(testTelegramToken, "-100")
+	tg.apiUrl = srv.URL
+	m := message{Title: strings.Repeat("t", 2000), Link: "http://coroot"}
+	for i := 0; i < 500; i++ {
+		m.Items = append(m.Items, fmt.Sprintf("alert %d 🔥 <firing>", i))
+	}
+	require.NoError(t, tg.send(context.Background(), m))
+
+	text := (<-requests).payload["text"].(string)
+	assert.LessOrEqual(t, telegramTextLength(text), telegramMaxMessageLength)
+	assert.True(t, strings.HasSuffix(text, "\n…"))
+	assert.Contains(t, text, strings.Repeat("t", telegramMaxFieldLength)+"…</b></a>")
+	assert.Contains(t, text, "• alert 0 🔥 &lt;firing&gt;\n")
+	assert.NotContains(t, text, "alert 499")
+}
@@ -50,6 +50,10 @@ type AlertTemplateValues struct {
 	Duration    string              `json:"duration,omitempty"`
 	ResolvedBy  string              `json:"resolved_by,omitempty"`
 	URL         string              `json:"url"`
+
+	// set for grouped alerts only
+	GroupLabels map[string]string     `json:"group_labels,omitempty"`
+	Alerts      []AlertTemplateValues `json:"alerts,omitempty"`
 }
 
 func NewWebhook(cfg *db.IntegrationWebhook) *Webhook {
@@ -160,6 +164,55 @@ func (wh *Webhook) SendAlert(ctx context.Context, baseUrl string, n *db.AlertNot
 	return wh.send(ctx, data.Bytes())
 }
 
+// SendAlertGroup renders the alert template with the group summary in place of a single alert.
+// The members of the group are available to the template as .Alerts.
+func (wh *Webhook) SendAlertGroup(ctx context.Context, baseUrl string, g *db.AlertGroup) error {
+	if wh.cfg.AlertTemplate == "" {
+		return nil
+	}
+	tmpl, err := template.New("alertTemplate").Funcs(templateFunctions).Parse(wh.cfg.AlertTemplate)
+	if err != nil {
+		return fmt.Errorf("invalid alert template: %s", err)
+	}
+
+	status := g.Status()
+	values := AlertTemplateValues{
+		Status:      strings.ToUpper(status.String()),
+		Severity:    status.String(),
+		Summary:     alertGroupTitle(g),
+		URL:         alertGroupUrl(baseUrl, g),
+		GroupLabels: g.Labels,
+	}
+	for _, m := range g.SortedMembers() {
+		mv := AlertTemplateValues{
+			Status:      strings.ToUpper(m.Status.String()),
+			Application: m.ApplicationId,
+			URL:         alertUrl(baseUrl, &db.AlertNotification{ProjectId: g.ProjectId, AlertId: m.AlertId}),
+		}
+		if m.Resolved() {
+			mv.Status = strings.ToUpper(model.OK.String())
+		}
+		if m.Details != nil {
+			values.ProjectName = m.Details.ProjectName
+			mv.ProjectName = m.Details.ProjectName
+			mv.RuleName = m.Details.RuleName
+			mv.Severity = m.Details.Severity
+			mv.Summary = m.Details.Summary
+			mv.Details = m.Details.Details
+			mv.Duration = m.Details.Duration
+			mv.ResolvedBy = m.Details.ResolvedBy
+		}
+		values.Alerts = append(values.Alerts, mv)
+	}
+	var data bytes.Buffer
+	err = tmpl.Execute(&data, mergeCustomFields(values, wh.cfg.CustomFields))
+	if err != nil {
+		return fmt.Errorf("invalid alert template: %s", err)
+	}
+
+	return wh.send(ctx, data.Bytes())
+}
+
 func (wh *Webhook) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
 	tmpl, err := template.New("deploymentTemplate").Funcs(templateFunctions).Parse(wh.cfg.DeploymentTemplate)
 	if err != nil {
@@ -0,0 +1,312 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	discoveryPath   = "/.well-known/openid-configuration"
+	requestTimeout  = 10 * time.Second
+	jwksMinInterval = time.Minute
+)
+
+var (
+	DefaultScopes = []string{"openid", "email", "profile"}
+
+	ErrNoEmail = errors.New("the ID token contains no email claim")
+
+	signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+)
+
+type Config struct {
+	IssuerURL    string
+	ClientId     string
+	ClientSecret string
+	Scopes       []string
+}
+
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+type Provider struct {
+	cfg        Config
+	metadata   Metadata
+	httpClient *http.Client
+
+	lock          sync.Mutex
+	keys          map[string]any
+	keysFetchedAt time.Time
+}
+
+// Discover fetches the provider metadata from the issuer's well-known endpoint.
+func Discover(ctx context.Context, cfg Config, httpClient *http.Client) (*Provider, error) {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: requestTimeout}
+	}
+	issuer := strings.TrimRight(cfg.IssuerURL, "/")
+	p := &Provider{cfg: cfg, httpClient: httpClient}
+	if err := p.getJson(ctx, issuer+discoveryPath, &p.metadata); err != nil {
+		return nil, fmt.Errorf("discovery failed: %w", err)
+	}
+	if strings.TrimRight(p.metadata.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("issuer mismatch: expected %s, got %s", issuer, p.metadata.Issuer)
+	}
+	if p.metadata.AuthorizationEndpoint == "" || p.metadata.TokenEndpoint == "" || p.metadata.JwksURI == "" {
+		return nil, fmt.Errorf("incomplete provider metadata")
+	}
+	return p, nil
+}
+
+func (p *Provider) Config() Config {
+	return p.cfg
+}
+
+func (p *Provider) Metadata() Metadata {
+	return p.metadata
+}
+
+// AuthCodeURL returns the URL of the provider's consent page for the authorization code flow with PKCE (S256).
+func (p *Provider) AuthCodeURL(redirectURL, state, nonce, codeVerifier string) string {
+	scopes := p.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientId)
+	q.Set("redirect_uri", redirectURL)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", CodeChallenge(codeVerifier))
+	q.Set("code_challenge_method", "S256")
+	sep := "?"
+	if strings.Contains(p.metadata.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.metadata.AuthorizationEndpoint + sep + q.Encode()
+}
+
+// Exchange trades the authorization code for tokens and returns the raw ID token.
+func (p *Provider) Exchange(ctx context.Context, redirectURL, code, codeVerifier string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.cfg.ClientId)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientId), url.QueryEscape(p.cfg.ClientSecret))
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	var tokens struct {
+		IdToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	_ = json.Unmarshal(body, &tokens)
+	if resp.StatusCode != http.StatusOK {
+		if tokens.Error != "" {
+			return "", fmt.Errorf("token exchange failed: %s: %s", tokens.Error, tokens.ErrorDescription)
+		}
+		return "", fmt.Errorf("token exchange failed: %s", resp.Status)
+	}
+	if tokens.IdToken == "" {
+		return "", fmt.Errorf("no id_token in the token response")
+	}
+	return tokens.IdToken, nil
+}
+
+// VerifyIDToken checks the signature of the ID token against the provider's JWKS,
+// as well as its issuer, audience, expiration, and nonce.
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIdToken, nonce string) (Claims, error) {
+	claims := Claims{}
+	_, err := jwt.ParseWithClaims(rawIdToken, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.getKey(ctx, kid)
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(p.metadata.Issuer),
+		jwt.WithAudience(p.cfg.ClientId),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if claims.String("nonce") != nonce {
+		return nil, fmt.Errorf("nonce mismatch")
+	}
+	return claims, nil
+}
+
+func (p *Provider) getKey(ctx context.Context, kid string) (any, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if key := p.findKey(kid); key != nil {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < jwksMinInterval && p.keys != nil {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+	var set jsonWebKeySet
+	if err := p.getJson(ctx, p.metadata.JwksURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	p.keys = set.publicKeys()
+	p.keysFetchedAt = time.Now()
+	if key := p.findKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key id: %s", kid)
+}
+
+func (p *Provider) findKey(kid string) any {
+	if kid != "" {
+		return p.keys[kid]
+	}
+	if len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k
+		}
+	}
+	return nil
+}
+
+func (p *Provider) getJson(ctx context.Context, u string, dest any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", u, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(dest)
+}
+
+type Claims map[string]any
+
+func (c Claims) GetExpirationTime() (*jwt.NumericDate, error) {
+	return jwt.MapClaims(c).GetExpirationTime()
+}
+
+func (c Claims) GetIssuedAt() (*jwt.NumericDate, error) {
+	return jwt.MapClaims(c).GetIssuedAt()
+}
+
+func (c Claims) GetNotBefore() (*jwt.NumericDate, error) {
+	return jwt.MapClaims(c).GetNotBefore()
+}
+
+func (c Claims) GetIssuer() (string, error) {
+	return jwt.MapClaims(c).GetIssuer()
+}
+
+func (c Claims) GetSubject() (string, error) {
+	return jwt.MapClaims(c).GetSubject()
+}
+
+func (c Claims) GetAudience() (jwt.ClaimStrings, error) {
+	return jwt.MapClaims(c).GetAudience()
+}
+
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Strings returns the values of a claim that can be either a string or a list of strings (e.g., groups).
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return strings.Fields(strings.ReplaceAll(v, ",", " "))
+	case []any:
+		res := make([]string, 0, len(v))
+		for _, i := range v {
+			if s, ok := i.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+func (c Claims) Email() string {
+	return c.String("email")
+}
+
+// EmailVerified reports whether the identity provider has explicitly verified the email.
+// Some providers (e.g., AWS Cognito) send the claim as a string.
+func (c Claims) EmailVerified() bool {
+	switch v := c["email_verified"].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+func (c Claims) Name() string {
+	if name := c.String("name"); name != "" {
+		return name
+	}
+	name := strings.TrimSpace(c.String("given_name") + " " + c.String("family_name"))
+	if name != "" {
+		return name
+	}
+	return c.String("preferred_username")
+}
+
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func CodeChallenge(verifier string) string {
+	h := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
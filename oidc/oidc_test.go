@@ -0,0 +1,180 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockIssuer struct {
+	*httptest.Server
+	key      *rsa.PrivateKey
+	clientId string
+	secret   string
+	claims   jwt.MapClaims
+	codes    map[string]url.Values
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	m := &mockIssuer{key: key, clientId: "coroot", secret: "secret", codes: map[string]url.Values{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Metadata{
+			Issuer:                m.URL,
+			AuthorizationEndpoint: m.URL + "/authorize",
+			TokenEndpoint:         m.URL + "/token",
+			JwksURI:               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jsonWebKeySet{Keys: []jsonWebKey{{
+			Kid: "k1",
+			Kty: "RSA",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if user != m.clientId || pass != m.secret {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		_ = r.ParseForm()
+		auth := m.codes[r.Form.Get("code")]
+		if auth == nil || CodeChallenge(r.Form.Get("code_verifier")) != auth.Get("code_challenge") || r.Form.Get("redirect_uri") != auth.Get("redirect_uri") {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		claims := jwt.MapClaims{
+			"iss":   m.URL,
+			"aud":   m.clientId,
+			"sub":   "user-1",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": auth.Get("nonce"),
+		}
+		for k, v := range m.claims {
+			claims[k] = v
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "k1"
+		idToken, err := token.SignedString(key)
+		require.NoError(t, err)
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "at", "token_type": "Bearer", "id_token": idToken})
+	})
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+// authorize emulates the user's consent: it issues a code bound to the parameters of the authorization request.
+func (m *mockIssuer) authorize(t *testing.T, authCodeURL string) string {
+	u, err := url.Parse(authCodeURL)
+	require.NoError(t, err)
+	assert.Equal(t, m.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+	code := "code-" + u.Query().Get("state")
+	m.codes[code] = u.Query()
+	return code
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	issuer := newMockIssuer(t)
+	issuer.claims = jwt.MapClaims{"email": "john@example.com", "given_name": "John", "family_name": "Doe", "groups": []string{"devs", "ops"}}
+
+	p, err := Discover(ctx, Config{IssuerURL: issuer.URL + "/", ClientId: "coroot", ClientSecret: "secret"}, nil)
+	require.NoError(t, err)
+
+	redirectURL := "http://coroot.local/sso/oidc"
+	verifier, _ := RandomString()
+	code := issuer.authorize(t, p.AuthCodeURL(redirectURL, "s1", "n1", verifier))
+
+	_, err = p.Exchange(ctx, redirectURL, code, "wrong-verifier")
+	assert.ErrorContains(t, err, "invalid_grant")
+
+	idToken, err := p.Exchange(ctx, redirectURL, code, verifier)
+	require.NoError(t, err)
+
+	_, err = p.VerifyIDToken(ctx, idToken, "n2")
+	assert.ErrorContains(t, err, "nonce mismatch")
+
+	claims, err := p.VerifyIDToken(ctx, idToken, "n1")
+	require.NoError(t, err)
+	assert.Equal(t, "john@example.com", claims.Email())
+	assert.Equal(t, "John Doe", claims.Name())
+	assert.Equal(t, []string{"devs", "ops"}, claims.Strings("groups"))
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	ctx := context.Background()
+	issuer := newMockIssuer(t)
+	p, err := Discover(ctx, Config{IssuerURL: issuer.URL, ClientId: "coroot"}, nil)
+	require.NoError(t, err)
+
+	sign := func(claims jwt.MapClaims, key *rsa.PrivateKey) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "k1"
+		s, err := token.SignedString(key)
+		require.NoError(t, err)
+		return s
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	_, err = p.VerifyIDToken(ctx, sign(jwt.MapClaims{"iss": issuer.URL, "aud": "coroot", "exp": exp}, issuer.key), "")
+	assert.NoError(t, err)
+
+	_, err = p.VerifyIDToken(ctx, sign(jwt.MapClaims{"iss": issuer.URL, "aud": "other", "exp": exp}, issuer.key), "")
+	assert.Error(t, err)
+
+	_, err = p.VerifyIDToken(ctx, sign(jwt.MapClaims{"iss": "https://evil", "aud": "coroot", "exp": exp}, issuer.key), "")
+	assert.Error(t, err)
+
+	_, err = p.VerifyIDToken(ctx, sign(jwt.MapClaims{"iss": issuer.URL, "aud": "coroot", "exp": time.Now().Add(-time.Hour).Unix()}, issuer.key), "")
+	assert.Error(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = p.VerifyIDToken(ctx, sign(jwt.MapClaims{"iss": issuer.URL, "aud": "coroot", "exp": exp}, otherKey), "")
+	assert.Error(t, err)
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"iss": issuer.URL, "aud": "coroot", "exp": exp}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	_, err = p.VerifyIDToken(ctx, unsigned, "")
+	assert.Error(t, err)
+}
+
+func TestClaimsEmailVerified(t *testing.T) {
+	assert.True(t, Claims{"email_verified": true}.EmailVerified())
+	assert.True(t, Claims{"email_verified": "true"}.EmailVerified())
+	assert.False(t, Claims{"email_verified": false}.EmailVerified())
+	assert.False(t, Claims{"email_verified": "false"}.EmailVerified())
+	assert.False(t, Claims{}.EmailVerified(), "a missing claim means the email is not verified")
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	issuer := newMockIssuer(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, issuer.URL+r.URL.Path, http.StatusFound)
+	}))
+	defer srv.Close()
+	_, err := Discover(context.Background(), Config{IssuerURL: srv.URL, ClientId: "coroot"}, nil)
+	assert.ErrorContains(t, err, "issuer mismatch")
+}
@@ -13,6 +13,7 @@ const (
 	ScopeSettings                     Scope = "settings"
 	ScopeUsers                        Scope = "users"
 	ScopeRoles                        Scope = "roles"
+	ScopeAudit                        Scope = "audit"
 	ScopeProjectAll                   Scope = "project.*"
 	ScopeProjectSettings              Scope = "project.settings"
 	ScopeProjectIntegrations          Scope = "project.integrations"
@@ -35,6 +36,18 @@ const (
 	ScopeProjectAlerts                Scope = "project.alerts"
 )
 
+var (
+	ObjectKeys = []string{
+		"project_id",
+		"application_category",
+		"application_namespace",
+		"application_kind",
+		"application_name",
+		"node_name",
+		"dashboard_name",
+	}
+)
+
 type Action struct {
 	Scope  Scope
 	Action Verb
@@ -22,6 +22,10 @@ func (as ActionSet) Roles() RolesActionSet {
 	return RolesActionSet{}
 }
 
+func (as ActionSet) Audit() AuditActionSet {
+	return AuditActionSet{}
+}
+
 func (as ActionSet) Project(id string) ProjectActionSet {
 	return ProjectActionSet{id: id}
 }
@@ -31,6 +35,7 @@ func (as ActionSet) List() []Action {
 		as.Settings().Edit(),
 		as.Users().Edit(),
 		as.Roles().Edit(),
+		as.Audit().View(),
 	},
 		as.Project("").List()...)
 }
@@ -53,6 +58,12 @@ func (as RolesActionSet) Edit() Action {
 	return NewAction(ScopeRoles, ActionEdit, nil)
 }
 
+type AuditActionSet struct{}
+
+func (as AuditActionSet) View() Action {
+	return NewAction(ScopeAudit, ActionView, nil)
+}
+
 type ProjectActionSet struct {
 	id string
 }
@@ -70,8 +81,8 @@ func (as ProjectActionSet) List() []Action {
 		as.CustomCloudPricing().Edit(),
 		as.Inspections().Edit(),
 		as.Instrumentations().Edit(),
-		as.Traces().View(),
-		as.Logs().View(),
+		as.Application("*", "*", "*", "*").Traces().View(),
+		as.Application("*", "*", "*", "*").Logs().View(),
 		as.Costs().View(),
 		as.Anomalies().View(),
 		as.Risks().View(),
@@ -215,6 +226,23 @@ func (as ApplicationActionSet) View() Action {
 	return NewAction(ScopeApplication, ActionView, as.object())
 }
 
+func (as ApplicationActionSet) Traces() ApplicationViewAction {
+	return ApplicationViewAction{application: &as, scope: ScopeProjectTraces}
+}
+
+func (as ApplicationActionSet) Logs() ApplicationViewAction {
+	return ApplicationViewAction{application: &as, scope: ScopeProjectLogs}
+}
+
+type ApplicationViewAction struct {
+	application *ApplicationActionSet
+	scope       Scope
+}
+
+func (as ApplicationViewAction) View() Action {
+	return NewAction(as.scope, ActionView, as.application.object())
+}
+
 type NodeActionSet struct {
 	project *ProjectActionSet
 	name    string
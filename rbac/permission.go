@@ -1,6 +1,9 @@
 package rbac
 
 import (
+	"fmt"
+	"slices"
+
 	"github.com/coroot/coroot/utils"
 )
 
@@ -14,8 +17,36 @@ func NewPermission(scope Scope, action Verb, object Object) Permission {
 	return Permission{Scope: scope, Action: action, Object: object}
 }
 
+func (p Permission) Validate() error {
+	if p.Scope == "" || !utils.GlobValidate([]string{string(p.Scope)}) {
+		return fmt.Errorf("invalid scope: '%s'", p.Scope)
+	}
+	if !slices.Contains([]Verb{ActionAll, ActionView, ActionEdit}, p.Action) {
+		return fmt.Errorf("invalid action: '%s'", p.Action)
+	}
+	for k, v := range p.Object {
+		if !slices.Contains(ObjectKeys, k) {
+			return fmt.Errorf("unknown object selector: '%s'", k)
+		}
+		if !utils.GlobValidate([]string{v}) {
+			return fmt.Errorf("invalid pattern for '%s': '%s'", k, v)
+		}
+	}
+	return nil
+}
+
+// matchesScope reports whether the permission covers the scope of the action.
+// The audit log is only covered by full access or an explicit audit permission,
+// so roles that can view everything (like Viewer and Editor) can't read it.
+func (p Permission) matchesScope(action Action) bool {
+	if action.Scope == ScopeAudit && p.Scope != ScopeAudit && p.Action != ActionAll {
+		return false
+	}
+	return utils.GlobMatch(string(action.Scope), string(p.Scope))
+}
+
 func (p Permission) allows(action Action) bool {
-	if !utils.GlobMatch(string(action.Scope), string(p.Scope)) {
+	if !p.matchesScope(action) {
 		return false
 	}
 	if !utils.GlobMatch(string(action.Action), string(p.Action)) {
@@ -40,7 +71,7 @@ func (p Permission) allows(action Action) bool {
 }
 
 func (p Permission) allowsForObject(action Action) (bool, Object) {
-	if !utils.GlobMatch(string(action.Scope), string(p.Scope)) {
+	if !p.matchesScope(action) {
 		return false, nil
 	}
 	if !utils.GlobMatch(string(action.Action), string(p.Action)) {
@@ -76,6 +107,28 @@ func (ps PermissionSet) Allows(action Action) bool {
 	return false
 }
 
+// AllowsUnrestricted reports whether the action is allowed for any value of the object keys set to "*" in the action.
+// For example, it can be used to check whether a role that allows viewing logs only for certain applications
+// can run a project-wide log query.
+func (ps PermissionSet) AllowsUnrestricted(action Action) bool {
+	for _, p := range ps {
+		if !p.allows(action) {
+			continue
+		}
+		restricted := false
+		for k, av := range action.Object {
+			if pv, ok := p.Object[k]; ok && av == "*" && pv != "*" {
+				restricted = true
+				break
+			}
+		}
+		if !restricted {
+			return true
+		}
+	}
+	return false
+}
+
 func (ps PermissionSet) AllowsForObjects(action Action) []Object {
 	var objects []Object
 	for _, p := range ps {
@@ -43,3 +43,47 @@ func TestPermissions(t *testing.T) {
 	assert.False(t, p.allows(Actions.Project("foo").Node("foo").View()))
 	assert.False(t, p.allows(Actions.Project("bar").Node("bar").View()))
 }
+
+func TestApplicationSelectors(t *testing.T) {
+	ps := PermissionSet{
+		NewPermission(ScopeProjectLogs, ActionView, Object{"application_namespace": "payments"}),
+		NewPermission(ScopeProjectTraces, ActionView, Object{"application_namespace": "payments", "application_kind": "Deployment"}),
+	}
+	assert.True(t, ps.Allows(Actions.Project("p1").Application("application", "payments", "Deployment", "api").Logs().View()))
+	assert.False(t, ps.Allows(Actions.Project("p1").Application("application", "default", "Deployment", "api").Logs().View()))
+	assert.True(t, ps.Allows(Actions.Project("p1").Application("application", "payments", "Deployment", "api").Traces().View()))
+	assert.False(t, ps.Allows(Actions.Project("p1").Application("application", "payments", "StatefulSet", "db").Traces().View()))
+	assert.False(t, ps.Allows(Actions.Project("p1").Application("application", "payments", "Deployment", "api").View()))
+
+	all := Actions.Project("p1").Application("*", "*", "*", "*")
+	assert.True(t, ps.Allows(all.Logs().View()))
+	assert.False(t, ps.AllowsUnrestricted(all.Logs().View()))
+	assert.Equal(t, []Object{{"application_namespace": "payments"}}, ps.AllowsForObjects(all.Logs().View()))
+
+	assert.True(t, PermissionSet{NewPermission(ScopeAll, ActionView, nil)}.AllowsUnrestricted(all.Logs().View()))
+	assert.True(t, PermissionSet{NewPermission(ScopeProjectAll, ActionAll, Object{"project_id": "p1"})}.AllowsUnrestricted(all.Logs().View()))
+	assert.False(t, PermissionSet{NewPermission(ScopeProjectAll, ActionAll, Object{"project_id": "p2"})}.AllowsUnrestricted(all.Logs().View()))
+}
+
+func TestRoleValidate(t *testing.T) {
+	assert.NoError(t, NewRole("payments", NewPermission(ScopeProjectLogs, ActionView, Object{"application_namespace": "pay*"})).Validate())
+	assert.Error(t, NewRole("", NewPermission(ScopeProjectLogs, ActionView, nil)).Validate())
+	assert.Error(t, NewRole("payments").Validate())
+	assert.Error(t, NewRole("payments", NewPermission(ScopeProjectLogs, "delete", nil)).Validate())
+	assert.Error(t, NewRole("payments", NewPermission(ScopeProjectLogs, ActionView, Object{"pod": "x"})).Validate())
+	assert.Error(t, NewRole("payments", NewPermission(ScopeProjectLogs, ActionView, Object{"application_name": "[x"})).Validate())
+}
+
+func TestAuditLogAccess(t *testing.T) {
+	roles := map[RoleName]PermissionSet{}
+	for _, r := range Roles {
+		roles[r.Name] = r.Permissions
+	}
+	assert.True(t, roles[RoleAdmin].Allows(Actions.Audit().View()))
+	assert.False(t, roles[RoleEditor].Allows(Actions.Audit().View()))
+	assert.False(t, roles[RoleViewer].Allows(Actions.Audit().View()))
+	assert.True(t, roles[RoleViewer].Allows(Actions.Project("p1").Node("n1").View()))
+
+	assert.True(t, PermissionSet{NewPermission(ScopeAudit, ActionView, nil)}.Allows(Actions.Audit().View()))
+	assert.False(t, PermissionSet{NewPermission(ScopeAll, ActionView, nil)}.Allows(Actions.Audit().View()))
+}
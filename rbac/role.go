@@ -1,6 +1,8 @@
 package rbac
 
 import (
+	"errors"
+	"fmt"
 	"slices"
 )
 
@@ -51,10 +53,34 @@ func NewRole(name RoleName, permissions ...Permission) Role {
 	return Role{Name: name, Permissions: permissions}
 }
 
+func (r Role) Validate() error {
+	if r.Name == "" {
+		return errors.New("role name is required")
+	}
+	if len(r.Permissions) == 0 {
+		return errors.New("at least one permission is required")
+	}
+	for _, p := range r.Permissions {
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("invalid permission %s:%s: %w", p.Scope, p.Action, err)
+		}
+	}
+	return nil
+}
+
 type RoleManager interface {
 	GetRoles() ([]Role, error)
 }
 
+// EditableRoleManager is a RoleManager that allows managing custom roles.
+// Built-in roles cannot be modified.
+type EditableRoleManager interface {
+	RoleManager
+	AddRole(role Role) error
+	UpdateRole(name RoleName, role Role) error
+	DeleteRole(name RoleName) error
+}
+
 type StaticRoleManager struct{}
 
 func NewStaticRoleManager() *StaticRoleManager {
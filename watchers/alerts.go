@@ -56,6 +56,7 @@ type Alerts struct {
 	kubernetesEventEvaluator KubernetesEventEvaluator
 	globalPrometheus         *db.IntegrationPrometheus
 	globalClickHouse         *db.IntegrationClickhouse
+	silences                 model.Silences
 }
 
 func NewAlerts(database *db.DB, globalPrometheus *db.IntegrationPrometheus, globalClickHouse *db.IntegrationClickhouse, logPatternEvaluator LogPatternEvaluator, kubernetesEventEvaluator KubernetesEventEvaluator) *Alerts {
@@ -82,6 +83,11 @@ func (w *Alerts) Check(project *db.Project, world *model.World, from, to timeser
 	now := timeseries.Now()
 	var alertsEvaluated int
 
+	w.silences, err = w.db.GetActiveSilences(project.Id, now)
+	if err != nil {
+		klog.Errorln("failed to get silences:", err)
+	}
+
 	projectKey := string(project.Id)
 	if !w.initializedProjects[projectKey] {
 		hasAlerts, err := w.db.HasAlerts(project.Id)
@@ -201,6 +207,7 @@ func (w *Alerts) evaluateCheckAlert(project *db.Project, rule *model.AlertingRul
 				Details:             details,
 				Report:              report,
 			}
+			w.silence(alert, now)
 			if err := w.db.CreateAlert(project.Id, alert); err != nil {
 				klog.Errorln("failed to create alert:", err)
 			} else {
@@ -210,8 +217,11 @@ func (w *Alerts) evaluateCheckAlert(project *db.Project, rule *model.AlertingRul
 			existingAlert.Severity = severity
 			existingAlert.Summary = summary
 			existingAlert.Details = details
+			announce := w.unsilence(existingAlert, now)
 			if err := w.db.UpdateAlert(project.Id, existingAlert); err != nil {
 				klog.Errorln("failed to update alert:", err)
+			} else if announce {
+				w.notifier.Enqueue(project, app, existingAlert, rule, now)
 			}
 		}
 	} else {
@@ -329,8 +339,11 @@ func (w *Alerts) evaluateLogPatternAlerts(project *db.Project, rule *model.Alert
 							}
 						}
 						blockingAlert.Details = updatedDetails
+						announce := w.unsilence(blockingAlert, now)
 						if err := w.db.UpdateAlert(project.Id, blockingAlert); err != nil {
 							klog.Errorln("failed to update log pattern alert:", err)
+						} else if announce {
+							w.notifier.Enqueue(project, app, blockingAlert, rule, now)
 						}
 					}
 					continue
@@ -385,6 +398,7 @@ func (w *Alerts) evaluateLogPatternAlerts(project *db.Project, rule *model.Alert
 					Suppressed:          aiSuppressed,
 					ResolvedBy:          resolvedBy,
 				}
+				w.silence(alert, now)
 				if err := w.db.CreateAlert(project.Id, alert); err != nil {
 					klog.Errorln("failed to create log pattern alert:", err)
 				} else {
@@ -511,6 +525,7 @@ func (w *Alerts) evaluatePromQLAlerts(project *db.Project, rule *model.AlertingR
 				Summary:       summary,
 				Details:       details,
 			}
+			w.silence(alert, now)
 			if err := w.db.CreateAlert(project.Id, alert); err != nil {
 				klog.Errorln("failed to create PromQL alert:", err)
 			} else {
@@ -520,8 +535,11 @@ func (w *Alerts) evaluatePromQLAlerts(project *db.Project, rule *model.AlertingR
 			existingAlert.Severity = severity
 			existingAlert.Summary = summary
 			existingAlert.Details = details
+			announce := w.unsilence(existingAlert, now)
 			if err := w.db.UpdateAlert(project.Id, existingAlert); err != nil {
 				klog.Errorln("failed to update PromQL alert:", err)
+			} else if announce {
+				w.notifier.Enqueue(project, nil, existingAlert, rule, now)
 			}
 		}
 	}
@@ -800,8 +818,11 @@ func (w *Alerts) evaluateKubernetesEventsAlerts(project *db.Project, rule *model
 			existingAlert.Severity = severity
 			existingAlert.Summary = summary
 			existingAlert.Details = details
+			announce := w.unsilence(existingAlert, now)
 			if err := w.db.UpdateAlert(project.Id, existingAlert); err != nil {
 				klog.Errorln("failed to update k8s event alert:", err)
+			} else if announce {
+				w.notifier.Enqueue(project, g.app, existingAlert, rule, now)
 			}
 			continue
 		}
@@ -856,6 +877,7 @@ func (w *Alerts) evaluateKubernetesEventsAlerts(project *db.Project, rule *model
 			Suppressed:          aiSuppressed,
 			ResolvedBy:          resolvedBy,
 		}
+		w.silence(alert, now)
 		if err := w.db.CreateAlert(project.Id, alert); err != nil {
 			klog.Errorln("failed to create k8s event alert:", err)
 		} else {
@@ -986,6 +1008,30 @@ func (w *Alerts) resolveNonMatchingAlerts(project *db.Project, rule *model.Alert
 	}
 }
 
+// silence marks the alert as silenced if it matches an active silence.
+// Silenced alerts are stored and shown as usual, but no notifications are sent for them.
+func (w *Alerts) silence(alert *model.Alert, now timeseries.Time) {
+	if s := w.silences.FindForAlert(alert, now); s != nil {
+		klog.Infof("%s: alert %s of rule %s is silenced by %s", alert.ProjectId, alert.Fingerprint, alert.RuleId, s.Id)
+		alert.SilenceId = s.Id
+	}
+}
+
+// unsilence re-checks a silenced alert that is still firing and clears its silence marker
+// once no active silence matches it anymore. It returns true if the alert is due to be announced.
+func (w *Alerts) unsilence(alert *model.Alert, now timeseries.Time) bool {
+	if alert.SilenceId == "" {
+		return false
+	}
+	if s := w.silences.FindForAlert(alert, now); s != nil {
+		alert.SilenceId = s.Id
+		return false
+	}
+	klog.Infof("%s: the silence of alert %s of rule %s has ended", alert.ProjectId, alert.Fingerprint, alert.RuleId)
+	alert.SilenceId = ""
+	return true
+}
+
 func (w *Alerts) cleanupPendingAlerts(now timeseries.Time) {
 	threshold := timeseries.DurationFromStandard(30 * time.Minute)
 	for fingerprint, firstSeen := range w.pendingAlerts {
@@ -0,0 +1,48 @@
+package watchers
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertsSilence(t *testing.T) {
+	now := timeseries.Time(1000)
+	appId := model.NewApplicationId("c1", "default", model.ApplicationKindDeployment, "api")
+	w := &Alerts{silences: model.Silences{
+		{Id: "s1", StartsAt: now.Add(-timeseries.Minute), EndsAt: now.Add(timeseries.Hour), Matchers: model.SilenceMatchers{RuleIds: []model.AlertingRuleId{"cpu"}}},
+	}}
+
+	silenced := &model.Alert{RuleId: "cpu", ApplicationId: appId}
+	w.silence(silenced, now)
+	assert.Equal(t, "s1", silenced.SilenceId)
+
+	notSilenced := &model.Alert{RuleId: "memory", ApplicationId: appId}
+	w.silence(notSilenced, now)
+	assert.Empty(t, notSilenced.SilenceId)
+
+	expired := &model.Alert{RuleId: "cpu", ApplicationId: appId}
+	w.silence(expired, now.Add(2*timeseries.Hour))
+	assert.Empty(t, expired.SilenceId)
+}
+
+func TestAlertsUnsilence(t *testing.T) {
+	now := timeseries.Time(1000)
+	appId := model.NewApplicationId("c1", "default", model.ApplicationKindDeployment, "api")
+	w := &Alerts{silences: model.Silences{
+		{Id: "s1", StartsAt: now.Add(-timeseries.Minute), EndsAt: now.Add(timeseries.Hour), Matchers: model.SilenceMatchers{RuleIds: []model.AlertingRuleId{"cpu"}}},
+	}}
+
+	notified := &model.Alert{RuleId: "cpu", ApplicationId: appId}
+	assert.False(t, w.unsilence(notified, now), "alerts that weren't silenced have already been announced")
+
+	silenced := &model.Alert{RuleId: "cpu", ApplicationId: appId, SilenceId: "s1"}
+	assert.False(t, w.unsilence(silenced, now))
+	assert.Equal(t, "s1", silenced.SilenceId)
+
+	assert.True(t, w.unsilence(silenced, now.Add(2*timeseries.Hour)), "the alert is announced once the silence ends")
+	assert.Empty(t, silenced.SilenceId)
+	assert.False(t, w.unsilence(silenced, now.Add(2*timeseries.Hour)))
+}
@@ -2,6 +2,7 @@ package watchers
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/coroot/coroot/db"
@@ -16,12 +17,21 @@ type Incidents struct {
 	db       *db.DB
 	rca      IncidentRCA
 	notifier *notifications.IncidentNotifier
+
+	// apps for which an incident wasn't opened due to a maintenance window, used to log it only once
+	maintenance     map[db.ProjectId]map[model.ApplicationId]bool
+	maintenanceLock sync.Mutex
 }
 
 type IncidentRCA func(ctx context.Context, project *db.Project, world *model.World, incident *model.ApplicationIncident)
 
-func NewIncidents(db *db.DB, rca IncidentRCA) *Incidents {
-	return &Incidents{db: db, notifier: notifications.NewIncidentNotifier(db), rca: rca}
+func NewIncidents(database *db.DB, rca IncidentRCA) *Incidents {
+	return &Incidents{
+		db:          database,
+		notifier:    notifications.NewIncidentNotifier(database),
+		rca:         rca,
+		maintenance: map[db.ProjectId]map[model.ApplicationId]bool{},
+	}
 }
 
 func (w *Incidents) Check(project *db.Project, world *model.World) {
@@ -31,6 +41,11 @@ func (w *Incidents) Check(project *db.Project, world *model.World) {
 
 	now := timeseries.Now()
 
+	w.maintenanceLock.Lock()
+	inMaintenance := w.maintenance[project.Id]
+	w.maintenanceLock.Unlock()
+	stillInMaintenance := map[model.ApplicationId]bool{}
+
 	for _, app := range world.Applications {
 		var (
 			aBadF, aTotalF sumFromFunc
@@ -82,6 +97,12 @@ func (w *Incidents) Check(project *db.Project, world *model.World) {
 		switch {
 		case incident == nil && status <= model.OK:
 			continue
+		case incident == nil && project.InMaintenanceWindow(app.Category, now):
+			if !inMaintenance[app.Id] {
+				klog.Infof("%s: %s is in a maintenance window, not opening an incident", project.Id, app.Id)
+			}
+			stillInMaintenance[app.Id] = true
+			continue
 		case incident == nil:
 			incident = &model.ApplicationIncident{
 				ApplicationId: app.Id,
@@ -127,6 +148,10 @@ func (w *Incidents) Check(project *db.Project, world *model.World) {
 	}
 	w.resolveIncidentsForMissingApps(project, world, now)
 
+	w.maintenanceLock.Lock()
+	w.maintenance[project.Id] = stillInMaintenance
+	w.maintenanceLock.Unlock()
+
 	klog.Infof("%s: checked %d apps in %s", project.Id, apps, time.Since(start).Truncate(time.Millisecond))
 }
 